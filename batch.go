@@ -0,0 +1,133 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package geocatalogo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/repository"
+)
+
+// DefaultFlushInterval is the maximum time records are buffered by a
+// Batcher when not set in configuration
+const DefaultFlushInterval = 5 * time.Second
+
+// Batcher buffers metadata records and indexes them in batches, flushing
+// when the buffer reaches the configured batch size or when the flush
+// interval elapses, whichever comes first
+type Batcher struct {
+	catalogue *GeoCatalogue
+	size      int
+	records   []metadata.Record
+	result    repository.BulkResult
+	err       error
+	mutex     sync.Mutex
+	ticker    *time.Ticker
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewBatcher creates a Batcher using the repository batch size and
+// flush interval configuration
+func (c *GeoCatalogue) NewBatcher() *Batcher {
+	size := c.Config.Repository.BatchSize
+	if size <= 0 {
+		size = repository.DefaultBatchSize
+	}
+	interval := c.Config.Repository.FlushInterval
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+
+	b := &Batcher{
+		catalogue: c,
+		size:      size,
+		ticker:    time.NewTicker(interval),
+		done:      make(chan struct{}),
+	}
+
+	go func() {
+		for {
+			select {
+			case <-b.ticker.C:
+				b.Flush()
+			case <-b.done:
+				return
+			}
+		}
+	}()
+
+	return b
+}
+
+// Add buffers a record, flushing the buffer if it is full
+func (b *Batcher) Add(record metadata.Record) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.records = append(b.records, record)
+	if len(b.records) >= b.size {
+		b.flush()
+	}
+}
+
+// Flush indexes all buffered records
+func (b *Batcher) Flush() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.flush()
+}
+
+func (b *Batcher) flush() {
+	if len(b.records) == 0 {
+		return
+	}
+	result, err := b.catalogue.IndexBatch(b.records)
+	b.result.Add(result)
+	if err != nil && b.err == nil {
+		b.err = err
+	}
+	b.records = nil
+}
+
+// Close flushes any remaining records, stops the flush timer and returns
+// the accumulated result of all batches along with the first error
+// encountered, if any.  Closing a closed Batcher returns the same result
+func (b *Batcher) Close() (repository.BulkResult, error) {
+	b.closeOnce.Do(func() {
+		b.ticker.Stop()
+		close(b.done)
+		b.Flush()
+	})
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.result, b.err
+}
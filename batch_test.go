@@ -0,0 +1,33 @@
+package geocatalogo_test
+
+import (
+	"testing"
+
+	"github.com/go-spatial/geocatalogo"
+	"github.com/go-spatial/geocatalogo/config"
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+func TestBatcherClose(t *testing.T) {
+	var cfg config.Config
+	cfg.Repository.Type = "memory"
+	cfg.Repository.BatchSize = 2
+	cfg.Logging.Level = "ERROR"
+	cat, err := geocatalogo.New(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := cat.NewBatcher()
+	defer b.Close()
+	for _, id := range []string{"a", "b", "c"} {
+		b.Add(metadata.Record{Identifier: id, Type: "Feature"})
+	}
+	result, err := b.Close()
+	if err != nil || result.Indexed != 3 {
+		t.Fatalf("expected 3 indexed records, got %+v, %v", result, err)
+	}
+	if again, err := b.Close(); err != nil || again.Indexed != 3 {
+		t.Errorf("expected the same result when closing again, got %+v, %v", again, err)
+	}
+}
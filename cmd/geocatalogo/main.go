@@ -27,15 +27,19 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"flag"
@@ -44,8 +48,13 @@ import (
 
 	"github.com/go-spatial/geocatalogo"
 	"github.com/go-spatial/geocatalogo/config"
+	"github.com/go-spatial/geocatalogo/metadata"
 	"github.com/go-spatial/geocatalogo/metadata/parsers"
+	"github.com/go-spatial/geocatalogo/metadata/tabular"
+	"github.com/go-spatial/geocatalogo/metadata/writers"
 	"github.com/go-spatial/geocatalogo/repository"
+	"github.com/go-spatial/geocatalogo/search"
+	"github.com/go-spatial/geocatalogo/search/cql2"
 	"github.com/go-spatial/geocatalogo/web"
 )
 
@@ -64,8 +73,13 @@ func main() {
 		fmt.Println("Commands: ")
 		fmt.Println(" createindex: add a metadata record to the index")
 		fmt.Println(" index: add a metadata record to the index")
+		fmt.Println(" import-csv: add rows of a CSV/TSV file to the index with a column mapping")
+		fmt.Println(" update: replace a metadata record in the index")
+		fmt.Println(" delete: remove metadata records from the index")
+		fmt.Println(" collection: add, replace or remove a collection")
 		fmt.Println(" search: search the index")
 		fmt.Println(" get: get metadata record by id")
+		fmt.Println(" export: export metadata records (dc, iso19139, dcat, datacite)")
 		fmt.Println(" serve: run web server")
 		fmt.Println(" version: geocatalogo version")
 		return
@@ -76,21 +90,52 @@ func main() {
 	indexCommand := flag.NewFlagSet("index", flag.ExitOnError)
 	fileFlag := indexCommand.String("file", "", "Path to metadata file")
 	dirFlag := indexCommand.String("dir", "", "Path to directory of metadata files")
+	catalogFlag := indexCommand.String("catalog", "", "Path to static STAC catalog (catalog.json, or a Collection, ItemCollection or Item)")
+	formatFlag := indexCommand.String("format", "", "Metadata format ("+strings.Join(parsers.Names(), ", ")+"), detected if not set")
+
+	importCSVCommand := flag.NewFlagSet("import-csv", flag.ExitOnError)
+	importCSVFileFlag := importCSVCommand.String("file", "", "Path to CSV/TSV file (- for standard input)")
+	importCSVMappingFlag := importCSVCommand.String("mapping", "", "Path to column mapping file (YAML)")
+	importCSVDryRunFlag := importCSVCommand.Bool("dry-run", false, "Print records as JSON instead of indexing them")
+
+	updateCommand := flag.NewFlagSet("update", flag.ExitOnError)
+	updateFileFlag := updateCommand.String("file", "", "Path to metadata file (XML or geocatalogo JSON)")
+	updateFormatFlag := updateCommand.String("format", "", "Metadata format ("+strings.Join(parsers.Names(), ", ")+"), detected if not set")
+
+	deleteCommand := flag.NewFlagSet("delete", flag.ExitOnError)
+	deleteIdFlag := deleteCommand.String("id", "", "list of identifiers (comma-separated)")
+	deleteCollectionsFlag := deleteCommand.String("collections", "", "delete all records in collections (comma-separated)")
+	deleteSourcesFlag := deleteCommand.String("sources", "", "delete all records from sources (comma-separated)")
+
+	collectionCommand := flag.NewFlagSet("collection", flag.ExitOnError)
+	collectionFileFlag := collectionCommand.String("file", "", "Path to collection file (JSON)")
+	collectionDeleteFlag := collectionCommand.String("delete", "", "Identifier of collection to remove")
 
 	searchCommand := flag.NewFlagSet("search", flag.ExitOnError)
 	collectionsFlag := searchCommand.String("collections", "", "Collections")
 	termFlag := searchCommand.String("term", "", "Search term(s)")
 	bboxFlag := searchCommand.String("bbox", "", "Bounding box (minx,miny,maxx,maxy)")
+	intersectsFlag := searchCommand.String("intersects", "", "GeoJSON geometry")
+	relationFlag := searchCommand.String("relation", "intersects", "Spatial relation to bbox or intersects (intersects, within, contains, disjoint)")
 	timeFlag := searchCommand.String("time", "", "Time (t1[,t2]), RFC3339 format")
+	filterFlag := searchCommand.String("filter", "", "CQL2 filter")
+	filterLangFlag := searchCommand.String("filter-lang", "cql2-text", "Filter language (cql2-text or cql2-json)")
+	sortbyFlag := searchCommand.String("sortby", "", "Sort fields (comma-separated, -field for descending)")
 	fromFlag := searchCommand.Int("from", 0, "Start position / offset (default=0)")
 	sizeFlag := searchCommand.Int("size", 10, "Number of results to return (default=10)")
 
 	getCommand := flag.NewFlagSet("get", flag.ExitOnError)
 	idFlag := getCommand.String("id", "", "list of identifiers (comma-separated)")
 
+	exportCommand := flag.NewFlagSet("export", flag.ExitOnError)
+	exportFormatFlag := exportCommand.String("format", "", "Output format ("+strings.Join(writers.Names(), ", ")+")")
+	exportIdFlag := exportCommand.String("id", "", "list of identifiers (comma-separated), else all records")
+	exportCollectionsFlag := exportCommand.String("collections", "", "export records in collections (comma-separated)")
+	exportDirFlag := exportCommand.String("dir", "", "Directory to write a file per record to, else standard output")
+
 	serveCommand := flag.NewFlagSet("serve", flag.ExitOnError)
 	portFlag := serveCommand.Int("port", 8000, "port")
-	apiFlag := serveCommand.String("api", "default", "API to serve (default, stac)")
+	apiFlag := serveCommand.String("api", "default", "API to serve (default, stac, records)")
 
 	versionCommand := flag.NewFlagSet("version", flag.ExitOnError)
 
@@ -99,10 +144,20 @@ func main() {
 		createIndexCommand.Parse(os.Args[2:])
 	case "index":
 		indexCommand.Parse(os.Args[2:])
+	case "import-csv":
+		importCSVCommand.Parse(os.Args[2:])
+	case "update":
+		updateCommand.Parse(os.Args[2:])
+	case "delete":
+		deleteCommand.Parse(os.Args[2:])
+	case "collection":
+		collectionCommand.Parse(os.Args[2:])
 	case "search":
 		searchCommand.Parse(os.Args[2:])
 	case "get":
 		getCommand.Parse(os.Args[2:])
+	case "export":
+		exportCommand.Parse(os.Args[2:])
 	case "serve":
 		serveCommand.Parse(os.Args[2:])
 	case "version":
@@ -140,16 +195,25 @@ func main() {
 		fmt.Println(err)
 		os.Exit(10002)
 	}
+	defer cat.Close()
 
 	if indexCommand.Parsed() {
-		if *fileFlag == "" && *dirFlag == "" {
-			fmt.Println("Please supply path to metadata file(s) via -file or -dir")
+		if *fileFlag == "" && *dirFlag == "" && *catalogFlag == "" {
+			fmt.Println("Please supply path to metadata file(s) via -file, -dir or -catalog")
 			os.Exit(10003)
 		}
-		if *fileFlag != "" && *dirFlag != "" {
-			fmt.Println("Only one of -file or -dir is allowed")
+		if *fileFlag != "" && *dirFlag != "" || *catalogFlag != "" && (*fileFlag != "" || *dirFlag != "") {
+			fmt.Println("Only one of -file, -dir or -catalog is allowed")
 			os.Exit(10004)
 		}
+		if _, ok := parsers.Lookup(*formatFlag); *formatFlag != "" && !ok {
+			fmt.Printf("Unknown format %q (one of %s)\n", *formatFlag, strings.Join(parsers.Names(), ", "))
+			os.Exit(10026)
+		}
+		if *catalogFlag != "" {
+			indexSTACCatalog(cat, *catalogFlag)
+			return
+		}
 		if *fileFlag != "" {
 			fileList = append(fileList, *fileFlag)
 		} else if *dirFlag != "" {
@@ -169,31 +233,223 @@ func main() {
 
 		fmt.Printf("Indexing %d file%s\n", len(fileList), plural)
 
+		start := time.Now()
+		batcher := cat.NewBatcher()
+		formatCounts := map[string]int{}
+		recordCount := 0
+		collectionCount := 0
+		failures := map[string]string{}
+
 		for _, file := range fileList {
-			start := time.Now()
-			parseStart := time.Now()
-			fmt.Printf("Indexing file %d of %d: %q\n", fileCounter, fileCount, file)
+			fmt.Printf("Parsing file %d of %d: %q\n", fileCounter, fileCount, file)
+			fileCounter++
 			source, err := ioutil.ReadFile(file)
 			if err != nil {
 				fmt.Printf("Could not read file: %s\n", err)
+				failures[file] = err.Error()
 				continue
 			}
-			metadataRecord, err := parsers.ParseCSWRecord(source)
+			records, collections, format, err := parseRecords(file, source, *formatFlag)
 			if err != nil {
 				fmt.Printf("Could not parse metadata: %s\n", err)
+				failures[file] = err.Error()
 				continue
 			}
-			parseElapsed := time.Since(parseStart)
-			indexStart := time.Now()
-			result := cat.Index(metadataRecord)
-			if !result {
-				fmt.Println("Error Indexing")
+			for _, collection := range collections {
+				if err := cat.PutCollection(collection); err != nil {
+					fmt.Printf("Error Indexing collection %s: %s\n", collection.Identifier, err)
+					failures[file] = err.Error()
+					continue
+				}
+				collectionCount++
 			}
-			indexElapsed := time.Since(indexStart)
-			elapsed := time.Since(start)
-			fmt.Printf("Function took %s (parse: %s, index: %s)\n", elapsed, parseElapsed, indexElapsed)
-			fileCounter++
+			formatCounts[format] += len(records)
+			recordCount += len(records)
+			for _, metadataRecord := range records {
+				batcher.Add(metadataRecord)
+			}
+		}
+
+		result, err := batcher.Close()
+		if err != nil {
+			fmt.Printf("Error Indexing: %s\n", err)
+		}
+		for _, e := range result.Errors {
+			fmt.Printf("Error Indexing %s: %s\n", e.Identifier, e.Reason)
+		}
+		fmt.Printf("Indexed %d of %d record(s) from %d file%s in %s\n", result.Indexed, recordCount, fileCount, plural, time.Since(start))
+		if collectionCount > 0 {
+			fmt.Printf("  collections: %d\n", collectionCount)
+		}
+		for _, name := range parsers.Names() {
+			if formatCounts[name] > 0 {
+				fmt.Printf("  %s: %d\n", name, formatCounts[name])
+			}
+		}
+		if len(failures) > 0 {
+			fmt.Printf("  failed: %d\n", len(failures))
+			for _, file := range fileList {
+				if reason, ok := failures[file]; ok {
+					fmt.Printf("    %s: %s\n", file, reason)
+				}
+			}
+		}
+	} else if importCSVCommand.Parsed() {
+		if *importCSVFileFlag == "" || *importCSVMappingFlag == "" {
+			fmt.Println("Please supply path to CSV/TSV file via -file and column mapping via -mapping")
+			os.Exit(10030)
+		}
+		mapping, err := tabular.LoadMapping(*importCSVMappingFlag)
+		if err != nil {
+			fmt.Printf("Invalid mapping: %s\n", err)
+			os.Exit(10031)
+		}
+		if mapping.Delimiter == "" && strings.EqualFold(filepath.Ext(*importCSVFileFlag), ".tsv") {
+			mapping.Delimiter = "tab"
+		}
+		var input io.Reader = os.Stdin
+		if *importCSVFileFlag != "-" {
+			f, err := os.Open(*importCSVFileFlag)
+			if err != nil {
+				fmt.Printf("Could not read file: %s\n", err)
+				os.Exit(10032)
+			}
+			defer f.Close()
+			input = f
+		}
+		reader, err := mapping.NewReader(input)
+		if err != nil {
+			fmt.Printf("Could not read header: %s\n", err)
+			os.Exit(10033)
+		}
+
+		start := time.Now()
+		batcher := cat.NewBatcher()
+		rowCount := 0
+		rowErrors := 0
+		for {
+			rec, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if _, ok := err.(*tabular.RowError); ok {
+				fmt.Fprintf(os.Stderr, "Skipping %s\n", err)
+				rowCount++
+				rowErrors++
+				continue
+			}
+			if err != nil {
+				fmt.Printf("Could not read file: %s\n", err)
+				break
+			}
+			rowCount++
+			if *importCSVDryRunFlag {
+				b, _ := json.Marshal(rec)
+				fmt.Printf("%s\n", b)
+				continue
+			}
+			batcher.Add(rec)
+		}
+
+		result, err := batcher.Close()
+		if err != nil {
+			fmt.Printf("Error Indexing: %s\n", err)
+		}
+		for _, e := range result.Errors {
+			fmt.Printf("Error Indexing %s: %s\n", e.Identifier, e.Reason)
+		}
+		if *importCSVDryRunFlag {
+			fmt.Fprintf(os.Stderr, "Read %d of %d row(s) (%d invalid) in %s\n", rowCount-rowErrors, rowCount, rowErrors, time.Since(start))
+		} else {
+			fmt.Printf("Indexed %d of %d row(s) (%d invalid) in %s\n", result.Indexed, rowCount, rowErrors, time.Since(start))
+		}
+	} else if updateCommand.Parsed() {
+		if *updateFileFlag == "" {
+			fmt.Println("Please supply path to metadata file via -file")
+			os.Exit(10010)
+		}
+		source, err := ioutil.ReadFile(*updateFileFlag)
+		if err != nil {
+			fmt.Printf("Could not read file: %s\n", err)
+			os.Exit(10011)
+		}
+		if _, ok := parsers.Lookup(*updateFormatFlag); *updateFormatFlag != "" && !ok {
+			fmt.Printf("Unknown format %q (one of %s)\n", *updateFormatFlag, strings.Join(parsers.Names(), ", "))
+			os.Exit(10026)
+		}
+		metadataRecord, _, err := parseRecord(*updateFileFlag, source, *updateFormatFlag)
+		if err != nil {
+			fmt.Printf("Could not parse metadata: %s\n", err)
+			os.Exit(10012)
+		}
+		if !cat.Update(metadataRecord) {
+			fmt.Println("Error Updating")
+			os.Exit(10013)
+		}
+		fmt.Printf("Updated %s\n", metadataRecord.Identifier)
+	} else if deleteCommand.Parsed() {
+		if *deleteIdFlag == "" && *deleteCollectionsFlag == "" && *deleteSourcesFlag == "" {
+			fmt.Println("Please supply one of -id, -collections or -sources")
+			os.Exit(10014)
+		}
+		if *deleteIdFlag != "" && (*deleteCollectionsFlag != "" || *deleteSourcesFlag != "") {
+			fmt.Println("-id cannot be combined with -collections or -sources")
+			os.Exit(10015)
+		}
+		if *deleteIdFlag != "" {
+			recordids := strings.Split(*deleteIdFlag, ",")
+			if !cat.UnIndex(recordids) {
+				fmt.Println("Error Deleting")
+				os.Exit(10016)
+			}
+			fmt.Printf("Deleted %d record(s)\n", len(recordids))
+		} else {
+			var sources []string
+			if *deleteCollectionsFlag != "" {
+				collections = strings.Split(*deleteCollectionsFlag, ",")
+			}
+			if *deleteSourcesFlag != "" {
+				sources = strings.Split(*deleteSourcesFlag, ",")
+			}
+			count, ok := cat.UnIndexByQuery(collections, sources)
+			if !ok {
+				fmt.Println("Error Deleting")
+				os.Exit(10016)
+			}
+			fmt.Printf("Deleted %d record(s)\n", count)
+		}
+	} else if collectionCommand.Parsed() {
+		if (*collectionFileFlag == "") == (*collectionDeleteFlag == "") {
+			fmt.Println("Please supply one of -file or -delete")
+			os.Exit(10021)
+		}
+		if *collectionDeleteFlag != "" {
+			if err := cat.DeleteCollection(*collectionDeleteFlag); err != nil {
+				fmt.Printf("Error Deleting collection: %s\n", err)
+				os.Exit(10022)
+			}
+			fmt.Printf("Deleted collection %s\n", *collectionDeleteFlag)
+			return
+		}
+		source, err := ioutil.ReadFile(*collectionFileFlag)
+		if err != nil {
+			fmt.Printf("Could not read file: %s\n", err)
+			os.Exit(10023)
+		}
+		var collection metadata.Collection
+		if err := json.Unmarshal(source, &collection); err != nil {
+			fmt.Printf("Could not parse collection: %s\n", err)
+			os.Exit(10024)
+		}
+		if collection.Identifier == "" {
+			fmt.Println("Collection has no id")
+			os.Exit(10024)
 		}
+		if err := cat.PutCollection(collection); err != nil {
+			fmt.Printf("Error Storing collection: %s\n", err)
+			os.Exit(10025)
+		}
+		fmt.Printf("Stored collection %s\n", collection.Identifier)
 	} else if searchCommand.Parsed() {
 		if *collectionsFlag != "" {
 			collections = strings.Split(*collectionsFlag, ",")
@@ -219,15 +475,70 @@ func main() {
 				timeVal = append(timeVal, timestep)
 			}
 		}
-		results := cat.Search(collections, *termFlag, bbox, timeVal, *fromFlag, *sizeFlag)
+		sortby, err := search.ParseSortBy(*sortbyFlag)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(10017)
+		}
+		var intersects *metadata.Geometry
+		if *intersectsFlag != "" {
+			intersects = &metadata.Geometry{}
+			if err := json.Unmarshal([]byte(*intersectsFlag), intersects); err != nil {
+				fmt.Printf("intersects error: %s\n", err)
+				os.Exit(10019)
+			}
+		}
+		relation, err := search.ParseRelation(*relationFlag)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(10020)
+		}
+		var filter cql2.Expr
+		if *filterFlag != "" {
+			filter, err = cql2.Parse(*filterFlag, *filterLangFlag)
+			if err == nil {
+				err = cql2.Validate(filter)
+			}
+			if err != nil {
+				fmt.Printf("filter error: %s\n", err)
+				os.Exit(10018)
+			}
+		}
+		results, err := cat.Query(context.Background(), search.Query{
+			Collections: collections,
+			Term:        *termFlag,
+			BBox:        bbox,
+			Intersects:  intersects,
+			Relation:    relation,
+			Time:        timeVal,
+			Filter:      filter,
+			SortBy:      sortby,
+			From:        *fromFlag,
+			Size:        *sizeFlag,
+		})
+		if err != nil {
+			fmt.Printf("search error: %s\n", err)
+			os.Exit(10034)
+		}
 		fmt.Printf("Found %d records\n", results.Matches)
 		for _, result := range results.Records {
 			fmt.Printf("    %s - %s\n", result.Identifier, result.Properties.Title)
 		}
 	} else if serveCommand.Parsed() {
+		hangup := make(chan os.Signal, 1)
+		signal.Notify(hangup, syscall.SIGHUP)
+		go func() {
+			for range hangup {
+				if err := cat.Reload(); err != nil {
+					fmt.Printf("Could not reload repository: %s\n", err)
+				}
+			}
+		}()
 		fmt.Printf("Serving on port %d\n", *portFlag)
 		if *apiFlag == "stac" {
 			router = web.STACRouter(cat)
+		} else if *apiFlag == "records" {
+			router = web.RecordsRouter(cat)
 		} else { // csw3-opensearch is the default
 			router = web.CSW3OpenSearchRouter(cat)
 		}
@@ -235,6 +546,75 @@ func main() {
 			fmt.Println(err)
 			os.Exit(10008)
 		}
+	} else if exportCommand.Parsed() {
+		writer, ok := writers.Lookup(*exportFormatFlag)
+		if !ok {
+			fmt.Printf("Please supply -format (one of %s)\n", strings.Join(writers.Names(), ", "))
+			os.Exit(10027)
+		}
+		if *exportDirFlag != "" {
+			if err := os.MkdirAll(*exportDirFlag, 0755); err != nil {
+				fmt.Printf("Could not create directory: %s\n", err)
+				os.Exit(10028)
+			}
+		}
+
+		count := 0
+		export := func(records []metadata.Record) {
+			for _, rec := range records {
+				data, err := writer.Marshal(&rec, true)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Could not export %s: %s\n", rec.Identifier, err)
+					continue
+				}
+				if *exportDirFlag == "" {
+					fmt.Printf("%s\n", data)
+				} else {
+					filename := filepath.Join(*exportDirFlag, exportFilename(rec.Identifier)+writer.Extension)
+					if err := ioutil.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+						fmt.Printf("Could not write file: %s\n", err)
+						os.Exit(10029)
+					}
+				}
+				count++
+			}
+		}
+
+		if *exportIdFlag != "" {
+			export(cat.Get(strings.Split(*exportIdFlag, ",")).Records)
+		} else {
+			if *exportCollectionsFlag != "" {
+				collections = strings.Split(*exportCollectionsFlag, ",")
+			}
+			// pages follow the last identifier exported rather than an
+			// offset, which the repository may limit (e.g. the ES
+			// max_result_window)
+			var after cql2.Expr
+			for {
+				results, err := cat.Query(context.Background(), search.Query{
+					Collections: collections,
+					Filter:      after,
+					SortBy:      []search.SortField{{Field: "id"}},
+					Size:        exportPageSize,
+				})
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Could not export records: %s\n", err)
+					os.Exit(10035)
+				}
+				export(results.Records)
+				if len(results.Records) == 0 || len(results.Records) >= results.Matches {
+					break
+				}
+				after = cql2.Comparison{
+					Op:    ">",
+					Left:  cql2.Property{Name: "id"},
+					Right: cql2.Literal{Value: results.Records[len(results.Records)-1].Identifier},
+				}
+			}
+		}
+		if *exportDirFlag != "" {
+			fmt.Printf("Exported %d record(s) to %s\n", count, *exportDirFlag)
+		}
 	} else if getCommand.Parsed() {
 		if *idFlag == "" {
 			fmt.Println("Please provide identifier")
@@ -249,3 +629,93 @@ func main() {
 	}
 	return
 }
+
+// exportPageSize is the number of records queried at a time by export
+const exportPageSize = 500
+
+// exportFilename makes an identifier safe as a file name
+func exportFilename(identifier string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, identifier)
+}
+
+// parseRecord parses a metadata file with the parser of format, else
+// the parser detected for it, returning the name of the parser
+func parseRecord(filename string, source []byte, format string) (metadata.Record, string, error) {
+	if format == "" {
+		return parsers.Parse(filename, source)
+	}
+	p, _ := parsers.Lookup(format)
+	metadataRecord, err := p.Parse(source)
+	return metadataRecord, p.Name, err
+}
+
+// parseRecords parses the records and collections of a metadata file
+// (e.g. a catalog) with the parser of format, else the parser detected
+// for it, returning the name of the parser
+func parseRecords(filename string, source []byte, format string) ([]metadata.Record, []metadata.Collection, string, error) {
+	p, ok := parsers.Lookup(format)
+	if !ok {
+		var err error
+		if p, err = parsers.Detect(filename, source); err != nil {
+			return nil, nil, "", err
+		}
+	}
+	records, err := p.Records(source)
+	if err != nil {
+		return nil, nil, p.Name, err
+	}
+	collections, err := p.Collections(source)
+	return records, collections, p.Name, err
+}
+
+// indexSTACCatalog indexes the Collections and Items of a static STAC
+// catalog
+func indexSTACCatalog(cat *geocatalogo.GeoCatalogue, path string) {
+	var collectionCount, itemCount int
+	failures := 0
+
+	fmt.Printf("Indexing STAC catalog %q\n", path)
+
+	start := time.Now()
+	batcher := cat.NewBatcher()
+
+	err := parsers.WalkSTACCatalog(path, func(path string, rec *metadata.Record, collection *metadata.Collection, err error) error {
+		switch {
+		case err != nil:
+			fmt.Printf("Could not parse %q: %s\n", path, err)
+			failures++
+		case collection != nil:
+			if err := cat.PutCollection(*collection); err != nil {
+				fmt.Printf("Error Indexing collection %s: %s\n", collection.Identifier, err)
+				failures++
+				return nil
+			}
+			collectionCount++
+		default:
+			batcher.Add(*rec)
+			itemCount++
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error walking catalog: %s\n", err)
+	}
+
+	result, err := batcher.Close()
+	if err != nil {
+		fmt.Printf("Error Indexing: %s\n", err)
+	}
+	for _, e := range result.Errors {
+		fmt.Printf("Error Indexing %s: %s\n", e.Identifier, e.Reason)
+	}
+	fmt.Printf("Indexed %d collection(s) and %d of %d item(s) in %s\n", collectionCount, result.Indexed, itemCount, time.Since(start))
+	if failures > 0 {
+		fmt.Printf("  failed: %d\n", failures)
+	}
+}
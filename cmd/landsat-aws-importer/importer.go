@@ -0,0 +1,309 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-spatial/geocatalogo"
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/repository"
+)
+
+// importer indexes the scenes of a scene list.  Rows are read in
+// order and dispatched in batches to a pool of workers, which skip
+// scenes already indexed with identical content and index the rest.
+// The product id of the last row of each batch is recorded in the
+// state file once it and all batches before it are indexed, so that an
+// interrupted import can resume after it
+type importer struct {
+	catalogue *geocatalogo.GeoCatalogue
+	filter    sceneFilter
+	workers   int
+	batchSize int
+	// after skips the rows up to and including this product id
+	after string
+	// stateFile records the product id of the last processed row
+	stateFile string
+	// stop interrupts reading, letting dispatched batches complete
+	stop <-chan struct{}
+	log  io.Writer
+}
+
+// summary counts the rows of an import
+type summary struct {
+	Rows      int
+	Skipped   int
+	Invalid   int
+	Filtered  int
+	Unchanged int
+	Last      string
+	repository.BulkResult
+}
+
+// batch is a run of scenes, numbered in order of the scene list
+type batch struct {
+	seq     int
+	records []metadata.Record
+	// last is the product id of the last row read, included or not
+	last string
+}
+
+// batchResult is the outcome of indexing a batch
+type batchResult struct {
+	batch
+	unchanged int
+	result    repository.BulkResult
+	err       error
+}
+
+// run imports a scene list
+func (im *importer) run(scenes *sceneList) (summary, error) {
+	var s summary
+	batches := make(chan batch)
+	results := make(chan batchResult)
+
+	var workers sync.WaitGroup
+	for i := 0; i < im.workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for b := range batches {
+				results <- im.index(b)
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	collected := make(chan error, 1)
+	go func() {
+		collected <- im.collect(results, &s)
+	}()
+
+	readErr := im.read(scenes, batches, &s)
+	close(batches)
+	if err := <-collected; err != nil {
+		return s, err
+	}
+	return s, readErr
+}
+
+// read reads the scene list, dispatching batches of the scenes which
+// pass the filter
+func (im *importer) read(scenes *sceneList, batches chan<- batch, s *summary) error {
+	current := batch{last: im.after}
+	skipping := im.after != ""
+	dispatch := func() {
+		batches <- current
+		current = batch{seq: current.seq + 1, last: current.last}
+	}
+
+	for {
+		select {
+		case <-im.stop:
+			fmt.Fprintln(im.log, "Interrupted, finishing dispatched batches")
+			dispatch()
+			return nil
+		default:
+		}
+
+		rec, err := scenes.read()
+		if err == io.EOF {
+			break
+		}
+		if e, ok := err.(*rowError); ok {
+			s.Rows++
+			s.Invalid++
+			fmt.Fprintf(im.log, "Skipping %s\n", e)
+			continue
+		}
+		if err != nil {
+			dispatch()
+			return err
+		}
+		s.Rows++
+		if skipping {
+			s.Skipped++
+			skipping = rec.Identifier != im.after
+			continue
+		}
+		current.last = rec.Identifier
+		if !im.filter.match(rec) {
+			s.Filtered++
+			continue
+		}
+		current.records = append(current.records, rec)
+		if len(current.records) >= im.batchSize {
+			dispatch()
+		}
+	}
+	dispatch()
+	if skipping {
+		return fmt.Errorf("product id %s to resume after not found", im.after)
+	}
+	return nil
+}
+
+// index indexes the scenes of a batch which are not already indexed
+// with identical content
+func (im *importer) index(b batch) batchResult {
+	r := batchResult{batch: b}
+	if len(b.records) == 0 {
+		return r
+	}
+
+	ids := make([]string, len(b.records))
+	for i, rec := range b.records {
+		ids[i] = rec.Identifier
+	}
+	existing := map[string]metadata.Record{}
+	for _, rec := range im.catalogue.Get(ids).Records {
+		existing[rec.Identifier] = rec
+	}
+
+	var changed []metadata.Record
+	for _, rec := range b.records {
+		if indexed, ok := existing[rec.Identifier]; ok && sameContent(rec, indexed) {
+			r.unchanged++
+			continue
+		}
+		changed = append(changed, rec)
+	}
+	if len(changed) > 0 {
+		r.result, r.err = im.catalogue.IndexBatch(changed)
+	}
+	return r
+}
+
+// collect accumulates batch results, recording progress in the state
+// file as batches complete in order.  Progress stops at the first
+// batch with errors, so that resuming retries it; the first error of a
+// batch or of the state file is returned
+func (im *importer) collect(results <-chan batchResult, s *summary) error {
+	pending := map[int]batchResult{}
+	next := 0
+	failed := false
+	reported := time.Now()
+	var firstErr error
+
+	for r := range results {
+		s.Unchanged += r.unchanged
+		s.BulkResult.Add(r.result)
+		if r.err != nil {
+			fmt.Fprintf(im.log, "Error Indexing batch ending at %s: %s\n", r.last, r.err)
+			if firstErr == nil {
+				firstErr = r.err
+			}
+		}
+		for _, e := range r.result.Errors {
+			fmt.Fprintf(im.log, "Error Indexing %s: %s\n", e.Identifier, e.Reason)
+		}
+
+		pending[r.seq] = r
+		advanced := false
+		for !failed {
+			done, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			if done.err != nil || len(done.result.Errors) > 0 {
+				failed = true
+				break
+			}
+			s.Last = done.last
+			advanced = true
+		}
+		if advanced && im.stateFile != "" {
+			if err := writeState(im.stateFile, s.Last); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		if advanced && time.Since(reported) >= 10*time.Second {
+			fmt.Fprintf(im.log, "Processed up to %s: %d indexed, %d unchanged\n", s.Last, s.Indexed, s.Unchanged)
+			reported = time.Now()
+		}
+	}
+	return firstErr
+}
+
+// sameContent reports whether a record has the content of an indexed
+// record, ignoring the time it was indexed
+func sameContent(rec, indexed metadata.Record) bool {
+	rec.Properties.Geocatalogo.Inserted = time.Time{}
+	indexed.Properties.Geocatalogo.Inserted = time.Time{}
+	a, err := json.Marshal(rec)
+	if err != nil {
+		return false
+	}
+	b, err := json.Marshal(indexed)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(a, b)
+}
+
+// readState returns the product id recorded in a state file, empty if
+// the file does not exist
+func readState(filename string) (string, error) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return strings.TrimSpace(string(data)), err
+}
+
+// writeState records a product id in a state file, replacing it
+// atomically
+func writeState(filename, productID string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.WriteString(productID + "\n"); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
@@ -27,27 +27,36 @@
 package main
 
 import (
-	"encoding/csv"
-	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
-	"strings"
+	"os/signal"
+	"runtime"
+	"syscall"
 	"time"
 
 	"github.com/go-spatial/geocatalogo"
-	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/repository"
 )
 
 func main() {
-	var acquisitionDateLayout = "2006-01-02 15:04:05"
 	if len(os.Args) == 1 {
-		fmt.Printf("Usage: %s -file </path/to/scene-list>\n", os.Args[0])
+		fmt.Printf("Usage: %s -file </path/to/scene-list> [options]\n", os.Args[0])
+		flag.PrintDefaults()
 		return
 	}
 
-	sceneListFlag := flag.String("file", "", "Path to scene_list csv")
+	sceneListFlag := flag.String("file", "", "Path to scene_list csv (optionally gzipped, - for standard input)")
+	workersFlag := flag.Int("workers", runtime.NumCPU(), "Number of concurrent indexing workers")
+	batchFlag := flag.Int("batch", 0, "Number of scenes per batch (default GEOCATALOGO_REPOSITORY_BATCHSIZE)")
+	stateFlag := flag.String("state", "", "File recording the product id of the last processed scene, to resume from")
+	afterFlag := flag.String("after", "", "Resume after this product id (overrides -state)")
+	startFlag := flag.String("start", "", "Minimum acquisition date (RFC3339 or YYYY-MM-DD)")
+	endFlag := flag.String("end", "", "Maximum acquisition date (RFC3339 or YYYY-MM-DD, inclusive)")
+	pathFlag := flag.String("path", "", "WRS paths (comma-separated values or ranges, e.g. 12,140-150)")
+	rowFlag := flag.String("row", "", "WRS rows (comma-separated values or ranges, e.g. 1-40)")
+	cloudCoverFlag := flag.Float64("max-cloud-cover", -1, "Maximum cloud cover (percent)")
 	flag.Parse()
 
 	if *sceneListFlag == "" {
@@ -55,92 +64,97 @@ func main() {
 		os.Exit(1)
 	}
 
-	f, err := os.Open(*sceneListFlag)
-	if err != nil {
-		panic(err)
+	filter := sceneFilter{maxCloudCover: *cloudCoverFlag}
+	var err error
+	if filter.start, err = parseDate(*startFlag, false); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
-	defer f.Close()
-
-	cat, err := geocatalogo.NewFromEnv()
-
-	lines, err := csv.NewReader(f).ReadAll()
-	if err != nil {
-		panic(err)
+	if filter.end, err = parseDate(*endFlag, true); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if filter.paths, err = parseRanges(*pathFlag); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if filter.rows, err = parseRanges(*rowFlag); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 
-	for lineno, line := range lines {
-		if lineno == 0 { // skip header
-			continue
-		}
-		acquisitionDate, _ := time.Parse(acquisitionDateLayout, line[2])
-		cloudCover, _ := strconv.ParseFloat(line[3], 64)
-		path, _ := strconv.ParseUint(line[5], 10, 64)
-		row, _ := strconv.ParseUint(line[6], 10, 64)
-		minLat, _ := strconv.ParseFloat(line[7], 64)
-		minLon, _ := strconv.ParseFloat(line[8], 64)
-		maxLat, _ := strconv.ParseFloat(line[9], 64)
-		maxLon, _ := strconv.ParseFloat(line[10], 64)
-		downloadURL := line[11]
-		metadataURL := strings.Replace(line[11], "/index.html", "/"+line[0]+"_MTL.json", 1)
-
-		metadataRecord := metadata.Record{}
-
-		metadataRecord.Type = "Feature"
-
-		metadataRecord.Identifier = line[0]
-		metadataRecord.Properties.Title = line[1]
-		metadataRecord.Properties.Abstract = "Landsat 8 scene " + line[1]
-		metadataRecord.Properties.Collection = "landsat8"
-		metadataRecord.Properties.Datetime = &acquisitionDate
-		metadataRecord.Links = append(metadataRecord.Links, metadata.Link{URL: downloadURL})
-		metadataRecord.Links = append(metadataRecord.Links, metadata.Link{URL: metadataURL})
-
-		pi := &metadata.ProductInfo{
-			Collection:        "landsat8",
-			ProductIdentifier: line[0],
-			SceneIdentifier:   line[1],
-			AcquisitionDate:   &acquisitionDate,
-			CloudCover:        cloudCover,
-			ProcessingLevel:   line[4],
-			Path:              path,
-			Row:               row,
+	after := *afterFlag
+	if after == "" && *stateFlag != "" {
+		if after, err = readState(*stateFlag); err != nil {
+			fmt.Printf("Could not read state: %s\n", err)
+			os.Exit(1)
 		}
+	}
 
-		url_thumb := strings.Replace(line[11], "/index.html", "/"+line[0]+"_thumb_small.jpg", 1)
-		metadataRecord.Assets = append(metadataRecord.Assets, metadata.Link{URL: url_thumb, Name: "thumbnail", Type: "thumbnail"})
-
-		for i := 0; i < 10; i++ {
-			url := fmt.Sprintf("%v_B%d.TIF", strings.Replace(metadataURL, "_MTL.json", "", 1), i)
-			metadataRecord.Assets = append(metadataRecord.Assets, metadata.Link{URL: url, Name: fmt.Sprintf("B%d", i), Type: "image/vnd.stac.geotiff"})
+	var input io.Reader = os.Stdin
+	if *sceneListFlag != "-" {
+		f, err := os.Open(*sceneListFlag)
+		if err != nil {
+			fmt.Printf("Could not read file: %s\n", err)
+			os.Exit(1)
 		}
+		defer f.Close()
+		input = f
+	}
+	scenes, err := newSceneList(input)
+	if err != nil {
+		fmt.Printf("Could not read scene list: %s\n", err)
+		os.Exit(1)
+	}
 
-		metadataRecord.Properties.ProductInfo = pi
-
-		metadataRecord.Properties.Geocatalogo.Inserted = time.Now()
-
-		metadataRecord.Geometry.Type = "Polygon"
-
-		var coordinates = [][][2]float64{{
-			{minLon, minLat},
-			{minLon, maxLat},
-			{maxLon, maxLat},
-			{maxLon, minLat},
-			{minLon, minLat},
-		}}
-
-		metadataRecord.Geometry.Coordinates = coordinates
-		metadataRecord.BoundingBox = metadataRecord.Geometry.Bounds()
-
-		metadataRecord.Properties.Geocatalogo.Schema = "local"
-		metadataRecord.Properties.Geocatalogo.Source = metadataURL
-
-		res, _ := json.Marshal(metadataRecord)
-		fmt.Println(string(res))
+	cat, err := geocatalogo.NewFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	defer cat.Close()
+
+	im := &importer{
+		catalogue: cat,
+		filter:    filter,
+		workers:   *workersFlag,
+		batchSize: *batchFlag,
+		after:     after,
+		stateFile: *stateFlag,
+		log:       os.Stdout,
+	}
+	if im.workers < 1 {
+		im.workers = 1
+	}
+	if im.batchSize <= 0 {
+		im.batchSize = cat.Config.Repository.BatchSize
+	}
+	if im.batchSize <= 0 {
+		im.batchSize = repository.DefaultBatchSize
+	}
 
-		result := cat.Index(metadataRecord)
-		if !result {
-			fmt.Println("ERROR Indexing " + metadataRecord.Identifier)
-		}
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+	im.stop = stop
+
+	if after != "" {
+		fmt.Printf("Resuming after %s\n", after)
+	}
+	start := time.Now()
+	s, err := im.run(scenes)
+	fmt.Printf("Indexed %d of %d row(s) in %s: %d unchanged, %d filtered, %d skipped, %d invalid, %d failed\n",
+		s.Indexed, s.Rows, time.Since(start), s.Unchanged, s.Filtered, s.Skipped, s.Invalid, len(s.Errors))
+	if s.Last != "" && s.Last != after {
+		fmt.Printf("Last processed product id: %s\n", s.Last)
+	}
+	if err != nil {
+		fmt.Printf("ERROR: %s\n", err)
+		cat.Close()
+		os.Exit(1)
 	}
-	return
 }
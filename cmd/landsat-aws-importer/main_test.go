@@ -0,0 +1,136 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-spatial/geocatalogo"
+	"github.com/go-spatial/geocatalogo/config"
+)
+
+const sceneListCSV = `productId,entityId,acquisitionDate,cloudCover,processingLevel,path,row,min_lat,min_lon,max_lat,max_lon,download_url
+LC08_L1TP_149039_20170411_20170415_01_T1,LC81490392017101LGN00,2017-04-11 05:36:29.349932,0.0,L1TP,149,39,29.22165,72.41205,31.34742,74.84666,https://landsat-pds.s3.amazonaws.com/c1/L8/149/039/LC08_L1TP_149039_20170411_20170415_01_T1/index.html
+LC08_L1TP_012001_20170411_20170415_01_T1,LC80120012017101LGN00,2017-04-11 15:14:40.001201,0.15,L1TP,12,1,79.51968,-22.17301,81.49027,-9.5833,https://landsat-pds.s3.amazonaws.com/c1/L8/012/001/LC08_L1TP_012001_20170411_20170415_01_T1/index.html
+LC08_L1GT_012002_20170412_20170412_01_RT,LC80120022017102LGN00,2017-04-12 15:15:03.962109,95.5,L1GT,12,2,78.07041,-25.24566,80.14339,-14.3454,https://landsat-pds.s3.amazonaws.com/c1/L8/012/002/LC08_L1GT_012002_20170412_20170412_01_RT/index.html
+LC08_L1TP_012003_20170412_20170412_01_RT,LC80120032017102LGN00,yesterday,5,L1TP,12,3,76.6,-25.9,78.7,-16.2,https://landsat-pds.s3.amazonaws.com/c1/L8/012/003/LC08_L1TP_012003_20170412_20170412_01_RT/index.html
+LC08_L1TP_013001_20170413_20170413_01_RT,LC80130012017103LGN00,2017-04-13 15:20:51.100000,20,L1TP,13,1,79.5,-23.7,81.5,-11.1,https://landsat-pds.s3.amazonaws.com/c1/L8/013/001/LC08_L1TP_013001_20170413_20170413_01_RT/index.html
+`
+
+func newCatalogue(t *testing.T) *geocatalogo.GeoCatalogue {
+	t.Helper()
+	var cfg config.Config
+	cfg.Repository.Type = "memory"
+	cfg.Logging.Level = "ERROR"
+	cat, err := geocatalogo.New(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cat
+}
+
+func runImport(t *testing.T, im *importer) summary {
+	t.Helper()
+	scenes, err := newSceneList(strings.NewReader(sceneListCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	im.workers, im.batchSize, im.log = 3, 1, ioutil.Discard
+	s, err := im.run(scenes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSceneRecord(t *testing.T) {
+	scenes, err := newSceneList(strings.NewReader(sceneListCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := scenes.read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pi := rec.Properties.ProductInfo
+	if rec.Identifier != "LC08_L1TP_149039_20170411_20170415_01_T1" || pi.Path != 149 || pi.Row != 39 || pi.ProcessingLevel != "L1TP" {
+		t.Errorf("unexpected scene %s %+v", rec.Identifier, pi)
+	}
+	if !pi.AcquisitionDate.Equal(time.Date(2017, 4, 11, 5, 36, 29, 349932000, time.UTC)) {
+		t.Errorf("unexpected acquisition date %v", pi.AcquisitionDate)
+	}
+	if rec.BoundingBox != [4]float64{72.41205, 29.22165, 74.84666, 31.34742} {
+		t.Errorf("unexpected bbox %v", rec.BoundingBox)
+	}
+	if len(rec.Links) != 2 || !strings.HasSuffix(rec.Links[1].URL, "/LC08_L1TP_149039_20170411_20170415_01_T1_MTL.json") || len(rec.Assets) != 11 {
+		t.Errorf("unexpected links %+v assets %d", rec.Links, len(rec.Assets))
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err = scenes.read(); i < 2 && err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err == nil || err.Error() != `line 5: invalid acquisitionDate: parsing time "yesterday" as "2006-01-02 15:04:05": cannot parse "yesterday" as "2006"` {
+		t.Errorf("expected invalid row error, got %v", err)
+	}
+
+	if _, err := newSceneList(strings.NewReader("productId,entityId\n")); err == nil {
+		t.Error("expected missing column error")
+	}
+}
+
+func TestImport(t *testing.T) {
+	cat := newCatalogue(t)
+	state := filepath.Join(t.TempDir(), "state")
+
+	s := runImport(t, &importer{catalogue: cat, stateFile: state, filter: sceneFilter{maxCloudCover: -1}})
+	if s.Rows != 5 || s.Indexed != 4 || s.Invalid != 1 || s.Last != "LC08_L1TP_013001_20170413_20170413_01_RT" {
+		t.Errorf("unexpected summary %+v", s)
+	}
+	if last, _ := readState(state); last != s.Last {
+		t.Errorf("expected state %s, got %s", s.Last, last)
+	}
+
+	s = runImport(t, &importer{catalogue: cat, filter: sceneFilter{maxCloudCover: -1}})
+	if s.Indexed != 0 || s.Unchanged != 4 {
+		t.Errorf("expected unchanged scenes to be skipped, got %+v", s)
+	}
+
+	s = runImport(t, &importer{catalogue: newCatalogue(t), after: "LC08_L1TP_012001_20170411_20170415_01_T1", filter: sceneFilter{maxCloudCover: -1}})
+	if s.Skipped != 2 || s.Indexed != 2 {
+		t.Errorf("expected to resume after the second scene, got %+v", s)
+	}
+}
+
+func TestSceneFilter(t *testing.T) {
+	start, _ := parseDate("2017-04-12", false)
+	end, _ := parseDate("2017-04-12", true)
+	paths, err := parseRanges("12,140-150")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		filter  sceneFilter
+		indexed int
+	}{
+		{sceneFilter{maxCloudCover: -1, start: start, end: end}, 1},
+		{sceneFilter{maxCloudCover: -1, paths: paths}, 3},
+		{sceneFilter{maxCloudCover: -1, rows: ranges{{1, 1}}}, 2},
+		{sceneFilter{maxCloudCover: 10}, 2},
+	}
+	for i, test := range tests {
+		s := runImport(t, &importer{catalogue: newCatalogue(t), filter: test.filter})
+		if s.Indexed != test.indexed || s.Filtered != 4-test.indexed {
+			t.Errorf("%d: expected %d scenes, got %+v", i, test.indexed, s)
+		}
+	}
+
+	for _, invalid := range []string{"a", "5-1", "1,", "-3"} {
+		if _, err := parseRanges(invalid); err == nil {
+			t.Errorf("expected invalid range %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+const acquisitionDateLayout = "2006-01-02 15:04:05"
+
+// sceneColumns are the columns of the scene list used by the importer
+var sceneColumns = []string{
+	"productId", "entityId", "acquisitionDate", "cloudCover",
+	"processingLevel", "path", "row", "min_lat", "min_lon", "max_lat",
+	"max_lon", "download_url",
+}
+
+// rowError reports an invalid row of the scene list
+type rowError struct {
+	line int
+	err  error
+}
+
+func (e *rowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.err)
+}
+
+// sceneList reads the scenes of a scene list (scene_list or
+// scene_list.gz), a row at a time
+type sceneList struct {
+	csv    *csv.Reader
+	column map[string]int
+}
+
+// newSceneList reads the header of a scene list, decompressing it if
+// it is gzipped
+func newSceneList(r io.Reader) (*sceneList, error) {
+	buffered := bufio.NewReader(r)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		r = gz
+	} else {
+		r = buffered
+	}
+
+	s := &sceneList{csv: csv.NewReader(r), column: map[string]int{}}
+	s.csv.ReuseRecord = true
+	header, err := s.csv.Read()
+	if err == io.EOF {
+		return nil, errors.New("empty scene list")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range header {
+		s.column[name] = i
+	}
+	for _, name := range sceneColumns {
+		if _, ok := s.column[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+	s.csv.FieldsPerRecord = len(header)
+	return s, nil
+}
+
+// read returns the record of the next scene, or io.EOF.  Invalid rows
+// return a *rowError
+func (s *sceneList) read() (metadata.Record, error) {
+	line, err := s.csv.Read()
+	if err == io.EOF {
+		return metadata.Record{}, err
+	}
+	var parseError *csv.ParseError
+	if errors.As(err, &parseError) {
+		return metadata.Record{}, &rowError{line: parseError.StartLine, err: parseError.Err}
+	}
+	if err != nil {
+		return metadata.Record{}, err
+	}
+	lineno, _ := s.csv.FieldPos(0)
+
+	rec, err := sceneRecord(func(name string) string {
+		return line[s.column[name]]
+	})
+	if err != nil {
+		return rec, &rowError{line: lineno, err: err}
+	}
+	return rec, nil
+}
+
+// sceneRecord creates the record of a scene from its columns
+func sceneRecord(column func(string) string) (metadata.Record, error) {
+	var rec metadata.Record
+
+	productID := column("productId")
+	if productID == "" {
+		return rec, errors.New("missing productId")
+	}
+	acquisitionDate, err := time.Parse(acquisitionDateLayout, column("acquisitionDate"))
+	if err != nil {
+		return rec, fmt.Errorf("invalid acquisitionDate: %s", err)
+	}
+	cloudCover, err := strconv.ParseFloat(column("cloudCover"), 64)
+	if err != nil {
+		return rec, fmt.Errorf("invalid cloudCover: %s", err)
+	}
+	path, err := strconv.ParseUint(column("path"), 10, 64)
+	if err != nil {
+		return rec, fmt.Errorf("invalid path: %s", err)
+	}
+	row, err := strconv.ParseUint(column("row"), 10, 64)
+	if err != nil {
+		return rec, fmt.Errorf("invalid row: %s", err)
+	}
+	var bbox [4]float64
+	for i, name := range []string{"min_lon", "min_lat", "max_lon", "max_lat"} {
+		if bbox[i], err = strconv.ParseFloat(column(name), 64); err != nil {
+			return rec, fmt.Errorf("invalid %s: %s", name, err)
+		}
+	}
+	downloadURL := column("download_url")
+	metadataURL := strings.Replace(downloadURL, "/index.html", "/"+productID+"_MTL.json", 1)
+
+	rec.Type = "Feature"
+	rec.Identifier = productID
+	rec.Properties.Title = column("entityId")
+	rec.Properties.Abstract = "Landsat 8 scene " + column("entityId")
+	rec.Properties.Collection = "landsat8"
+	rec.Properties.Datetime = &acquisitionDate
+	rec.Links = append(rec.Links, metadata.Link{URL: downloadURL})
+	rec.Links = append(rec.Links, metadata.Link{URL: metadataURL})
+
+	rec.Properties.ProductInfo = &metadata.ProductInfo{
+		Collection:        "landsat8",
+		ProductIdentifier: productID,
+		SceneIdentifier:   column("entityId"),
+		AcquisitionDate:   &acquisitionDate,
+		CloudCover:        cloudCover,
+		ProcessingLevel:   column("processingLevel"),
+		Path:              path,
+		Row:               row,
+	}
+
+	thumbnailURL := strings.Replace(downloadURL, "/index.html", "/"+productID+"_thumb_small.jpg", 1)
+	rec.Assets = append(rec.Assets, metadata.Link{URL: thumbnailURL, Name: "thumbnail", Type: "thumbnail"})
+
+	for i := 0; i < 10; i++ {
+		url := fmt.Sprintf("%v_B%d.TIF", strings.Replace(metadataURL, "_MTL.json", "", 1), i)
+		rec.Assets = append(rec.Assets, metadata.Link{URL: url, Name: fmt.Sprintf("B%d", i), Type: "image/vnd.stac.geotiff"})
+	}
+
+	rec.Geometry = metadata.NewEnvelope(bbox)
+	rec.BoundingBox = rec.Geometry.Bounds()
+
+	rec.Properties.Geocatalogo.Schema = "local"
+	rec.Properties.Geocatalogo.Source = metadataURL
+
+	return rec, nil
+}
+
+// sceneFilter selects scenes by acquisition date, path, row and cloud
+// cover
+type sceneFilter struct {
+	start, end    time.Time
+	paths, rows   ranges
+	maxCloudCover float64
+}
+
+// match reports whether a scene record passes the filter
+func (f *sceneFilter) match(rec metadata.Record) bool {
+	pi := rec.Properties.ProductInfo
+	switch {
+	case !f.start.IsZero() && pi.AcquisitionDate.Before(f.start),
+		!f.end.IsZero() && !pi.AcquisitionDate.Before(f.end),
+		!f.paths.contains(pi.Path),
+		!f.rows.contains(pi.Row),
+		f.maxCloudCover >= 0 && pi.CloudCover > f.maxCloudCover:
+		return false
+	}
+	return true
+}
+
+// parseDate parses a date filter (RFC 3339 or YYYY-MM-DD); the end of
+// a YYYY-MM-DD range is the start of the next day
+func parseDate(s string, end bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return t, fmt.Errorf("invalid date %q (RFC 3339 or YYYY-MM-DD)", s)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// ranges is a set of inclusive ranges of unsigned integers; an empty
+// set contains any value
+type ranges [][2]uint64
+
+// parseRanges parses comma-separated values and ranges (e.g. 1,5-10)
+func parseRanges(s string) (ranges, error) {
+	var r ranges
+	if s == "" {
+		return r, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+		min, err := strconv.ParseUint(bounds[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q", part)
+		}
+		max := min
+		if len(bounds) == 2 {
+			if max, err = strconv.ParseUint(bounds[1], 10, 64); err != nil || max < min {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+		}
+		r = append(r, [2]uint64{min, max})
+	}
+	return r, nil
+}
+
+func (r ranges) contains(v uint64) bool {
+	if len(r) == 0 {
+		return true
+	}
+	for _, bounds := range r {
+		if v >= bounds[0] && v <= bounds[1] {
+			return true
+		}
+	}
+	return false
+}
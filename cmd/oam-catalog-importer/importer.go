@@ -0,0 +1,186 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-spatial/geocatalogo"
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/parsers"
+	"github.com/go-spatial/geocatalogo/repository"
+)
+
+// defaultLimit is the number of results requested per page
+const defaultLimit = 100
+
+// metaClient pages through the results of an OAM compatible /meta
+// endpoint, most recently uploaded first
+type metaClient struct {
+	url    string
+	limit  int
+	client *http.Client
+}
+
+// fetch requests a page of results (from 1)
+func (c *metaClient) fetch(page int) (parsers.OAMCatalogResults, error) {
+	var results parsers.OAMCatalogResults
+
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return results, err
+	}
+	q := u.Query()
+	q.Set("page", strconv.Itoa(page))
+	q.Set("limit", strconv.Itoa(c.limit))
+	q.Set("order_by", "uploaded_at")
+	q.Set("sort", "desc")
+	u.RawQuery = q.Encode()
+
+	resp, err := c.client.Get(u.String())
+	if err != nil {
+		return results, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return results, fmt.Errorf("GET %s: %s %s", u, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return results, fmt.Errorf("page %d: %s", page, err)
+	}
+	return results, nil
+}
+
+// summary counts the results of an import
+type summary struct {
+	Found   int
+	Fetched int
+	Skipped int
+	Invalid int
+	repository.BulkResult
+}
+
+// importer indexes OAM Catalog results uploaded after a time, if set,
+// keeping track of the most recent upload indexed
+type importer struct {
+	catalogue *geocatalogo.GeoCatalogue
+	since     time.Time
+	latest    time.Time
+	failed    bool
+	summary   summary
+	log       io.Writer
+}
+
+// fetchAll indexes the results of all pages, a page at a time.  As
+// results are ordered by upload time, paging stops at the first result
+// uploaded before the last run
+func (im *importer) fetchAll(c *metaClient) error {
+	for page := 1; ; page++ {
+		results, err := c.fetch(page)
+		if err != nil {
+			return err
+		}
+		if page == 1 {
+			im.summary.Found = results.Meta.Found
+		}
+		im.summary.Fetched += len(results.Result)
+		older := im.index(results.Result, page)
+		if older || len(results.Result) == 0 || im.summary.Fetched >= results.Meta.Found {
+			return nil
+		}
+	}
+}
+
+// index indexes results, reporting whether any was uploaded before the
+// last run
+func (im *importer) index(results []parsers.OAMCatalogResult, page int) bool {
+	var records []metadata.Record
+	older := false
+
+	for i, result := range results {
+		if result.UploadedAt != nil && !im.since.IsZero() && !result.UploadedAt.After(im.since) {
+			im.summary.Skipped++
+			older = true
+			continue
+		}
+		rec, err := parsers.ParseOAMCatalogResult(result)
+		if err == nil && rec.Identifier == "" {
+			err = fmt.Errorf("no _id")
+		}
+		if err != nil {
+			fmt.Fprintf(im.log, "Skipping result %d of page %d: %s\n", i+1, page, err)
+			im.summary.Invalid++
+			continue
+		}
+		records = append(records, rec)
+		if result.UploadedAt != nil && result.UploadedAt.After(im.latest) {
+			im.latest = *result.UploadedAt
+		}
+	}
+	if len(records) == 0 {
+		return older
+	}
+
+	result, err := im.catalogue.IndexBatch(records)
+	im.summary.Add(result)
+	if err != nil {
+		fmt.Fprintf(im.log, "ERROR Indexing page %d: %s\n", page, err)
+		im.failed = true
+	}
+	for _, e := range result.Errors {
+		fmt.Fprintf(im.log, "ERROR Indexing %s: %s\n", e.Identifier, e.Reason)
+		im.failed = true
+	}
+	return older
+}
+
+// readState returns the upload time recorded in a state file, zero if
+// the file does not exist
+func readState(filename string) (time.Time, error) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+}
+
+// writeState records an upload time in a state file
+func writeState(filename string, t time.Time) error {
+	return ioutil.WriteFile(filename, []byte(t.UTC().Format(time.RFC3339Nano)+"\n"), 0644)
+}
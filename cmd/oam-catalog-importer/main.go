@@ -31,48 +31,93 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/go-spatial/geocatalogo"
 	"github.com/go-spatial/geocatalogo/metadata/parsers"
 )
 
 func main() {
-	//var acquisitionDateLayout = "2006-01-02 15:04:05"
 	if len(os.Args) == 1 {
-		fmt.Printf("Usage: %s -file </path/to/oam.json>\n", os.Args[0])
+		fmt.Printf("Usage: %s -file </path/to/oam.json> | -url <https://api.openaerialmap.org/meta> [options]\n", os.Args[0])
+		flag.PrintDefaults()
 		return
 	}
 
 	fileFlag := flag.String("file", "", "Path to oam.json")
+	urlFlag := flag.String("url", "", "URL of an OAM compatible /meta endpoint")
+	limitFlag := flag.Int("limit", defaultLimit, "Number of results per page")
+	stateFlag := flag.String("state", "", "File recording the upload time of the most recent result indexed, to import newer results only")
+	sinceFlag := flag.String("since", "", "Import results uploaded after this time (RFC3339, overrides -state)")
+	timeoutFlag := flag.Duration("timeout", time.Minute, "HTTP request timeout")
 	flag.Parse()
 
-	if *fileFlag == "" {
-		fmt.Println("Missing file flag")
+	if (*fileFlag == "") == (*urlFlag == "") {
+		fmt.Println("Please supply one of -file or -url")
 		os.Exit(1)
 	}
 
-	raw, err := ioutil.ReadFile(*fileFlag)
-	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+	var since time.Time
+	var err error
+	if *sinceFlag != "" {
+		if since, err = time.Parse(time.RFC3339, *sinceFlag); err != nil {
+			fmt.Printf("Invalid since: %s\n", err)
+			os.Exit(1)
+		}
+	} else if *stateFlag != "" {
+		if since, err = readState(*stateFlag); err != nil {
+			fmt.Printf("Could not read state: %s\n", err)
+			os.Exit(1)
+		}
 	}
 
 	cat, err := geocatalogo.NewFromEnv()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	defer cat.Close()
 
-	var results parsers.OAMCatalogResults
-
-	json.Unmarshal(raw, &results)
+	im := &importer{catalogue: cat, since: since, latest: since, log: os.Stdout}
+	if !since.IsZero() {
+		fmt.Printf("Importing results uploaded after %s\n", since.Format(time.RFC3339))
+	}
 
-	for _, res := range results.Result {
-		rec, err := parsers.ParseOAMCatalogResult(res)
+	if *fileFlag != "" {
+		raw, err := ioutil.ReadFile(*fileFlag)
 		if err != nil {
-			fmt.Println(err)
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		var results parsers.OAMCatalogResults
+		if err := json.Unmarshal(raw, &results); err != nil {
+			fmt.Printf("Could not parse %s: %s\n", *fileFlag, err)
+			os.Exit(1)
 		}
-		result := cat.Index(rec)
-		if !result {
-			fmt.Println("ERROR Indexing " + rec.Identifier)
+		im.summary.Found = len(results.Result)
+		im.summary.Fetched = len(results.Result)
+		im.index(results.Result, 1)
+	} else {
+		client := &metaClient{url: *urlFlag, limit: *limitFlag, client: &http.Client{Timeout: *timeoutFlag}}
+		if err := im.fetchAll(client); err != nil {
+			fmt.Printf("ERROR Fetching: %s\n", err)
+			im.failed = true
+		}
+	}
+
+	s := im.summary
+	fmt.Printf("Indexed %d of %d records (%d found, %d older, %d invalid, %d failed)\n",
+		s.Indexed, s.Fetched, s.Found, s.Skipped, s.Invalid, len(s.Errors))
+	if im.failed {
+		cat.Close()
+		os.Exit(1)
+	}
+	if *stateFlag != "" && im.latest.After(since) {
+		if err := writeState(*stateFlag, im.latest); err != nil {
+			fmt.Printf("Could not write state: %s\n", err)
+			os.Exit(1)
 		}
 	}
-	return
 }
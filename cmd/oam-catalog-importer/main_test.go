@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-spatial/geocatalogo"
+	"github.com/go-spatial/geocatalogo/config"
+	"github.com/go-spatial/geocatalogo/metadata/parsers"
+)
+
+// metaAPI is a stand-in for the OAM /meta endpoint, capping limit at
+// maxLimit
+type metaAPI struct {
+	results  []parsers.OAMCatalogResult
+	maxLimit int
+	requests []string
+}
+
+func (api *metaAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.requests = append(api.requests, r.URL.RawQuery)
+	q := r.URL.Query()
+	if r.URL.Path != "/meta" || q.Get("order_by") != "uploaded_at" || q.Get("sort") != "desc" {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	page, _ := strconv.Atoi(q.Get("page"))
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit > api.maxLimit {
+		limit = api.maxLimit
+	}
+
+	results := append([]parsers.OAMCatalogResult(nil), api.results...)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].UploadedAt.After(*results[j].UploadedAt)
+	})
+	from, to := (page-1)*limit, page*limit
+	if from > len(results) {
+		from = len(results)
+	}
+	if to > len(results) {
+		to = len(results)
+	}
+	json.NewEncoder(w).Encode(parsers.OAMCatalogResults{
+		Meta:   parsers.OAMCatalogMeta{Page: page, Limit: limit, Found: len(results)},
+		Result: results[from:to],
+	})
+}
+
+func (api *metaAPI) add(n int) {
+	uploaded := time.Date(2019, 5, n, 12, 0, 0, 0, time.UTC)
+	acquired := uploaded.AddDate(0, 0, -1)
+	api.results = append(api.results, parsers.OAMCatalogResult{
+		Identifier:       fmt.Sprintf("5cd%03d", n),
+		Uuid:             fmt.Sprintf("https://oin-hotosm.s3.amazonaws.com/%d/0/image.tif", n),
+		Title:            fmt.Sprintf("Image %d", n),
+		Gsd:              0.04 * float64(n),
+		Filesize:         int64(n) * 1000000,
+		AcquisitionStart: &acquired,
+		AcquisitionEnd:   &acquired,
+		Platform:         "uav",
+		Provider:         "OpenAerialMap",
+		UploadedAt:       &uploaded,
+		Bbox:             [4]float64{39.1, -6.9, 39.2, -6.8},
+	})
+}
+
+func newCatalogue(t *testing.T) *geocatalogo.GeoCatalogue {
+	t.Helper()
+	var cfg config.Config
+	cfg.Repository.Type = "memory"
+	cfg.Logging.Level = "ERROR"
+	cat, err := geocatalogo.New(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cat
+}
+
+func TestFetchAll(t *testing.T) {
+	api := &metaAPI{maxLimit: 2}
+	for n := 1; n <= 5; n++ {
+		api.add(n)
+	}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	client := &metaClient{url: server.URL + "/meta", limit: 100, client: server.Client()}
+	cat := newCatalogue(t)
+
+	im := &importer{catalogue: cat, log: ioutil.Discard}
+	if err := im.fetchAll(client); err != nil {
+		t.Fatal(err)
+	}
+	if im.summary.Found != 5 || im.summary.Fetched != 5 || im.summary.Indexed != 5 || len(api.requests) != 3 || im.failed {
+		t.Errorf("expected 5 results from 3 pages, got %+v from %d requests", im.summary, len(api.requests))
+	}
+	if !im.latest.Equal(*api.results[4].UploadedAt) {
+		t.Errorf("unexpected latest upload %v", im.latest)
+	}
+	records := cat.Get([]string{"5cd003"}).Records
+	if len(records) != 1 {
+		t.Fatalf("expected record 5cd003, got %d records", len(records))
+	}
+	pi := records[0].Properties.ProductInfo
+	if pi.GSD != 0.12 || pi.FileSize != 3000000 || pi.Platform != "uav" {
+		t.Errorf("unexpected product info %+v", pi)
+	}
+
+	// incremental update: only results uploaded after the last run
+	state := filepath.Join(t.TempDir(), "state")
+	if err := writeState(state, im.latest); err != nil {
+		t.Fatal(err)
+	}
+	since, err := readState(state)
+	if err != nil || !since.Equal(im.latest) {
+		t.Fatalf("unexpected state %v (%v)", since, err)
+	}
+	api.add(6)
+	api.add(7)
+	api.add(8)
+	api.requests = nil
+	im = &importer{catalogue: cat, since: since, latest: since, log: ioutil.Discard}
+	if err := im.fetchAll(client); err != nil {
+		t.Fatal(err)
+	}
+	if im.summary.Indexed != 3 || im.summary.Skipped != 1 || len(api.requests) != 2 {
+		t.Errorf("expected 3 new results from 2 pages, got %+v from %d requests", im.summary, len(api.requests))
+	}
+	if !im.latest.Equal(*api.results[7].UploadedAt) {
+		t.Errorf("unexpected latest upload %v", im.latest)
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/broken/meta":
+			http.Error(w, "database unavailable", http.StatusServiceUnavailable)
+		case "/invalid/meta":
+			fmt.Fprint(w, `{"meta": {"found": 1}, "results": [{"_id": "a", "gsd": "fine"}]}`)
+		case "/noid/meta":
+			fmt.Fprint(w, `{"meta": {"found": 2}, "results": [{"_id": "a"}, {"title": "no id"}]}`)
+		}
+	}))
+	defer server.Close()
+
+	tests := map[string]string{
+		"broken":  "503 Service Unavailable database unavailable",
+		"invalid": "page 1: json: cannot unmarshal string",
+	}
+	for path, expected := range tests {
+		client := &metaClient{url: server.URL + "/" + path + "/meta", limit: 10, client: server.Client()}
+		im := &importer{catalogue: newCatalogue(t), log: ioutil.Discard}
+		if err := im.fetchAll(client); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error %q, got %v", path, expected, err)
+		}
+	}
+
+	client := &metaClient{url: server.URL + "/noid/meta", limit: 10, client: server.Client()}
+	im := &importer{catalogue: newCatalogue(t), log: ioutil.Discard}
+	if err := im.fetchAll(client); err != nil {
+		t.Fatal(err)
+	}
+	if im.summary.Indexed != 1 || im.summary.Invalid != 1 {
+		t.Errorf("expected the result without _id to be skipped, got %+v", im.summary)
+	}
+}
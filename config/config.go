@@ -30,17 +30,24 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
 
 // Repository provides an object model for backends.
 type Repository struct {
-	Type     string
-	URL      string
-	Username string
-	Password string
-	Mappings map[string]string
+	Type             string
+	URL              string
+	Username         string
+	Password         string
+	Mappings         map[string]string
+	BatchSize        int
+	FlushInterval    time.Duration
+	Retries          int
+	Persist          bool
+	SnapshotInterval time.Duration
+	Strict           bool
 }
 
 // Config provides an object model for configuration.
@@ -54,6 +61,11 @@ type Config struct {
 		PrettyPrint bool
 		Limit       int
 		CORS        bool
+		// credentials protecting transactions (HTTP Basic or Bearer);
+		// transactions are disabled unless set
+		Username string
+		Password string
+		Token    string
 	}
 	Logging struct {
 		Level   string
@@ -102,7 +114,7 @@ func LoadFromEnv() Config {
 	var cfg Config
 	cfg.Repository.Mappings = make(map[string]string)
 	for _, e := range os.Environ() {
-		pair := strings.Split(e, "=")
+		pair := strings.SplitN(e, "=", 2)
 
 		switch pair[0] {
 		case "GEOCATALOGO_SERVER_OPENAPI":
@@ -121,6 +133,12 @@ func LoadFromEnv() Config {
 			cfg.Server.Limit, _ = strconv.Atoi(pair[1])
 		case "GEOCATALOGO_SERVER_CORS":
 			cfg.Server.CORS, _ = strconv.ParseBool(pair[1])
+		case "GEOCATALOGO_SERVER_USERNAME":
+			cfg.Server.Username = pair[1]
+		case "GEOCATALOGO_SERVER_PASSWORD":
+			cfg.Server.Password = pair[1]
+		case "GEOCATALOGO_SERVER_TOKEN":
+			cfg.Server.Token = pair[1]
 		case "GEOCATALOGO_LOGGING_LEVEL":
 			cfg.Logging.Level = pair[1]
 		case "GEOCATALOGO_LOGGING_LOGFILE":
@@ -183,6 +201,18 @@ func LoadFromEnv() Config {
 			cfg.Repository.Username = pair[1]
 		case "GEOCATALOGO_REPOSITORY_PASSWORD":
 			cfg.Repository.Password = pair[1]
+		case "GEOCATALOGO_REPOSITORY_BATCHSIZE":
+			cfg.Repository.BatchSize, _ = strconv.Atoi(pair[1])
+		case "GEOCATALOGO_REPOSITORY_FLUSHINTERVAL":
+			cfg.Repository.FlushInterval, _ = time.ParseDuration(pair[1])
+		case "GEOCATALOGO_REPOSITORY_RETRIES":
+			cfg.Repository.Retries, _ = strconv.Atoi(pair[1])
+		case "GEOCATALOGO_REPOSITORY_PERSIST":
+			cfg.Repository.Persist, _ = strconv.ParseBool(pair[1])
+		case "GEOCATALOGO_REPOSITORY_SNAPSHOTINTERVAL":
+			cfg.Repository.SnapshotInterval, _ = time.ParseDuration(pair[1])
+		case "GEOCATALOGO_REPOSITORY_STRICT":
+			cfg.Repository.Strict, _ = strconv.ParseBool(pair[1])
 		default:
 			if strings.HasPrefix(pair[0], "GEOCATALOGO_REPOSITORY_MAPPINGS") {
 				tokens := strings.Split(pair[0], "GEOCATALOGO_REPOSITORY_MAPPINGS_")
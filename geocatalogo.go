@@ -28,7 +28,10 @@
 package geocatalogo
 
 import (
+	"context"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -105,20 +108,79 @@ func (c *GeoCatalogue) Index(record metadata.Record) bool {
 	return true
 }
 
-// UnIndex removes a metadata record to the Index
-func (c *GeoCatalogue) UnIndex() bool {
-	return c.Repository.Delete()
+// IndexBatch adds a batch of metadata records to the Index
+func (c *GeoCatalogue) IndexBatch(records []metadata.Record) (repository.BulkResult, error) {
+	log.Infof("Indexing batch of %d records", len(records))
+	result, err := c.Repository.BulkInsert(records)
+	if err != nil {
+		log.Errorf("Batch indexing failed: %v", err)
+		return result, err
+	}
+	for _, e := range result.Errors {
+		log.Errorf("Indexing %s failed: %s", e.Identifier, e.Reason)
+	}
+	return result, nil
 }
 
-// Search performs a search/query against the Index
-func (c *GeoCatalogue) Search(collections []string, term string, bbox []float64, timeVal []time.Time, from int, size int) search.Results {
+// Update replaces a metadata record in the Index
+func (c *GeoCatalogue) Update(record metadata.Record) bool {
+	log.Info("Updating " + record.Identifier)
+	err := c.Repository.Update(record)
+	if err != nil {
+		log.Errorf("Updating failed: %v", err)
+		return false
+	}
+	return true
+}
+
+// UnIndex removes metadata records from the Index
+func (c *GeoCatalogue) UnIndex(identifiers []string) bool {
+	log.Info("Removing " + strings.Join(identifiers, ","))
+	err := c.Repository.Delete(identifiers)
+	if err != nil {
+		log.Errorf("Removing failed: %v", err)
+		return false
+	}
+	return true
+}
+
+// UnIndexByQuery removes all metadata records belonging to the given
+// collections and/or sources from the Index, returning the number of
+// records removed
+func (c *GeoCatalogue) UnIndexByQuery(collections []string, sources []string) (int, bool) {
+	log.Infof("Removing records (collections: %v, sources: %v)", collections, sources)
+	count, err := c.Repository.DeleteByQuery(collections, sources)
+	if err != nil {
+		log.Errorf("Removing failed: %v", err)
+		return count, false
+	}
+	return count, true
+}
+
+// Query performs a search/query against the Index.  Queries the
+// repository cannot run fail with repository.ErrInvalidQuery
+func (c *GeoCatalogue) Query(ctx context.Context, q search.Query) (search.Results, error) {
 	sr := search.Results{}
 	log.Info("Searching index")
-	err := c.Repository.Query(collections, term, bbox, timeVal, from, size, &sr)
+	err := c.Repository.Query(ctx, q, &sr)
 	if err != nil {
-		log.Warn(err)
-		return sr
+		log.Warnf("Searching failed: %v", err)
+		return search.Results{}, err
 	}
+	return sr, nil
+}
+
+// Search performs a search/query against the Index (see Query).  Errors
+// are logged, and give empty results
+func (c *GeoCatalogue) Search(collections []string, term string, bbox []float64, timeVal []time.Time, from int, size int) search.Results {
+	sr, _ := c.Query(context.Background(), search.Query{
+		Collections: collections,
+		Term:        term,
+		BBox:        bbox,
+		Time:        timeVal,
+		From:        from,
+		Size:        size,
+	})
 	return sr
 }
 
@@ -133,3 +195,80 @@ func (c *GeoCatalogue) Get(identifiers []string) search.Results {
 	}
 	return sr
 }
+
+// PutCollection adds or replaces a collection in the Index
+func (c *GeoCatalogue) PutCollection(collection metadata.Collection) error {
+	log.Info("Storing collection " + collection.Identifier)
+	err := c.Repository.PutCollection(collection)
+	if err != nil {
+		log.Errorf("Storing collection failed: %v", err)
+	}
+	return err
+}
+
+// DeleteCollection removes a collection from the Index.  Records in
+// the collection are kept
+func (c *GeoCatalogue) DeleteCollection(id string) error {
+	log.Info("Removing collection " + id)
+	err := c.Repository.DeleteCollection(id)
+	if err != nil {
+		log.Errorf("Removing collection failed: %v", err)
+	}
+	return err
+}
+
+// Collection retrieves a collection from the Index.  The extent is
+// computed from the records in the collection, falling back to the
+// stored extent for collections without records
+func (c *GeoCatalogue) Collection(id string) (metadata.Collection, error) {
+	collection, err := c.Repository.GetCollection(id)
+	if err != nil {
+		return collection, err
+	}
+	return c.withExtent(collection), nil
+}
+
+// Collections retrieves all collections from the Index (see Collection)
+func (c *GeoCatalogue) Collections() ([]metadata.Collection, error) {
+	collections, err := c.Repository.ListCollections()
+	if err != nil {
+		return nil, err
+	}
+	for i := range collections {
+		collections[i] = c.withExtent(collections[i])
+	}
+	return collections, nil
+}
+
+// withExtent sets the extent of a collection from its records
+func (c *GeoCatalogue) withExtent(collection metadata.Collection) metadata.Collection {
+	extent, err := c.Repository.CollectionExtent(collection.Identifier)
+	if err != nil {
+		log.Warnf("Computing extent of %s failed: %v", collection.Identifier, err)
+		return collection
+	}
+	if !extent.IsEmpty() {
+		collection.Extent = extent
+	}
+	return collection
+}
+
+// Reload reloads the Index from its backing store, for repositories
+// which support it
+func (c *GeoCatalogue) Reload() error {
+	reloader, ok := c.Repository.(repository.Reloader)
+	if !ok {
+		return nil
+	}
+	log.Info("Reloading repository")
+	return reloader.Reload()
+}
+
+// Close releases the repository, flushing any pending changes
+func (c *GeoCatalogue) Close() error {
+	closer, ok := c.Repository.(io.Closer)
+	if !ok {
+		return nil
+	}
+	return closer.Close()
+}
@@ -0,0 +1,129 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package metadata
+
+import (
+	"math"
+	"time"
+)
+
+// Provider describes an organization providing a collection
+type Provider struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	URL         string   `json:"url,omitempty"`
+}
+
+// SpatialExtent provides the bounding boxes of a collection, the first
+// of which covers all items
+type SpatialExtent struct {
+	BBox [][4]float64 `json:"bbox"`
+}
+
+// TemporalExtent provides the time intervals of a collection, the
+// first of which covers all items.  A nil bound is open
+type TemporalExtent struct {
+	Interval [][2]*time.Time `json:"interval"`
+}
+
+// Extent describes the spatial and temporal extent of a collection
+type Extent struct {
+	Spatial  SpatialExtent  `json:"spatial"`
+	Temporal TemporalExtent `json:"temporal"`
+}
+
+// Collection describes a collection of records
+type Collection struct {
+	Identifier  string                 `json:"id"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description"`
+	Keywords    []string               `json:"keywords,omitempty"`
+	License     string                 `json:"license"`
+	Providers   []Provider             `json:"providers,omitempty"`
+	Extent      Extent                 `json:"extent"`
+	Summaries   map[string]interface{} `json:"summaries,omitempty"`
+	Links       []Link                 `json:"links,omitempty"`
+}
+
+// IsEmpty reports whether an extent has neither bounding boxes nor
+// intervals
+func (e *Extent) IsEmpty() bool {
+	return len(e.Spatial.BBox) == 0 && len(e.Temporal.Interval) == 0
+}
+
+// Include grows an extent to cover a record: its bounding box (or the
+// bounds of its geometry), and its datetime or temporal extent
+func (e *Extent) Include(r *Record) {
+	bbox := r.BoundingBox
+	if bbox == ([4]float64{}) && !r.Geometry.IsEmpty() {
+		bbox = r.Geometry.Bounds()
+	}
+	if bbox != ([4]float64{}) {
+		if len(e.Spatial.BBox) == 0 {
+			e.Spatial.BBox = [][4]float64{bbox}
+		} else {
+			e.Spatial.BBox[0] = unionBBox(e.Spatial.BBox[0], bbox)
+		}
+	}
+
+	var start, end *time.Time
+	if r.Properties.Datetime != nil {
+		start, end = r.Properties.Datetime, r.Properties.Datetime
+	} else if te := r.Properties.TemporalExtent; te != nil && (te.Begin != nil || te.End != nil) {
+		start, end = te.Begin, te.End
+	} else {
+		return
+	}
+
+	if len(e.Temporal.Interval) == 0 {
+		e.Temporal.Interval = [][2]*time.Time{{copyTime(start), copyTime(end)}}
+		return
+	}
+	interval := &e.Temporal.Interval[0]
+	if start == nil || interval[0] != nil && start.Before(*interval[0]) {
+		interval[0] = copyTime(start)
+	}
+	if end == nil || interval[1] != nil && end.After(*interval[1]) {
+		interval[1] = copyTime(end)
+	}
+}
+
+// copyTime copies a time, so that extents do not share times with records
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+// unionBBox computes the bounding box covering two bounding boxes,
+// either of which may cross the antimeridian
+func unionBBox(a, b [4]float64) [4]float64 {
+	minx, maxx := combineLongitudes([]lonRange{{a[0], a[2]}, {b[0], b[2]}})
+	return [4]float64{minx, math.Min(a[1], b[1]), maxx, math.Max(a[3], b[3])}
+}
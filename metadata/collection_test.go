@@ -0,0 +1,39 @@
+package metadata_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+func TestExtentInclude(t *testing.T) {
+	t1 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	var extent metadata.Extent
+	if !extent.IsEmpty() {
+		t.Error("expected zero extent to be empty")
+	}
+
+	var r metadata.Record
+	r.BoundingBox = [4]float64{170, 0, 175, 5}
+	r.Properties.Datetime = &t1
+	extent.Include(&r)
+
+	// bounds are derived from the geometry when there is no bbox
+	r = metadata.Record{Geometry: metadata.NewEnvelope([4]float64{178, 5, -175, 10})}
+	r.Properties.TemporalExtent = &metadata.Temporal{Begin: &t2}
+	extent.Include(&r)
+
+	if extent.Spatial.BBox[0] != [4]float64{170, 0, -175, 10} {
+		t.Errorf("expected bbox crossing the antimeridian, got %v", extent.Spatial.BBox[0])
+	}
+	interval := extent.Temporal.Interval[0]
+	if interval[0] == nil || !interval[0].Equal(t1) || interval[1] != nil {
+		t.Errorf("expected open interval starting %s, got %v", t1, interval)
+	}
+	if interval[0] == r.Properties.TemporalExtent.Begin {
+		t.Error("expected extent not to share times with records")
+	}
+}
@@ -0,0 +1,367 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Position provides a longitude, latitude pair
+type Position [2]float64
+
+// Geometry provides a GeoJSON geometry.  Coordinates are held in the
+// field matching the nesting depth of Type:
+//
+//	Point                        Point
+//	LineString, MultiPoint       Line
+//	Polygon, MultiLineString     Rings
+//	MultiPolygon                 Polygons
+//	GeometryCollection           Geometries
+//
+// The zero Geometry is empty and encodes as null
+type Geometry struct {
+	Type       string
+	Point      Position
+	Line       []Position
+	Rings      [][]Position
+	Polygons   [][][]Position
+	Geometries []Geometry
+}
+
+// NewPoint creates a Point geometry
+func NewPoint(x, y float64) Geometry {
+	return Geometry{Type: "Point", Point: Position{x, y}}
+}
+
+// NewLineString creates a LineString geometry
+func NewLineString(positions ...Position) Geometry {
+	return Geometry{Type: "LineString", Line: positions}
+}
+
+// NewPolygon creates a Polygon geometry from an exterior ring and
+// optional interior rings
+func NewPolygon(rings ...[]Position) Geometry {
+	return Geometry{Type: "Polygon", Rings: rings}
+}
+
+// NewMultiPolygon creates a MultiPolygon geometry
+func NewMultiPolygon(polygons ...[][]Position) Geometry {
+	return Geometry{Type: "MultiPolygon", Polygons: polygons}
+}
+
+// NewGeometryCollection creates a GeometryCollection
+func NewGeometryCollection(geometries ...Geometry) Geometry {
+	return Geometry{Type: "GeometryCollection", Geometries: geometries}
+}
+
+// NewEnvelope creates a Polygon from a bounding box (minx, miny, maxx,
+// maxy).  A bounding box crossing the antimeridian (minx > maxx)
+// creates a MultiPolygon split at the antimeridian
+func NewEnvelope(bbox [4]float64) Geometry {
+	ring := func(minx, miny, maxx, maxy float64) [][]Position {
+		return [][]Position{{
+			{minx, miny}, {maxx, miny}, {maxx, maxy}, {minx, maxy}, {minx, miny},
+		}}
+	}
+	if bbox[0] > bbox[2] {
+		return NewMultiPolygon(
+			ring(bbox[0], bbox[1], 180, bbox[3]),
+			ring(-180, bbox[1], bbox[2], bbox[3]),
+		)
+	}
+	return NewPolygon(ring(bbox[0], bbox[1], bbox[2], bbox[3])...)
+}
+
+// IsEmpty reports whether a geometry has no coordinates
+func (g *Geometry) IsEmpty() bool {
+	switch g.Type {
+	case "Point":
+		return false
+	case "LineString", "MultiPoint":
+		return len(g.Line) == 0
+	case "Polygon", "MultiLineString":
+		return len(g.Rings) == 0 || len(g.Rings[0]) == 0
+	case "MultiPolygon":
+		return len(g.Polygons) == 0
+	case "GeometryCollection":
+		for i := range g.Geometries {
+			if !g.Geometries[i].IsEmpty() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+	Geometries  []Geometry      `json:"geometries,omitempty"`
+}
+
+// MarshalJSON encodes a geometry as GeoJSON
+func (g Geometry) MarshalJSON() ([]byte, error) {
+	var coordinates interface{}
+	switch g.Type {
+	case "":
+		return []byte("null"), nil
+	case "Point":
+		coordinates = g.Point
+	case "LineString", "MultiPoint":
+		coordinates = g.Line
+	case "Polygon", "MultiLineString":
+		coordinates = g.Rings
+	case "MultiPolygon":
+		coordinates = g.Polygons
+	case "GeometryCollection":
+		geometries := g.Geometries
+		if geometries == nil {
+			geometries = []Geometry{}
+		}
+		return json.Marshal(struct {
+			Type       string     `json:"type"`
+			Geometries []Geometry `json:"geometries"`
+		}{g.Type, geometries})
+	default:
+		return nil, fmt.Errorf("unsupported geometry type %q", g.Type)
+	}
+	data, err := json.Marshal(coordinates)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		data = []byte("[]")
+	}
+	return json.Marshal(geoJSONGeometry{Type: g.Type, Coordinates: data})
+}
+
+// UnmarshalJSON decodes a GeoJSON geometry.  Positions are truncated
+// to two dimensions
+func (g *Geometry) UnmarshalJSON(data []byte) error {
+	*g = Geometry{}
+	if string(data) == "null" {
+		return nil
+	}
+	var raw geoJSONGeometry
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var target interface{}
+	switch raw.Type {
+	case "":
+		return nil
+	case "Point":
+		target = &g.Point
+	case "LineString", "MultiPoint":
+		target = &g.Line
+	case "Polygon", "MultiLineString":
+		target = &g.Rings
+	case "MultiPolygon":
+		target = &g.Polygons
+	case "GeometryCollection":
+		g.Type = raw.Type
+		g.Geometries = raw.Geometries
+		return nil
+	default:
+		return fmt.Errorf("unsupported geometry type %q", raw.Type)
+	}
+	g.Type = raw.Type
+	if len(raw.Coordinates) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw.Coordinates, target)
+}
+
+// lonRange is a longitude interval; lo > hi if it crosses the
+// antimeridian
+type lonRange struct {
+	lo, hi float64
+}
+
+// Bounds computes the envelope (minx, miny, maxx, maxy) of a geometry.
+// Following RFC 7946, minx > maxx when the geometry crosses the
+// antimeridian.  Empty geometries have a zero envelope
+func (g *Geometry) Bounds() [4]float64 {
+	var lons []lonRange
+	miny, maxy := math.Inf(1), math.Inf(-1)
+
+	addLat := func(positions []Position) {
+		for _, p := range positions {
+			miny = math.Min(miny, p[1])
+			maxy = math.Max(maxy, p[1])
+		}
+	}
+	addPart := func(positions []Position) {
+		if len(positions) > 0 {
+			lons = append(lons, partLongitudes(positions))
+			addLat(positions)
+		}
+	}
+
+	var walk func(g *Geometry)
+	walk = func(g *Geometry) {
+		switch g.Type {
+		case "Point":
+			addPart([]Position{g.Point})
+		case "MultiPoint":
+			for _, p := range g.Line {
+				addPart([]Position{p})
+			}
+		case "LineString":
+			addPart(g.Line)
+		case "Polygon":
+			if len(g.Rings) > 0 {
+				addPart(g.Rings[0])
+			}
+		case "MultiLineString":
+			for _, line := range g.Rings {
+				addPart(line)
+			}
+		case "MultiPolygon":
+			for _, polygon := range g.Polygons {
+				if len(polygon) > 0 {
+					addPart(polygon[0])
+				}
+			}
+		case "GeometryCollection":
+			for i := range g.Geometries {
+				walk(&g.Geometries[i])
+			}
+		}
+	}
+	walk(g)
+
+	if len(lons) == 0 {
+		return [4]float64{}
+	}
+	minx, maxx := combineLongitudes(lons)
+	return [4]float64{minx, miny, maxx, maxy}
+}
+
+// partLongitudes computes the longitude range of a contiguous part.  A
+// segment spanning more than 180 degrees whose ends are not on the
+// antimeridian is taken to cross it
+func partLongitudes(positions []Position) lonRange {
+	x := positions[0][0]
+	lo, hi := x, x
+	// unwrapped longitudes are offset by multiples of 360, rather than
+	// accumulated, to keep them exact
+	offset := 0.0
+	for i := 1; i < len(positions); i++ {
+		prev, next := positions[i-1][0], positions[i][0]
+		dx := next - prev
+		if math.Abs(dx) > 180 && math.Abs(prev) != 180 && math.Abs(next) != 180 {
+			if dx > 0 {
+				offset -= 360
+			} else {
+				offset += 360
+			}
+		}
+		x = next + offset
+		lo = math.Min(lo, x)
+		hi = math.Max(hi, x)
+	}
+	if hi-lo >= 360 {
+		return lonRange{-180, 180}
+	}
+	return lonRange{normalizeLongitude(lo), normalizeLongitude(hi)}
+}
+
+func normalizeLongitude(x float64) float64 {
+	for x > 180 {
+		x -= 360
+	}
+	for x < -180 {
+		x += 360
+	}
+	return x
+}
+
+// combineLongitudes merges the longitude ranges of all parts.  The
+// result crosses the antimeridian if any part does, or if parts meet
+// at the antimeridian from either side
+func combineLongitudes(lons []lonRange) (float64, float64) {
+	var crosses, east, west, global bool
+	for _, r := range lons {
+		switch {
+		case r.lo > r.hi:
+			crosses = true
+		case r.lo == -180 && r.hi == 180:
+			global = true
+		case r.hi == 180:
+			east = true
+		case r.lo == -180:
+			west = true
+		}
+	}
+
+	if global || !crosses && !(east && west) {
+		minx, maxx := math.Inf(1), math.Inf(-1)
+		for _, r := range lons {
+			minx = math.Min(minx, r.lo)
+			maxx = math.Max(maxx, r.hi)
+		}
+		return minx, maxx
+	}
+
+	// combine in [0, 360), where the antimeridian is contiguous
+	minx, maxx := math.Inf(1), math.Inf(-1)
+	for _, r := range lons {
+		lo, hi := r.lo, r.hi
+		switch {
+		case lo > hi:
+			hi += 360
+		case hi <= 0:
+			lo += 360
+			hi += 360
+		case lo < 0:
+			// a part spanning the prime meridian: only the whole
+			// globe contains both
+			return -180, 180
+		}
+		minx = math.Min(minx, lo)
+		maxx = math.Max(maxx, hi)
+	}
+	if maxx-minx >= 360 {
+		return -180, 180
+	}
+	return normalizeLongitude(minx), normalizeLongitude(maxx)
+}
+
+// SplitBBox splits a bounding box crossing the antimeridian (minx >
+// maxx) into its eastern and western parts
+func SplitBBox(bbox [4]float64) [][4]float64 {
+	if bbox[0] > bbox[2] {
+		return [][4]float64{
+			{bbox[0], bbox[1], 180, bbox[3]},
+			{-180, bbox[1], bbox[2], bbox[3]},
+		}
+	}
+	return [][4]float64{bbox}
+}
@@ -0,0 +1,114 @@
+package metadata_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+func TestGeometryJSON(t *testing.T) {
+	cases := []string{
+		`{"type":"Point","coordinates":[-75.7,45.4]}`,
+		`{"type":"MultiPoint","coordinates":[[0,0],[1,1]]}`,
+		`{"type":"LineString","coordinates":[[0,0],[1,1],[2,0]]}`,
+		`{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[2,2],[3,3]]]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[2,1],[2,2],[1,1]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,5],[6,5],[6,6],[5,5]]]]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"LineString","coordinates":[[0,0],[1,1]]}]}`,
+	}
+	for _, c := range cases {
+		var g metadata.Geometry
+		if err := json.Unmarshal([]byte(c), &g); err != nil {
+			t.Errorf("%s: %v", c, err)
+			continue
+		}
+		data, err := json.Marshal(g)
+		if err != nil {
+			t.Errorf("%s: %v", c, err)
+			continue
+		}
+		if string(data) != c {
+			t.Errorf("expected %s, got %s", c, data)
+		}
+	}
+
+	var g metadata.Geometry
+	if err := json.Unmarshal([]byte(`{"type":"Point","coordinates":[1,2,3]}`), &g); err != nil || g.Point != (metadata.Position{1, 2}) {
+		t.Errorf("expected 3D position truncated to 2D, got %v (%v)", g.Point, err)
+	}
+	if err := json.Unmarshal([]byte(`{"type":"Circle","coordinates":[1,2]}`), &g); err == nil {
+		t.Error("expected error for unsupported geometry type")
+	}
+
+	// records without a geometry encode it as null
+	data, _ := json.Marshal(metadata.Record{Identifier: "a"})
+	var r map[string]interface{}
+	json.Unmarshal(data, &r)
+	if r["geometry"] != nil {
+		t.Errorf("expected null geometry, got %v", r["geometry"])
+	}
+}
+
+func TestGeometryBounds(t *testing.T) {
+	var empty metadata.Geometry
+	cases := map[string]struct {
+		geometry metadata.Geometry
+		bounds   [4]float64
+	}{
+		"empty": {empty, [4]float64{}},
+		"point": {metadata.NewPoint(-75.7, 45.4), [4]float64{-75.7, 45.4, -75.7, 45.4}},
+		"non-rectangular polygon": {
+			metadata.NewPolygon([]metadata.Position{{0, 0}, {10, -2}, {12, 8}, {3, 11}, {0, 0}}),
+			[4]float64{0, -2, 12, 11},
+		},
+		"multipolygon": {
+			metadata.NewMultiPolygon(
+				[][]metadata.Position{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				[][]metadata.Position{{{-20, 5}, {-19, 5}, {-19, 30}, {-20, 5}}},
+			),
+			[4]float64{-20, 0, 1, 30},
+		},
+		"collection": {
+			metadata.NewGeometryCollection(metadata.NewPoint(5, 5), metadata.NewLineString(metadata.Position{-1, 2}, metadata.Position{3, 9})),
+			[4]float64{-1, 2, 5, 9},
+		},
+		"polygon crossing the antimeridian": {
+			metadata.NewPolygon([]metadata.Position{{170, -10}, {-170, -10}, {-170, 10}, {170, 10}, {170, -10}}),
+			[4]float64{170, -10, -170, 10},
+		},
+		"parts meeting at the antimeridian": {
+			metadata.NewEnvelope([4]float64{175, -5, -178, 5}),
+			[4]float64{175, -5, -178, 5},
+		},
+		"global": {
+			metadata.NewEnvelope([4]float64{-180, -90, 180, 90}),
+			[4]float64{-180, -90, 180, 90},
+		},
+	}
+	for name, c := range cases {
+		if b := c.geometry.Bounds(); b != c.bounds {
+			t.Errorf("%s: expected %v, got %v", name, c.bounds, b)
+		}
+	}
+
+	point := metadata.NewPoint(0, 0)
+	if !empty.IsEmpty() || point.IsEmpty() {
+		t.Error("unexpected IsEmpty result")
+	}
+}
+
+func TestSplitBBox(t *testing.T) {
+	parts := metadata.SplitBBox([4]float64{170, -10, -170, 10})
+	expected := [][4]float64{{170, -10, 180, 10}, {-180, -10, -170, 10}}
+	if !reflect.DeepEqual(parts, expected) {
+		t.Errorf("expected %v, got %v", expected, parts)
+	}
+	if parts := metadata.SplitBBox([4]float64{0, 0, 1, 1}); len(parts) != 1 {
+		t.Errorf("expected one part, got %v", parts)
+	}
+	if g := metadata.NewEnvelope([4]float64{170, -10, -170, 10}); g.Type != "MultiPolygon" || len(g.Polygons) != 2 {
+		t.Errorf("expected envelope split into a MultiPolygon, got %v", g)
+	}
+}
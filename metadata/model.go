@@ -29,17 +29,22 @@ import (
 	"time"
 )
 
-type keywords struct {
-	Keyword []string
-	Type    string
+// Keywords describes a set of keywords, of a type (e.g. theme, place)
+// and optionally from a thesaurus
+type Keywords struct {
+	Keyword   []string
+	Type      string
+	Thesaurus string `json:",omitempty"`
 }
 
+// Contact describes a responsible party (Value) by its role (Type)
 type Contact struct {
 	Type  string
 	Value string
 }
 
-type date struct {
+// Date describes a date (Value) by its type (e.g. creation, revision)
+type Date struct {
 	Type  string
 	Value string
 }
@@ -57,6 +62,8 @@ type ProductInfo struct {
 	AcquisitionDate   *time.Time `json:"acquisition_date,omitempty"`
 	ProcessingLevel   string     `json:"processing_level,omitempty"`
 	SensorIdentifier  string     `json:"sensor_id,omitempty"`
+	GSD               float64    `json:"gsd,omitempty"`
+	FileSize          int64      `json:"file_size,omitempty"`
 }
 
 // Temporal describes temporal bounds
@@ -65,18 +72,16 @@ type Temporal struct {
 	End   *time.Time `json:"end,omitempty"`
 }
 
-// Link describes link constructs
+// Link describes link constructs (and assets, by Name).  Rel is the
+// relation of a link, and Roles the roles of an asset
 type Link struct {
-	Name        string `json:"name,omitempty"`
-	Type        string `json:"type,omitempty"`
-	Description string `json:"description,omitempty"`
-	Protocol    string `json:"protocol,omitempty"`
-	URL         string `json:"url,omitempty"`
-}
-
-type Geometry struct {
-	Type        string         `json:"type"`
-	Coordinates [][][2]float64 `json:"coordinates"`
+	Name        string   `json:"name,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Protocol    string   `json:"protocol,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Rel         string   `json:"rel,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
 }
 
 type geocatalogo struct {
@@ -92,9 +97,9 @@ type Properties struct {
 	Created        *time.Time   `json:"created,omitempty"`
 	Modified       *time.Time   `json:"modified,omitempty"`
 	Abstract       string       `json:"abstract,omitempty"`
-	KeywordsSets   []keywords   `json:"keywords,omitempty"`
+	KeywordsSets   []Keywords   `json:"keywords,omitempty"`
 	Contacts       []Contact    `json:"contact,omitempty"`
-	Dates          []date       `json:"dates,omitempty"`
+	Dates          []Date       `json:"dates,omitempty"`
 	License        string       `json:"license,omitempty"`
 	Language       string       `json:"language,omitempty"`
 	TemporalExtent *Temporal    `json:"temporal_extent,omitempty"`
@@ -102,6 +107,8 @@ type Properties struct {
 	Geocatalogo    geocatalogo  `json:"_geocatalogo,omitempty"`
 	Datetime       *time.Time   `json:"datetime,omitempty"`
 	Collection     string       `json:"collection,omitempty"`
+	// Extensions holds properties of STAC extensions (e.g. eo:bands)
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
 }
 
 // Record describes a generic metadata record
@@ -113,9 +120,18 @@ type Record struct {
 	Properties  Properties `json:"properties"`
 	Links       []Link     `json:"links,omitempty"`
 	Assets      []Link     `json:"assets,omitempty"`
+	// StacExtensions lists the schemas of the STAC extensions used
+	StacExtensions []string `json:"stac_extensions,omitempty"`
 }
 
-func (g *Geometry) Bounds() [4]float64 {
-	var a = [4]float64{g.Coordinates[0][0][0], g.Coordinates[0][0][1], g.Coordinates[0][2][0], g.Coordinates[0][2][1]}
-	return a
+// Collection returns the collection of a record: properties.collection,
+// else the collection of its product information, if any
+func (r *Record) Collection() string {
+	if r.Properties.Collection != "" {
+		return r.Properties.Collection
+	}
+	if r.Properties.ProductInfo != nil {
+		return r.Properties.ProductInfo.Collection
+	}
+	return ""
 }
@@ -28,7 +28,6 @@ package parsers
 import (
 	"bytes"
 	"encoding/xml"
-	"fmt"
 	"strconv"
 	"strings"
 
@@ -97,19 +96,12 @@ func (e *boundingBox) Maxy() (float64, error) {
 }
 
 // BBox generates a list of minx,miny,maxx,maxy
-func (e *boundingBox) BBox() [][][2]float64 {
+func (e *boundingBox) BBox() [4]float64 {
 	minx, _ := e.Minx()
 	miny, _ := e.Miny()
 	maxx, _ := e.Maxx()
 	maxy, _ := e.Maxy()
-	var a = [][][2]float64{{
-		{minx, miny},
-		{minx, maxy},
-		{maxx, maxy},
-		{maxx, miny},
-		{minx, miny},
-	}}
-	return a
+	return [4]float64{minx, miny, maxx, maxy}
 }
 
 // ParseCSWRecord parses CSWRecord
@@ -132,17 +124,15 @@ func ParseCSWRecord(xmlBuffer []byte) (metadata.Record, error) {
 	metadataRecord.Properties.Type = cswRecord.Type
 	metadataRecord.Properties.Title = cswRecord.Title
 	metadataRecord.Properties.Abstract = cswRecord.Abstract
-	metadataRecord.Geometry.Type = "Polygon"
 
-	fmt.Println(cswRecord)
 	for _, ref := range cswRecord.References {
 		metadataRecord.Links = append(metadataRecord.Links, metadata.Link{URL: ref})
 	}
 
 	if (cswRecord.WGS84BoundingBox != boundingBox{}) {
-		metadataRecord.Geometry.Coordinates = cswRecord.WGS84BoundingBox.BBox()
+		metadataRecord.Geometry = metadata.NewEnvelope(cswRecord.WGS84BoundingBox.BBox())
 	} else if (cswRecord.BoundingBox != boundingBox{}) {
-		metadataRecord.Geometry.Coordinates = cswRecord.BoundingBox.BBox()
+		metadataRecord.Geometry = metadata.NewEnvelope(cswRecord.BoundingBox.BBox())
 	}
 
 	metadataRecord.BoundingBox = metadataRecord.Geometry.Bounds()
@@ -0,0 +1,184 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package parsers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// SchemaDCATUS is the schema of DCAT-US (Project Open Data) data.json
+// catalogs
+const SchemaDCATUS = "https://project-open-data.cio.gov/v1.1/schema"
+
+// DataJSONDistribution provides a DCAT-US distribution
+type DataJSONDistribution struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	DownloadURL string `json:"downloadURL"`
+	AccessURL   string `json:"accessURL"`
+	MediaType   string `json:"mediaType"`
+	Format      string `json:"format"`
+}
+
+// DataJSONDataset provides a DCAT-US dataset
+type DataJSONDataset struct {
+	Type        string   `json:"@type"`
+	Identifier  string   `json:"identifier"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Keyword     []string `json:"keyword"`
+	Theme       []string `json:"theme"`
+	Issued      string   `json:"issued"`
+	Modified    string   `json:"modified"`
+	Publisher   struct {
+		Name string `json:"name"`
+	} `json:"publisher"`
+	ContactPoint struct {
+		Fn       string `json:"fn"`
+		HasEmail string `json:"hasEmail"`
+	} `json:"contactPoint"`
+	AccessLevel  string                 `json:"accessLevel"`
+	License      string                 `json:"license"`
+	Rights       string                 `json:"rights"`
+	Spatial      string                 `json:"spatial"`
+	Temporal     string                 `json:"temporal"`
+	Language     []string               `json:"language"`
+	LandingPage  string                 `json:"landingPage"`
+	IsPartOf     string                 `json:"isPartOf"`
+	Distribution []DataJSONDistribution `json:"distribution"`
+}
+
+// DataJSONCatalog provides a DCAT-US data.json catalog
+type DataJSONCatalog struct {
+	ConformsTo string            `json:"conformsTo"`
+	Dataset    []DataJSONDataset `json:"dataset"`
+}
+
+// ParseDataJSONRecords parses the datasets of a data.json catalog (or
+// of a DCAT-US 1.0 array of datasets)
+func ParseDataJSONRecords(data []byte) ([]metadata.Record, error) {
+	var catalog DataJSONCatalog
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &catalog.Dataset); err != nil {
+			return nil, err
+		}
+	} else if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, err
+	}
+	if len(catalog.Dataset) == 0 {
+		return nil, fmt.Errorf("data.json catalog has no datasets")
+	}
+	records := make([]metadata.Record, 0, len(catalog.Dataset))
+	for _, dataset := range catalog.Dataset {
+		rec, err := DataJSONDataset2Record(dataset)
+		if err != nil {
+			return nil, fmt.Errorf("dataset %q: %s", dataset.Identifier, err)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// ParseDataJSONRecord parses a data.json catalog of a single dataset
+func ParseDataJSONRecord(data []byte) (metadata.Record, error) {
+	return singleRecord(ParseDataJSONRecords(data))
+}
+
+// DataJSONDataset2Record maps a DCAT-US dataset to a record
+func DataJSONDataset2Record(dataset DataJSONDataset) (metadata.Record, error) {
+	var metadataRecord metadata.Record
+	p := &metadataRecord.Properties
+
+	metadataRecord.Type = "Feature"
+	metadataRecord.Identifier = strings.TrimSpace(dataset.Identifier)
+	if metadataRecord.Identifier == "" {
+		return metadataRecord, fmt.Errorf("dataset has no identifier")
+	}
+
+	p.Type = "dataset"
+	p.Title = collapseSpace(dataset.Title)
+	p.Abstract = strings.TrimSpace(dataset.Description)
+	p.Collection = strings.TrimSpace(dataset.IsPartOf)
+	p.License = firstOf(strings.TrimSpace(dataset.License), strings.TrimSpace(dataset.Rights))
+	if len(dataset.Language) > 0 {
+		p.Language = dataset.Language[0]
+	}
+	if t, ok := parseISODate(dataset.Issued); ok {
+		p.Created = &t
+	}
+	// modified may also be a repeating interval (e.g. R/P1D)
+	if t, ok := parseISODate(dataset.Modified); ok {
+		p.Modified = &t
+	}
+
+	appendFGDCKeywords(p, "", "", dataset.Keyword)
+	appendFGDCKeywords(p, "theme", "", dataset.Theme)
+	appendFGDCContact(p, "publisher", dataset.Publisher.Name)
+	appendFGDCContact(p, "pointOfContact", firstOf(dataset.ContactPoint.Fn, strings.TrimPrefix(dataset.ContactPoint.HasEmail, "mailto:")))
+
+	if dataset.Spatial != "" {
+		g, ok, err := parseSpatial(dataset.Spatial)
+		if err != nil {
+			return metadataRecord, err
+		}
+		if ok {
+			metadataRecord.Geometry = g
+			metadataRecord.BoundingBox = g.Bounds()
+		} else {
+			appendFGDCKeywords(p, "place", "", []string{dataset.Spatial})
+		}
+	}
+	if dataset.Temporal != "" {
+		begin, end := parseInterval(dataset.Temporal)
+		setTemporal(p, begin, end)
+	}
+
+	appendUniqueLink(&metadataRecord, metadata.Link{URL: dataset.LandingPage, Protocol: "WWW:LINK"})
+	for _, d := range dataset.Distribution {
+		link := metadata.Link{
+			Name:        d.Title,
+			Description: d.Description,
+			Type:        firstOf(d.MediaType, d.Format),
+			URL:         d.DownloadURL,
+			Protocol:    "WWW:DOWNLOAD",
+		}
+		if link.URL == "" {
+			link.URL, link.Protocol = d.AccessURL, "WWW:LINK"
+		}
+		appendUniqueLink(&metadataRecord, link)
+	}
+
+	p.Geocatalogo.Schema = SchemaDCATUS
+	p.Geocatalogo.Typename = "dcat:Dataset"
+	p.Geocatalogo.Source = "local"
+
+	return metadataRecord, nil
+}
@@ -0,0 +1,111 @@
+package parsers_test
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/parsers"
+)
+
+func TestParseDataJSONRecords(t *testing.T) {
+	source, err := ioutil.ReadFile("testdata/data.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := parsers.ParseDataJSONRecords(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	rec := records[0]
+	p := rec.Properties
+	if rec.Identifier != "https://data.example.gov/id/watershed-boundaries" || p.Title != "Watershed Boundary Dataset" {
+		t.Errorf("unexpected identifier %q title %q", rec.Identifier, p.Title)
+	}
+	if p.Geocatalogo.Schema != parsers.SchemaDCATUS || p.Geocatalogo.Typename != "dcat:Dataset" {
+		t.Errorf("unexpected schema %q typename %q", p.Geocatalogo.Schema, p.Geocatalogo.Typename)
+	}
+	if rec.BoundingBox != [4]float64{-124.7, 24.5, -66.9, 49.4} {
+		t.Errorf("unexpected bbox %v", rec.BoundingBox)
+	}
+	if p.TemporalExtent == nil || !p.TemporalExtent.Begin.Equal(date("2000-01-01T00:00:00Z")) ||
+		!p.TemporalExtent.End.Equal(date("2022-12-31T00:00:00Z")) {
+		t.Errorf("unexpected temporal extent %+v", p.TemporalExtent)
+	}
+	if !p.Created.Equal(date("2012-05-01T00:00:00Z")) || !p.Modified.Equal(date("2023-02-14T08:30:00Z")) {
+		t.Errorf("unexpected dates %v %v", p.Created, p.Modified)
+	}
+	if p.Language != "en-US" || p.License != "https://creativecommons.org/publicdomain/zero/1.0/" {
+		t.Errorf("unexpected language %q license %q", p.Language, p.License)
+	}
+	expectedContacts := []metadata.Contact{
+		{Type: "publisher", Value: "Example Geological Survey"},
+		{Type: "pointOfContact", Value: "Hydrography Team"},
+	}
+	if !reflect.DeepEqual(p.Contacts, expectedContacts) {
+		t.Errorf("expected contacts %+v, got %+v", expectedContacts, p.Contacts)
+	}
+	expectedLinks := []metadata.Link{
+		{Protocol: "WWW:LINK", URL: "https://data.example.gov/watershed-boundaries"},
+		{Name: "Shapefile", Type: "application/zip", Protocol: "WWW:DOWNLOAD", URL: "https://data.example.gov/files/wbd.zip"},
+		{Name: "Map service", Type: "Esri REST", Description: "ArcGIS REST map service", Protocol: "WWW:LINK", URL: "https://services.example.gov/arcgis/rest/services/wbd/MapServer"},
+	}
+	if !reflect.DeepEqual(rec.Links, expectedLinks) {
+		t.Errorf("expected links %+v, got %+v", expectedLinks, rec.Links)
+	}
+
+	rec = records[1]
+	p = rec.Properties
+	if !rec.Geometry.IsEmpty() || len(p.KeywordsSets) != 2 || !reflect.DeepEqual(p.KeywordsSets[1], metadata.Keywords{Keyword: []string{"Alaska"}, Type: "place"}) {
+		t.Errorf("expected place keyword for a named location, got %+v %+v", rec.Geometry, p.KeywordsSets)
+	}
+	if p.Modified != nil {
+		t.Errorf("expected no modified date for a repeating interval, got %v", p.Modified)
+	}
+	if p.TemporalExtent == nil || p.TemporalExtent.Begin == nil || p.TemporalExtent.End != nil {
+		t.Errorf("unexpected temporal extent %+v", p.TemporalExtent)
+	}
+	if p.Collection != "https://data.example.gov/id/weather" || p.Contacts[1].Value != "stations@example.gov" {
+		t.Errorf("unexpected collection %q contacts %+v", p.Collection, p.Contacts)
+	}
+}
+
+func TestParseDataJSONSpatial(t *testing.T) {
+	tests := map[string][4]float64{
+		`{"type": "Polygon", "coordinates": [[[0, 0], [4, 0], [4, 3], [0, 0]]]}`: {0, 0, 4, 3},
+		`MULTIPOINT((1 2), (3 5))`: {1, 2, 3, 5},
+		`-10.5 20 -5 25`:           {-10.5, 20, -5, 25},
+	}
+	for spatial, bbox := range tests {
+		rec, err := parsers.DataJSONDataset2Record(parsers.DataJSONDataset{Identifier: "d", Spatial: spatial})
+		if err != nil {
+			t.Errorf("%s: %s", spatial, err)
+			continue
+		}
+		if rec.BoundingBox != bbox {
+			t.Errorf("%s: expected bbox %v, got %v", spatial, bbox, rec.BoundingBox)
+		}
+	}
+	if _, err := parsers.DataJSONDataset2Record(parsers.DataJSONDataset{Identifier: "d", Spatial: `{"type": "Polygon"`}); err == nil {
+		t.Error("expected error for invalid GeoJSON")
+	}
+}
+
+func TestParseDataJSONErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"conformsTo": "https://project-open-data.cio.gov/v1.1/schema", "dataset": []}`: "no datasets",
+		`[{"title": "untitled"}]`: "no identifier",
+		`{"dataset": {}}`:         "cannot unmarshal",
+	}
+	for source, expected := range tests {
+		if _, err := parsers.ParseDataJSONRecords([]byte(source)); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error %q, got %v", source, expected, err)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package parsers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+const (
+	// NamespaceDCAT is the namespace of DCAT
+	NamespaceDCAT = "http://www.w3.org/ns/dcat#"
+	// NamespaceSchemaOrg is the namespace of schema.org
+	NamespaceSchemaOrg = "http://schema.org/"
+
+	namespaceDCT   = "http://purl.org/dc/terms/"
+	namespaceFOAF  = "http://xmlns.com/foaf/0.1/"
+	namespaceLOCN  = "http://www.w3.org/ns/locn#"
+	namespaceRDFS  = "http://www.w3.org/2000/01/rdf-schema#"
+	namespaceSKOS  = "http://www.w3.org/2004/02/skos/core#"
+	namespaceVCard = "http://www.w3.org/2006/vcard/ns#"
+)
+
+// ParseDCATRecords parses the datasets (dcat:Dataset, or schema:Dataset)
+// of a DCAT catalog or dataset document, as JSON-LD or RDF/XML
+func ParseDCATRecords(data []byte) ([]metadata.Record, error) {
+	var g *rdfGraph
+	var err error
+	if bytes.HasPrefix(bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))), []byte("<")) {
+		g, err = parseRDFXML(data)
+	} else {
+		g, err = parseJSONLD(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rdfDatasets(g, NamespaceDCAT+"Dataset", NamespaceSchemaOrg+"Dataset")
+}
+
+// ParseDCATRecord parses a DCAT document of a single dataset
+func ParseDCATRecord(data []byte) (metadata.Record, error) {
+	return singleRecord(ParseDCATRecords(data))
+}
+
+// ParseSchemaOrgDatasets parses the schema.org Datasets of a JSON-LD
+// document (e.g. as embedded in web pages)
+func ParseSchemaOrgDatasets(data []byte) ([]metadata.Record, error) {
+	g, err := parseJSONLD(data)
+	if err != nil {
+		return nil, err
+	}
+	return rdfDatasets(g, NamespaceSchemaOrg+"Dataset")
+}
+
+// ParseSchemaOrgDataset parses a JSON-LD document of a single schema.org
+// Dataset
+func ParseSchemaOrgDataset(data []byte) (metadata.Record, error) {
+	return singleRecord(ParseSchemaOrgDatasets(data))
+}
+
+// singleRecord returns the record of a document expected to hold one
+func singleRecord(records []metadata.Record, err error) (metadata.Record, error) {
+	if err != nil {
+		return metadata.Record{}, err
+	}
+	if len(records) != 1 {
+		return metadata.Record{}, fmt.Errorf("document holds %d datasets, expected 1", len(records))
+	}
+	return records[0], nil
+}
+
+// rdfDatasets maps the resources of a graph having any of the types
+func rdfDatasets(g *rdfGraph, types ...string) ([]metadata.Record, error) {
+	nodes := g.Typed(types...)
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("document has no datasets")
+	}
+	records := make([]metadata.Record, 0, len(nodes))
+	for _, n := range nodes {
+		rec, err := rdfDataset2Record(n)
+		if err != nil {
+			return nil, fmt.Errorf("dataset %q: %s", n.ID, err)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// rdfDataset2Record maps a DCAT or schema.org dataset to a record
+func rdfDataset2Record(n *rdfNode) (metadata.Record, error) {
+	const (
+		dcat   = NamespaceDCAT
+		dct    = namespaceDCT
+		schema = NamespaceSchemaOrg
+	)
+	var metadataRecord metadata.Record
+	p := &metadataRecord.Properties
+
+	metadataRecord.Type = "Feature"
+	for _, v := range n.values([]string{dct + "identifier", schema + "identifier"}) {
+		switch v := v.(type) {
+		case string:
+			metadataRecord.Identifier = strings.TrimSpace(v)
+		case *rdfNode:
+			metadataRecord.Identifier = firstOf(v.Text(schema+"value"), v.IRI())
+		}
+		if metadataRecord.Identifier != "" {
+			break
+		}
+	}
+	metadataRecord.Identifier = firstOf(metadataRecord.Identifier, n.IRI(), n.Text(schema+"url"))
+	if metadataRecord.Identifier == "" {
+		return metadataRecord, fmt.Errorf("dataset has no identifier")
+	}
+
+	p.Type = "dataset"
+	p.Title = collapseSpace(n.Text(dct+"title", schema+"name"))
+	p.Abstract = n.Text(dct+"description", schema+"description")
+	p.Created = rdfDate(n, dct+"issued", schema+"datePublished", dct+"created", schema+"dateCreated")
+	p.Modified = rdfDate(n, dct+"modified", schema+"dateModified")
+	p.Language = lastSegment(n.Text(dct+"language", schema+"inLanguage"))
+	p.License = firstOf(n.Text(dct+"license", schema+"license"), n.Text(dct+"rights"))
+
+	keywords := n.Texts(dcat + "keyword")
+	for _, k := range n.Texts(schema + "keywords") {
+		keywords = append(keywords, strings.Split(k, ",")...)
+	}
+	appendFGDCKeywords(p, "", "", keywords)
+	appendFGDCKeywords(p, "theme", "", n.Texts(dcat+"theme"))
+
+	for _, c := range []struct {
+		role       string
+		properties []string
+	}{
+		{"creator", []string{dct + "creator", schema + "creator", schema + "author"}},
+		{"publisher", []string{dct + "publisher", schema + "publisher"}},
+		{"pointOfContact", []string{dcat + "contactPoint", schema + "contactPoint"}},
+	} {
+		for _, v := range n.values(c.properties) {
+			switch v := v.(type) {
+			case string:
+				appendFGDCContact(p, c.role, v)
+			case *rdfNode:
+				email := v.Text(namespaceVCard+"hasEmail", namespaceFOAF+"mbox", schema+"email")
+				appendFGDCContact(p, c.role, firstOf(v.Label(), strings.TrimPrefix(email, "mailto:"), v.IRI()))
+			}
+		}
+	}
+
+	var places []string
+	for _, v := range n.values([]string{dct + "spatial", schema + "spatialCoverage"}) {
+		var g metadata.Geometry
+		var label string
+		switch v := v.(type) {
+		case string:
+			geometry, ok, err := parseSpatial(v)
+			if err != nil {
+				return metadataRecord, err
+			}
+			if ok {
+				g = geometry
+			} else {
+				label = v
+			}
+		case *rdfNode:
+			for _, s := range v.Texts(namespaceLOCN+"geometry", dcat+"bbox", dcat+"centroid") {
+				geometry, ok, err := parseSpatial(s)
+				if err != nil {
+					return metadataRecord, err
+				}
+				if ok {
+					g = geometry
+					break
+				}
+			}
+			for _, geo := range v.Nodes(schema + "geo") {
+				if g.IsEmpty() {
+					g = schemaOrgGeo(geo)
+				}
+			}
+			label = v.Label()
+		}
+		if !g.IsEmpty() && metadataRecord.Geometry.IsEmpty() {
+			metadataRecord.Geometry = g
+			metadataRecord.BoundingBox = g.Bounds()
+		}
+		if label != "" {
+			places = append(places, label)
+		}
+	}
+	appendFGDCKeywords(p, "place", "", places)
+
+	for _, v := range n.values([]string{dct + "temporal", schema + "temporalCoverage"}) {
+		var begin, end *time.Time
+		switch v := v.(type) {
+		case string:
+			begin, end = parseInterval(v)
+		case *rdfNode:
+			begin = rdfDate(v, dcat+"startDate", schema+"startDate")
+			end = rdfDate(v, dcat+"endDate", schema+"endDate")
+		}
+		if begin != nil || end != nil {
+			setTemporal(p, begin, end)
+			break
+		}
+	}
+
+	for _, url := range n.Texts(dcat+"landingPage", schema+"url") {
+		appendUniqueLink(&metadataRecord, metadata.Link{URL: url, Protocol: "WWW:LINK"})
+	}
+	for _, d := range n.Nodes(dcat+"distribution", schema+"distribution") {
+		link := metadata.Link{
+			Name:        d.Text(dct+"title", schema+"name"),
+			Description: d.Text(dct+"description", schema+"description"),
+			Type:        mediaType(d.Text(dcat+"mediaType", schema+"encodingFormat", dct+"format")),
+			URL:         d.Text(dcat+"downloadURL", schema+"contentUrl"),
+			Protocol:    "WWW:DOWNLOAD",
+		}
+		if link.URL == "" {
+			link.URL, link.Protocol = d.Text(dcat+"accessURL", schema+"url"), "WWW:LINK"
+		}
+		appendUniqueLink(&metadataRecord, link)
+	}
+
+	if n.Is(dcat + "Dataset") {
+		p.Geocatalogo.Schema = NamespaceDCAT
+		p.Geocatalogo.Typename = "dcat:Dataset"
+	} else {
+		p.Geocatalogo.Schema = NamespaceSchemaOrg
+		p.Geocatalogo.Typename = "schema:Dataset"
+	}
+	p.Geocatalogo.Source = "local"
+
+	return metadataRecord, nil
+}
+
+// rdfDate returns the first date of the properties of a resource
+func rdfDate(n *rdfNode, properties ...string) *time.Time {
+	if t, ok := parseISODate(n.Text(properties...)); ok {
+		return &t
+	}
+	return nil
+}
+
+// schemaOrgGeo maps a schema.org GeoShape (box or polygon) or
+// GeoCoordinates to a geometry
+func schemaOrgGeo(n *rdfNode) metadata.Geometry {
+	const schema = NamespaceSchemaOrg
+
+	// box and polygon are lists of latitude longitude pairs
+	if values, ok := parseFloats(n.Text(schema + "box")); ok && len(values) == 4 {
+		return metadata.NewEnvelope([4]float64{values[1], values[0], values[3], values[2]})
+	}
+	if values, ok := parseFloats(n.Text(schema + "polygon")); ok && len(values) >= 8 && len(values)%2 == 0 {
+		var ring []metadata.Position
+		for i := 0; i < len(values); i += 2 {
+			ring = append(ring, metadata.Position{values[i+1], values[i]})
+		}
+		return metadata.NewPolygon(ring)
+	}
+	lat, err1 := strconv.ParseFloat(n.Text(schema+"latitude"), 64)
+	lon, err2 := strconv.ParseFloat(n.Text(schema+"longitude"), 64)
+	if err1 == nil && err2 == nil {
+		return metadata.NewPoint(lon, lat)
+	}
+	return metadata.Geometry{}
+}
+
+// wktGeometries are the WKT geometry types recognized by parseSpatial
+var wktGeometries = []string{"point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection"}
+
+// parseSpatial parses a geometry literal: WKT (optionally prefixed by a
+// GeoSPARQL CRS IRI), GeoJSON or a "west,south,east,north" bounding box.
+// Other values (e.g. place names) are not geometries
+func parseSpatial(value string) (metadata.Geometry, bool, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "<") {
+		if i := strings.Index(value, ">"); i > 0 {
+			value = strings.TrimSpace(value[i+1:])
+		}
+	}
+
+	if strings.HasPrefix(value, "{") {
+		var g metadata.Geometry
+		if err := json.Unmarshal([]byte(value), &g); err != nil {
+			return g, false, fmt.Errorf("invalid GeoJSON geometry: %s", err)
+		}
+		return g, !g.IsEmpty(), nil
+	}
+	if i := strings.IndexAny(value, "( "); i > 0 && contains(wktGeometries, strings.ToLower(value[:i])) {
+		g, err := metadata.ParseWKT(value)
+		if err != nil {
+			return g, false, fmt.Errorf("invalid WKT geometry: %s", err)
+		}
+		return g, true, nil
+	}
+	if values, ok := parseFloats(value); ok && len(values) == 4 {
+		return metadata.NewEnvelope([4]float64{values[0], values[1], values[2], values[3]}), true, nil
+	}
+	return metadata.Geometry{}, false, nil
+}
+
+// parseFloats parses a list of numbers separated by commas or spaces
+func parseFloats(value string) ([]float64, bool) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	if len(fields) == 0 {
+		return nil, false
+	}
+	values := make([]float64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, false
+		}
+		values[i] = v
+	}
+	return values, true
+}
+
+// parseInterval parses an ISO 8601 instant or interval (start/end, ".."
+// or empty for open bounds, optionally prefixed by a repetition R/)
+func parseInterval(value string) (*time.Time, *time.Time) {
+	parts := strings.Split(strings.TrimSpace(value), "/")
+	if len(parts) == 3 && strings.HasPrefix(parts[0], "R") {
+		parts = parts[1:]
+	}
+	bound := func(s string) *time.Time {
+		if t, ok := parseISODate(s); ok {
+			return &t
+		}
+		return nil
+	}
+	switch len(parts) {
+	case 1:
+		t := bound(parts[0])
+		return t, t
+	case 2:
+		return bound(parts[0]), bound(parts[1])
+	}
+	return nil, nil
+}
+
+// setTemporal sets the datetime (for an instant) or temporal extent
+func setTemporal(p *metadata.Properties, begin *time.Time, end *time.Time) {
+	switch {
+	case begin != nil && end != nil && begin.Equal(*end):
+		p.Datetime = begin
+	case begin != nil || end != nil:
+		p.TemporalExtent = &metadata.Temporal{Begin: begin, End: end}
+	}
+}
+
+// appendUniqueLink adds a link, once per URL
+func appendUniqueLink(rec *metadata.Record, link metadata.Link) {
+	if link.URL = strings.TrimSpace(link.URL); link.URL == "" {
+		return
+	}
+	for _, l := range rec.Links {
+		if l.URL == link.URL {
+			return
+		}
+	}
+	rec.Links = append(rec.Links, link)
+}
+
+// mediaType returns the media type of an IANA media type IRI, the last
+// segment of other IRIs (e.g. EU file types), else the value
+func mediaType(value string) string {
+	if i := strings.Index(value, "/media-types/"); i >= 0 {
+		return value[i+len("/media-types/"):]
+	}
+	return lastSegment(value)
+}
+
+// lastSegment returns the last path segment of an IRI, else the value
+func lastSegment(value string) string {
+	if !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+		return value
+	}
+	value = strings.TrimRight(value, "/#")
+	return value[strings.LastIndexAny(value, "/#")+1:]
+}
@@ -0,0 +1,215 @@
+package parsers_test
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/parsers"
+	"github.com/go-spatial/geocatalogo/metadata/writers"
+)
+
+func TestParseDCATJSONLD(t *testing.T) {
+	rec := parseFixture(t, "dcat-ap.jsonld", parsers.ParseDCATRecord)
+	p := rec.Properties
+
+	if rec.Identifier != "air-quality-2021" || p.Title != "Air quality measurements 2021" {
+		t.Errorf("unexpected identifier %q title %q", rec.Identifier, p.Title)
+	}
+	if p.Geocatalogo.Schema != parsers.NamespaceDCAT || p.Geocatalogo.Typename != "dcat:Dataset" {
+		t.Errorf("unexpected schema %q typename %q", p.Geocatalogo.Schema, p.Geocatalogo.Typename)
+	}
+	if p.Abstract != "Hourly NO2 and PM10 concentrations." || p.Language != "ENG" {
+		t.Errorf("unexpected abstract %q language %q", p.Abstract, p.Language)
+	}
+	if p.License != "https://creativecommons.org/licenses/by/4.0/" {
+		t.Errorf("unexpected license %q", p.License)
+	}
+	if !p.Created.Equal(date("2022-01-15T00:00:00Z")) || !p.Modified.Equal(date("2022-03-01T10:00:00Z")) {
+		t.Errorf("unexpected dates %v %v", p.Created, p.Modified)
+	}
+	if rec.BoundingBox != [4]float64{5.9, 47.3, 15.0, 55.1} {
+		t.Errorf("unexpected bbox %v", rec.BoundingBox)
+	}
+	if p.TemporalExtent == nil || !p.TemporalExtent.Begin.Equal(date("2021-01-01T00:00:00Z")) ||
+		!p.TemporalExtent.End.Equal(date("2021-12-31T00:00:00Z")) {
+		t.Errorf("unexpected temporal extent %+v", p.TemporalExtent)
+	}
+	if len(p.KeywordsSets) != 2 || !reflect.DeepEqual(p.KeywordsSets[0].Keyword, []string{"air quality", "NO2"}) {
+		t.Errorf("unexpected keywords %+v", p.KeywordsSets)
+	}
+
+	expectedContacts := []metadata.Contact{
+		{Type: "publisher", Value: "Example Environment Agency"},
+		{Type: "pointOfContact", Value: "air@example.eu"},
+	}
+	if !reflect.DeepEqual(p.Contacts, expectedContacts) {
+		t.Errorf("expected contacts %+v, got %+v", expectedContacts, p.Contacts)
+	}
+
+	expectedLinks := []metadata.Link{
+		{Protocol: "WWW:LINK", URL: "https://data.example.eu/air-quality"},
+		{Name: "Measurements (CSV)", Type: "text/csv", Protocol: "WWW:DOWNLOAD", URL: "https://data.example.eu/files/air-quality-2021.csv"},
+	}
+	if !reflect.DeepEqual(rec.Links, expectedLinks) {
+		t.Errorf("expected links %+v, got %+v", expectedLinks, rec.Links)
+	}
+}
+
+func TestParseDCATRDFXML(t *testing.T) {
+	rec := parseFixture(t, "dcat-ap.rdf", parsers.ParseDCATRecord)
+	p := rec.Properties
+
+	if rec.Identifier != "orthophotos-2019" || p.Title != "Orthophotos 2019" {
+		t.Errorf("unexpected identifier %q title %q", rec.Identifier, p.Title)
+	}
+	if rec.BoundingBox != [4]float64{6.6, 51.3, 11.6, 53.9} {
+		t.Errorf("unexpected bbox %v", rec.BoundingBox)
+	}
+	if p.TemporalExtent == nil || !p.TemporalExtent.Begin.Equal(date("2019-04-01T00:00:00Z")) ||
+		!p.TemporalExtent.End.Equal(date("2019-09-30T00:00:00Z")) {
+		t.Errorf("unexpected temporal extent %+v", p.TemporalExtent)
+	}
+	if p.License != "Open Data Licence 2.0" {
+		t.Errorf("unexpected license %q", p.License)
+	}
+
+	expectedKeywords := []metadata.Keywords{
+		{Keyword: []string{"orthophoto", "imagery"}},
+		{Keyword: []string{"Orthoimagery"}, Type: "theme"},
+		{Keyword: []string{"Lower Saxony"}, Type: "place"},
+	}
+	if !reflect.DeepEqual(p.KeywordsSets, expectedKeywords) {
+		t.Errorf("expected keywords %+v, got %+v", expectedKeywords, p.KeywordsSets)
+	}
+	expectedContacts := []metadata.Contact{
+		{Type: "publisher", Value: "Example Mapping Agency"},
+		{Type: "pointOfContact", Value: "Geodata Service Desk"},
+	}
+	if !reflect.DeepEqual(p.Contacts, expectedContacts) {
+		t.Errorf("expected contacts %+v, got %+v", expectedContacts, p.Contacts)
+	}
+
+	expectedLinks := []metadata.Link{
+		{Name: "WMS", Type: "WMS_SRVC", Protocol: "WWW:LINK", URL: "https://geodata.example.org/wms/dop?SERVICE=WMS&REQUEST=GetCapabilities"},
+		{Name: "GeoTIFF tiles", Type: "application/zip", Protocol: "WWW:DOWNLOAD", URL: "https://geodata.example.org/files/dop-2019.zip"},
+	}
+	if !reflect.DeepEqual(rec.Links, expectedLinks) {
+		t.Errorf("expected links %+v, got %+v", expectedLinks, rec.Links)
+	}
+}
+
+func TestParseDCATRecords(t *testing.T) {
+	source := `{
+		"@context": {"dcat": "http://www.w3.org/ns/dcat#", "dct": "http://purl.org/dc/terms/"},
+		"@type": "dcat:Catalog",
+		"dcat:dataset": [
+			{"@id": "https://example.org/a", "@type": "dcat:Dataset", "dct:title": "A", "dct:spatial": "POINT(1 2)"},
+			{"@id": "https://example.org/b", "@type": "dcat:Dataset", "dct:title": "B", "dct:temporal": "2020-05-01"}
+		]
+	}`
+	records, err := parsers.ParseDCATRecords([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Identifier != "https://example.org/a" || records[1].Identifier != "https://example.org/b" {
+		t.Fatalf("unexpected records %+v", records)
+	}
+	if records[0].Geometry.Type != "Point" || records[0].BoundingBox != [4]float64{1, 2, 1, 2} {
+		t.Errorf("unexpected geometry %+v", records[0].Geometry)
+	}
+	if dt := records[1].Properties.Datetime; dt == nil || !dt.Equal(date("2020-05-01T00:00:00Z")) {
+		t.Errorf("expected datetime of an instant, got %v", dt)
+	}
+	if _, err := parsers.ParseDCATRecord([]byte(source)); err == nil {
+		t.Error("expected error parsing a catalog of 2 datasets as a single record")
+	}
+}
+
+func TestParseSchemaOrgDataset(t *testing.T) {
+	source, err := ioutil.ReadFile("testdata/schemaorg-dataset.jsonld")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := parsers.ParseSchemaOrgDataset(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := rec.Properties
+
+	if rec.Identifier != "10.1000/182" || p.Title != "NCDC Storm Events Database" {
+		t.Errorf("unexpected identifier %q title %q", rec.Identifier, p.Title)
+	}
+	if p.Geocatalogo.Schema != parsers.NamespaceSchemaOrg || p.Geocatalogo.Typename != "schema:Dataset" {
+		t.Errorf("unexpected schema %q typename %q", p.Geocatalogo.Schema, p.Geocatalogo.Typename)
+	}
+	// box is "south west north east"
+	if rec.BoundingBox != [4]float64{-180, 18, -65, 72} {
+		t.Errorf("unexpected bbox %v", rec.BoundingBox)
+	}
+	if p.TemporalExtent == nil || !p.TemporalExtent.Begin.Equal(date("1950-01-01T00:00:00Z")) || p.TemporalExtent.End != nil {
+		t.Errorf("unexpected temporal extent %+v", p.TemporalExtent)
+	}
+	expectedKeywords := []metadata.Keywords{
+		{Keyword: []string{"ATMOSPHERE > ATMOSPHERIC PHENOMENA > CYCLONES", "storms", "weather"}},
+		{Keyword: []string{"United States"}, Type: "place"},
+	}
+	if !reflect.DeepEqual(p.KeywordsSets, expectedKeywords) {
+		t.Errorf("expected keywords %+v, got %+v", expectedKeywords, p.KeywordsSets)
+	}
+	if len(p.Contacts) != 1 || p.Contacts[0].Type != "creator" {
+		t.Errorf("unexpected contacts %+v", p.Contacts)
+	}
+	if len(rec.Links) != 3 || rec.Links[1].Protocol != "WWW:DOWNLOAD" || rec.Links[2].Name != "Storm Events Database search" {
+		t.Errorf("unexpected links %+v", rec.Links)
+	}
+}
+
+func TestParseDCATErrors(t *testing.T) {
+	tests := map[string]string{
+		`[]`: "no resources",
+		`{"@context": {"dcat": "http://www.w3.org/ns/dcat#"}, "@type": "dcat:Catalog"}`:                                     "no datasets",
+		`{"@context": {"dcat": "http://www.w3.org/ns/dcat#"}, "@type": "dcat:Dataset"}`:                                     "no identifier",
+		`{"@id": "x", "@type": "http://www.w3.org/ns/dcat#Dataset", "http://purl.org/dc/terms/spatial": "POLYGON((0 0, 1"}`: "invalid WKT",
+		`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"/>`:                                                "no resources",
+		`<DIF/>`: "not an RDF/XML",
+	}
+	for source, expected := range tests {
+		if _, err := parsers.ParseDCATRecords([]byte(source)); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error %q, got %v", source, expected, err)
+		}
+	}
+}
+
+func TestDCATRoundTrip(t *testing.T) {
+	rec := parseFixture(t, "inspire.xml", parsers.ParseISORecord)
+	w, _ := writers.Lookup("dcat")
+	data, err := w.Marshal(&rec, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := parsers.ParseDCATRecord(data)
+	if err != nil {
+		t.Fatalf("parsing DCAT output: %s\n%s", err, data)
+	}
+
+	if parsed.Identifier != rec.Identifier || parsed.Properties.Title != rec.Properties.Title {
+		t.Errorf("identification lost: %q %q", parsed.Identifier, parsed.Properties.Title)
+	}
+	if parsed.BoundingBox != rec.BoundingBox {
+		t.Errorf("expected bbox %v, got %v", rec.BoundingBox, parsed.BoundingBox)
+	}
+	if parsed.Properties.License != rec.Properties.License {
+		t.Errorf("expected license %q, got %q", rec.Properties.License, parsed.Properties.License)
+	}
+	if len(parsed.Links) != len(rec.Links) {
+		t.Fatalf("expected %d links, got %+v", len(rec.Links), parsed.Links)
+	}
+	for i := range rec.Links {
+		if parsed.Links[i].URL != rec.Links[i].URL {
+			t.Errorf("expected link %q, got %q", rec.Links[i].URL, parsed.Links[i].URL)
+		}
+	}
+}
@@ -0,0 +1,410 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package parsers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// NASA Directory Interchange Format (DIF) namespaces: DIF 9 and DIF 10
+const (
+	NamespaceDIF9  = "http://gcmd.gsfc.nasa.gov/Aboutus/xml/dif/"
+	NamespaceDIF10 = "http://gcmd.nasa.gov/Aboutus/xml/dif/"
+)
+
+// difKeyword provides a GCMD science keyword (DIF 9 Parameters or DIF
+// 10 Science_Keywords)
+type difKeyword struct {
+	Category         string `xml:"Category"`
+	Topic            string `xml:"Topic"`
+	Term             string `xml:"Term"`
+	VariableLevel1   string `xml:"Variable_Level_1"`
+	VariableLevel2   string `xml:"Variable_Level_2"`
+	VariableLevel3   string `xml:"Variable_Level_3"`
+	DetailedVariable string `xml:"Detailed_Variable"`
+}
+
+// String returns the keyword path (e.g. EARTH SCIENCE > ATMOSPHERE)
+func (k difKeyword) String() string {
+	return joinLevels(k.Category, k.Topic, k.Term, k.VariableLevel1, k.VariableLevel2, k.VariableLevel3, k.DetailedVariable)
+}
+
+// difLocation provides a GCMD location keyword
+type difLocation struct {
+	Category         string `xml:"Location_Category"`
+	Type             string `xml:"Location_Type"`
+	Subregion1       string `xml:"Location_Subregion1"`
+	Subregion2       string `xml:"Location_Subregion2"`
+	Subregion3       string `xml:"Location_Subregion3"`
+	DetailedLocation string `xml:"Detailed_Location"`
+}
+
+// String returns the location path (e.g. CONTINENT > AFRICA)
+func (l difLocation) String() string {
+	return joinLevels(l.Category, l.Type, l.Subregion1, l.Subregion2, l.Subregion3, l.DetailedLocation)
+}
+
+// difBoundingRectangle provides DIF bounding coordinates
+type difBoundingRectangle struct {
+	South string `xml:"Southernmost_Latitude"`
+	North string `xml:"Northernmost_Latitude"`
+	West  string `xml:"Westernmost_Longitude"`
+	East  string `xml:"Easternmost_Longitude"`
+}
+
+// difPersonnel provides a DIF 9 or DIF 10 contact person (or group)
+type difPersonnel struct {
+	Roles     []string `xml:"Role"`
+	FirstName string   `xml:"First_Name"`
+	LastName  string   `xml:"Last_Name"`
+	Person    struct {
+		FirstName string `xml:"First_Name"`
+		LastName  string `xml:"Last_Name"`
+	} `xml:"Contact_Person"`
+	Group string `xml:"Contact_Group>Name"`
+}
+
+// Name returns the name of a contact person, else group
+func (p difPersonnel) Name() string {
+	name := collapseSpace(p.FirstName + " " + p.LastName)
+	if name == "" {
+		name = collapseSpace(p.Person.FirstName + " " + p.Person.LastName)
+	}
+	if name == "" {
+		name = collapseSpace(p.Group)
+	}
+	return name
+}
+
+// difName provides the short and long names of a DIF organization
+type difName struct {
+	ShortName string `xml:"Short_Name"`
+	LongName  string `xml:"Long_Name"`
+}
+
+// String returns the long name, else the short name
+func (n difName) String() string {
+	return firstOf(collapseSpace(n.LongName), collapseSpace(n.ShortName))
+}
+
+// difCenter provides a DIF 9 Data_Center or a DIF 10 Organization
+type difCenter struct {
+	Types            []string       `xml:"Organization_Type"`
+	DataCenterName   difName        `xml:"Data_Center_Name"`
+	OrganizationName difName        `xml:"Organization_Name"`
+	Personnel        []difPersonnel `xml:"Personnel"`
+}
+
+// difText provides DIF text, given as content in DIF 9 and as a child
+// element in DIF 10 (e.g. Summary>Abstract)
+type difText struct {
+	Text        string `xml:",chardata"`
+	Abstract    string `xml:"Abstract"`
+	Description string `xml:"Description"`
+}
+
+// String returns the child element text, else the content
+func (t difText) String() string {
+	return firstOf(collapseSpace(t.Abstract), collapseSpace(t.Description), collapseSpace(t.Text))
+}
+
+// difEntryID provides a DIF 9 Entry_ID, or a DIF 10 Entry_ID of short
+// name and version
+type difEntryID struct {
+	Text      string `xml:",chardata"`
+	ShortName string `xml:"Short_Name"`
+	Version   string `xml:"Version"`
+}
+
+// difRoles maps DIF personnel and organization roles to ISO roles
+var difRoles = map[string]string{
+	"investigator":      "principalInvestigator",
+	"technical contact": "pointOfContact",
+	"dif author":        "pointOfContact",
+	"metadata author":   "pointOfContact",
+	"service provider":  "resourceProvider",
+	"distributor":       "distributor",
+	"archiver":          "custodian",
+	"originator":        "originator",
+	"processor":         "processor",
+}
+
+// difCitation provides a DIF dataset citation
+type difCitation struct {
+	Creator     string `xml:"Dataset_Creator"`
+	ReleaseDate string `xml:"Dataset_Release_Date"`
+	Publisher   string `xml:"Dataset_Publisher"`
+}
+
+// DIFRecord provides a NASA Directory Interchange Format (DIF 9 or DIF
+// 10) model
+type DIFRecord struct {
+	XMLName           xml.Name
+	EntryId           difEntryID     `xml:"Entry_ID"`
+	EntryTitle        string         `xml:"Entry_Title"`
+	Citations         []difCitation  `xml:"Dataset_Citation"`
+	CitationsDIF9     []difCitation  `xml:"Data_Set_Citation"`
+	Personnel         []difPersonnel `xml:"Personnel"`
+	Parameters        []difKeyword   `xml:"Parameters"`
+	ScienceKeywords   []difKeyword   `xml:"Science_Keywords"`
+	TopicCategories   []string       `xml:"ISO_Topic_Category"`
+	Keywords          []string       `xml:"Keyword"`
+	AncillaryKeywords []string       `xml:"Ancillary_Keyword"`
+	Locations         []difLocation  `xml:"Location"`
+	TemporalCoverage  []struct {
+		StartDate string   `xml:"Start_Date"`
+		StopDate  string   `xml:"Stop_Date"`
+		Beginning string   `xml:"Range_DateTime>Beginning_Date_Time"`
+		Ending    string   `xml:"Range_DateTime>Ending_Date_Time"`
+		Singles   []string `xml:"Single_DateTime"`
+	} `xml:"Temporal_Coverage"`
+	SpatialCoverage []struct {
+		difBoundingRectangle
+		Rectangles []difBoundingRectangle `xml:"Geometry>Bounding_Rectangle"`
+	} `xml:"Spatial_Coverage"`
+	AccessConstraints difText     `xml:"Access_Constraints"`
+	UseConstraints    difText     `xml:"Use_Constraints"`
+	Languages         []string    `xml:"Data_Set_Language"`
+	DatasetLanguages  []string    `xml:"Dataset_Language"`
+	DataCenters       []difCenter `xml:"Data_Center"`
+	Organizations     []difCenter `xml:"Organization"`
+	Summary           difText     `xml:"Summary"`
+	RelatedURLs       []struct {
+		Types       []string `xml:"URL_Content_Type>Type"`
+		URLs        []string `xml:"URL"`
+		Description string   `xml:"Description"`
+	} `xml:"Related_URL"`
+	ParentDIF        string `xml:"Parent_DIF"`
+	CreationDate     string `xml:"DIF_Creation_Date"`
+	RevisionDate     string `xml:"Last_DIF_Revision_Date"`
+	MetadataCreation string `xml:"Metadata_Dates>Metadata_Creation"`
+	MetadataRevision string `xml:"Metadata_Dates>Metadata_Last_Revision"`
+	DataCreation     string `xml:"Metadata_Dates>Data_Creation"`
+}
+
+// ParseDIFRecord parses NASA DIF 9 or DIF 10 metadata
+func ParseDIFRecord(xmlBuffer []byte) (metadata.Record, error) {
+	var difRecord DIFRecord
+	var metadataRecord metadata.Record
+	decoder := xml.NewDecoder(bytes.NewReader(xmlBuffer))
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	if err := decoder.Decode(&difRecord); err != nil {
+		return metadataRecord, err
+	}
+	if difRecord.XMLName.Local != "DIF" {
+		return metadataRecord, fmt.Errorf("not a DIF metadata document: %s", difRecord.XMLName.Local)
+	}
+
+	p := &metadataRecord.Properties
+	metadataRecord.Type = "Feature"
+
+	// DIF 10 identifies entries by short name and version
+	metadataRecord.Identifier = strings.TrimSpace(difRecord.EntryId.Text)
+	if short := strings.TrimSpace(difRecord.EntryId.ShortName); short != "" {
+		metadataRecord.Identifier = short
+		if v := strings.TrimSpace(difRecord.EntryId.Version); v != "" && !strings.EqualFold(v, "not provided") {
+			metadataRecord.Identifier += "_" + v
+		}
+	}
+	if metadataRecord.Identifier == "" {
+		return metadataRecord, fmt.Errorf("DIF record has no Entry_ID")
+	}
+
+	p.Type = "dataset"
+	p.Title = collapseSpace(difRecord.EntryTitle)
+	p.Abstract = difRecord.Summary.String()
+	p.Collection = strings.TrimSpace(difRecord.ParentDIF)
+	p.Language = strings.TrimSpace(firstOf(append(difRecord.DatasetLanguages, difRecord.Languages...)...))
+
+	if t, ok := parseISODate(firstOf(difRecord.DataCreation, difRecord.CreationDate, difRecord.MetadataCreation)); ok {
+		p.Created = &t
+		p.Dates = append(p.Dates, metadata.Date{Type: "creation", Value: t.Format("2006-01-02")})
+	}
+	if t, ok := parseISODate(firstOf(difRecord.MetadataRevision, difRecord.RevisionDate)); ok {
+		p.Modified = &t
+	}
+
+	// contacts
+	for _, c := range append(difRecord.Citations, difRecord.CitationsDIF9...) {
+		appendFGDCContact(p, "author", c.Creator)
+		appendFGDCContact(p, "publisher", c.Publisher)
+		if t, ok := parseISODate(c.ReleaseDate); ok {
+			p.Dates = append(p.Dates, metadata.Date{Type: "publication", Value: t.Format("2006-01-02")})
+		}
+	}
+	for _, person := range difRecord.Personnel {
+		appendFGDCContact(p, difRole(person.Roles, "pointOfContact"), person.Name())
+	}
+	for _, center := range append(difRecord.DataCenters, difRecord.Organizations...) {
+		appendFGDCContact(p, difRole(center.Types, "distributor"), firstOf(center.DataCenterName.String(), center.OrganizationName.String()))
+		for _, person := range center.Personnel {
+			appendFGDCContact(p, difRole(person.Roles, "pointOfContact"), person.Name())
+		}
+	}
+
+	// keywords
+	var science []string
+	for _, k := range append(difRecord.Parameters, difRecord.ScienceKeywords...) {
+		if s := k.String(); s != "" {
+			science = append(science, s)
+		}
+	}
+	appendFGDCKeywords(p, "theme", "GCMD Science Keywords", science)
+	var places []string
+	for _, l := range difRecord.Locations {
+		if s := l.String(); s != "" {
+			places = append(places, s)
+		}
+	}
+	appendFGDCKeywords(p, "place", "GCMD Location Keywords", places)
+	appendFGDCKeywords(p, "", "", append(difRecord.Keywords, difRecord.AncillaryKeywords...))
+	appendFGDCKeywords(p, "isoTopicCategory", "", difRecord.TopicCategories)
+
+	// CMR fills mandatory DIF 10 constraints with "Not provided"
+	var licenses []string
+	for _, constraint := range []difText{difRecord.AccessConstraints, difRecord.UseConstraints} {
+		if c := constraint.String(); c != "" && !strings.EqualFold(c, "none") && !strings.EqualFold(c, "not provided") {
+			licenses = append(licenses, c)
+		}
+	}
+	p.License = strings.Join(licenses, "; ")
+
+	// temporal coverage, as the union of its ranges and dates
+	for _, tc := range difRecord.TemporalCoverage {
+		bounds := [][2]string{{firstOf(tc.StartDate, tc.Beginning), firstOf(tc.StopDate, tc.Ending)}}
+		for _, single := range tc.Singles {
+			bounds = append(bounds, [2]string{single, single})
+		}
+		for _, b := range bounds {
+			begin, bok := parseISODate(b[0])
+			end, eok := parseISODate(b[1])
+			if !bok && !eok {
+				continue
+			}
+			if p.TemporalExtent == nil {
+				p.TemporalExtent = &metadata.Temporal{}
+				if bok {
+					p.TemporalExtent.Begin = &begin
+				}
+				if eok {
+					p.TemporalExtent.End = &end
+				}
+				continue
+			}
+			if bok && (p.TemporalExtent.Begin == nil || begin.Before(*p.TemporalExtent.Begin)) {
+				p.TemporalExtent.Begin = &begin
+			}
+			if !eok {
+				p.TemporalExtent.End = nil
+			} else if p.TemporalExtent.End != nil && end.After(*p.TemporalExtent.End) {
+				p.TemporalExtent.End = &end
+			}
+		}
+	}
+
+	// spatial coverage, as the union of its bounding rectangles
+	var bbox [4]float64
+	hasBBox := false
+	for _, sc := range difRecord.SpatialCoverage {
+		for _, r := range append([]difBoundingRectangle{sc.difBoundingRectangle}, sc.Rectangles...) {
+			if r == (difBoundingRectangle{}) {
+				continue
+			}
+			var e [4]float64
+			for i, v := range []string{r.West, r.South, r.East, r.North} {
+				f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+				if err != nil {
+					return metadataRecord, fmt.Errorf("invalid bounding rectangle: %s", err)
+				}
+				e[i] = f
+			}
+			if !hasBBox {
+				bbox, hasBBox = e, true
+				continue
+			}
+			bbox = [4]float64{min(bbox[0], e[0]), min(bbox[1], e[1]), max(bbox[2], e[2]), max(bbox[3], e[3])}
+		}
+	}
+	if hasBBox {
+		metadataRecord.Geometry = metadata.NewEnvelope(bbox)
+		metadataRecord.BoundingBox = metadataRecord.Geometry.Bounds()
+	}
+
+	for _, r := range difRecord.RelatedURLs {
+		for _, url := range r.URLs {
+			if url = strings.TrimSpace(url); url == "" {
+				continue
+			}
+			link := metadata.Link{URL: url, Description: collapseSpace(r.Description)}
+			if len(r.Types) > 0 {
+				link.Name = collapseSpace(r.Types[0])
+			}
+			metadataRecord.Links = append(metadataRecord.Links, link)
+		}
+	}
+
+	if difRecord.XMLName.Space == "" {
+		p.Geocatalogo.Schema = NamespaceDIF9
+	} else {
+		p.Geocatalogo.Schema = difRecord.XMLName.Space
+	}
+	p.Geocatalogo.Typename = "dif:DIF"
+	p.Geocatalogo.Source = "local"
+
+	return metadataRecord, nil
+}
+
+// difRole maps the first known DIF role to an ISO role, else fallback
+func difRole(roles []string, fallback string) string {
+	for _, role := range roles {
+		if r, ok := difRoles[strings.ToLower(strings.TrimSpace(role))]; ok {
+			return r
+		}
+	}
+	return fallback
+}
+
+// joinLevels joins the levels of a GCMD keyword, up to the first
+// empty level
+func joinLevels(levels ...string) string {
+	var path []string
+	for _, level := range levels {
+		if level = collapseSpace(level); level == "" {
+			break
+		}
+		path = append(path, level)
+	}
+	return strings.Join(path, " > ")
+}
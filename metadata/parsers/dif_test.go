@@ -0,0 +1,126 @@
+package parsers_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/parsers"
+)
+
+func TestParseDIFRecord9(t *testing.T) {
+	rec := parseFixture(t, "dif9.xml", parsers.ParseDIFRecord)
+	p := rec.Properties
+
+	if rec.Identifier != "NSIDC-0051" {
+		t.Errorf("unexpected identifier %q", rec.Identifier)
+	}
+	if !strings.HasPrefix(p.Title, "Sea Ice Concentrations") || !strings.HasPrefix(p.Abstract, "This data set is generated") {
+		t.Errorf("unexpected title %q abstract %q", p.Title, p.Abstract)
+	}
+	if p.Geocatalogo.Schema != parsers.NamespaceDIF9 || p.Geocatalogo.Typename != "dif:DIF" {
+		t.Errorf("unexpected schema %q typename %q", p.Geocatalogo.Schema, p.Geocatalogo.Typename)
+	}
+	if rec.BoundingBox != [4]float64{-180, -90, 180, 90} {
+		t.Errorf("expected union of spatial coverages, got %v", rec.BoundingBox)
+	}
+	if p.TemporalExtent == nil || !p.TemporalExtent.Begin.Equal(date("1978-10-25T00:00:00Z")) || p.TemporalExtent.End != nil {
+		t.Errorf("expected open ended temporal extent, got %+v", p.TemporalExtent)
+	}
+	if !p.Created.Equal(date("1998-02-03T00:00:00Z")) || !p.Modified.Equal(date("2019-06-14T00:00:00Z")) {
+		t.Errorf("unexpected dates %v %v", p.Created, p.Modified)
+	}
+	if p.Language != "English" || p.License != "Please cite the data set when used in publications." {
+		t.Errorf("unexpected language %q license %q", p.Language, p.License)
+	}
+
+	expectedKeywords := []metadata.Keywords{
+		{
+			Keyword: []string{
+				"EARTH SCIENCE > CRYOSPHERE > SEA ICE > SEA ICE CONCENTRATION",
+				"EARTH SCIENCE > OCEANS > SEA ICE > ICE EXTENT",
+			},
+			Type:      "theme",
+			Thesaurus: "GCMD Science Keywords",
+		},
+		{
+			Keyword:   []string{"GEOGRAPHIC REGION > ARCTIC", "GEOGRAPHIC REGION > POLAR"},
+			Type:      "place",
+			Thesaurus: "GCMD Location Keywords",
+		},
+		{Keyword: []string{"passive microwave", "SMMR"}},
+		{Keyword: []string{"OCEANS"}, Type: "isoTopicCategory"},
+	}
+	if !reflect.DeepEqual(p.KeywordsSets, expectedKeywords) {
+		t.Errorf("expected keywords %+v, got %+v", expectedKeywords, p.KeywordsSets)
+	}
+
+	expectedContacts := []metadata.Contact{
+		{Type: "author", Value: "Cavalieri, D. J., C. L. Parkinson, P. Gloersen, and H. J. Zwally"},
+		{Type: "publisher", Value: "NASA DAAC at the National Snow and Ice Data Center"},
+		{Type: "principalInvestigator", Value: "Donald Cavalieri"},
+		{Type: "pointOfContact", Value: "NSIDC User Services"},
+		{Type: "distributor", Value: "NASA Distributed Active Archive Center at the National Snow and Ice Data Center"},
+	}
+	if !reflect.DeepEqual(p.Contacts, expectedContacts) {
+		t.Errorf("expected contacts %+v, got %+v", expectedContacts, p.Contacts)
+	}
+
+	expectedLinks := []metadata.Link{
+		{Name: "GET DATA", Description: "Data access (HTTPS)", URL: "https://n5eil01u.ecs.nsidc.org/PM/NSIDC-0051.001/"},
+		{Name: "VIEW RELATED INFORMATION", URL: "https://nsidc.org/data/nsidc-0051"},
+	}
+	if !reflect.DeepEqual(rec.Links, expectedLinks) {
+		t.Errorf("expected links %+v, got %+v", expectedLinks, rec.Links)
+	}
+}
+
+func TestParseDIFRecord10(t *testing.T) {
+	rec := parseFixture(t, "dif10.xml", parsers.ParseDIFRecord)
+	p := rec.Properties
+
+	if rec.Identifier != "MOD10A1_61" {
+		t.Errorf("unexpected identifier %q", rec.Identifier)
+	}
+	if p.Geocatalogo.Schema != parsers.NamespaceDIF10 {
+		t.Errorf("unexpected schema %q", p.Geocatalogo.Schema)
+	}
+	if p.Abstract != "This global Level-3 data set provides daily snow cover and albedo derived from the MODIS snow cover algorithm." {
+		t.Errorf("unexpected abstract %q", p.Abstract)
+	}
+	if rec.BoundingBox != [4]float64{-180, -90, 180, 90} {
+		t.Errorf("unexpected bbox %v", rec.BoundingBox)
+	}
+	if p.TemporalExtent == nil || !p.TemporalExtent.Begin.Equal(date("2000-02-24T00:00:00Z")) ||
+		!p.TemporalExtent.End.Equal(date("2023-12-31T23:59:59.999Z")) {
+		t.Errorf("unexpected temporal extent %+v", p.TemporalExtent)
+	}
+	if !p.Created.Equal(date("2020-06-22T00:00:00Z")) || !p.Modified.Equal(date("2023-03-01T00:00:00Z")) {
+		t.Errorf("unexpected dates %v %v", p.Created, p.Modified)
+	}
+	if len(p.KeywordsSets) == 0 || p.KeywordsSets[0].Keyword[0] != "EARTH SCIENCE > CRYOSPHERE > SNOW/ICE > SNOW COVER" {
+		t.Errorf("unexpected keywords %+v", p.KeywordsSets)
+	}
+	if p.License != "These data are freely available without restriction." {
+		t.Errorf("unexpected license %q", p.License)
+	}
+	if !reflect.DeepEqual(p.Contacts[0], metadata.Contact{Type: "author", Value: "Hall, D. K. and G. A. Riggs"}) {
+		t.Errorf("unexpected contacts %+v", p.Contacts)
+	}
+	if len(rec.Links) != 1 || rec.Links[0].Name != "GET DATA" {
+		t.Errorf("unexpected links %+v", rec.Links)
+	}
+}
+
+func TestParseDIFRecordErrors(t *testing.T) {
+	tests := map[string]string{
+		`<metadata/>`: "not a DIF",
+		`<DIF xmlns="http://gcmd.nasa.gov/Aboutus/xml/dif/"><Entry_Title>t</Entry_Title></DIF>`: "no Entry_ID",
+	}
+	for source, expected := range tests {
+		if _, err := parsers.ParseDIFRecord([]byte(source)); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error %q, got %v", source, expected, err)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package parsers
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// SchemaFGDC identifies FGDC CSDGM metadata (as in CSW outputSchema)
+const SchemaFGDC = "http://www.opengis.net/cat/csw/csdgm"
+
+// fgdcDate provides an FGDC calendar date and time of day
+type fgdcDate struct {
+	Date string `xml:"caldate"`
+	Time string `xml:"time"`
+}
+
+// fgdcDateRange provides an FGDC range of dates
+type fgdcDateRange struct {
+	BeginDate string `xml:"begdate"`
+	BeginTime string `xml:"begtime"`
+	EndDate   string `xml:"enddate"`
+	EndTime   string `xml:"endtime"`
+}
+
+// fgdcContact provides an FGDC cntinfo, either organization or person
+// primary
+type fgdcContact struct {
+	Organization       string `xml:"cntorgp>cntorg"`
+	OrganizationPerson string `xml:"cntorgp>cntper"`
+	Person             string `xml:"cntperp>cntper"`
+	PersonOrganization string `xml:"cntperp>cntorg"`
+}
+
+// Name returns the primary organization or person of a contact
+func (c fgdcContact) Name() string {
+	return strings.TrimSpace(firstOf(
+		strings.TrimSpace(c.Organization), strings.TrimSpace(c.Person),
+		strings.TrimSpace(c.PersonOrganization), strings.TrimSpace(c.OrganizationPerson)))
+}
+
+// fgdcKeywords provides FGDC theme or place keywords of a thesaurus
+type fgdcKeywords struct {
+	Thesaurus string   `xml:"themekt"`
+	Place     string   `xml:"placekt"`
+	Theme     []string `xml:"themekey"`
+	PlaceKeys []string `xml:"placekey"`
+}
+
+// FGDCRecord provides an FGDC Content Standard for Digital Geospatial
+// Metadata (CSDGM) model
+type FGDCRecord struct {
+	XMLName        xml.Name
+	Identification struct {
+		DatasetId string `xml:"datsetid"`
+		Citation  struct {
+			Originators     []string `xml:"origin"`
+			PublicationDate string   `xml:"pubdate"`
+			Title           string   `xml:"title"`
+			OnlineLinkages  []string `xml:"onlink"`
+		} `xml:"citation>citeinfo"`
+		Abstract   string `xml:"descript>abstract"`
+		TimePeriod struct {
+			Single   []fgdcDate    `xml:"sngdate"`
+			Multiple []fgdcDate    `xml:"mdattim>sngdate"`
+			Range    fgdcDateRange `xml:"rngdates"`
+		} `xml:"timeperd>timeinfo"`
+		Bounding struct {
+			West  string `xml:"westbc"`
+			East  string `xml:"eastbc"`
+			North string `xml:"northbc"`
+			South string `xml:"southbc"`
+		} `xml:"spdom>bounding"`
+		Themes            []fgdcKeywords `xml:"keywords>theme"`
+		Places            []fgdcKeywords `xml:"keywords>place"`
+		AccessConstraints string         `xml:"accconst"`
+		UseConstraints    string         `xml:"useconst"`
+		PointOfContact    fgdcContact    `xml:"ptcontac>cntinfo"`
+	} `xml:"idinfo"`
+	Distributions []struct {
+		Distributor     fgdcContact `xml:"distrib>cntinfo"`
+		NetworkAccesses []struct {
+			Resources []string `xml:"networkr"`
+		} `xml:"stdorder>digform>digtopt>onlinopt>computer>networka"`
+	} `xml:"distinfo"`
+	MetadataDate     string      `xml:"metainfo>metd"`
+	MetadataContact  fgdcContact `xml:"metainfo>metc>cntinfo"`
+	MetadataStandard string      `xml:"metainfo>metstdn"`
+}
+
+// ParseFGDCRecord parses FGDC CSDGM metadata.  Records are identified
+// by their dataset identifier (if any), else their first online
+// linkage, else a digest of their title, publication date and
+// originators
+func ParseFGDCRecord(xmlBuffer []byte) (metadata.Record, error) {
+	var fgdcRecord FGDCRecord
+	var metadataRecord metadata.Record
+	decoder := xml.NewDecoder(bytes.NewReader(xmlBuffer))
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	if err := decoder.Decode(&fgdcRecord); err != nil {
+		return metadataRecord, err
+	}
+	if fgdcRecord.XMLName.Local != "metadata" {
+		return metadataRecord, fmt.Errorf("not an FGDC metadata document: %s", fgdcRecord.XMLName.Local)
+	}
+
+	id := &fgdcRecord.Identification
+	citation := &id.Citation
+	p := &metadataRecord.Properties
+
+	metadataRecord.Type = "Feature"
+	metadataRecord.Identifier = strings.TrimSpace(id.DatasetId)
+	if metadataRecord.Identifier == "" && len(citation.OnlineLinkages) > 0 {
+		metadataRecord.Identifier = strings.TrimSpace(citation.OnlineLinkages[0])
+	}
+	if metadataRecord.Identifier == "" {
+		if strings.TrimSpace(citation.Title) == "" {
+			return metadataRecord, fmt.Errorf("FGDC record has neither identifier nor title")
+		}
+		digest := sha1.Sum([]byte(strings.Join(append([]string{citation.Title, citation.PublicationDate}, citation.Originators...), "\n")))
+		metadataRecord.Identifier = "fgdc-" + hex.EncodeToString(digest[:8])
+	}
+
+	p.Type = "dataset"
+	p.Title = collapseSpace(citation.Title)
+	p.Abstract = strings.TrimSpace(id.Abstract)
+
+	if t, ok := parseFGDCDate(citation.PublicationDate, ""); ok {
+		p.Created = &t
+		p.Dates = append(p.Dates, metadata.Date{Type: "publication", Value: t.Format("2006-01-02")})
+	}
+	if t, ok := parseFGDCDate(fgdcRecord.MetadataDate, ""); ok {
+		p.Modified = &t
+	}
+
+	for _, origin := range citation.Originators {
+		appendFGDCContact(p, "originator", strings.TrimSpace(origin))
+	}
+	appendFGDCContact(p, "pointOfContact", id.PointOfContact.Name())
+	for _, d := range fgdcRecord.Distributions {
+		appendFGDCContact(p, "distributor", d.Distributor.Name())
+	}
+	appendFGDCContact(p, "pointOfContact", fgdcRecord.MetadataContact.Name())
+
+	for _, theme := range id.Themes {
+		appendFGDCKeywords(p, "theme", theme.Thesaurus, theme.Theme)
+	}
+	for _, place := range id.Places {
+		appendFGDCKeywords(p, "place", place.Place, place.PlaceKeys)
+	}
+
+	var licenses []string
+	for _, constraint := range []string{id.AccessConstraints, id.UseConstraints} {
+		if c := strings.TrimSpace(constraint); c != "" && !strings.EqualFold(c, "none") {
+			licenses = append(licenses, c)
+		}
+	}
+	p.License = strings.Join(licenses, "; ")
+
+	// time period of content: a single date, multiple dates or a range
+	tp := &id.TimePeriod
+	var begin, end *time.Time
+	include := func(t time.Time) {
+		if begin == nil || t.Before(*begin) {
+			b := t
+			begin = &b
+		}
+		if end == nil || t.After(*end) {
+			e := t
+			end = &e
+		}
+	}
+	for _, d := range append(tp.Single, tp.Multiple...) {
+		if t, ok := parseFGDCDate(d.Date, d.Time); ok {
+			include(t)
+		}
+	}
+	if tp.Range.BeginDate != "" || tp.Range.EndDate != "" {
+		b, bok := parseFGDCDate(tp.Range.BeginDate, tp.Range.BeginTime)
+		e, eok := parseFGDCDate(tp.Range.EndDate, tp.Range.EndTime)
+		if bok {
+			begin = &b
+		}
+		if eok {
+			end = &e
+		}
+	}
+	switch {
+	case begin != nil && end != nil && begin.Equal(*end):
+		p.Datetime = begin
+	case begin != nil || end != nil:
+		p.TemporalExtent = &metadata.Temporal{Begin: begin, End: end}
+	}
+
+	b := &id.Bounding
+	if b.West != "" || b.East != "" || b.South != "" || b.North != "" {
+		var bbox [4]float64
+		for i, v := range []string{b.West, b.South, b.East, b.North} {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return metadataRecord, fmt.Errorf("invalid bounding coordinates: %s", err)
+			}
+			bbox[i] = f
+		}
+		metadataRecord.Geometry = metadata.NewEnvelope(bbox)
+		metadataRecord.BoundingBox = metadataRecord.Geometry.Bounds()
+	}
+
+	links := make(map[string]bool)
+	appendLink := func(url string, protocol string) {
+		if url = strings.TrimSpace(url); url != "" && !links[url] {
+			links[url] = true
+			metadataRecord.Links = append(metadataRecord.Links, metadata.Link{URL: url, Protocol: protocol})
+		}
+	}
+	for _, link := range citation.OnlineLinkages {
+		appendLink(link, "WWW:LINK")
+	}
+	for _, d := range fgdcRecord.Distributions {
+		for _, access := range d.NetworkAccesses {
+			for _, r := range access.Resources {
+				appendLink(r, "WWW:DOWNLOAD")
+			}
+		}
+	}
+
+	p.Geocatalogo.Schema = SchemaFGDC
+	p.Geocatalogo.Typename = "fgdc:metadata"
+	p.Geocatalogo.Source = "local"
+
+	return metadataRecord, nil
+}
+
+// appendFGDCContact adds a contact, once per role
+func appendFGDCContact(p *metadata.Properties, role string, name string) {
+	contact := metadata.Contact{Type: role, Value: collapseSpace(name)}
+	if contact.Value == "" {
+		return
+	}
+	for _, c := range p.Contacts {
+		if c == contact {
+			return
+		}
+	}
+	p.Contacts = append(p.Contacts, contact)
+}
+
+// appendFGDCKeywords adds a set of keywords of a type and thesaurus
+func appendFGDCKeywords(p *metadata.Properties, keywordType string, thesaurus string, keys []string) {
+	keywords := metadata.Keywords{Type: keywordType}
+	if t := strings.TrimSpace(thesaurus); !strings.EqualFold(t, "none") {
+		keywords.Thesaurus = t
+	}
+	for _, k := range keys {
+		if k = collapseSpace(k); k != "" {
+			keywords.Keyword = append(keywords.Keyword, k)
+		}
+	}
+	if len(keywords.Keyword) > 0 {
+		p.KeywordsSets = append(p.KeywordsSets, keywords)
+	}
+}
+
+// parseFGDCDate parses an FGDC calendar date (YYYY, YYYYMM or
+// YYYYMMDD) and optional time of day (hh, hhmm or hhmmss, optionally
+// followed by a time zone, e.g. Z or -0500).  Unknown and Present are
+// not dates
+func parseFGDCDate(date string, tod string) (time.Time, bool) {
+	date = strings.TrimSpace(date)
+	var t time.Time
+	var err error
+	switch len(date) {
+	case 4:
+		t, err = time.Parse("2006", date)
+	case 6:
+		t, err = time.Parse("200601", date)
+	case 8:
+		t, err = time.Parse("20060102", date)
+	default:
+		// some producers use ISO 8601 dates
+		return parseISODate(date)
+	}
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	tod = strings.TrimSpace(tod)
+	if tod == "" || strings.EqualFold(tod, "unknown") {
+		return t, true
+	}
+	digits := tod
+	for i, r := range tod {
+		if r < '0' || r > '9' {
+			digits = tod[:i]
+			break
+		}
+	}
+	zone := tod[len(digits):]
+	for _, layout := range []string{"150405", "1504", "15"} {
+		if len(layout) != len(digits) {
+			continue
+		}
+		clock, err := time.Parse(layout, digits)
+		if err != nil {
+			return t, true
+		}
+		t = t.Add(time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute + time.Duration(clock.Second())*time.Second)
+	}
+	if zone != "" && zone != "Z" {
+		if z, err := time.Parse("-0700", zone); err == nil {
+			_, offset := z.Zone()
+			t = t.Add(-time.Duration(offset) * time.Second)
+		}
+	}
+	return t, true
+}
+
+// collapseSpace collapses runs of white space, as found in wrapped text
+func collapseSpace(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
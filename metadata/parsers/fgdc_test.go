@@ -0,0 +1,117 @@
+package parsers_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/parsers"
+)
+
+func TestParseFGDCRecordUSGS(t *testing.T) {
+	rec := parseFixture(t, "fgdc-usgs.xml", parsers.ParseFGDCRecord)
+	p := rec.Properties
+
+	if rec.Identifier != "https://www.sciencebase.gov/catalog/item/5f1a2b3c4d5e6f7a8b9c0d1e" {
+		t.Errorf("unexpected identifier %q", rec.Identifier)
+	}
+	if p.Title != "National Hydrography Dataset (NHD) - Subregion 0601, Upper Tennessee" {
+		t.Errorf("unexpected title %q", p.Title)
+	}
+	if !strings.HasPrefix(p.Abstract, "The National Hydrography Dataset") {
+		t.Errorf("unexpected abstract %q", p.Abstract)
+	}
+	if p.Geocatalogo.Schema != parsers.SchemaFGDC || p.Geocatalogo.Typename != "fgdc:metadata" {
+		t.Errorf("unexpected schema %q typename %q", p.Geocatalogo.Schema, p.Geocatalogo.Typename)
+	}
+	if rec.BoundingBox != [4]float64{-84.7316, 34.9708, -81.2187, 37.3059} {
+		t.Errorf("unexpected bbox %v", rec.BoundingBox)
+	}
+	if !p.Created.Equal(date("2015-06-11T00:00:00Z")) || !p.Modified.Equal(date("2020-04-15T00:00:00Z")) {
+		t.Errorf("unexpected dates %v %v", p.Created, p.Modified)
+	}
+	if p.Datetime != nil || p.TemporalExtent == nil ||
+		!p.TemporalExtent.Begin.Equal(date("1999-01-01T00:00:00Z")) || p.TemporalExtent.End != nil {
+		t.Errorf("unexpected temporal extent %+v", p.TemporalExtent)
+	}
+
+	expectedKeywords := []metadata.Keywords{
+		{Keyword: []string{"inlandWaters"}, Type: "theme", Thesaurus: "ISO 19115 Topic Category"},
+		{Keyword: []string{"hydrography", "streams"}, Type: "theme", Thesaurus: "USGS Thesaurus"},
+		{Keyword: []string{"Tennessee", "Virginia"}, Type: "place", Thesaurus: "Geographic Names Information System (GNIS)"},
+	}
+	if !reflect.DeepEqual(p.KeywordsSets, expectedKeywords) {
+		t.Errorf("expected keywords %+v, got %+v", expectedKeywords, p.KeywordsSets)
+	}
+
+	expectedContacts := []metadata.Contact{
+		{Type: "originator", Value: "U.S. Geological Survey"},
+		{Type: "originator", Value: "Jane Doe"},
+		{Type: "pointOfContact", Value: "U.S. Geological Survey, National Geospatial Program"},
+		{Type: "distributor", Value: "U.S. Geological Survey, The National Map"},
+		{Type: "pointOfContact", Value: "John Smith"},
+	}
+	if !reflect.DeepEqual(p.Contacts, expectedContacts) {
+		t.Errorf("expected contacts %+v, got %+v", expectedContacts, p.Contacts)
+	}
+	if !strings.HasPrefix(p.License, "None. Users should acknowledge") {
+		t.Errorf("unexpected license %q", p.License)
+	}
+
+	expectedLinks := []metadata.Link{
+		{Protocol: "WWW:LINK", URL: "https://www.sciencebase.gov/catalog/item/5f1a2b3c4d5e6f7a8b9c0d1e"},
+		{Protocol: "WWW:LINK", URL: "https://www.usgs.gov/national-hydrography"},
+		{Protocol: "WWW:DOWNLOAD", URL: "https://prd-tnm.s3.amazonaws.com/StagedProducts/Hydrography/NHD/HU4/GDB/NHD_H_0601_HU4_GDB.zip"},
+	}
+	if !reflect.DeepEqual(rec.Links, expectedLinks) {
+		t.Errorf("expected links %+v, got %+v", expectedLinks, rec.Links)
+	}
+}
+
+func TestParseFGDCRecordMinimal(t *testing.T) {
+	rec := parseFixture(t, "fgdc-minimal.xml", parsers.ParseFGDCRecord)
+	p := rec.Properties
+
+	if !strings.HasPrefix(rec.Identifier, "fgdc-") {
+		t.Errorf("expected a derived identifier, got %q", rec.Identifier)
+	}
+	if again := parseFixture(t, "fgdc-minimal.xml", parsers.ParseFGDCRecord); again.Identifier != rec.Identifier {
+		t.Errorf("expected a stable identifier, got %q and %q", rec.Identifier, again.Identifier)
+	}
+	if !p.Created.Equal(date("2003-01-01T00:00:00Z")) {
+		t.Errorf("unexpected created %v", p.Created)
+	}
+	if p.TemporalExtent != nil || p.Datetime == nil || !p.Datetime.Equal(time.Date(2002, 6, 15, 14, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected datetime %v", p.Datetime)
+	}
+	for _, k := range p.KeywordsSets {
+		if k.Thesaurus != "" {
+			t.Errorf("expected no thesaurus, got %q", k.Thesaurus)
+		}
+	}
+	if p.License != "" {
+		t.Errorf("expected no license, got %q", p.License)
+	}
+	if len(p.Contacts) != 2 || p.Contacts[1].Value != "GIS Coordinator" {
+		t.Errorf("unexpected contacts %+v", p.Contacts)
+	}
+	if len(rec.Links) != 1 || rec.Links[0].Protocol != "WWW:DOWNLOAD" {
+		t.Errorf("unexpected links %+v", rec.Links)
+	}
+}
+
+func TestParseFGDCRecordErrors(t *testing.T) {
+	tests := map[string]string{
+		`<DIF/>`:                         "not an FGDC",
+		`<metadata><idinfo/></metadata>`: "neither identifier nor title",
+		`<metadata><idinfo><citation><citeinfo><title>t</title></citeinfo></citation>` +
+			`<spdom><bounding><westbc>west</westbc></bounding></spdom></idinfo></metadata>`: "invalid bounding",
+	}
+	for source, expected := range tests {
+		if _, err := parsers.ParseFGDCRecord([]byte(source)); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error %q, got %v", source, expected, err)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package parsers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// ISO metadata namespaces: ISO 19139 (ISO 19115:2003) and ISO 19115-3
+const (
+	NamespaceGMD = "http://www.isotc211.org/2005/gmd"
+	NamespaceGCO = "http://www.isotc211.org/2005/gco"
+	NamespaceMDB = "http://standards.iso.org/iso/19115/-3/mdb/2.0"
+)
+
+// isoText provides a gco:CharacterString (or gmx:Anchor) property
+type isoText struct {
+	CharacterString string `xml:"CharacterString"`
+	Anchor          string `xml:"Anchor"`
+}
+
+func (t isoText) String() string {
+	if s := strings.TrimSpace(t.CharacterString); s != "" {
+		return s
+	}
+	return strings.TrimSpace(t.Anchor)
+}
+
+// isoCode provides a codelist property, or its free text fallback
+type isoCode struct {
+	isoText
+	Codes []struct {
+		Value string `xml:"codeListValue,attr"`
+		Text  string `xml:",chardata"`
+	} `xml:",any"`
+}
+
+func (c isoCode) String() string {
+	if s := c.isoText.String(); s != "" {
+		return s
+	}
+	for _, code := range c.Codes {
+		if v := strings.TrimSpace(code.Value); v != "" {
+			return v
+		}
+		if v := strings.TrimSpace(code.Text); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// isoDate provides a gco:Date or gco:DateTime property
+type isoDate struct {
+	Date     string `xml:"Date"`
+	DateTime string `xml:"DateTime"`
+}
+
+func (d isoDate) String() string {
+	return strings.TrimSpace(d.DateTime + d.Date)
+}
+
+// isoCitationDate provides a CI_Date
+type isoCitationDate struct {
+	Date isoDate `xml:"date"`
+	Type isoCode `xml:"dateType"`
+}
+
+type isoDecimal struct {
+	Decimal string `xml:"Decimal"`
+}
+
+type isoBoundingBox struct {
+	West  isoDecimal `xml:"westBoundLongitude"`
+	East  isoDecimal `xml:"eastBoundLongitude"`
+	South isoDecimal `xml:"southBoundLatitude"`
+	North isoDecimal `xml:"northBoundLatitude"`
+}
+
+// BBox generates a list of minx,miny,maxx,maxy
+func (b isoBoundingBox) BBox() ([4]float64, error) {
+	var bbox [4]float64
+	for i, v := range []string{b.West.Decimal, b.South.Decimal, b.East.Decimal, b.North.Decimal} {
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return bbox, fmt.Errorf("invalid bounding box: %s", err)
+		}
+		bbox[i] = f
+	}
+	return bbox, nil
+}
+
+// isoTemporalExtent provides a gml:TimePeriod or gml:TimeInstant
+type isoTemporalExtent struct {
+	BeginPosition string `xml:"TimePeriod>beginPosition"`
+	EndPosition   string `xml:"TimePeriod>endPosition"`
+	Begin         string `xml:"TimePeriod>begin>TimeInstant>timePosition"`
+	End           string `xml:"TimePeriod>end>TimeInstant>timePosition"`
+	Instant       string `xml:"TimeInstant>timePosition"`
+}
+
+// Bounds returns the begin and end of a temporal extent, nil if open
+// or indeterminate
+func (t isoTemporalExtent) Bounds() (*time.Time, *time.Time) {
+	bound := func(values ...string) *time.Time {
+		for _, v := range values {
+			if d, ok := parseISODate(v); ok {
+				return &d
+			}
+		}
+		return nil
+	}
+	return bound(t.BeginPosition, t.Begin, t.Instant), bound(t.EndPosition, t.End, t.Instant)
+}
+
+type isoExtent struct {
+	BoundingBoxes []isoBoundingBox    `xml:"geographicElement>EX_GeographicBoundingBox"`
+	Temporal      []isoTemporalExtent `xml:"temporalElement>EX_TemporalExtent>extent"`
+}
+
+// isoParty provides a gmd:CI_ResponsibleParty, or an ISO 19115-3
+// cit:CI_Responsibility
+type isoParty struct {
+	Role             isoCode   `xml:"role"`
+	IndividualName   isoText   `xml:"individualName"`
+	OrganisationName isoText   `xml:"organisationName"`
+	Organisations    []isoText `xml:"party>CI_Organisation>name"`
+	Individuals      []isoText `xml:"party>CI_Individual>name"`
+}
+
+// Name returns the organisation, else the individual, of a party
+func (p isoParty) Name() string {
+	names := append([]isoText{p.OrganisationName}, p.Organisations...)
+	names = append(names, p.IndividualName)
+	names = append(names, p.Individuals...)
+	for _, name := range names {
+		if s := name.String(); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// isoResponsibleParty wraps either party element
+type isoResponsibleParty struct {
+	Party isoParty `xml:",any"`
+}
+
+type isoKeywords struct {
+	Keywords  []isoText `xml:"keyword"`
+	Type      isoCode   `xml:"type"`
+	Thesaurus isoText   `xml:"thesaurusName>CI_Citation>title"`
+}
+
+// isoConstraints provides gmd:MD_Constraints, gmd:MD_LegalConstraints
+// and gmd:MD_SecurityConstraints (or their ISO 19115-3 mco equivalents)
+type isoConstraints struct {
+	UseLimitations    []isoText `xml:"useLimitation"`
+	AccessConstraints []isoCode `xml:"accessConstraints"`
+	UseConstraints    []isoCode `xml:"useConstraints"`
+	OtherConstraints  []isoText `xml:"otherConstraints"`
+}
+
+// isoIdentification provides gmd:MD_DataIdentification and
+// srv:SV_ServiceIdentification (or their ISO 19115-3 mri equivalents)
+type isoIdentification struct {
+	Title           isoText               `xml:"citation>CI_Citation>title"`
+	Dates           []isoCitationDate     `xml:"citation>CI_Citation>date>CI_Date"`
+	Abstract        isoText               `xml:"abstract"`
+	PointsOfContact []isoResponsibleParty `xml:"pointOfContact"`
+	Keywords        []isoKeywords         `xml:"descriptiveKeywords>MD_Keywords"`
+	TopicCategories []string              `xml:"topicCategory>MD_TopicCategoryCode"`
+	Constraints     []struct {
+		Constraints isoConstraints `xml:",any"`
+	} `xml:"resourceConstraints"`
+	Language isoCode     `xml:"language"`
+	Extents  []isoExtent `xml:"extent>EX_Extent"`
+}
+
+type isoOnlineResource struct {
+	Linkage struct {
+		URL             string `xml:"URL"`
+		CharacterString string `xml:"CharacterString"`
+	} `xml:"linkage"`
+	Protocol    isoText `xml:"protocol"`
+	Name        isoText `xml:"name"`
+	Description isoText `xml:"description"`
+}
+
+// ISORecord provides an ISO 19139 gmd:MD_Metadata, or an ISO 19115-3
+// mdb:MD_Metadata, model
+type ISORecord struct {
+	XMLName            xml.Name
+	FileIdentifier     isoText               `xml:"fileIdentifier"`
+	MetadataIdentifier isoText               `xml:"metadataIdentifier>MD_Identifier>code"`
+	ParentIdentifier   isoText               `xml:"parentIdentifier"`
+	ParentMetadata     isoText               `xml:"parentMetadata>CI_Citation>identifier>MD_Identifier>code"`
+	Language           isoCode               `xml:"language"`
+	Locale             isoCode               `xml:"defaultLocale>PT_Locale>language"`
+	HierarchyLevel     isoCode               `xml:"hierarchyLevel"`
+	ResourceScope      isoCode               `xml:"metadataScope>MD_MetadataScope>resourceScope"`
+	Contacts           []isoResponsibleParty `xml:"contact"`
+	DateStamp          isoDate               `xml:"dateStamp"`
+	DateInfo           []isoCitationDate     `xml:"dateInfo>CI_Date"`
+	Identification     []struct {
+		Info isoIdentification `xml:",any"`
+	} `xml:"identificationInfo"`
+	OnlineResources []isoOnlineResource `xml:"distributionInfo>MD_Distribution>transferOptions>MD_DigitalTransferOptions>onLine>CI_OnlineResource"`
+}
+
+// IsISORecord tells whether a document is ISO 19139 or ISO 19115-3
+// metadata
+func IsISORecord(xmlBuffer []byte) bool {
+	root, err := rootElement(xmlBuffer)
+	return err == nil && root.Local == "MD_Metadata" && isISONamespace(root.Space)
+}
+
+func isISONamespace(space string) bool {
+	return space == NamespaceGMD || strings.HasPrefix(space, "http://standards.iso.org/iso/19115/-3/mdb/")
+}
+
+// ParseISORecord parses ISO 19139 (gmd:MD_Metadata) or ISO 19115-3
+// (mdb:MD_Metadata) metadata
+func ParseISORecord(xmlBuffer []byte) (metadata.Record, error) {
+	var isoRecord ISORecord
+	var metadataRecord metadata.Record
+	decoder := xml.NewDecoder(bytes.NewReader(xmlBuffer))
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	if err := decoder.Decode(&isoRecord); err != nil {
+		return metadataRecord, err
+	}
+	if isoRecord.XMLName.Local != "MD_Metadata" || !isISONamespace(isoRecord.XMLName.Space) {
+		return metadataRecord, fmt.Errorf("not an ISO metadata document: %s", isoRecord.XMLName.Local)
+	}
+
+	p := &metadataRecord.Properties
+	metadataRecord.Type = "Feature"
+	metadataRecord.Identifier = firstOf(isoRecord.FileIdentifier.String(), isoRecord.MetadataIdentifier.String())
+	p.Collection = firstOf(isoRecord.ParentIdentifier.String(), isoRecord.ParentMetadata.String())
+	p.Type = firstOf(isoRecord.HierarchyLevel.String(), isoRecord.ResourceScope.String(), "dataset")
+	p.Language = firstOf(isoRecord.Language.String(), isoRecord.Locale.String())
+
+	// the metadata date, else its latest revision or creation
+	metadataDate := isoRecord.DateStamp.String()
+	for _, d := range isoRecord.DateInfo {
+		if metadataDate == "" || d.Type.String() == "revision" {
+			metadataDate = d.Date.String()
+		}
+	}
+	if t, ok := parseISODate(metadataDate); ok {
+		p.Modified = &t
+	}
+
+	for _, c := range isoRecord.Contacts {
+		appendContact(p, c.Party)
+	}
+
+	bbox, hasBBox := [4]float64{}, false
+	licenses := make(map[string]bool)
+	for i, identification := range isoRecord.Identification {
+		info := identification.Info
+		if i == 0 {
+			p.Title = info.Title.String()
+			p.Abstract = info.Abstract.String()
+			if p.Language == "" {
+				p.Language = info.Language.String()
+			}
+		}
+
+		for _, d := range info.Dates {
+			date := metadata.Date{Type: d.Type.String(), Value: d.Date.String()}
+			if date.Value == "" {
+				continue
+			}
+			p.Dates = append(p.Dates, date)
+			if t, ok := parseISODate(date.Value); ok && date.Type == "creation" && p.Created == nil {
+				p.Created = &t
+			}
+		}
+
+		for _, c := range info.PointsOfContact {
+			appendContact(p, c.Party)
+		}
+
+		for _, k := range info.Keywords {
+			keywords := metadata.Keywords{Type: k.Type.String(), Thesaurus: k.Thesaurus.String()}
+			for _, keyword := range k.Keywords {
+				if s := keyword.String(); s != "" {
+					keywords.Keyword = append(keywords.Keyword, s)
+				}
+			}
+			if len(keywords.Keyword) > 0 {
+				p.KeywordsSets = append(p.KeywordsSets, keywords)
+			}
+		}
+		if len(info.TopicCategories) > 0 {
+			topics := metadata.Keywords{Type: "isoTopicCategory"}
+			for _, topic := range info.TopicCategories {
+				topics.Keyword = append(topics.Keyword, strings.TrimSpace(topic))
+			}
+			p.KeywordsSets = append(p.KeywordsSets, topics)
+		}
+
+		for _, c := range info.Constraints {
+			for _, text := range append(c.Constraints.OtherConstraints, c.Constraints.UseLimitations...) {
+				if s := text.String(); s != "" && !licenses[s] {
+					licenses[s] = true
+					p.License = strings.TrimPrefix(p.License+"; "+s, "; ")
+				}
+			}
+		}
+
+		for _, extent := range info.Extents {
+			for _, b := range extent.BoundingBoxes {
+				e, err := b.BBox()
+				if err != nil {
+					return metadataRecord, err
+				}
+				if !hasBBox {
+					bbox, hasBBox = e, true
+					continue
+				}
+				bbox = [4]float64{min(bbox[0], e[0]), min(bbox[1], e[1]), max(bbox[2], e[2]), max(bbox[3], e[3])}
+			}
+			for _, t := range extent.Temporal {
+				begin, end := t.Bounds()
+				if begin == nil && end == nil {
+					continue
+				}
+				if p.TemporalExtent == nil {
+					p.TemporalExtent = &metadata.Temporal{Begin: begin, End: end}
+					continue
+				}
+				if begin != nil && (p.TemporalExtent.Begin == nil || begin.Before(*p.TemporalExtent.Begin)) {
+					p.TemporalExtent.Begin = begin
+				}
+				if end != nil && (p.TemporalExtent.End == nil || end.After(*p.TemporalExtent.End)) {
+					p.TemporalExtent.End = end
+				}
+			}
+		}
+	}
+	if hasBBox {
+		metadataRecord.Geometry = metadata.NewEnvelope(bbox)
+		metadataRecord.BoundingBox = metadataRecord.Geometry.Bounds()
+	}
+
+	for _, r := range isoRecord.OnlineResources {
+		url := strings.TrimSpace(firstOf(r.Linkage.URL, r.Linkage.CharacterString))
+		if url == "" {
+			continue
+		}
+		metadataRecord.Links = append(metadataRecord.Links, metadata.Link{
+			URL:         url,
+			Protocol:    r.Protocol.String(),
+			Name:        r.Name.String(),
+			Description: r.Description.String(),
+		})
+	}
+
+	if isoRecord.XMLName.Space == NamespaceGMD {
+		p.Geocatalogo.Schema = NamespaceGMD
+		p.Geocatalogo.Typename = "gmd:MD_Metadata"
+	} else {
+		p.Geocatalogo.Schema = isoRecord.XMLName.Space
+		p.Geocatalogo.Typename = "mdb:MD_Metadata"
+	}
+	p.Geocatalogo.Source = "local"
+
+	return metadataRecord, nil
+}
+
+// appendContact adds a responsible party, once per role
+func appendContact(p *metadata.Properties, party isoParty) {
+	contact := metadata.Contact{Type: party.Role.String(), Value: party.Name()}
+	if contact.Value == "" {
+		return
+	}
+	for _, c := range p.Contacts {
+		if c == contact {
+			return
+		}
+	}
+	p.Contacts = append(p.Contacts, contact)
+}
+
+// firstOf returns the first non empty value
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseISODate parses a gco:Date, gco:DateTime or gml:timePosition
+func parseISODate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,205 @@
+package parsers_test
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/parsers"
+)
+
+func parseFixture(t *testing.T, name string, parse func([]byte) (metadata.Record, error)) metadata.Record {
+	t.Helper()
+	source, err := ioutil.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := parse(source)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", name, err)
+	}
+	return rec
+}
+
+func date(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
+
+func TestParseISORecordINSPIRE(t *testing.T) {
+	rec := parseFixture(t, "inspire.xml", parsers.ParseISORecord)
+	p := rec.Properties
+
+	if rec.Identifier != "5b1e7f4d-3c7a-4e0a-9d2b-inspire-hy" {
+		t.Errorf("unexpected identifier %q", rec.Identifier)
+	}
+	if p.Title != "Hydrography - Rivers of Europe" || p.Type != "dataset" || p.Language != "eng" {
+		t.Errorf("unexpected title/type/language %q/%q/%q", p.Title, p.Type, p.Language)
+	}
+	if p.Modified == nil || !p.Modified.Equal(date("2019-05-20T00:00:00Z")) {
+		t.Errorf("expected modified from dateStamp, got %v", p.Modified)
+	}
+	if p.Created == nil || !p.Created.Equal(date("2012-03-01T00:00:00Z")) {
+		t.Errorf("expected created from creation date, got %v", p.Created)
+	}
+	expectedDates := []metadata.Date{{Type: "creation", Value: "2012-03-01"}, {Type: "revision", Value: "2018-11-15"}}
+	if !reflect.DeepEqual(p.Dates, expectedDates) {
+		t.Errorf("expected dates %v, got %v", expectedDates, p.Dates)
+	}
+
+	expectedKeywords := []metadata.Keywords{
+		{Keyword: []string{"Hydrography"}, Thesaurus: "GEMET - INSPIRE themes, version 1.0"},
+		{Keyword: []string{"rivers", "water"}, Type: "theme"},
+		{Keyword: []string{"inlandWaters"}, Type: "isoTopicCategory"},
+	}
+	if !reflect.DeepEqual(p.KeywordsSets, expectedKeywords) {
+		t.Errorf("expected keywords %v, got %v", expectedKeywords, p.KeywordsSets)
+	}
+
+	expectedContacts := []metadata.Contact{
+		{Type: "pointOfContact", Value: "European Environment Agency"},
+		{Type: "owner", Value: "European Environment Agency"},
+	}
+	if !reflect.DeepEqual(p.Contacts, expectedContacts) {
+		t.Errorf("expected contacts %v, got %v", expectedContacts, p.Contacts)
+	}
+
+	if p.License != "No limitations to public access; CC-BY 4.0" {
+		t.Errorf("unexpected license %q", p.License)
+	}
+
+	if rec.BoundingBox != [4]float64{-31.27, 27.64, 44.83, 71.15} {
+		t.Errorf("unexpected bbox %v", rec.BoundingBox)
+	}
+	if rec.Geometry.Type != "Polygon" {
+		t.Errorf("expected envelope polygon, got %s", rec.Geometry.Type)
+	}
+	te := p.TemporalExtent
+	if te == nil || te.Begin == nil || te.End == nil || !te.Begin.Equal(date("2006-01-01T00:00:00Z")) || !te.End.Equal(date("2012-12-31T00:00:00Z")) {
+		t.Errorf("unexpected temporal extent %+v", te)
+	}
+
+	if len(rec.Links) != 2 {
+		t.Fatalf("expected 2 links, got %d", len(rec.Links))
+	}
+	expectedLink := metadata.Link{
+		URL:         "https://example.eu/wms/hydrography?service=WMS&request=GetCapabilities",
+		Protocol:    "OGC:WMS",
+		Name:        "HY.Network",
+		Description: "INSPIRE View Service",
+	}
+	if !reflect.DeepEqual(rec.Links[0], expectedLink) {
+		t.Errorf("expected link %+v, got %+v", expectedLink, rec.Links[0])
+	}
+
+	if p.Geocatalogo.Schema != parsers.NamespaceGMD || p.Geocatalogo.Typename != "gmd:MD_Metadata" {
+		t.Errorf("unexpected schema %q/%q", p.Geocatalogo.Schema, p.Geocatalogo.Typename)
+	}
+}
+
+func TestParseISORecordNAP(t *testing.T) {
+	rec := parseFixture(t, "nap.xml", parsers.ParseISORecord)
+	p := rec.Properties
+
+	if p.Type != "RI_632" || p.Collection != "cdem-series" || p.Language != "eng; CAN" {
+		t.Errorf("unexpected type/collection/language %q/%q/%q", p.Type, p.Collection, p.Language)
+	}
+	if p.Title != "Canadian Digital Elevation Model - Web Map Service" {
+		t.Errorf("expected title of the service identification, got %q", p.Title)
+	}
+	if p.Modified == nil || !p.Modified.Equal(date("2017-09-14T10:30:00Z")) {
+		t.Errorf("expected modified from dateStamp, got %v", p.Modified)
+	}
+	expectedContacts := []metadata.Contact{
+		{Type: "RI_414", Value: "Geospatial Data Officer"},
+		{Type: "RI_412", Value: "Government of Canada; Natural Resources Canada"},
+	}
+	if !reflect.DeepEqual(p.Contacts, expectedContacts) {
+		t.Errorf("expected contacts %v, got %v", expectedContacts, p.Contacts)
+	}
+	if len(p.KeywordsSets) != 1 || p.KeywordsSets[0].Thesaurus != "Government of Canada Core Subject Thesaurus" || p.KeywordsSets[0].Type != "RI_528" {
+		t.Errorf("unexpected keywords %v", p.KeywordsSets)
+	}
+	if p.License != "Open Government Licence - Canada (http://open.canada.ca/en/open-government-licence-canada)" {
+		t.Errorf("expected license from useLimitation, got %q", p.License)
+	}
+	// union of the geographic elements
+	if rec.BoundingBox != [4]float64{-141, 41.7, -52.6, 83.1} {
+		t.Errorf("unexpected bbox %v", rec.BoundingBox)
+	}
+	te := p.TemporalExtent
+	if te == nil || te.Begin == nil || !te.Begin.Equal(date("2015-02-10T00:00:00Z")) || te.End != nil {
+		t.Errorf("expected temporal extent open at its end, got %+v", te)
+	}
+}
+
+func TestParseISORecord19115_3(t *testing.T) {
+	rec := parseFixture(t, "iso19115-3.xml", parsers.ParseISORecord)
+	p := rec.Properties
+
+	if rec.Identifier != "9d2a1c3e-19115-3-coastline" || p.Collection != "coastline-series" {
+		t.Errorf("unexpected identifier/collection %q/%q", rec.Identifier, p.Collection)
+	}
+	if p.Type != "dataset" || p.Language != "eng" || p.Title != "Coastline 1:50 000" {
+		t.Errorf("unexpected type/language/title %q/%q/%q", p.Type, p.Language, p.Title)
+	}
+	if p.Modified == nil || !p.Modified.Equal(date("2021-06-30T12:00:00Z")) {
+		t.Errorf("expected modified from the revision date, got %v", p.Modified)
+	}
+	if p.Created == nil || !p.Created.Equal(date("2019-10-01T00:00:00Z")) {
+		t.Errorf("expected created from the citation, got %v", p.Created)
+	}
+	expectedContacts := []metadata.Contact{
+		{Type: "pointOfContact", Value: "National Mapping Agency"},
+		{Type: "publisher", Value: "Mary Major"},
+	}
+	if !reflect.DeepEqual(p.Contacts, expectedContacts) {
+		t.Errorf("expected contacts %v, got %v", expectedContacts, p.Contacts)
+	}
+	if p.License != "CC0 1.0" {
+		t.Errorf("unexpected license %q", p.License)
+	}
+	te := p.TemporalExtent
+	if te == nil || te.Begin == nil || te.End == nil || !te.Begin.Equal(*te.End) {
+		t.Errorf("expected instant temporal extent, got %+v", te)
+	}
+	if len(rec.Links) != 1 || rec.Links[0].URL != "https://example.org/coastline.zip" || rec.Links[0].Description != "Shapefile download" {
+		t.Errorf("unexpected links %+v", rec.Links)
+	}
+	if p.Geocatalogo.Schema != parsers.NamespaceMDB || p.Geocatalogo.Typename != "mdb:MD_Metadata" {
+		t.Errorf("unexpected schema %q/%q", p.Geocatalogo.Schema, p.Geocatalogo.Typename)
+	}
+}
+
+func TestIsISORecord(t *testing.T) {
+	for _, name := range []string{"inspire.xml", "nap.xml", "iso19115-3.xml"} {
+		source, err := ioutil.ReadFile("testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !parsers.IsISORecord(source) {
+			t.Errorf("expected %s to be detected as ISO metadata", name)
+		}
+	}
+}
+
+func TestParseISORecordErrors(t *testing.T) {
+	record := []byte(`<csw:Record xmlns:csw="http://www.opengis.net/cat/csw/2.0.2"/>`)
+	if parsers.IsISORecord(record) {
+		t.Error("expected csw:Record not to be detected as ISO metadata")
+	}
+	if _, err := parsers.ParseISORecord(record); err == nil {
+		t.Error("expected error parsing a csw:Record")
+	}
+
+	bbox := []byte(`<gmd:MD_Metadata xmlns:gmd="http://www.isotc211.org/2005/gmd" xmlns:gco="http://www.isotc211.org/2005/gco">
+<gmd:identificationInfo><gmd:MD_DataIdentification><gmd:extent><gmd:EX_Extent><gmd:geographicElement><gmd:EX_GeographicBoundingBox>
+<gmd:westBoundLongitude><gco:Decimal>west</gco:Decimal></gmd:westBoundLongitude>
+</gmd:EX_GeographicBoundingBox></gmd:geographicElement></gmd:EX_Extent></gmd:extent></gmd:MD_DataIdentification></gmd:identificationInfo>
+</gmd:MD_Metadata>`)
+	if _, err := parsers.ParseISORecord(bbox); err == nil {
+		t.Error("expected error parsing an invalid bounding box")
+	}
+}
@@ -46,20 +46,29 @@ type OAMCatalogResult struct {
 	Version          int        `json:"__v"`
 	Title            string     `json:"title"`
 	Projection       string     `json:"projection"`
-	Gsd              int        `json:"gsd"`
-	Filesize         int        `json:"file_size"`
+	Gsd              float64    `json:"gsd"`
+	Filesize         int64      `json:"file_size"`
 	AcquisitionStart *time.Time `json:"acquisition_start"`
 	AcquisitionEnd   *time.Time `json:"acquisition_end"`
 	Platform         string     `json:"platform"`
 	Provider         string     `json:"provider"`
 	Contact          string     `json:"contact"`
 	MetaUri          string     `json:"meta_uri"`
+	UploadedAt       *time.Time `json:"uploaded_at"`
 	Properties       properties `json:"properties"`
 	Bbox             [4]float64 `json:"bbox"`
 }
 
+// OAMCatalogMeta provides the paging of OAM Catalog Results
+type OAMCatalogMeta struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+	Found int `json:"found"`
+}
+
 // OAMCatalogResults provides OAM Catalog Results
 type OAMCatalogResults struct {
+	Meta   OAMCatalogMeta     `json:"meta"`
 	Result []OAMCatalogResult `json:"results"`
 }
 
@@ -71,7 +80,6 @@ func ParseOAMCatalogResult(result OAMCatalogResult) (metadata.Record, error) {
 	metadataRecord.Identifier = result.Identifier
 	metadataRecord.Properties.Type = "dataset"
 	metadataRecord.Properties.Title = result.Title
-	metadataRecord.Geometry.Type = "Polygon"
 
 	metadataRecord.Properties.Contacts = append(metadataRecord.Properties.Contacts, metadata.Contact{Value: result.Provider})
 	metadataRecord.Properties.Contacts = append(metadataRecord.Properties.Contacts, metadata.Contact{Value: result.Contact})
@@ -81,6 +89,8 @@ func ParseOAMCatalogResult(result OAMCatalogResult) (metadata.Record, error) {
 	mpi.Platform = result.Platform
 	mpi.SensorIdentifier = result.Properties.Sensor
 	mpi.AcquisitionDate = result.AcquisitionStart
+	mpi.GSD = result.Gsd
+	mpi.FileSize = result.Filesize
 	metadataRecord.Properties.ProductInfo = &mpi
 
 	metadataRecord.Links = append(metadataRecord.Links, metadata.Link{URL: result.Uuid})
@@ -89,15 +99,7 @@ func ParseOAMCatalogResult(result OAMCatalogResult) (metadata.Record, error) {
 	metadataRecord.Links = append(metadataRecord.Links, metadata.Link{URL: result.Properties.WTMS, Protocol: "OGC:WMTS"})
 	metadataRecord.Links = append(metadataRecord.Links, metadata.Link{URL: result.MetaUri, Protocol: "WWW:LINK"})
 
-	var bbox = [][][2]float64{{
-		{result.Bbox[0], result.Bbox[1]},
-		{result.Bbox[0], result.Bbox[3]},
-		{result.Bbox[2], result.Bbox[3]},
-		{result.Bbox[2], result.Bbox[1]},
-		{result.Bbox[0], result.Bbox[1]},
-	}}
-
-	metadataRecord.Geometry.Coordinates = bbox
+	metadataRecord.Geometry = metadata.NewEnvelope(result.Bbox)
 	metadataRecord.BoundingBox = metadataRecord.Geometry.Bounds()
 
 	metadataRecord.Properties.Geocatalogo.Typename = "oam:meta"
@@ -0,0 +1,549 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package parsers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// NamespaceRDF is the namespace of RDF
+const NamespaceRDF = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
+// rdfPrefixes are the prefixes known to JSON-LD documents without (or
+// with a remote) @context
+var rdfPrefixes = map[string]string{
+	"adms":    "http://www.w3.org/ns/adms#",
+	"dcat":    NamespaceDCAT,
+	"dct":     namespaceDCT,
+	"dcterms": namespaceDCT,
+	"foaf":    namespaceFOAF,
+	"gsp":     "http://www.opengis.net/ont/geosparql#",
+	"locn":    namespaceLOCN,
+	"rdf":     NamespaceRDF,
+	"rdfs":    namespaceRDFS,
+	"schema":  NamespaceSchemaOrg,
+	"skos":    namespaceSKOS,
+	"vcard":   namespaceVCard,
+	"xsd":     "http://www.w3.org/2001/XMLSchema#",
+}
+
+// rdfNode provides an RDF resource: its IRI (or blank node identifier),
+// types and properties by IRI.  Property values are literals (string) or
+// resources (*rdfNode)
+type rdfNode struct {
+	ID         string
+	Types      []string
+	Properties map[string][]interface{}
+}
+
+func (n *rdfNode) add(property string, value interface{}) {
+	if n.Properties == nil {
+		n.Properties = make(map[string][]interface{})
+	}
+	n.Properties[property] = append(n.Properties[property], value)
+}
+
+// Is reports whether a node has any of the types
+func (n *rdfNode) Is(types ...string) bool {
+	for _, t := range n.Types {
+		if contains(types, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Text returns the first literal of the properties, else the label or
+// IRI of the first resource
+func (n *rdfNode) Text(properties ...string) string {
+	for _, v := range n.values(properties) {
+		switch v := v.(type) {
+		case string:
+			if v = strings.TrimSpace(v); v != "" {
+				return v
+			}
+		case *rdfNode:
+			if label := firstOf(v.Label(), v.IRI()); label != "" {
+				return label
+			}
+		}
+	}
+	return ""
+}
+
+// Texts returns the literals (or labels, else IRIs, of resources) of the
+// properties
+func (n *rdfNode) Texts(properties ...string) []string {
+	var texts []string
+	for _, v := range n.values(properties) {
+		switch v := v.(type) {
+		case string:
+			if v = strings.TrimSpace(v); v != "" {
+				texts = append(texts, v)
+			}
+		case *rdfNode:
+			if label := firstOf(v.Label(), v.IRI()); label != "" {
+				texts = append(texts, label)
+			}
+		}
+	}
+	return texts
+}
+
+// Nodes returns the resources of the properties
+func (n *rdfNode) Nodes(properties ...string) []*rdfNode {
+	var nodes []*rdfNode
+	for _, v := range n.values(properties) {
+		if node, ok := v.(*rdfNode); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Label returns the name or label of a resource
+func (n *rdfNode) Label() string {
+	return n.Text(
+		namespaceFOAF+"name", namespaceVCard+"fn", namespaceVCard+"organization-name",
+		NamespaceSchemaOrg+"name", namespaceSKOS+"prefLabel", namespaceRDFS+"label")
+}
+
+// IRI returns the IRI of a resource, empty for blank nodes
+func (n *rdfNode) IRI() string {
+	if strings.HasPrefix(n.ID, "_:") {
+		return ""
+	}
+	return n.ID
+}
+
+func (n *rdfNode) values(properties []string) []interface{} {
+	var values []interface{}
+	for _, p := range properties {
+		values = append(values, n.Properties[p]...)
+	}
+	return values
+}
+
+// rdfGraph provides the resources of an RDF document
+type rdfGraph struct {
+	nodes []*rdfNode
+	byID  map[string]*rdfNode
+	blank int
+}
+
+// node returns the resource of an identifier, creating it (once) when
+// needed; an empty identifier creates a blank node
+func (g *rdfGraph) node(id string) *rdfNode {
+	if id == "" {
+		g.blank++
+		id = fmt.Sprintf("_:b%d", g.blank)
+	}
+	if g.byID == nil {
+		g.byID = make(map[string]*rdfNode)
+	}
+	if n, ok := g.byID[id]; ok {
+		return n
+	}
+	n := &rdfNode{ID: id}
+	g.byID[id] = n
+	g.nodes = append(g.nodes, n)
+	return n
+}
+
+// Typed returns the resources having any of the types
+func (g *rdfGraph) Typed(types ...string) []*rdfNode {
+	var nodes []*rdfNode
+	for _, n := range g.nodes {
+		if n.Is(types...) {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// jsonldContext provides the term and prefix definitions of a JSON-LD
+// @context, and the terms whose string values are IRIs ("@type": "@id")
+type jsonldContext struct {
+	vocab string
+	terms map[string]string
+	iris  map[string]bool
+}
+
+// with returns the context updated by a @context value; remote contexts
+// are not retrieved, except schema.org which is known
+func (c jsonldContext) with(value interface{}) jsonldContext {
+	updated := jsonldContext{vocab: c.vocab, terms: make(map[string]string), iris: make(map[string]bool)}
+	for k, v := range c.terms {
+		updated.terms[k] = v
+	}
+	for k, v := range c.iris {
+		updated.iris[k] = v
+	}
+
+	switch value := value.(type) {
+	case []interface{}:
+		for _, v := range value {
+			updated = updated.with(v)
+		}
+	case string:
+		if strings.Contains(value, "schema.org") {
+			updated.vocab = NamespaceSchemaOrg
+			updated.terms["id"] = "@id"
+			updated.terms["type"] = "@type"
+		}
+	case map[string]interface{}:
+		for k, v := range value {
+			switch v := v.(type) {
+			case string:
+				if k == "@vocab" {
+					updated.vocab = v
+				} else {
+					updated.terms[k] = v
+				}
+			case map[string]interface{}:
+				if id, ok := v["@id"].(string); ok {
+					updated.terms[k] = id
+				}
+				if v["@type"] == "@id" {
+					updated.iris[k] = true
+				}
+			}
+		}
+	}
+	return updated
+}
+
+// expand expands a term or compact IRI to an IRI (or keyword); vocab
+// allows terms relative to @vocab.  Unknown terms expand to ""
+func (c jsonldContext) expand(term string, vocab bool) string {
+	if strings.HasPrefix(term, "@") {
+		return term
+	}
+	if iri, ok := c.terms[term]; ok && iri != term {
+		return c.expand(iri, vocab)
+	}
+	if i := strings.Index(term, ":"); i > 0 {
+		prefix, suffix := term[:i], term[i+1:]
+		if iri, ok := c.terms[prefix]; ok && !strings.HasPrefix(suffix, "//") {
+			return normalizeIRI(iri + suffix)
+		}
+		return normalizeIRI(term)
+	}
+	if vocab && c.vocab != "" {
+		return normalizeIRI(c.vocab + term)
+	}
+	if !vocab {
+		return term
+	}
+	return ""
+}
+
+// normalizeIRI maps the https schema.org namespace to the canonical one
+func normalizeIRI(iri string) string {
+	if strings.HasPrefix(iri, "https://schema.org/") {
+		return NamespaceSchemaOrg + strings.TrimPrefix(iri, "https://schema.org/")
+	}
+	return iri
+}
+
+// parseJSONLD reads the resources of a JSON-LD document.  Only the
+// subset of JSON-LD used by catalogues is supported: @context prefixes,
+// terms and @vocab, @graph, @id references, @type, @value, @list and
+// @set
+func parseJSONLD(data []byte) (*rdfGraph, error) {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	g := &rdfGraph{}
+	ctx := jsonldContext{terms: rdfPrefixes}.with(nil)
+	g.readJSONLD(document, ctx)
+	if len(g.nodes) == 0 {
+		return nil, fmt.Errorf("JSON-LD document has no resources")
+	}
+	return g, nil
+}
+
+// readJSONLD reads the resources of a top level JSON-LD value
+func (g *rdfGraph) readJSONLD(value interface{}, ctx jsonldContext) {
+	switch value := value.(type) {
+	case []interface{}:
+		for _, v := range value {
+			g.readJSONLD(v, ctx)
+		}
+	case map[string]interface{}:
+		if c, ok := value["@context"]; ok {
+			ctx = ctx.with(c)
+		}
+		if graph, ok := value["@graph"]; ok {
+			g.readJSONLD(graph, ctx)
+			return
+		}
+		g.readJSONLDNode(value, ctx)
+	}
+}
+
+// readJSONLDNode reads a JSON-LD node object
+func (g *rdfGraph) readJSONLDNode(object map[string]interface{}, ctx jsonldContext) *rdfNode {
+	if c, ok := object["@context"]; ok {
+		ctx = ctx.with(c)
+	}
+	var id string
+	for k, v := range object {
+		if ctx.expand(k, true) == "@id" {
+			id, _ = v.(string)
+			id = ctx.expand(id, false)
+		}
+	}
+	n := g.node(id)
+	for k, v := range object {
+		switch property := ctx.expand(k, true); property {
+		case "", "@id", "@context":
+		case "@type":
+			for _, t := range jsonldValues(v) {
+				if s, ok := t.(string); ok {
+					n.Types = append(n.Types, ctx.expand(s, true))
+				}
+			}
+		default:
+			if strings.HasPrefix(property, "@") {
+				continue
+			}
+			for _, value := range jsonldValues(v) {
+				if s, ok := value.(string); ok && ctx.iris[k] {
+					value = map[string]interface{}{"@id": s}
+				}
+				if value = g.readJSONLDValue(value, ctx); value != nil {
+					n.add(property, value)
+				}
+			}
+		}
+	}
+	return n
+}
+
+// readJSONLDValue reads a property value: a literal, value object,
+// reference or embedded node
+func (g *rdfGraph) readJSONLDValue(value interface{}, ctx jsonldContext) interface{} {
+	switch value := value.(type) {
+	case string:
+		return value
+	case float64, bool:
+		return fmt.Sprint(value)
+	case map[string]interface{}:
+		if v, ok := value["@value"]; ok {
+			if v == nil {
+				return nil
+			}
+			return fmt.Sprint(v)
+		}
+		return g.readJSONLDNode(value, ctx)
+	}
+	return nil
+}
+
+// jsonldValues flattens arrays, @list and @set values
+func jsonldValues(value interface{}) []interface{} {
+	switch value := value.(type) {
+	case []interface{}:
+		var values []interface{}
+		for _, v := range value {
+			values = append(values, jsonldValues(v)...)
+		}
+		return values
+	case map[string]interface{}:
+		if list, ok := value["@list"]; ok {
+			return jsonldValues(list)
+		}
+		if set, ok := value["@set"]; ok {
+			return jsonldValues(set)
+		}
+	case nil:
+		return nil
+	}
+	return []interface{}{value}
+}
+
+// parseRDFXML reads the resources of an RDF/XML document
+func parseRDFXML(data []byte) (*rdfGraph, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	root, err := nextElement(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if root.Name.Space != NamespaceRDF || root.Name.Local != "RDF" {
+		return nil, fmt.Errorf("not an RDF/XML document: %s", root.Name.Local)
+	}
+	g := &rdfGraph{}
+	for {
+		start, err := nextElement(decoder)
+		if err == errEndElement {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, err := g.readNodeElement(decoder, start); err != nil {
+			return nil, err
+		}
+	}
+	if len(g.nodes) == 0 {
+		return nil, fmt.Errorf("RDF/XML document has no resources")
+	}
+	return g, nil
+}
+
+var errEndElement = fmt.Errorf("end element")
+
+// nextElement returns the next start element, skipping character data,
+// comments and processing instructions; errEndElement is returned at
+// the end of the current element
+func nextElement(decoder *xml.Decoder) (xml.StartElement, error) {
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return xml.StartElement{}, errEndElement
+		}
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			return t, nil
+		case xml.EndElement:
+			return xml.StartElement{}, errEndElement
+		}
+	}
+}
+
+// readNodeElement reads an RDF/XML node element and its property
+// elements
+func (g *rdfGraph) readNodeElement(decoder *xml.Decoder, start xml.StartElement) (*rdfNode, error) {
+	var id string
+	for _, a := range start.Attr {
+		switch {
+		case a.Name.Space == NamespaceRDF && a.Name.Local == "about":
+			id = a.Value
+		case a.Name.Space == NamespaceRDF && a.Name.Local == "nodeID":
+			id = "_:" + a.Value
+		}
+	}
+	n := g.node(id)
+	if start.Name.Space != NamespaceRDF || start.Name.Local != "Description" {
+		n.Types = append(n.Types, start.Name.Space+start.Name.Local)
+	}
+	for _, a := range start.Attr {
+		switch {
+		case a.Name.Space == NamespaceRDF && a.Name.Local == "type":
+			n.Types = append(n.Types, a.Value)
+		case a.Name.Space != NamespaceRDF && a.Name.Space != "" && a.Name.Space != "xmlns" && a.Name.Space != "http://www.w3.org/XML/1998/namespace":
+			n.add(a.Name.Space+a.Name.Local, a.Value)
+		}
+	}
+	return n, g.readPropertyElements(decoder, n)
+}
+
+// readPropertyElements reads the property elements of a node, up to
+// the end of its element
+func (g *rdfGraph) readPropertyElements(decoder *xml.Decoder, n *rdfNode) error {
+	for {
+		start, err := nextElement(decoder)
+		if err == errEndElement {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := g.readPropertyElement(decoder, n, start); err != nil {
+			return err
+		}
+	}
+}
+
+// readPropertyElement reads a property element: a resource reference,
+// a nested node element, a parseType="Resource" blank node or a literal
+func (g *rdfGraph) readPropertyElement(decoder *xml.Decoder, n *rdfNode, start xml.StartElement) error {
+	property := start.Name.Space + start.Name.Local
+	if property == NamespaceRDF+"type" {
+		for _, a := range start.Attr {
+			if a.Name.Space == NamespaceRDF && a.Name.Local == "resource" {
+				n.Types = append(n.Types, a.Value)
+			}
+		}
+		return decoder.Skip()
+	}
+	for _, a := range start.Attr {
+		if a.Name.Space != NamespaceRDF {
+			continue
+		}
+		switch a.Name.Local {
+		case "resource":
+			n.add(property, g.node(a.Value))
+			return decoder.Skip()
+		case "nodeID":
+			n.add(property, g.node("_:"+a.Value))
+			return decoder.Skip()
+		case "parseType":
+			if a.Value == "Resource" {
+				blank := g.node("")
+				n.add(property, blank)
+				return g.readPropertyElements(decoder, blank)
+			}
+		}
+	}
+
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			value, err := g.readNodeElement(decoder, t)
+			if err != nil {
+				return err
+			}
+			n.add(property, value)
+		case xml.EndElement:
+			if s := strings.TrimSpace(text.String()); s != "" {
+				n.add(property, s)
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,326 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package parsers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// ErrUnknownFormat is returned when no parser recognizes a document
+var ErrUnknownFormat = errors.New("unknown metadata format")
+
+// Parser describes a metadata format, how its documents are recognized
+// and how they are parsed
+type Parser struct {
+	// Name identifies the format (e.g. for the index -format option)
+	Name string
+	// RootElement and Namespaces match the root element of XML
+	// documents (local name, and any of the namespaces)
+	RootElement string
+	Namespaces  []string
+	// Keys match JSON objects having all of these top level keys; the
+	// parser matching most keys is chosen
+	Keys []string
+	// KeySets are alternatives to Keys, for formats having several
+	// kinds of JSON documents
+	KeySets [][]string
+	// Extensions match file names (e.g. .xml) when the content of a
+	// document is not recognized
+	Extensions []string
+	// Parse parses a document
+	Parse func(data []byte) (metadata.Record, error)
+	// ParseAll parses a document holding several records (e.g. a
+	// catalog); when nil, documents hold a single record
+	ParseAll func(data []byte) ([]metadata.Record, error)
+	// ParseCollections parses the collections described by a document;
+	// when nil, documents describe no collections
+	ParseCollections func(data []byte) ([]metadata.Collection, error)
+}
+
+// Records parses the records of a document
+func (p Parser) Records(data []byte) ([]metadata.Record, error) {
+	if p.ParseAll != nil {
+		return p.ParseAll(data)
+	}
+	rec, err := p.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return []metadata.Record{rec}, nil
+}
+
+// Collections parses the collections of a document
+func (p Parser) Collections(data []byte) ([]metadata.Collection, error) {
+	if p.ParseCollections == nil {
+		return nil, nil
+	}
+	return p.ParseCollections(data)
+}
+
+// registry lists the parsers, in order of precedence
+var registry = []Parser{
+	{
+		Name:        "csw",
+		RootElement: "Record",
+		Namespaces:  []string{"http://www.opengis.net/cat/csw/2.0.2", "http://www.opengis.net/cat/csw/3.0"},
+		Extensions:  []string{".xml"},
+		Parse:       ParseCSWRecord,
+	},
+	{
+		Name:        "iso",
+		RootElement: "MD_Metadata",
+		Namespaces:  []string{NamespaceGMD, "http://standards.iso.org/iso/19115/-3/mdb/1.0", NamespaceMDB},
+		Extensions:  []string{".xml"},
+		Parse:       ParseISORecord,
+	},
+	{
+		Name:        "fgdc",
+		RootElement: "metadata",
+		Namespaces:  []string{""},
+		Extensions:  []string{".xml"},
+		Parse:       ParseFGDCRecord,
+	},
+	{
+		Name:        "dif",
+		RootElement: "DIF",
+		Namespaces:  []string{NamespaceDIF9, NamespaceDIF10, ""},
+		Extensions:  []string{".xml", ".dif"},
+		Parse:       ParseDIFRecord,
+	},
+	{
+		Name:        "dcat",
+		RootElement: "RDF",
+		Namespaces:  []string{NamespaceRDF},
+		Keys:        []string{"@context"},
+		Extensions:  []string{".rdf", ".jsonld"},
+		Parse:       ParseDCATRecord,
+		ParseAll:    ParseDCATRecords,
+	},
+	{
+		Name:     "schemaorg",
+		Keys:     []string{"@context", "@type", "name"},
+		Parse:    ParseSchemaOrgDataset,
+		ParseAll: ParseSchemaOrgDatasets,
+	},
+	{
+		Name:     "datajson",
+		Keys:     []string{"conformsTo", "dataset"},
+		Parse:    ParseDataJSONRecord,
+		ParseAll: ParseDataJSONRecords,
+	},
+	{
+		Name:       "oam",
+		Keys:       []string{"uuid", "acquisition_start", "gsd"},
+		Extensions: []string{".json"},
+		Parse:      parseOAMCatalogResultJSON,
+	},
+	{
+		Name: "stac",
+		Keys: []string{"type", "stac_version", "id", "geometry", "properties"},
+		KeySets: [][]string{
+			{"type", "features"},
+			{"type", "stac_version", "id"},
+		},
+		Extensions:       []string{".json"},
+		Parse:            ParseSTACItem,
+		ParseAll:         ParseSTACRecords,
+		ParseCollections: ParseSTACCollections,
+	},
+	{
+		Name:       "geocatalogo",
+		Keys:       []string{"id", "type", "properties"},
+		Extensions: []string{".json"},
+		Parse:      parseRecordJSON,
+	},
+}
+
+// Register adds a parser, replacing any parser of the same name
+func Register(p Parser) {
+	for i := range registry {
+		if registry[i].Name == p.Name {
+			registry[i] = p
+			return
+		}
+	}
+	registry = append(registry, p)
+}
+
+// Parsers lists the registered parsers
+func Parsers() []Parser {
+	return append([]Parser(nil), registry...)
+}
+
+// Names lists the names of the registered parsers
+func Names() []string {
+	var names []string
+	for _, p := range registry {
+		names = append(names, p.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the parser of a name
+func Lookup(name string) (Parser, bool) {
+	for _, p := range registry {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Parser{}, false
+}
+
+// Detect selects the parser of a document by its content (XML root
+// element, JSON keys), else by its file extension
+func Detect(filename string, data []byte) (Parser, error) {
+	content := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+
+	switch {
+	case bytes.HasPrefix(content, []byte("<")):
+		root, err := rootElement(content)
+		if err != nil {
+			return Parser{}, err
+		}
+		for _, p := range registry {
+			if p.RootElement == root.Local && contains(p.Namespaces, root.Space) {
+				return p, nil
+			}
+		}
+		return Parser{}, fmt.Errorf("%w: XML root element {%s}%s", ErrUnknownFormat, root.Space, root.Local)
+	case bytes.HasPrefix(content, []byte("{")):
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(content, &object); err != nil {
+			return Parser{}, err
+		}
+		best, matched := Parser{}, 0
+		for _, p := range registry {
+			for _, keys := range append([][]string{p.Keys}, p.KeySets...) {
+				if len(keys) > matched && hasKeys(object, keys) {
+					best, matched = p, len(keys)
+				}
+			}
+		}
+		if matched > 0 {
+			return best, nil
+		}
+		return Parser{}, fmt.Errorf("%w: JSON object", ErrUnknownFormat)
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, p := range registry {
+		if ext != "" && contains(p.Extensions, ext) {
+			return p, nil
+		}
+	}
+	return Parser{}, ErrUnknownFormat
+}
+
+// Parse parses a document with the parser detected for it, returning
+// the name of the parser
+func Parse(filename string, data []byte) (metadata.Record, string, error) {
+	p, err := Detect(filename, data)
+	if err != nil {
+		return metadata.Record{}, "", err
+	}
+	rec, err := p.Parse(data)
+	return rec, p.Name, err
+}
+
+// ParseAll parses the records of a document with the parser detected
+// for it, returning the name of the parser
+func ParseAll(filename string, data []byte) ([]metadata.Record, string, error) {
+	p, err := Detect(filename, data)
+	if err != nil {
+		return nil, "", err
+	}
+	records, err := p.Records(data)
+	return records, p.Name, err
+}
+
+// rootElement returns the name of the root element of an XML document
+func rootElement(data []byte) (xml.Name, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.Name{}, err
+		}
+		if root, ok := token.(xml.StartElement); ok {
+			return root.Name, nil
+		}
+	}
+}
+
+func hasKeys(object map[string]json.RawMessage, keys []string) bool {
+	for _, k := range keys {
+		if _, ok := object[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOAMCatalogResultJSON parses an OAM Catalog Result document
+func parseOAMCatalogResultJSON(data []byte) (metadata.Record, error) {
+	var result OAMCatalogResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return metadata.Record{}, err
+	}
+	return ParseOAMCatalogResult(result)
+}
+
+// parseRecordJSON parses a geocatalogo JSON record (as output by get)
+func parseRecordJSON(data []byte) (metadata.Record, error) {
+	var rec metadata.Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return rec, err
+	}
+	if rec.Identifier == "" {
+		return rec, fmt.Errorf("record has no id")
+	}
+	return rec, nil
+}
@@ -0,0 +1,104 @@
+package parsers_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/parsers"
+)
+
+func TestDetect(t *testing.T) {
+	inspire, err := ioutil.ReadFile("testdata/inspire.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	iso19115_3, err := ioutil.ReadFile("testdata/iso19115-3.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		filename string
+		data     string
+		format   string
+	}{
+		{"inspire.xml", string(inspire), "iso"},
+		{"iso19115-3.xml", string(iso19115_3), "iso"},
+		{"record.dat", `<?xml version="1.0"?><csw:Record xmlns:csw="http://www.opengis.net/cat/csw/2.0.2"/>`, "csw"},
+		{"record.xml", `<Record xmlns="http://www.opengis.net/cat/csw/3.0"/>`, "csw"},
+		{"nhd.xml", `<?xml version="1.0"?><metadata><idinfo/></metadata>`, "fgdc"},
+		{"entry.xml", `<DIF xmlns="http://gcmd.gsfc.nasa.gov/Aboutus/xml/dif/"/>`, "dif"},
+		{"entry.dif", `<DIF xmlns="http://gcmd.nasa.gov/Aboutus/xml/dif/"/>`, "dif"},
+		{"catalog.rdf", `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"/>`, "dcat"},
+		{"dataset.json", `{"@context": {}, "@graph": []}`, "dcat"},
+		{"dataset.json", `{"@context": "https://schema.org/", "@type": "Dataset", "name": "n"}`, "schemaorg"},
+		{"data.json", `{"@context": "c", "@type": "dcat:Catalog", "conformsTo": "s", "dataset": []}`, "datajson"},
+		{"scene.json", `{"uuid": "u", "acquisition_start": "2017-01-01T00:00:00Z", "gsd": 1}`, "oam"},
+		{"record.txt", `{"id": "r", "type": "Feature", "properties": {}}`, "geocatalogo"},
+		{"item.json", `{"type": "Feature", "stac_version": "1.0.0", "id": "i", "geometry": null, "properties": {}}`, "stac"},
+		{"items.json", `{"type": "FeatureCollection", "features": []}`, "stac"},
+		{"collection.json", `{"type": "Collection", "stac_version": "1.0.0", "id": "c", "extent": {}}`, "stac"},
+		{"catalog.json", `{"type": "Catalog", "stac_version": "1.0.0", "id": "c", "links": []}`, "stac"},
+		{"record.json", `not JSON`, "oam"},
+	}
+
+	for _, test := range tests {
+		p, err := parsers.Detect(test.filename, []byte(test.data))
+		if err != nil {
+			t.Errorf("%s: %s", test.filename, err)
+			continue
+		}
+		if p.Name != test.format {
+			t.Errorf("%s: expected %s, got %s", test.filename, test.format, p.Name)
+		}
+	}
+}
+
+func TestDetectUnknown(t *testing.T) {
+	tests := []struct {
+		filename string
+		data     string
+	}{
+		{"record.xml", `<kml xmlns="http://www.opengis.net/kml/2.2"/>`},
+		{"record.json", `{"foo": "bar"}`},
+		{"record.txt", `plain text`},
+	}
+
+	for _, test := range tests {
+		if _, err := parsers.Detect(test.filename, []byte(test.data)); !errors.Is(err, parsers.ErrUnknownFormat) {
+			t.Errorf("%s: expected ErrUnknownFormat, got %v", test.filename, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	rec, format, err := parsers.Parse("record.json", []byte(`{"id": "r1", "type": "Feature", "properties": {"title": "Record"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "geocatalogo" || rec.Identifier != "r1" || rec.Properties.Title != "Record" {
+		t.Errorf("unexpected record %q (%s)", rec.Identifier, format)
+	}
+	if _, _, err := parsers.Parse("record.json", []byte(`{"id": "", "type": "Feature", "properties": {}}`)); err == nil {
+		t.Error("expected error for record without id")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	parsers.Register(parsers.Parser{
+		Name:       "test",
+		Extensions: []string{".test"},
+		Parse: func(data []byte) (metadata.Record, error) {
+			return metadata.Record{Identifier: string(data)}, nil
+		},
+	})
+	if _, ok := parsers.Lookup("test"); !ok {
+		t.Fatal("registered parser not found")
+	}
+	rec, format, err := parsers.Parse("record.test", []byte("r2"))
+	if err != nil || format != "test" || rec.Identifier != "r2" {
+		t.Errorf("unexpected result %q (%s): %v", rec.Identifier, format, err)
+	}
+}
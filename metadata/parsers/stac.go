@@ -0,0 +1,380 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// STACLink provides a STAC link
+type STACLink struct {
+	Rel   string `json:"rel"`
+	Type  string `json:"type,omitempty"`
+	Title string `json:"title,omitempty"`
+	Href  string `json:"href"`
+}
+
+// STACAsset provides a STAC asset
+type STACAsset struct {
+	Href        string   `json:"href"`
+	Type        string   `json:"type,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+}
+
+// STACItem provides a STAC Item
+type STACItem struct {
+	Type           string                     `json:"type"`
+	StacVersion    string                     `json:"stac_version"`
+	StacExtensions []string                   `json:"stac_extensions"`
+	Id             string                     `json:"id"`
+	Geometry       metadata.Geometry          `json:"geometry"`
+	BBox           []float64                  `json:"bbox"`
+	Properties     map[string]json.RawMessage `json:"properties"`
+	Links          []STACLink                 `json:"links"`
+	Assets         map[string]STACAsset       `json:"assets"`
+	Collection     string                     `json:"collection"`
+}
+
+// STACItemCollection provides a STAC ItemCollection (a GeoJSON
+// FeatureCollection of Items)
+type STACItemCollection struct {
+	Type     string     `json:"type"`
+	Features []STACItem `json:"features"`
+	Links    []STACLink `json:"links"`
+}
+
+// STACCollection provides a STAC Collection
+type STACCollection struct {
+	Type           string                 `json:"type"`
+	StacVersion    string                 `json:"stac_version"`
+	StacExtensions []string               `json:"stac_extensions"`
+	Id             string                 `json:"id"`
+	Title          string                 `json:"title"`
+	Description    string                 `json:"description"`
+	Keywords       []string               `json:"keywords"`
+	License        string                 `json:"license"`
+	Providers      []metadata.Provider    `json:"providers"`
+	Extent         stacExtent             `json:"extent"`
+	Summaries      map[string]interface{} `json:"summaries"`
+	Links          []STACLink             `json:"links"`
+	Assets         map[string]STACAsset   `json:"assets"`
+}
+
+// stacExtent provides the extent of a STAC Collection, whose bounding
+// boxes may be three dimensional
+type stacExtent struct {
+	Spatial struct {
+		BBox [][]float64 `json:"bbox"`
+	} `json:"spatial"`
+	Temporal struct {
+		Interval [][2]*time.Time `json:"interval"`
+	} `json:"temporal"`
+}
+
+// stacStructuralRels are the relations of links between the documents
+// of a STAC catalog, which are not kept as record links
+var stacStructuralRels = map[string]bool{
+	"self":       true,
+	"root":       true,
+	"parent":     true,
+	"child":      true,
+	"item":       true,
+	"items":      true,
+	"collection": true,
+}
+
+// ParseSTACItem parses a STAC Item
+func ParseSTACItem(data []byte) (metadata.Record, error) {
+	var item STACItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return metadata.Record{}, err
+	}
+	return STACItem2Record(item)
+}
+
+// ParseSTACItemCollection parses the Items of a STAC ItemCollection
+func ParseSTACItemCollection(data []byte) ([]metadata.Record, error) {
+	var itemCollection STACItemCollection
+	if err := json.Unmarshal(data, &itemCollection); err != nil {
+		return nil, err
+	}
+	if itemCollection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("not a STAC ItemCollection: %q", itemCollection.Type)
+	}
+	records := make([]metadata.Record, 0, len(itemCollection.Features))
+	for _, item := range itemCollection.Features {
+		rec, err := STACItem2Record(item)
+		if err != nil {
+			return nil, fmt.Errorf("item %q: %s", item.Id, err)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// ParseSTACCollection parses a STAC Collection
+func ParseSTACCollection(data []byte) (metadata.Collection, error) {
+	var sc STACCollection
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return metadata.Collection{}, err
+	}
+	if sc.Type != "" && sc.Type != "Collection" {
+		return metadata.Collection{}, fmt.Errorf("not a STAC Collection: %q", sc.Type)
+	}
+	if sc.Id == "" {
+		return metadata.Collection{}, fmt.Errorf("collection has no id")
+	}
+
+	c := metadata.Collection{
+		Identifier:  sc.Id,
+		Title:       sc.Title,
+		Description: sc.Description,
+		Keywords:    sc.Keywords,
+		License:     sc.License,
+		Providers:   sc.Providers,
+		Summaries:   sc.Summaries,
+		Links:       stacLinks(sc.Links),
+	}
+	for _, bbox := range sc.Extent.Spatial.BBox {
+		c.Extent.Spatial.BBox = append(c.Extent.Spatial.BBox, stacBBox(bbox))
+	}
+	c.Extent.Temporal.Interval = sc.Extent.Temporal.Interval
+	for _, asset := range stacAssets(sc.Assets) {
+		asset.Rel = "enclosure"
+		c.Links = append(c.Links, asset)
+	}
+	return c, nil
+}
+
+// ParseSTACRecords parses the Items of a STAC Item or ItemCollection.
+// Collections and Catalogs hold no Items
+func ParseSTACRecords(data []byte) ([]metadata.Record, error) {
+	var doc stacDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	switch doc.kind() {
+	case "Feature":
+		rec, err := ParseSTACItem(data)
+		if err != nil {
+			return nil, err
+		}
+		return []metadata.Record{rec}, nil
+	case "FeatureCollection":
+		return ParseSTACItemCollection(data)
+	case "Collection", "Catalog":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("not a STAC document: %q", doc.Type)
+}
+
+// ParseSTACCollections parses the Collection of a STAC Collection.
+// Items, ItemCollections and Catalogs describe no Collection
+func ParseSTACCollections(data []byte) ([]metadata.Collection, error) {
+	var doc stacDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	switch doc.kind() {
+	case "Collection":
+		c, err := ParseSTACCollection(data)
+		if err != nil {
+			return nil, err
+		}
+		return []metadata.Collection{c}, nil
+	case "Feature", "FeatureCollection", "Catalog":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("not a STAC document: %q", doc.Type)
+}
+
+// STACItem2Record maps a STAC Item to a record.  Common metadata is
+// mapped to the properties of the model; the properties of extensions
+// are kept as they are
+func STACItem2Record(item STACItem) (metadata.Record, error) {
+	rec := metadata.Record{}
+
+	if item.Type != "Feature" {
+		return rec, fmt.Errorf("not a STAC Item: %q", item.Type)
+	}
+	if item.Id == "" {
+		return rec, fmt.Errorf("item has no id")
+	}
+
+	rec.Type = "Feature"
+	rec.Identifier = item.Id
+	rec.Geometry = item.Geometry
+	if len(item.BBox) == 4 || len(item.BBox) == 6 {
+		rec.BoundingBox = stacBBox(item.BBox)
+	} else {
+		rec.BoundingBox = item.Geometry.Bounds()
+	}
+	rec.StacExtensions = item.StacExtensions
+
+	p := &rec.Properties
+	p.Type = "dataset"
+	p.Collection = item.Collection
+
+	var pi metadata.ProductInfo
+	var start, end *time.Time
+	for name, value := range item.Properties {
+		var err error
+		switch name {
+		case "title":
+			err = json.Unmarshal(value, &p.Title)
+		case "description":
+			err = json.Unmarshal(value, &p.Abstract)
+		case "datetime":
+			err = json.Unmarshal(value, &p.Datetime)
+		case "start_datetime":
+			err = json.Unmarshal(value, &start)
+		case "end_datetime":
+			err = json.Unmarshal(value, &end)
+		case "created":
+			err = json.Unmarshal(value, &p.Created)
+		case "updated":
+			err = json.Unmarshal(value, &p.Modified)
+		case "license":
+			err = json.Unmarshal(value, &p.License)
+		case "keywords":
+			var keywords []string
+			if err = json.Unmarshal(value, &keywords); err == nil && len(keywords) > 0 {
+				p.KeywordsSets = append(p.KeywordsSets, metadata.Keywords{Keyword: keywords})
+			}
+		case "providers":
+			var providers []metadata.Provider
+			if err = json.Unmarshal(value, &providers); err == nil {
+				for _, provider := range providers {
+					p.Contacts = append(p.Contacts, metadata.Contact{Type: strings.Join(provider.Roles, ","), Value: provider.Name})
+				}
+			}
+		default:
+			var v interface{}
+			if err = json.Unmarshal(value, &v); err != nil {
+				break
+			}
+			if p.Extensions == nil {
+				p.Extensions = make(map[string]interface{})
+			}
+			p.Extensions[name] = v
+
+			// properties searched as product information
+			switch name {
+			case "platform":
+				pi.Platform, _ = v.(string)
+			case "instruments":
+				var instruments []string
+				json.Unmarshal(value, &instruments)
+				pi.SensorIdentifier = strings.Join(instruments, ",")
+			case "eo:cloud_cover":
+				pi.CloudCover, _ = v.(float64)
+			case "gsd":
+				pi.GSD, _ = v.(float64)
+			case "processing:level":
+				pi.ProcessingLevel, _ = v.(string)
+			}
+		}
+		if err != nil {
+			return rec, fmt.Errorf("property %s: %s", name, err)
+		}
+	}
+	if start != nil || end != nil {
+		p.TemporalExtent = &metadata.Temporal{Begin: start, End: end}
+	}
+	if p.Datetime == nil && start == nil && end == nil {
+		return rec, fmt.Errorf("item has neither datetime nor start_datetime/end_datetime")
+	}
+	if pi != (metadata.ProductInfo{}) {
+		pi.AcquisitionDate = p.Datetime
+		p.ProductInfo = &pi
+	}
+
+	rec.Links = stacLinks(item.Links)
+	rec.Assets = stacAssets(item.Assets)
+
+	p.Geocatalogo.Typename = "stac:Item"
+	p.Geocatalogo.Schema = "https://schemas.stacspec.org/v" + item.StacVersion + "/item-spec/json-schema/item.json"
+	p.Geocatalogo.Source = "local"
+
+	return rec, nil
+}
+
+// stacLinks maps the links of a STAC document, other than the links
+// between the documents of its catalog
+func stacLinks(links []STACLink) []metadata.Link {
+	var mls []metadata.Link
+	for _, link := range links {
+		if stacStructuralRels[link.Rel] {
+			continue
+		}
+		mls = append(mls, metadata.Link{Name: link.Title, Type: link.Type, URL: link.Href, Rel: link.Rel})
+	}
+	return mls
+}
+
+// stacAssets maps the assets of a STAC document, in order of key
+func stacAssets(assets map[string]STACAsset) []metadata.Link {
+	keys := make([]string, 0, len(assets))
+	for key := range assets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var mas []metadata.Link
+	for _, key := range keys {
+		asset := assets[key]
+		description := asset.Title
+		if description == "" {
+			description = asset.Description
+		}
+		mas = append(mas, metadata.Link{
+			Name:        key,
+			Type:        asset.Type,
+			Description: description,
+			URL:         asset.Href,
+			Roles:       asset.Roles,
+		})
+	}
+	return mas
+}
+
+// stacBBox returns the two dimensional bounding box of a STAC bbox
+func stacBBox(bbox []float64) [4]float64 {
+	if len(bbox) == 6 {
+		return [4]float64{bbox[0], bbox[1], bbox[3], bbox[4]}
+	}
+	var b [4]float64
+	copy(b[:], bbox)
+	return b
+}
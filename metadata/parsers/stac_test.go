@@ -0,0 +1,233 @@
+package parsers_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/parsers"
+)
+
+func TestParseSTACItem(t *testing.T) {
+	source, err := ioutil.ReadFile("testdata/stac/landcover/2020/landcover-2020.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := parsers.ParseSTACItem(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := rec.Properties
+	if rec.Identifier != "landcover-2020" || p.Title != "Land cover 2020" || p.Abstract != "Land cover of Canada, 2020" {
+		t.Errorf("unexpected identification %q %q %q", rec.Identifier, p.Title, p.Abstract)
+	}
+	if rec.BoundingBox != [4]float64{-141.0, 41.7, -52.6, 83.1} {
+		t.Errorf("unexpected bbox %v", rec.BoundingBox)
+	}
+	if p.Datetime != nil || p.TemporalExtent == nil ||
+		!p.TemporalExtent.Begin.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) ||
+		!p.TemporalExtent.End.Equal(time.Date(2020, 12, 31, 23, 59, 59, 0, time.UTC)) {
+		t.Errorf("unexpected temporal extent %+v", p.TemporalExtent)
+	}
+	if len(p.KeywordsSets) != 1 || !reflect.DeepEqual(p.KeywordsSets[0].Keyword, []string{"land cover", "Canada"}) {
+		t.Errorf("unexpected keywords %+v", p.KeywordsSets)
+	}
+
+	if len(rec.StacExtensions) != 2 {
+		t.Errorf("expected 2 stac_extensions, got %v", rec.StacExtensions)
+	}
+	expectedExtensions := map[string]interface{}{
+		"platform":       "landsat-8",
+		"instruments":    []interface{}{"oli", "tirs"},
+		"eo:cloud_cover": 4.5,
+		"proj:epsg":      float64(3978),
+	}
+	if !reflect.DeepEqual(p.Extensions, expectedExtensions) {
+		t.Errorf("expected extension properties %v, got %v", expectedExtensions, p.Extensions)
+	}
+	if pi := p.ProductInfo; pi == nil || pi.Platform != "landsat-8" || pi.SensorIdentifier != "oli,tirs" || pi.CloudCover != 4.5 {
+		t.Errorf("unexpected product info %+v", p.ProductInfo)
+	}
+
+	if len(rec.Links) != 1 || rec.Links[0].Rel != "via" || rec.Links[0].Name != "Landing page" {
+		t.Errorf("unexpected links %+v", rec.Links)
+	}
+	expectedAssets := []metadata.Link{
+		{
+			Name:        "data",
+			Type:        "image/tiff; application=geotiff; profile=cloud-optimized",
+			Description: "Land cover",
+			URL:         "./landcover-2020.tif",
+			Roles:       []string{"data"},
+		},
+		{
+			Name:  "thumbnail",
+			Type:  "image/png",
+			URL:   "https://example.org/landcover/2020/thumbnail.png",
+			Roles: []string{"thumbnail"},
+		},
+	}
+	if !reflect.DeepEqual(rec.Assets, expectedAssets) {
+		t.Errorf("expected assets %+v, got %+v", expectedAssets, rec.Assets)
+	}
+}
+
+func TestParseSTACItemErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "Collection", "id": "c"}`:                                       "not a STAC Item",
+		`{"type": "Feature", "properties": {"datetime": "2020-01-01T00:00:00Z"}}`: "no id",
+		`{"type": "Feature", "id": "i", "properties": {}}`:                        "neither datetime",
+		`{"type": "Feature", "id": "i", "properties": {"datetime": "yesterday"}}`: "property datetime",
+	}
+	for source, expected := range tests {
+		if _, err := parsers.ParseSTACItem([]byte(source)); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error %q, got %v", source, expected, err)
+		}
+	}
+}
+
+func TestParseSTACItemCollection(t *testing.T) {
+	source, err := ioutil.ReadFile("testdata/stac-itemcollection.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := parsers.ParseSTACItemCollection(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	for _, rec := range records {
+		if rec.Properties.Collection != "scenes" || rec.Properties.Datetime == nil {
+			t.Errorf("%s: unexpected properties %+v", rec.Identifier, rec.Properties)
+		}
+	}
+	if records[1].BoundingBox != [4]float64{-79.4, 43.7, -79.4, 43.7} {
+		t.Errorf("unexpected bbox %v", records[1].BoundingBox)
+	}
+}
+
+func TestParseSTACCollection(t *testing.T) {
+	source, err := ioutil.ReadFile("testdata/stac/landcover/collection.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := parsers.ParseSTACCollection(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Identifier != "landcover" || c.Title != "Land cover" || c.License != "CC-BY-4.0" {
+		t.Errorf("unexpected collection %+v", c)
+	}
+	if len(c.Providers) != 1 || !reflect.DeepEqual(c.Providers[0].Roles, []string{"producer", "licensor"}) {
+		t.Errorf("unexpected providers %+v", c.Providers)
+	}
+	if !reflect.DeepEqual(c.Extent.Spatial.BBox, [][4]float64{{-141.0, 41.7, -52.6, 83.1}}) {
+		t.Errorf("unexpected spatial extent %v", c.Extent.Spatial.BBox)
+	}
+	if len(c.Extent.Temporal.Interval) != 1 || c.Extent.Temporal.Interval[0][1] != nil {
+		t.Errorf("unexpected temporal extent %v", c.Extent.Temporal.Interval)
+	}
+	if len(c.Links) != 1 || c.Links[0].Rel != "license" {
+		t.Errorf("unexpected links %+v", c.Links)
+	}
+}
+
+func TestWalkSTACCatalog(t *testing.T) {
+	var collections []string
+	var items []metadata.Record
+	var failures []string
+
+	err := parsers.WalkSTACCatalog("testdata/stac/catalog.json", func(path string, rec *metadata.Record, c *metadata.Collection, err error) error {
+		switch {
+		case err != nil:
+			failures = append(failures, path)
+		case c != nil:
+			collections = append(collections, c.Identifier)
+		default:
+			items = append(items, *rec)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(collections, []string{"landcover"}) {
+		t.Errorf("unexpected collections %v", collections)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+	if items[0].Properties.Collection != "landcover" {
+		t.Errorf("expected item in collection landcover, got %q", items[0].Properties.Collection)
+	}
+	dir, _ := filepath.Abs("testdata/stac/landcover/2020")
+	if items[0].Assets[0].URL != filepath.Join(dir, "landcover-2020.tif") {
+		t.Errorf("expected asset href resolved against the item, got %q", items[0].Assets[0].URL)
+	}
+	if items[0].Assets[1].URL != "https://example.org/landcover/2020/thumbnail.png" {
+		t.Errorf("unexpected asset href %q", items[0].Assets[1].URL)
+	}
+	if !reflect.DeepEqual(failures, []string{"https://example.org/stac/remote/catalog.json"}) {
+		t.Errorf("unexpected failures %v", failures)
+	}
+
+	stop := errors.New("stop")
+	err = parsers.WalkSTACCatalog("testdata/stac/catalog.json", func(path string, rec *metadata.Record, c *metadata.Collection, err error) error {
+		return stop
+	})
+	if err != stop {
+		t.Errorf("expected walk to stop, got %v", err)
+	}
+}
+
+func TestParseSTACDocuments(t *testing.T) {
+	tests := []struct {
+		source      string
+		records     int
+		collections []string
+	}{
+		{"testdata/stac/landcover/2020/landcover-2020.json", 1, nil},
+		{"testdata/stac-itemcollection.json", 2, nil},
+		{"testdata/stac/landcover/collection.json", 0, []string{"landcover"}},
+		{"testdata/stac/catalog.json", 0, nil},
+	}
+
+	for _, test := range tests {
+		source, err := ioutil.ReadFile(test.source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := parsers.Detect(test.source, source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records, err := p.Records(source)
+		if err != nil {
+			t.Errorf("%s: %s", test.source, err)
+		}
+		collections, err := p.Collections(source)
+		if err != nil {
+			t.Errorf("%s: %s", test.source, err)
+		}
+		var ids []string
+		for _, c := range collections {
+			ids = append(ids, c.Identifier)
+		}
+		if p.Name != "stac" || len(records) != test.records || !reflect.DeepEqual(ids, test.collections) {
+			t.Errorf("%s: unexpected %s records %d, collections %v", test.source, p.Name, len(records), ids)
+		}
+	}
+
+	if _, err := parsers.ParseSTACRecords([]byte(`{"type": "Topology"}`)); err == nil {
+		t.Error("expected error for a document which is not STAC")
+	}
+}
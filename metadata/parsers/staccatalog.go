@@ -0,0 +1,180 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// STACWalkFunc is called by WalkSTACCatalog for each Item (rec) and
+// Collection of a catalog, or with the error reading or parsing the
+// document at path.  Walking stops when it returns an error
+type STACWalkFunc func(path string, rec *metadata.Record, collection *metadata.Collection, err error) error
+
+// stacDocument provides the members of a STAC document needed to walk
+// a catalog
+type stacDocument struct {
+	Type   string     `json:"type"`
+	Id     string     `json:"id"`
+	Extent *struct{}  `json:"extent"`
+	Links  []STACLink `json:"links"`
+}
+
+// kind returns the type of a STAC document: Feature (Item),
+// FeatureCollection (ItemCollection), Collection or Catalog.  Collections
+// and Catalogs before STAC 1.0 have no type
+func (d stacDocument) kind() string {
+	switch {
+	case d.Type == "" && d.Extent != nil:
+		return "Collection"
+	case d.Type == "":
+		return "Catalog"
+	}
+	return d.Type
+}
+
+// WalkSTACCatalog walks a static STAC catalog on disk, starting from a
+// Catalog, Collection, ItemCollection or Item document, following its
+// child and item links.  Relative hrefs of links and assets are
+// resolved against the document.  Items without a collection are
+// assigned to the Collection linking to them
+func WalkSTACCatalog(path string, fn STACWalkFunc) error {
+	w := stacWalker{fn: fn, visited: make(map[string]bool)}
+	return w.walk(path, "")
+}
+
+type stacWalker struct {
+	fn      STACWalkFunc
+	visited map[string]bool
+}
+
+func (w *stacWalker) walk(path string, collection string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return w.fn(path, nil, nil, err)
+	}
+	if w.visited[path] {
+		return nil
+	}
+	w.visited[path] = true
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return w.fn(path, nil, nil, err)
+	}
+	var doc stacDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return w.fn(path, nil, nil, err)
+	}
+
+	switch doc.kind() {
+	case "Feature":
+		rec, err := ParseSTACItem(data)
+		if err != nil {
+			return w.fn(path, nil, nil, err)
+		}
+		w.include(path, &rec, collection)
+		return w.fn(path, &rec, nil, nil)
+	case "FeatureCollection":
+		records, err := ParseSTACItemCollection(data)
+		if err != nil {
+			return w.fn(path, nil, nil, err)
+		}
+		for i := range records {
+			w.include(path, &records[i], collection)
+			if err := w.fn(path, &records[i], nil, nil); err != nil {
+				return err
+			}
+		}
+	case "Collection":
+		c, err := ParseSTACCollection(data)
+		if err != nil {
+			return w.fn(path, nil, nil, err)
+		}
+		for i := range c.Links {
+			c.Links[i].URL = resolveHref(path, c.Links[i].URL)
+		}
+		if err := w.fn(path, nil, &c, nil); err != nil {
+			return err
+		}
+		collection = c.Identifier
+	case "Catalog":
+	default:
+		return w.fn(path, nil, nil, fmt.Errorf("not a STAC document: %q", doc.Type))
+	}
+
+	for _, link := range doc.Links {
+		if link.Rel != "child" && link.Rel != "item" {
+			continue
+		}
+		href := resolveHref(path, link.Href)
+		if !filepath.IsAbs(href) {
+			if err := w.fn(href, nil, nil, fmt.Errorf("only local links are followed")); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.walk(href, collection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// include resolves the hrefs of an item found at path, and assigns it
+// to collection if it has none
+func (w *stacWalker) include(path string, rec *metadata.Record, collection string) {
+	if rec.Properties.Collection == "" {
+		rec.Properties.Collection = collection
+	}
+	for i := range rec.Links {
+		rec.Links[i].URL = resolveHref(path, rec.Links[i].URL)
+	}
+	for i := range rec.Assets {
+		rec.Assets[i].URL = resolveHref(path, rec.Assets[i].URL)
+	}
+}
+
+// resolveHref resolves an href relative to the document at path.
+// Absolute URLs and paths are returned as is, file URLs as paths
+func resolveHref(path string, href string) string {
+	if u, err := url.Parse(href); err == nil && len(u.Scheme) > 1 {
+		if u.Scheme == "file" {
+			return filepath.FromSlash(u.Path)
+		}
+		return href
+	}
+	if href == "" || filepath.IsAbs(href) {
+		return href
+	}
+	return filepath.Join(filepath.Dir(path), filepath.FromSlash(href))
+}
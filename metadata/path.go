@@ -0,0 +1,256 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package metadata
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var recordType = reflect.TypeOf(Record{})
+
+// ResolveField resolves a dotted path of JSON property names (e.g.
+// properties.product_info.cloud_cover) against the Record model,
+// returning the canonical path and the type of the value.  Paths which
+// do not match a top level property are resolved against properties,
+// so that datetime is equivalent to properties.datetime
+func ResolveField(path string) (string, reflect.Type, bool) {
+	if t, ok := resolveType(recordType, strings.Split(path, ".")); ok {
+		return path, t, true
+	}
+	path = "properties." + path
+	if t, ok := resolveType(recordType, strings.Split(path, ".")); ok {
+		return path, t, true
+	}
+	return "", nil, false
+}
+
+func resolveType(t reflect.Type, segments []string) (reflect.Type, bool) {
+	for _, segment := range segments {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByJSONName(t, segment)
+			if !ok {
+				return nil, false
+			}
+			t = field.Type
+		case reflect.Slice, reflect.Array:
+			if _, err := strconv.Atoi(segment); err != nil {
+				return nil, false
+			}
+			t = t.Elem()
+		default:
+			return nil, false
+		}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t, true
+}
+
+// Value returns the value at a dotted path of JSON property names (see
+// ResolveField).  Pointers are dereferenced; ok is false if the path is
+// unknown or the value is absent
+func (r *Record) Value(path string) (interface{}, bool) {
+	canonical, _, ok := ResolveField(path)
+	if !ok {
+		return nil, false
+	}
+
+	v := reflect.ValueOf(*r)
+	for _, segment := range strings.Split(canonical, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil, false
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			field, _ := fieldByJSONName(v.Type(), segment)
+			v = v.FieldByIndex(field.Index)
+		case reflect.Slice, reflect.Array:
+			i, _ := strconv.Atoi(segment)
+			if i < 0 || i >= v.Len() {
+				return nil, false
+			}
+			v = v.Index(i)
+		}
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+// SetValue sets the value at a dotted path of JSON property names (see
+// ResolveField), allocating pointers and growing slices as needed.  The
+// value must be assignable to the field, or numeric for a numeric field
+func (r *Record) SetValue(path string, value interface{}) error {
+	canonical, _, ok := ResolveField(path)
+	if !ok {
+		return fmt.Errorf("unknown field %q", path)
+	}
+
+	v := reflect.ValueOf(r).Elem()
+	for _, segment := range strings.Split(canonical, ".") {
+		v = allocate(v)
+		switch v.Kind() {
+		case reflect.Struct:
+			field, _ := fieldByJSONName(v.Type(), segment)
+			v = v.FieldByIndex(field.Index)
+		case reflect.Slice, reflect.Array:
+			i, _ := strconv.Atoi(segment)
+			if i < 0 || v.Kind() == reflect.Array && i >= v.Len() {
+				return fmt.Errorf("index %d of %q out of range", i, path)
+			}
+			for v.Kind() == reflect.Slice && i >= v.Len() {
+				v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+			}
+			v = v.Index(i)
+		}
+	}
+	v = allocate(v)
+
+	x := reflect.ValueOf(value)
+	switch {
+	case !x.IsValid():
+		v.Set(reflect.Zero(v.Type()))
+	case x.Type().AssignableTo(v.Type()):
+		v.Set(x)
+	case isNumeric(x.Kind()) && isNumeric(v.Kind()):
+		v.Set(x.Convert(v.Type()))
+	default:
+		return fmt.Errorf("cannot set %q (%s) to a %s", path, v.Type(), x.Type())
+	}
+	return nil
+}
+
+// allocate dereferences pointers, allocating nil ones
+func allocate(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func isNumeric(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// fieldByJSONName finds a struct field by the name in its json tag
+func fieldByJSONName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "" {
+			tag = field.Name
+		}
+		if tag == name && tag != "-" {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// CompareValues orders two values as returned by Record.Value: numbers
+// numerically, times chronologically and anything else by its string
+// form.  It returns -1, 0 or 1
+func CompareValues(a, b interface{}) int {
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			switch {
+			case ta.Before(tb):
+				return -1
+			case ta.After(tb):
+				return 1
+			}
+			return 0
+		}
+	}
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(toString(a), toString(b))
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func toString(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case time.Time:
+		return s.Format(time.RFC3339Nano)
+	case bool:
+		return strconv.FormatBool(s)
+	}
+	if f, ok := toFloat(v); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package metadata_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+func TestSetValue(t *testing.T) {
+	var r metadata.Record
+	dt := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		path  string
+		value interface{}
+	}{
+		{"id", "LC08_1"},
+		{"title", "Landsat scene"},
+		{"properties.datetime", dt},
+		{"properties.product_info.cloud_cover", 7.5},
+		{"product_info.path", 16},
+		{"bbox.2", 20.0},
+		{"stac_extensions.1", "https://stac-extensions.github.io/eo/v1.0.0/schema.json"},
+	}
+	for _, test := range tests {
+		if err := r.SetValue(test.path, test.value); err != nil {
+			t.Errorf("%s: %s", test.path, err)
+		}
+	}
+
+	if r.Identifier != "LC08_1" || r.Properties.Title != "Landsat scene" || !r.Properties.Datetime.Equal(dt) {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if pi := r.Properties.ProductInfo; pi == nil || pi.CloudCover != 7.5 || pi.Path != 16 {
+		t.Errorf("unexpected product info %+v", r.Properties.ProductInfo)
+	}
+	if r.BoundingBox[2] != 20 || len(r.StacExtensions) != 2 || r.StacExtensions[0] != "" {
+		t.Errorf("unexpected bbox %v or stac_extensions %v", r.BoundingBox, r.StacExtensions)
+	}
+	if v, ok := r.Value("properties.product_info.path"); !ok || v != uint64(16) {
+		t.Errorf("expected path 16, got %v", v)
+	}
+
+	for path, value := range map[string]interface{}{
+		"properties.unknown":  "x",
+		"title":               1.5,
+		"properties.datetime": "2019-06-01",
+		"product_info.path":   "16",
+		"bbox.4":              1.0,
+	} {
+		if err := r.SetValue(path, value); err == nil {
+			t.Errorf("%s: expected error setting %v", path, value)
+		}
+	}
+}
@@ -0,0 +1,468 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package metadata
+
+import (
+	"math"
+	"sort"
+)
+
+// Spatial predicates treat coordinates as planar, except that
+// geometries crossing the antimeridian are unwrapped to continuous
+// longitudes (e.g. 176 to 182 rather than 176 to -178) and compared
+// with the other geometry offset by 0 and ±360 degrees.  Geometries are
+// decomposed into points, line segments and polygons; closed boundaries
+// are part of a polygon
+
+// components of a geometry: segments holds line segments and polygon
+// boundary edges, with points as zero length segments
+type components struct {
+	segments [][2]Position
+	polygons [][][]Position
+	vertices []Position
+}
+
+func decompose(g *Geometry) components {
+	var c components
+	addLine := func(line []Position) {
+		for i := 1; i < len(line); i++ {
+			c.segments = append(c.segments, [2]Position{line[i-1], line[i]})
+		}
+		if len(line) == 1 {
+			c.segments = append(c.segments, [2]Position{line[0], line[0]})
+		}
+		c.vertices = append(c.vertices, line...)
+	}
+	addPolygon := func(rings [][]Position) {
+		if len(rings) == 0 || len(rings[0]) == 0 {
+			return
+		}
+		for _, ring := range rings {
+			addLine(ring)
+		}
+		c.polygons = append(c.polygons, rings)
+	}
+
+	var walk func(g *Geometry)
+	walk = func(g *Geometry) {
+		switch g.Type {
+		case "Point":
+			addLine([]Position{g.Point})
+		case "MultiPoint":
+			for _, p := range g.Line {
+				addLine([]Position{p})
+			}
+		case "LineString":
+			addLine(g.Line)
+		case "MultiLineString":
+			for _, line := range g.Rings {
+				addLine(line)
+			}
+		case "Polygon":
+			addPolygon(g.Rings)
+		case "MultiPolygon":
+			for _, rings := range g.Polygons {
+				addPolygon(rings)
+			}
+		case "GeometryCollection":
+			for i := range g.Geometries {
+				walk(&g.Geometries[i])
+			}
+		}
+	}
+	walk(g)
+	return c
+}
+
+// Intersects reports whether two geometries share any point
+func (g *Geometry) Intersects(other *Geometry) bool {
+	if g.IsEmpty() || other.IsEmpty() {
+		return false
+	}
+	if !boundsIntersect(g.Bounds(), other.Bounds()) {
+		return false
+	}
+	return acrossAntimeridian(g, other, intersects)
+}
+
+// Contains reports whether other lies entirely within g (boundaries
+// included)
+func (g *Geometry) Contains(other *Geometry) bool {
+	if g.IsEmpty() || other.IsEmpty() {
+		return false
+	}
+	return acrossAntimeridian(g, other, contains)
+}
+
+// acrossAntimeridian applies a planar predicate to two geometries,
+// unwrapping them if either crosses the antimeridian
+func acrossAntimeridian(g, other *Geometry, predicate func(a, b *Geometry) bool) bool {
+	gb, ob := g.Bounds(), other.Bounds()
+	if gb[0] <= gb[2] && ob[0] <= ob[2] {
+		return predicate(g, other)
+	}
+	a, b := unwrap(g, gb), unwrap(other, ob)
+	for _, offset := range []float64{0, 360, -360} {
+		shifted := b
+		if offset != 0 {
+			shifted = shift(&b, offset)
+		}
+		if predicate(&a, &shifted) {
+			return true
+		}
+	}
+	return false
+}
+
+// unwrap makes the longitudes of each part of a geometry crossing the
+// antimeridian continuous, with parts west of it offset by 360 so that
+// they follow those east of it
+func unwrap(g *Geometry, bounds [4]float64) Geometry {
+	if bounds[0] <= bounds[2] {
+		return *g
+	}
+	return mapParts(g, func(positions []Position) []Position {
+		unwrapped := make([]Position, len(positions))
+		offset, lo := 0.0, math.Inf(1)
+		for i, p := range positions {
+			if i > 0 {
+				prev, next := positions[i-1][0], p[0]
+				if dx := next - prev; math.Abs(dx) > 180 && math.Abs(prev) != 180 && math.Abs(next) != 180 {
+					if dx > 0 {
+						offset -= 360
+					} else {
+						offset += 360
+					}
+				}
+			}
+			unwrapped[i] = Position{p[0] + offset, p[1]}
+			lo = math.Min(lo, unwrapped[i][0])
+		}
+		// parts starting west of the antimeridian
+		for lo < bounds[0] {
+			for i := range unwrapped {
+				unwrapped[i][0] += 360
+			}
+			lo += 360
+		}
+		return unwrapped
+	})
+}
+
+// shift offsets the longitudes of a geometry
+func shift(g *Geometry, offset float64) Geometry {
+	return mapParts(g, func(positions []Position) []Position {
+		shifted := make([]Position, len(positions))
+		for i, p := range positions {
+			shifted[i] = Position{p[0] + offset, p[1]}
+		}
+		return shifted
+	})
+}
+
+// mapParts copies a geometry, replacing each point, line and ring
+func mapParts(g *Geometry, f func([]Position) []Position) Geometry {
+	c := Geometry{Type: g.Type}
+	switch g.Type {
+	case "Point":
+		c.Point = f([]Position{g.Point})[0]
+	case "MultiPoint":
+		for _, p := range g.Line {
+			c.Line = append(c.Line, f([]Position{p})[0])
+		}
+	case "LineString":
+		c.Line = f(g.Line)
+	case "MultiLineString", "Polygon":
+		for _, line := range g.Rings {
+			c.Rings = append(c.Rings, f(line))
+		}
+	case "MultiPolygon":
+		for _, rings := range g.Polygons {
+			var polygon [][]Position
+			for _, ring := range rings {
+				polygon = append(polygon, f(ring))
+			}
+			c.Polygons = append(c.Polygons, polygon)
+		}
+	case "GeometryCollection":
+		for i := range g.Geometries {
+			c.Geometries = append(c.Geometries, mapParts(&g.Geometries[i], f))
+		}
+	}
+	return c
+}
+
+// intersects reports whether two geometries share any point, in the
+// plane
+func intersects(g, other *Geometry) bool {
+	a, b := decompose(g), decompose(other)
+
+	for _, sa := range a.segments {
+		for _, sb := range b.segments {
+			if segmentsIntersect(sa[0], sa[1], sb[0], sb[1]) {
+				return true
+			}
+		}
+	}
+	// without boundary intersections, a geometry may lie entirely
+	// inside a polygon of the other
+	for _, v := range a.vertices {
+		if inPolygons(v, b.polygons) {
+			return true
+		}
+	}
+	for _, v := range b.vertices {
+		if inPolygons(v, a.polygons) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether other lies entirely within g, in the plane
+func contains(g, other *Geometry) bool {
+	a, b := decompose(g), decompose(other)
+
+	if len(a.polygons) == 0 {
+		// a point or line only contains what lies on it
+		for _, sb := range b.segments {
+			if !onSegments(sb[0], a.segments) || !onSegments(sb[1], a.segments) ||
+				!onSegments(midpoint(sb[0], sb[1]), a.segments) {
+				return false
+			}
+		}
+		return len(b.polygons) == 0
+	}
+
+	for _, sb := range b.segments {
+		if !inPolygons(sb[0], a.polygons) || !inPolygons(sb[1], a.polygons) {
+			return false
+		}
+		// the segment must not leave the polygons between its ends:
+		// each piece between boundary intersections must lie inside
+		// (pieces may lie in adjacent polygons, e.g. either side of the
+		// antimeridian)
+		ts := []float64{0, 1}
+		for _, sa := range a.segments {
+			ts = append(ts, intersections(sb[0], sb[1], sa[0], sa[1])...)
+		}
+		sort.Float64s(ts)
+		for i := 1; i < len(ts); i++ {
+			if ts[i]-ts[i-1] <= epsilon {
+				continue
+			}
+			if !inPolygons(along(sb[0], sb[1], (ts[i-1]+ts[i])/2), a.polygons) {
+				return false
+			}
+		}
+	}
+	// no boundary of g (e.g. a hole) may lie inside a polygon of other
+	for _, v := range a.vertices {
+		if inPolygonsInterior(v, b.polygons) {
+			return false
+		}
+	}
+	return true
+}
+
+// Within reports whether g lies entirely within other
+func (g *Geometry) Within(other *Geometry) bool {
+	return other.Contains(g)
+}
+
+// Relate evaluates a named spatial relation (intersects, within,
+// contains or disjoint) of g to other
+func (g *Geometry) Relate(relation string, other *Geometry) bool {
+	switch relation {
+	case "intersects":
+		return g.Intersects(other)
+	case "disjoint":
+		return !g.Intersects(other)
+	case "within":
+		return g.Within(other)
+	case "contains":
+		return g.Contains(other)
+	}
+	return false
+}
+
+func boundsIntersect(a, b [4]float64) bool {
+	for _, pa := range SplitBBox(a) {
+		for _, pb := range SplitBBox(b) {
+			if pa[0] <= pb[2] && pa[2] >= pb[0] && pa[1] <= pb[3] && pa[3] >= pb[1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func midpoint(a, b Position) Position {
+	return Position{(a[0] + b[0]) / 2, (a[1] + b[1]) / 2}
+}
+
+// along returns the point at t (0 to 1) of segment ab
+func along(a, b Position, t float64) Position {
+	return Position{a[0] + t*(b[0]-a[0]), a[1] + t*(b[1]-a[1])}
+}
+
+// intersections returns the positions along segment ab (0 to 1) where
+// it meets segment cd, or the ends of their overlap if collinear
+func intersections(a, b, c, d Position) []float64 {
+	r := Position{b[0] - a[0], b[1] - a[1]}
+	s := Position{d[0] - c[0], d[1] - c[1]}
+	rr := r[0]*r[0] + r[1]*r[1]
+	if rr <= epsilon {
+		return nil
+	}
+	denom := r[0]*s[1] - r[1]*s[0]
+	ac := Position{c[0] - a[0], c[1] - a[1]}
+	if math.Abs(denom) <= epsilon {
+		var ts []float64
+		for _, p := range []Position{c, d} {
+			if onSegment(p, a, b) {
+				ts = append(ts, ((p[0]-a[0])*r[0]+(p[1]-a[1])*r[1])/rr)
+			}
+		}
+		return ts
+	}
+	t := (ac[0]*s[1] - ac[1]*s[0]) / denom
+	u := (ac[0]*r[1] - ac[1]*r[0]) / denom
+	if t < -epsilon || t > 1+epsilon || u < -epsilon || u > 1+epsilon {
+		return nil
+	}
+	return []float64{t}
+}
+
+const epsilon = 1e-12
+
+// orientation returns the sign of the cross product of ab and ac
+func orientation(a, b, c Position) int {
+	v := (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+	switch {
+	case v > epsilon:
+		return 1
+	case v < -epsilon:
+		return -1
+	}
+	return 0
+}
+
+// onSegment reports whether p lies on segment ab
+func onSegment(p, a, b Position) bool {
+	return orientation(a, b, p) == 0 &&
+		p[0] >= math.Min(a[0], b[0])-epsilon && p[0] <= math.Max(a[0], b[0])+epsilon &&
+		p[1] >= math.Min(a[1], b[1])-epsilon && p[1] <= math.Max(a[1], b[1])+epsilon
+}
+
+func onSegments(p Position, segments [][2]Position) bool {
+	for _, s := range segments {
+		if onSegment(p, s[0], s[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// segmentsIntersect reports whether segments ab and cd share any point
+func segmentsIntersect(a, b, c, d Position) bool {
+	o1, o2 := orientation(a, b, c), orientation(a, b, d)
+	o3, o4 := orientation(c, d, a), orientation(c, d, b)
+	if o1 != o2 && o3 != o4 {
+		return true
+	}
+	return onSegment(c, a, b) || onSegment(d, a, b) || onSegment(a, c, d) || onSegment(b, c, d)
+}
+
+// inRing reports whether p lies inside a ring (boundary excluded),
+// using the even-odd rule
+func inRing(p Position, ring []Position) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a[1] > p[1]) != (b[1] > p[1]) &&
+			p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func onRing(p Position, ring []Position) bool {
+	for i := 1; i < len(ring); i++ {
+		if onSegment(p, ring[i-1], ring[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// inPolygon reports whether p lies inside or on the boundary of a
+// polygon with holes
+func inPolygon(p Position, rings [][]Position) bool {
+	for _, ring := range rings {
+		if onRing(p, ring) {
+			return true
+		}
+	}
+	return inPolygonInterior(p, rings)
+}
+
+// inPolygonInterior reports whether p lies strictly inside a polygon
+func inPolygonInterior(p Position, rings [][]Position) bool {
+	for _, ring := range rings {
+		if onRing(p, ring) {
+			return false
+		}
+	}
+	if !inRing(p, rings[0]) {
+		return false
+	}
+	for _, hole := range rings[1:] {
+		if inRing(p, hole) {
+			return false
+		}
+	}
+	return true
+}
+
+func inPolygons(p Position, polygons [][][]Position) bool {
+	for _, rings := range polygons {
+		if inPolygon(p, rings) {
+			return true
+		}
+	}
+	return false
+}
+
+func inPolygonsInterior(p Position, polygons [][][]Position) bool {
+	for _, rings := range polygons {
+		if inPolygonInterior(p, rings) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package metadata_test
+
+import (
+	"testing"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+func square(minx, miny, maxx, maxy float64) []metadata.Position {
+	return []metadata.Position{{minx, miny}, {maxx, miny}, {maxx, maxy}, {minx, maxy}, {minx, miny}}
+}
+
+func TestGeometryPredicates(t *testing.T) {
+	outer := metadata.NewPolygon(square(0, 0, 10, 10))
+	holed := metadata.NewPolygon(square(0, 0, 10, 10), square(4, 4, 6, 6))
+	inner := metadata.NewPolygon(square(2, 2, 3, 3))
+	inHole := metadata.NewPolygon(square(4.5, 4.5, 5.5, 5.5))
+	overlapping := metadata.NewPolygon(square(8, 8, 12, 12))
+	apart := metadata.NewPolygon(square(20, 20, 30, 30))
+	// an L shape whose bounding box covers (8, 8)
+	lshape := metadata.NewPolygon([]metadata.Position{{0, 0}, {10, 0}, {10, 2}, {2, 2}, {2, 10}, {0, 10}, {0, 0}})
+	corner := metadata.NewPoint(8, 8)
+	crossing := metadata.NewLineString(metadata.Position{-5, 5}, metadata.Position{15, 5})
+	multi := metadata.NewMultiPolygon([][]metadata.Position{square(2, 2, 3, 3)}, [][]metadata.Position{square(40, 40, 41, 41)})
+	adjacent := metadata.NewMultiPolygon([][]metadata.Position{square(0, 0, 10, 10)}, [][]metadata.Position{square(10, 0, 20, 10)})
+	fiji := metadata.NewPolygon([]metadata.Position{{176, -20}, {-178, -20}, {-178, -15}, {176, -15}, {176, -20}})
+	fijiSplit := metadata.NewMultiPolygon([][]metadata.Position{square(176, -20, 180, -15)}, [][]metadata.Position{square(-180, -20, -178, -15)})
+
+	cases := []struct {
+		name     string
+		a, b     metadata.Geometry
+		relation string
+		expected bool
+	}{
+		{"polygon inside polygon", inner, outer, "within", true},
+		{"polygon contains polygon", outer, inner, "contains", true},
+		{"polygon in hole intersects", inHole, holed, "intersects", false},
+		{"polygon in hole within", inHole, holed, "within", false},
+		{"polygon over hole within", metadata.NewPolygon(square(3, 3, 7, 7)), holed, "within", false},
+		{"overlapping intersects", overlapping, outer, "intersects", true},
+		{"overlapping within", overlapping, outer, "within", false},
+		{"disjoint", apart, outer, "disjoint", true},
+		{"point in bbox but outside L", corner, lshape, "intersects", false},
+		{"point inside polygon", corner, outer, "within", true},
+		{"point on boundary", metadata.NewPoint(10, 5), outer, "intersects", true},
+		{"line crossing polygon", crossing, outer, "intersects", true},
+		{"line crossing polygon within", crossing, outer, "within", false},
+		{"line inside L", metadata.NewLineString(metadata.Position{1, 1}, metadata.Position{1, 9}), lshape, "within", true},
+		{"line leaving L", metadata.NewLineString(metadata.Position{1, 9}, metadata.Position{9, 1}), lshape, "within", false},
+		{"multipolygon partly inside", multi, outer, "within", false},
+		{"multipolygon intersects", multi, outer, "intersects", true},
+		{"point on line", metadata.NewPoint(0, 5), crossing, "within", true},
+		{"box inside L", metadata.NewPolygon(square(0.5, 0.5, 1.5, 9)), lshape, "within", true},
+		{"box across adjacent polygons", metadata.NewPolygon(square(4, 4, 16, 6)), adjacent, "within", true},
+		{"box beyond adjacent polygons", metadata.NewPolygon(square(4, 4, 26, 6)), adjacent, "within", false},
+		{"box inside footprint across antimeridian", metadata.NewEnvelope([4]float64{177, -18, 178, -16}), fiji, "intersects", true},
+		{"box inside footprint west of antimeridian", metadata.NewEnvelope([4]float64{-179.5, -18, -178.5, -16}), fiji, "within", true},
+		{"box east of footprint", metadata.NewEnvelope([4]float64{-170, -18, -160, -16}), fiji, "intersects", false},
+		{"box west of footprint", metadata.NewEnvelope([4]float64{160, -18, 170, -16}), fiji, "disjoint", true},
+		{"footprint within box across antimeridian", fiji, metadata.NewEnvelope([4]float64{170, -30, -170, 0}), "within", true},
+		{"box across antimeridian contains point", metadata.NewEnvelope([4]float64{170, -30, -170, 0}), metadata.NewPoint(-175, -10), "contains", true},
+		{"box across antimeridian excludes point", metadata.NewEnvelope([4]float64{170, -30, -170, 0}), metadata.NewPoint(0, -10), "intersects", false},
+		{"footprint across antimeridian split at it", fiji, fijiSplit, "within", true},
+	}
+	for _, c := range cases {
+		if got := c.a.Relate(c.relation, &c.b); got != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, got)
+		}
+	}
+}
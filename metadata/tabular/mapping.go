@@ -0,0 +1,332 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+// Package tabular maps the rows of delimited text files (CSV, TSV) to
+// metadata records with declarative column mappings
+package tabular
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// Mapping describes how the rows of a delimited text file map to
+// metadata records.  Mappings are read from YAML, e.g.:
+//
+//	delimiter: ","
+//	fields:
+//	  id: productId
+//	  title: {template: "Scene {{.entityId}}"}
+//	  collection: {value: landsat8}
+//	  datetime: {column: acquisitionDate, layout: "2006-01-02 15:04:05"}
+//	geometry:
+//	  bbox: [min_lon, min_lat, max_lon, max_lat]
+//	links:
+//	  - url: "{{.download_url}}"
+type Mapping struct {
+	// Delimiter separates columns: a single character or "tab"
+	// (default ",")
+	Delimiter string `yaml:"delimiter"`
+	// Comment starts comment lines
+	Comment string `yaml:"comment"`
+	// LazyQuotes allows quotes in unquoted and quoted fields
+	LazyQuotes bool `yaml:"lazy_quotes"`
+	// Columns names the columns of files without a header row; the
+	// first row names the columns otherwise
+	Columns []string `yaml:"columns"`
+	// Fields maps record fields, as dotted paths of JSON property names
+	// (e.g. id, properties.title, product_info.cloud_cover), or
+	// extensions.<name> for extension properties
+	Fields   map[string]Value `yaml:"fields"`
+	Keywords []Keywords       `yaml:"keywords"`
+	Contacts []Contact        `yaml:"contacts"`
+	Geometry Geometry         `yaml:"geometry"`
+	Links    []Link           `yaml:"links"`
+	Assets   []Link           `yaml:"assets"`
+
+	fields []field
+}
+
+// Value provides a value from a column, a constant or a template (Go
+// text/template of the row, with columns as {{.name}} or
+// {{index . "a name"}}).  A scalar in YAML names a column
+type Value struct {
+	Column   string `yaml:"column"`
+	Value    string `yaml:"value"`
+	Template string `yaml:"template"`
+	// Layout parses dates (Go reference time layout); RFC 3339 and
+	// YYYY-MM-DD [hh:mm:ss] dates are parsed by default
+	Layout string `yaml:"layout"`
+	// Split separates the items of lists (e.g. keywords)
+	Split string `yaml:"split"`
+	// Convert converts extension values to number, integer or boolean
+	// (default string)
+	Convert string `yaml:"convert"`
+
+	template *template.Template
+}
+
+// Keywords maps a set of keywords, of a type and thesaurus
+type Keywords struct {
+	Value     Value  `yaml:",inline"`
+	Type      string `yaml:"type"`
+	Thesaurus string `yaml:"thesaurus"`
+}
+
+// Contact maps a responsible party of a role
+type Contact struct {
+	Value Value  `yaml:",inline"`
+	Role  string `yaml:"role"`
+}
+
+// Geometry names the columns holding the geometry of a record: a
+// bounding box (minx, miny, maxx, maxy), WKT, GeoJSON or a point
+type Geometry struct {
+	BBox    []string `yaml:"bbox"`
+	WKT     string   `yaml:"wkt"`
+	GeoJSON string   `yaml:"geojson"`
+	Lat     string   `yaml:"lat"`
+	Lon     string   `yaml:"lon"`
+}
+
+// Link maps a link or asset; all but roles are templates.  Links with
+// an empty URL are skipped
+type Link struct {
+	Name        string   `yaml:"name"`
+	Type        string   `yaml:"type"`
+	Description string   `yaml:"description"`
+	Protocol    string   `yaml:"protocol"`
+	URL         string   `yaml:"url"`
+	Rel         string   `yaml:"rel"`
+	Roles       []string `yaml:"roles"`
+
+	templates [6]*template.Template
+}
+
+// field is a compiled field mapping
+type field struct {
+	path      string
+	extension string
+	kind      reflect.Type
+	value     *Value
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// funcs are the functions available to templates
+var funcs = template.FuncMap{
+	"replace":    func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"trim":       strings.TrimSpace,
+}
+
+// UnmarshalYAML reads a Value from a mapping, or a column name
+func (v *Value) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var column string
+	if err := unmarshal(&column); err == nil {
+		v.Column = column
+		return nil
+	}
+	type plain Value
+	return unmarshal((*plain)(v))
+}
+
+// LoadMapping reads a mapping file
+func LoadMapping(filename string) (*Mapping, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMapping(data)
+}
+
+// ParseMapping parses and validates a YAML mapping
+func ParseMapping(data []byte) (*Mapping, error) {
+	var m Mapping
+	if err := yaml.UnmarshalStrict(data, &m); err != nil {
+		return nil, err
+	}
+	if err := m.compile(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// compile validates a mapping, resolving fields and parsing templates
+func (m *Mapping) compile() error {
+	if _, err := m.delimiter(); err != nil {
+		return err
+	}
+
+	var paths []string
+	for path := range m.Fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	m.fields = nil
+	for _, path := range paths {
+		value := m.Fields[path]
+		if err := value.compile(); err != nil {
+			return fmt.Errorf("field %s: %s", path, err)
+		}
+		f := field{path: path, value: &value}
+		if name := strings.TrimPrefix(path, "properties."); strings.HasPrefix(name, "extensions.") {
+			switch value.Convert {
+			case "", "string", "number", "integer", "boolean":
+			default:
+				return fmt.Errorf("field %s: unknown conversion %q", path, value.Convert)
+			}
+			f.extension = strings.TrimPrefix(name, "extensions.")
+		} else {
+			_, kind, ok := metadata.ResolveField(path)
+			if !ok {
+				return fmt.Errorf("unknown field %s", path)
+			}
+			if !supported(kind) {
+				return fmt.Errorf("field %s: unsupported type %s", path, kind)
+			}
+			f.kind = kind
+		}
+		m.fields = append(m.fields, f)
+	}
+
+	for i := range m.Keywords {
+		if err := m.Keywords[i].Value.compile(); err != nil {
+			return fmt.Errorf("keywords %d: %s", i+1, err)
+		}
+	}
+	for i := range m.Contacts {
+		if err := m.Contacts[i].Value.compile(); err != nil {
+			return fmt.Errorf("contacts %d: %s", i+1, err)
+		}
+	}
+	if g := m.Geometry; len(g.BBox) != 0 && len(g.BBox) != 4 {
+		return fmt.Errorf("geometry bbox requires 4 columns, found %d", len(g.BBox))
+	}
+	if g := m.Geometry; (g.Lat == "") != (g.Lon == "") {
+		return fmt.Errorf("geometry requires both lat and lon columns")
+	}
+	for _, links := range [][]Link{m.Links, m.Assets} {
+		for i := range links {
+			if err := links[i].compile(); err != nil {
+				return fmt.Errorf("link %d: %s", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// delimiter returns the column separator
+func (m *Mapping) delimiter() (rune, error) {
+	switch d := []rune(m.Delimiter); {
+	case len(d) == 0:
+		return ',', nil
+	case m.Delimiter == "tab" || m.Delimiter == "\\t":
+		return '\t', nil
+	case len(d) == 1:
+		return d[0], nil
+	}
+	return 0, fmt.Errorf("invalid delimiter %q", m.Delimiter)
+}
+
+// columns lists the columns referred to by a mapping
+func (m *Mapping) columns() []string {
+	var columns []string
+	for _, f := range m.fields {
+		columns = append(columns, f.value.Column)
+	}
+	for _, k := range m.Keywords {
+		columns = append(columns, k.Value.Column)
+	}
+	for _, c := range m.Contacts {
+		columns = append(columns, c.Value.Column)
+	}
+	g := m.Geometry
+	columns = append(columns, g.BBox...)
+	columns = append(columns, g.WKT, g.GeoJSON, g.Lat, g.Lon)
+	return columns
+}
+
+func (v *Value) compile() error {
+	set := 0
+	for _, s := range []string{v.Column, v.Value, v.Template} {
+		if s != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("requires one of column, value or template")
+	}
+	if v.Template != "" {
+		t, err := template.New("").Funcs(funcs).Option("missingkey=error").Parse(v.Template)
+		if err != nil {
+			return err
+		}
+		v.template = t
+	}
+	return nil
+}
+
+func (l *Link) compile() error {
+	if l.URL == "" {
+		return fmt.Errorf("requires a url")
+	}
+	for i, s := range []string{l.Name, l.Type, l.Description, l.Protocol, l.URL, l.Rel} {
+		t, err := template.New("").Funcs(funcs).Option("missingkey=error").Parse(s)
+		if err != nil {
+			return err
+		}
+		l.templates[i] = t
+	}
+	return nil
+}
+
+// supported reports whether a field type can be converted from text
+func supported(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
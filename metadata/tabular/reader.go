@@ -0,0 +1,372 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// RowError reports an invalid row by its line number; reading may
+// continue with the next row
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+// Unwrap returns the error of the row
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads the records of a delimited text file, a row at a time
+type Reader struct {
+	mapping *Mapping
+	csv     *csv.Reader
+	columns []string
+	index   map[string]int
+}
+
+// NewReader creates a Reader of a mapping.  The header row is read,
+// unless the mapping names the columns, and the columns the mapping
+// refers to are checked
+func (m *Mapping) NewReader(r io.Reader) (*Reader, error) {
+	delimiter, err := m.delimiter()
+	if err != nil {
+		return nil, err
+	}
+	reader := &Reader{mapping: m, csv: csv.NewReader(r), columns: m.Columns}
+	reader.csv.Comma = delimiter
+	reader.csv.FieldsPerRecord = -1
+	reader.csv.LazyQuotes = m.LazyQuotes
+	reader.csv.ReuseRecord = true
+	if m.Comment != "" {
+		reader.csv.Comment = []rune(m.Comment)[0]
+	}
+
+	if len(reader.columns) == 0 {
+		header, err := reader.csv.Read()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no header row")
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, column := range header {
+			reader.columns = append(reader.columns, strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		}
+	}
+	reader.index = make(map[string]int, len(reader.columns))
+	for i, column := range reader.columns {
+		reader.index[column] = i
+	}
+	for _, column := range m.columns() {
+		if _, ok := reader.index[column]; column != "" && !ok {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+	}
+	return reader, nil
+}
+
+// Columns returns the names of the columns
+func (r *Reader) Columns() []string {
+	return r.columns
+}
+
+// Read returns the record of the next row, or io.EOF.  Invalid rows
+// return a *RowError
+func (r *Reader) Read() (metadata.Record, error) {
+	values, err := r.csv.Read()
+	if err == io.EOF {
+		return metadata.Record{}, err
+	}
+	var parseError *csv.ParseError
+	if errors.As(err, &parseError) {
+		return metadata.Record{}, &RowError{Line: parseError.StartLine, Err: parseError.Err}
+	}
+	if err != nil {
+		return metadata.Record{}, err
+	}
+
+	line, _ := r.csv.FieldPos(0)
+	if len(values) != len(r.columns) {
+		return metadata.Record{}, &RowError{Line: line, Err: fmt.Errorf("expected %d columns, found %d", len(r.columns), len(values))}
+	}
+	row := make(map[string]string, len(values))
+	for i, v := range values {
+		row[r.columns[i]] = v
+	}
+	rec, err := r.mapping.Record(row)
+	if err != nil {
+		return rec, &RowError{Line: line, Err: err}
+	}
+	return rec, nil
+}
+
+// Record maps a row, by column name, to a record
+func (m *Mapping) Record(row map[string]string) (metadata.Record, error) {
+	var rec metadata.Record
+	rec.Type = "Feature"
+	p := &rec.Properties
+
+	for _, f := range m.fields {
+		s, err := f.value.text(row)
+		if err != nil {
+			return rec, fmt.Errorf("%s: %s", f.path, err)
+		}
+		if s == "" {
+			continue
+		}
+		if f.extension != "" {
+			v, err := f.value.convert(s)
+			if err != nil {
+				return rec, fmt.Errorf("%s: %s", f.path, err)
+			}
+			if p.Extensions == nil {
+				p.Extensions = make(map[string]interface{})
+			}
+			p.Extensions[f.extension] = v
+			continue
+		}
+		v, err := f.value.parse(s, f.kind)
+		if err != nil {
+			return rec, fmt.Errorf("%s: %s", f.path, err)
+		}
+		if err := rec.SetValue(f.path, v); err != nil {
+			return rec, err
+		}
+	}
+	if rec.Identifier == "" {
+		return rec, fmt.Errorf("no id")
+	}
+
+	for _, k := range m.Keywords {
+		s, err := k.Value.text(row)
+		if err != nil {
+			return rec, fmt.Errorf("keywords: %s", err)
+		}
+		keywords := metadata.Keywords{Type: k.Type, Thesaurus: k.Thesaurus}
+		keywords.Keyword = k.Value.list(s)
+		if len(keywords.Keyword) > 0 {
+			p.KeywordsSets = append(p.KeywordsSets, keywords)
+		}
+	}
+	for _, c := range m.Contacts {
+		s, err := c.Value.text(row)
+		if err != nil {
+			return rec, fmt.Errorf("contacts: %s", err)
+		}
+		for _, name := range c.Value.list(s) {
+			p.Contacts = append(p.Contacts, metadata.Contact{Type: c.Role, Value: name})
+		}
+	}
+
+	g, err := m.Geometry.geometry(row)
+	if err != nil {
+		return rec, fmt.Errorf("geometry: %s", err)
+	}
+	if !g.IsEmpty() {
+		rec.Geometry = g
+		rec.BoundingBox = g.Bounds()
+	}
+
+	for _, l := range m.Links {
+		link, err := l.link(row)
+		if err != nil {
+			return rec, fmt.Errorf("links: %s", err)
+		}
+		if link.URL != "" {
+			rec.Links = append(rec.Links, link)
+		}
+	}
+	for _, l := range m.Assets {
+		link, err := l.link(row)
+		if err != nil {
+			return rec, fmt.Errorf("assets: %s", err)
+		}
+		if link.URL != "" {
+			rec.Assets = append(rec.Assets, link)
+		}
+	}
+
+	if p.Geocatalogo.Source == "" {
+		p.Geocatalogo.Source = "local"
+	}
+	return rec, nil
+}
+
+// text returns the value of a row
+func (v *Value) text(row map[string]string) (string, error) {
+	switch {
+	case v.template != nil:
+		var buf bytes.Buffer
+		if err := v.template.Execute(&buf, row); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(buf.String()), nil
+	case v.Column != "":
+		return strings.TrimSpace(row[v.Column]), nil
+	}
+	return v.Value, nil
+}
+
+// list splits a value into items
+func (v *Value) list(s string) []string {
+	if s == "" {
+		return nil
+	}
+	if v.Split == "" {
+		return []string{s}
+	}
+	var items []string
+	for _, item := range strings.Split(s, v.Split) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parse converts a value to the type of a field
+func (v *Value) parse(s string, t reflect.Type) (interface{}, error) {
+	if t == timeType {
+		return v.time(s)
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return s, nil
+	case reflect.Bool:
+		return strconv.ParseBool(s)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(s, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(s, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(s, 10, 64)
+	case reflect.Slice:
+		return v.list(s), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// convert converts an extension value
+func (v *Value) convert(s string) (interface{}, error) {
+	switch v.Convert {
+	case "number":
+		return strconv.ParseFloat(s, 64)
+	case "integer":
+		return strconv.ParseInt(s, 10, 64)
+	case "boolean":
+		return strconv.ParseBool(s)
+	}
+	return s, nil
+}
+
+// time parses a date with the layout of a value, else as RFC 3339 or
+// YYYY-MM-DD [hh:mm:ss]
+func (v *Value) time(s string) (time.Time, error) {
+	if v.Layout != "" {
+		t, err := time.Parse(v.Layout, s)
+		return t.UTC(), err
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// geometry returns the geometry of a row, empty if the columns are
+func (g *Geometry) geometry(row map[string]string) (metadata.Geometry, error) {
+	cell := func(column string) string {
+		return strings.TrimSpace(row[column])
+	}
+	switch {
+	case len(g.BBox) == 4 && cell(g.BBox[0]) != "":
+		var bbox [4]float64
+		for i, column := range g.BBox {
+			f, err := strconv.ParseFloat(cell(column), 64)
+			if err != nil {
+				return metadata.Geometry{}, err
+			}
+			bbox[i] = f
+		}
+		return metadata.NewEnvelope(bbox), nil
+	case g.WKT != "" && cell(g.WKT) != "":
+		return metadata.ParseWKT(cell(g.WKT))
+	case g.GeoJSON != "" && cell(g.GeoJSON) != "":
+		var geometry metadata.Geometry
+		err := json.Unmarshal([]byte(cell(g.GeoJSON)), &geometry)
+		return geometry, err
+	case g.Lat != "" && cell(g.Lat) != "":
+		lat, err := strconv.ParseFloat(cell(g.Lat), 64)
+		if err != nil {
+			return metadata.Geometry{}, err
+		}
+		lon, err := strconv.ParseFloat(cell(g.Lon), 64)
+		if err != nil {
+			return metadata.Geometry{}, err
+		}
+		return metadata.NewPoint(lon, lat), nil
+	}
+	return metadata.Geometry{}, nil
+}
+
+// link renders a link of a row
+func (l *Link) link(row map[string]string) (metadata.Link, error) {
+	var values [6]string
+	for i, t := range l.templates {
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, row); err != nil {
+			return metadata.Link{}, err
+		}
+		values[i] = strings.TrimSpace(buf.String())
+	}
+	return metadata.Link{
+		Name:        values[0],
+		Type:        values[1],
+		Description: values[2],
+		Protocol:    values[3],
+		URL:         values[4],
+		Rel:         values[5],
+		Roles:       l.Roles,
+	}, nil
+}
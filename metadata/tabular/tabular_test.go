@@ -0,0 +1,253 @@
+package tabular_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/tabular"
+)
+
+func readAll(t *testing.T, m *tabular.Mapping, r io.Reader) ([]metadata.Record, []error) {
+	t.Helper()
+	reader, err := m.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []metadata.Record
+	var rowErrors []error
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			return records, rowErrors
+		}
+		var rowError *tabular.RowError
+		if errors.As(err, &rowError) {
+			rowErrors = append(rowErrors, err)
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+}
+
+func openFixture(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestLandsatMapping(t *testing.T) {
+	m, err := tabular.LoadMapping("testdata/landsat.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, rowErrors := readAll(t, m, openFixture(t, "scenes.csv"))
+	if len(rowErrors) != 0 || len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d (%v)", len(records), rowErrors)
+	}
+
+	rec := records[0]
+	p := rec.Properties
+	if rec.Identifier != "LC08_L1TP_149039_20170411_20170415_01_T1" || rec.Type != "Feature" {
+		t.Errorf("unexpected identifier %q type %q", rec.Identifier, rec.Type)
+	}
+	if p.Title != "LC81490392017101LGN00" || p.Abstract != "Landsat 8 scene LC81490392017101LGN00" || p.Collection != "landsat8" {
+		t.Errorf("unexpected title %q abstract %q collection %q", p.Title, p.Abstract, p.Collection)
+	}
+	acquired := time.Date(2017, 4, 11, 5, 36, 29, 349932000, time.UTC)
+	if p.Datetime == nil || !p.Datetime.Equal(acquired) {
+		t.Errorf("unexpected datetime %v", p.Datetime)
+	}
+	expectedInfo := &metadata.ProductInfo{
+		Collection:        "landsat8",
+		ProductIdentifier: rec.Identifier,
+		SceneIdentifier:   "LC81490392017101LGN00",
+		AcquisitionDate:   &acquired,
+		ProcessingLevel:   "L1TP",
+		Path:              149,
+		Row:               39,
+	}
+	if !reflect.DeepEqual(p.ProductInfo, expectedInfo) {
+		t.Errorf("unexpected product info %+v", p.ProductInfo)
+	}
+	if records[1].Properties.ProductInfo.CloudCover != 0.15 {
+		t.Errorf("unexpected cloud cover %v", records[1].Properties.ProductInfo.CloudCover)
+	}
+	if rec.BoundingBox != [4]float64{72.41205, 29.22165, 74.84666, 31.34742} || rec.Geometry.Type != "Polygon" {
+		t.Errorf("unexpected bbox %v geometry %s", rec.BoundingBox, rec.Geometry.Type)
+	}
+
+	base := "https://s3-us-west-2.amazonaws.com/landsat-pds/c1/L8/149/039/LC08_L1TP_149039_20170411_20170415_01_T1/"
+	expectedLinks := []metadata.Link{
+		{URL: base + "index.html", Type: "text/html"},
+		{URL: base + "LC08_L1TP_149039_20170411_20170415_01_T1_MTL.json", Type: "application/json"},
+	}
+	if !reflect.DeepEqual(rec.Links, expectedLinks) {
+		t.Errorf("unexpected links %+v", rec.Links)
+	}
+	expectedAssets := []metadata.Link{
+		{Name: "thumbnail", Type: "image/jpeg", URL: base + "LC08_L1TP_149039_20170411_20170415_01_T1_thumb_small.jpg", Roles: []string{"thumbnail"}},
+	}
+	if !reflect.DeepEqual(rec.Assets, expectedAssets) {
+		t.Errorf("unexpected assets %+v", rec.Assets)
+	}
+	if p.Geocatalogo.Source != "local" {
+		t.Errorf("unexpected source %q", p.Geocatalogo.Source)
+	}
+}
+
+const datasetsMapping = `
+delimiter: tab
+comment: "#"
+fields:
+  id: id
+  title: title
+  created: date
+  extensions.score: {column: score, convert: number}
+  extensions.origin: {value: stations.tsv}
+keywords:
+  - {column: keywords, split: ";", type: theme}
+contacts:
+  - {column: publisher, role: publisher}
+geometry:
+  wkt: wkt
+  lat: lat
+  lon: lon
+links:
+  - url: "{{.page}}"
+    rel: about
+`
+
+func TestDatasetsMapping(t *testing.T) {
+	m, err := tabular.ParseMapping([]byte(datasetsMapping))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, rowErrors := readAll(t, m, openFixture(t, "datasets.tsv"))
+	if len(rowErrors) != 0 || len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d (%v)", len(records), rowErrors)
+	}
+
+	stations, buoys := records[0], records[1]
+	if !stations.Properties.Created.Equal(time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)) ||
+		!buoys.Properties.Created.Equal(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("unexpected created %v %v", stations.Properties.Created, buoys.Properties.Created)
+	}
+	expectedKeywords := []metadata.Keywords{{Keyword: []string{"weather", "climate"}, Type: "theme"}}
+	if !reflect.DeepEqual(stations.Properties.KeywordsSets, expectedKeywords) {
+		t.Errorf("unexpected keywords %+v", stations.Properties.KeywordsSets)
+	}
+	if !reflect.DeepEqual(stations.Properties.Contacts, []metadata.Contact{{Type: "publisher", Value: "MSC"}}) || buoys.Properties.Contacts != nil {
+		t.Errorf("unexpected contacts %+v %+v", stations.Properties.Contacts, buoys.Properties.Contacts)
+	}
+	if stations.BoundingBox != [4]float64{-80, 40, -70, 50} {
+		t.Errorf("unexpected WKT bbox %v", stations.BoundingBox)
+	}
+	if buoys.Geometry.Type != "Point" || buoys.BoundingBox != [4]float64{-63.25, 45.5, -63.25, 45.5} {
+		t.Errorf("unexpected point %s %v", buoys.Geometry.Type, buoys.BoundingBox)
+	}
+	expectedExtensions := map[string]interface{}{"score": 0.5, "origin": "stations.tsv"}
+	if !reflect.DeepEqual(stations.Properties.Extensions, expectedExtensions) {
+		t.Errorf("unexpected extensions %v", stations.Properties.Extensions)
+	}
+	if len(stations.Links) != 1 || stations.Links[0].Rel != "about" || len(buoys.Links) != 0 {
+		t.Errorf("expected links with a URL only, got %+v %+v", stations.Links, buoys.Links)
+	}
+}
+
+func TestRowErrors(t *testing.T) {
+	m, err := tabular.ParseMapping([]byte(`
+columns: [id, date, cloud, x, y]
+fields:
+  id: id
+  datetime: date
+  product_info.cloud_cover: cloud
+geometry:
+  lon: x
+  lat: y
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := strings.Join([]string{
+		"a,2020-01-01,10,1,2",
+		"b,yesterday,10,1,2",
+		"c,2020-01-01,cloudy,1,2",
+		",2020-01-01,10,1,2",
+		"d,2020-01-01",
+		"e,2020-01-01,10,east,2",
+		"f,,,,",
+	}, "\n")
+	records, rowErrors := readAll(t, m, strings.NewReader(data))
+	if len(records) != 2 || records[0].Identifier != "a" || records[1].Identifier != "f" {
+		t.Errorf("expected records a and f, got %+v", records)
+	}
+	expected := []string{
+		`line 2: datetime: invalid date "yesterday"`,
+		"line 3: product_info.cloud_cover: ",
+		"line 4: no id",
+		"line 5: expected 5 columns, found 2",
+		"line 6: geometry: ",
+	}
+	if len(rowErrors) != len(expected) {
+		t.Fatalf("expected %d row errors, got %v", len(expected), rowErrors)
+	}
+	for i, e := range expected {
+		if !strings.HasPrefix(rowErrors[i].Error(), e) {
+			t.Errorf("expected error %q, got %q", e, rowErrors[i])
+		}
+	}
+}
+
+func TestReaderColumns(t *testing.T) {
+	m, err := tabular.ParseMapping([]byte("fields:\n  id: identifier\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.NewReader(strings.NewReader("id,title\n")); err == nil || !strings.Contains(err.Error(), `unknown column "identifier"`) {
+		t.Errorf("expected unknown column error, got %v", err)
+	}
+	if _, err := m.NewReader(strings.NewReader("")); err == nil {
+		t.Error("expected missing header error")
+	}
+	reader, err := m.NewReader(strings.NewReader("\ufeffidentifier,title\nx,y\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec, err := reader.Read(); err != nil || rec.Identifier != "x" {
+		t.Errorf("unexpected record %+v (%v)", rec, err)
+	}
+}
+
+func TestParseMappingErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":    "fields:\n  colour: a\n",
+		"unsupported type": "fields:\n  properties.temporal_extent: a\n",
+		"ambiguous value":  "fields:\n  id: {column: a, value: b}\n",
+		"bad template":     "fields:\n  id: {template: \"{{.a\"}\n",
+		"bad conversion":   "fields:\n  extensions.a: {column: a, convert: date}\n",
+		"bad delimiter":    "delimiter: ab\n",
+		"bbox columns":     "geometry:\n  bbox: [a, b]\n",
+		"lat without lon":  "geometry:\n  lat: a\n",
+		"link without url": "links:\n  - name: a\n",
+		"unknown property": "colums: [a]\n",
+		"keyword without":  "keywords:\n  - type: theme\n",
+	}
+	for name, mapping := range tests {
+		if _, err := tabular.ParseMapping([]byte(mapping)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package metadata
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// wktTypes maps WKT geometry tags to GeoJSON types
+var wktTypes = map[string]string{
+	"point":              "Point",
+	"linestring":         "LineString",
+	"polygon":            "Polygon",
+	"multipoint":         "MultiPoint",
+	"multilinestring":    "MultiLineString",
+	"multipolygon":       "MultiPolygon",
+	"geometrycollection": "GeometryCollection",
+}
+
+// ParseWKT parses a WKT geometry (e.g. POLYGON((0 0, 1 0, 1 1, 0 0))).
+// Elevations are discarded
+func ParseWKT(s string) (Geometry, error) {
+	p := &wktParser{s: s}
+	g, err := p.geometry()
+	if err != nil {
+		return Geometry{}, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return Geometry{}, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	return g, nil
+}
+
+// wktParser parses WKT, reporting errors by byte offset
+type wktParser struct {
+	s   string
+	pos int
+}
+
+func (p *wktParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("position %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+// punct reports whether the next character is c and consumes it if so
+func (p *wktParser) punct(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *wktParser) expect(c byte) error {
+	if !p.punct(c) {
+		return p.errorf("expected %q", c)
+	}
+	return nil
+}
+
+// geometry parses a tagged geometry
+func (p *wktParser) geometry() (Geometry, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && unicode.IsLetter(rune(p.s[p.pos])) {
+		p.pos++
+	}
+	tag := p.s[start:p.pos]
+	kind, ok := wktTypes[strings.ToLower(tag)]
+	if !ok {
+		p.pos = start
+		return Geometry{}, p.errorf("expected a geometry, found %q", tag)
+	}
+	if err := p.expect('('); err != nil {
+		return Geometry{}, err
+	}
+
+	g := Geometry{Type: kind}
+	var err error
+	switch kind {
+	case "Point":
+		g.Point, err = p.position()
+	case "LineString":
+		g.Line, err = p.positions()
+	case "MultiPoint":
+		g.Line, err = p.multiPoint()
+	case "Polygon", "MultiLineString":
+		g.Rings, err = p.rings()
+	case "MultiPolygon":
+		for err == nil {
+			var rings [][]Position
+			if err = p.expect('('); err != nil {
+				break
+			}
+			if rings, err = p.rings(); err != nil {
+				break
+			}
+			if err = p.expect(')'); err != nil {
+				break
+			}
+			g.Polygons = append(g.Polygons, rings)
+			if !p.punct(',') {
+				break
+			}
+		}
+	case "GeometryCollection":
+		for err == nil {
+			var member Geometry
+			if member, err = p.geometry(); err != nil {
+				break
+			}
+			g.Geometries = append(g.Geometries, member)
+			if !p.punct(',') {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return Geometry{}, err
+	}
+	if err := p.expect(')'); err != nil {
+		return Geometry{}, err
+	}
+	return g, nil
+}
+
+// rings parses a comma separated list of parenthesised positions
+func (p *wktParser) rings() ([][]Position, error) {
+	var rings [][]Position
+	for {
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		ring, err := p.positions()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		rings = append(rings, ring)
+		if !p.punct(',') {
+			return rings, nil
+		}
+	}
+}
+
+// multiPoint parses MULTIPOINT positions, which may or may not be
+// parenthesised: MULTIPOINT((1 2), (3 4)) or MULTIPOINT(1 2, 3 4)
+func (p *wktParser) multiPoint() ([]Position, error) {
+	var positions []Position
+	for {
+		parenthesised := p.punct('(')
+		pos, err := p.position()
+		if err != nil {
+			return nil, err
+		}
+		if parenthesised {
+			if err := p.expect(')'); err != nil {
+				return nil, err
+			}
+		}
+		positions = append(positions, pos)
+		if !p.punct(',') {
+			return positions, nil
+		}
+	}
+}
+
+func (p *wktParser) positions() ([]Position, error) {
+	var positions []Position
+	for {
+		pos, err := p.position()
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, pos)
+		if !p.punct(',') {
+			return positions, nil
+		}
+	}
+}
+
+// position parses a position of two or three numbers
+func (p *wktParser) position() (Position, error) {
+	var values []float64
+	for {
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.s) && strings.IndexByte("0123456789.+-eE", p.s[p.pos]) >= 0 {
+			p.pos++
+		}
+		if start == p.pos {
+			break
+		}
+		text := p.s[start:p.pos]
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			p.pos = start
+			return Position{}, p.errorf("invalid number %q", text)
+		}
+		values = append(values, v)
+	}
+	if len(values) < 2 || len(values) > 3 {
+		return Position{}, p.errorf("invalid coordinate")
+	}
+	return Position{values[0], values[1]}, nil
+}
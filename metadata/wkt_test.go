@@ -0,0 +1,39 @@
+package metadata_test
+
+import (
+	"testing"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+func TestParseWKT(t *testing.T) {
+	g, err := metadata.ParseWKT(`MULTIPOLYGON(((0 0, 10 0, 10 10, 0 0)), ((20 20, 30 20, 30 30, 20 20)))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Type != "MultiPolygon" || len(g.Polygons) != 2 || g.Bounds() != [4]float64{0, 0, 30, 30} {
+		t.Errorf("unexpected geometry %+v", g)
+	}
+
+	cases := map[string]string{
+		`point (-75.5 45.25 100)`:                              "Point",
+		`MULTIPOINT((1 2), (3 4))`:                             "MultiPoint",
+		`MULTIPOINT(1 2, 3 4)`:                                 "MultiPoint",
+		`LINESTRING(0 0, 1e1 -1.5E1)`:                          "LineString",
+		`MULTILINESTRING((0 0, 1 1), (2 2, 3 3))`:              "MultiLineString",
+		`POLYGON((0 0, 4 0, 4 4, 0 0), (1 1, 2 1, 2 2, 1 1))`:  "Polygon",
+		`GEOMETRYCOLLECTION(POINT(1 2), LINESTRING(0 0, 1 1))`: "GeometryCollection",
+	}
+	for wkt, expected := range cases {
+		g, err := metadata.ParseWKT(wkt)
+		if err != nil || g.Type != expected {
+			t.Errorf("%s: expected %s, got %+v (%v)", wkt, expected, g, err)
+		}
+	}
+
+	for _, wkt := range []string{`title`, `POINT(1 2) POINT(3 4)`, `POLYGON((0 0, 1`, `POINT(1)`, `POINT(1 2 3 4)`, `POINT(1-2 3)`} {
+		if _, err := metadata.ParseWKT(wkt); err == nil {
+			t.Errorf("%s: expected error", wkt)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package writers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// NamespaceDataCite is the namespace of the DataCite Metadata Schema 4
+const NamespaceDataCite = "http://datacite.org/schema/kernel-4"
+
+// dataCiteSchemaLocation locates the DataCite Metadata Schema 4.4
+const dataCiteSchemaLocation = NamespaceDataCite + " http://schema.datacite.org/meta/kernel-4.4/metadata.xsd"
+
+// dataCiteUnavailable is the DataCite value of unavailable properties
+const dataCiteUnavailable = "(:unav)"
+
+// dataCiteContributorTypes maps contact roles to DataCite contributor
+// types
+var dataCiteContributorTypes = map[string]string{
+	"pointofcontact": "ContactPerson",
+	"distributor":    "Distributor",
+	"custodian":      "DataCurator",
+	"owner":          "RightsHolder",
+	"rightsholder":   "RightsHolder",
+	"processor":      "DataManager",
+	"funder":         "Sponsor",
+	"sponsor":        "Sponsor",
+	"producer":       "Producer",
+	"host":           "HostingInstitution",
+}
+
+// DataCite renders a DataCite (Metadata Schema 4) resource.  Records
+// identified by a DOI have it as identifier, others have their
+// identifier as an alternateIdentifier
+func DataCite(rec *metadata.Record) Element {
+	p := &rec.Properties
+
+	resource := El("resource", "").
+		Attr("xmlns", NamespaceDataCite).
+		Attr("xmlns:xsi", "http://www.w3.org/2001/XMLSchema-instance").
+		Attr("xsi:schemaLocation", dataCiteSchemaLocation)
+	add := func(children ...Element) {
+		resource.Children = append(resource.Children, children...)
+	}
+
+	if doi, ok := dataCiteDOI(rec.Identifier); ok {
+		add(El("identifier", doi).Attr("identifierType", "DOI"))
+	}
+
+	creators, contributors := El("creators", ""), El("contributors", "")
+	publisher := ""
+	for _, c := range p.Contacts {
+		switch contactRole(c) {
+		case "creator":
+			creators.Children = append(creators.Children, El("creator", "", El("creatorName", c.Value)))
+		case "publisher":
+			if publisher == "" {
+				publisher = c.Value
+			}
+		default:
+			contributorType, ok := dataCiteContributorTypes[strings.ToLower(c.Type)]
+			if !ok {
+				contributorType = "Other"
+			}
+			contributors.Children = append(contributors.Children,
+				El("contributor", "", El("contributorName", c.Value)).Attr("contributorType", contributorType))
+		}
+	}
+	if len(creators.Children) == 0 {
+		creators.Children = append(creators.Children, El("creator", "", El("creatorName", dataCiteUnavailable)))
+	}
+	if publisher == "" {
+		publisher = dataCiteUnavailable
+	}
+	add(creators)
+	add(El("titles", "", El("title", p.Title)))
+	add(El("publisher", publisher))
+	add(El("publicationYear", dataCitePublicationYear(p)))
+
+	resourceType := "Dataset"
+	switch strings.ToLower(p.Type) {
+	case "service":
+		resourceType = "Service"
+	case "series", "collection":
+		resourceType = "Collection"
+	case "software":
+		resourceType = "Software"
+	}
+	add(El("resourceType", p.Type).Attr("resourceTypeGeneral", resourceType))
+
+	subjects := El("subjects", "")
+	for _, ks := range p.KeywordsSets {
+		for _, k := range ks.Keyword {
+			subject := El("subject", k)
+			if ks.Thesaurus != "" {
+				subject = subject.Attr("subjectScheme", ks.Thesaurus)
+			}
+			subjects.Children = append(subjects.Children, subject)
+		}
+	}
+	if len(subjects.Children) > 0 {
+		add(subjects)
+	}
+	if len(contributors.Children) > 0 {
+		add(contributors)
+	}
+
+	dates := El("dates", "")
+	addDate := func(dateType string, value string) {
+		dates.Children = append(dates.Children, El("date", value).Attr("dateType", dateType))
+	}
+	if p.Created != nil {
+		addDate("Created", p.Created.UTC().Format(time.RFC3339))
+	}
+	if p.Modified != nil {
+		addDate("Updated", p.Modified.UTC().Format(time.RFC3339))
+	}
+	if te := p.TemporalExtent; te != nil && (te.Begin != nil || te.End != nil) {
+		addDate("Collected", dataCiteBound(te.Begin)+"/"+dataCiteBound(te.End))
+	} else if p.Datetime != nil {
+		addDate("Collected", p.Datetime.UTC().Format(time.RFC3339))
+	}
+	if len(dates.Children) > 0 {
+		add(dates)
+	}
+
+	if p.Language != "" {
+		add(El("language", p.Language))
+	}
+	if _, ok := dataCiteDOI(rec.Identifier); !ok && rec.Identifier != "" {
+		add(El("alternateIdentifiers", "",
+			El("alternateIdentifier", rec.Identifier).Attr("alternateIdentifierType", "local")))
+	}
+
+	var formats []string
+	for _, link := range append(append([]metadata.Link(nil), rec.Links...), rec.Assets...) {
+		if link.Type != "" && !containsString(formats, link.Type) {
+			formats = append(formats, link.Type)
+		}
+	}
+	if len(formats) > 0 {
+		f := El("formats", "")
+		for _, format := range formats {
+			f.Children = append(f.Children, El("format", format))
+		}
+		add(f)
+	}
+
+	if p.License != "" {
+		rights := El("rights", p.License)
+		if isURL(p.License) {
+			rights = rights.Attr("rightsURI", p.License)
+		}
+		add(El("rightsList", "", rights))
+	}
+	if p.Abstract != "" {
+		add(El("descriptions", "", El("description", p.Abstract).Attr("descriptionType", "Abstract")))
+	}
+	if bbox, ok := recordBBox(rec); ok {
+		add(El("geoLocations", "", El("geoLocation", "", El("geoLocationBox", "",
+			El("westBoundLongitude", formatFloat(bbox[0])),
+			El("eastBoundLongitude", formatFloat(bbox[2])),
+			El("southBoundLatitude", formatFloat(bbox[1])),
+			El("northBoundLatitude", formatFloat(bbox[3]))))))
+	}
+	return resource
+}
+
+// dataCiteDOI returns the DOI of an identifier (e.g. 10.5066/F7VX0DSK,
+// doi:10.5066/F7VX0DSK or https://doi.org/10.5066/F7VX0DSK)
+func dataCiteDOI(identifier string) (string, bool) {
+	doi := identifier
+	for _, prefix := range []string{"https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/", "doi:"} {
+		if strings.HasPrefix(strings.ToLower(doi), prefix) {
+			doi = doi[len(prefix):]
+			break
+		}
+	}
+	return doi, strings.HasPrefix(doi, "10.") && strings.Contains(doi, "/")
+}
+
+// dataCitePublicationYear returns the year of creation, else of
+// modification, else of the data, of a record
+func dataCitePublicationYear(p *metadata.Properties) string {
+	times := []*time.Time{p.Created, p.Modified, p.Datetime}
+	if p.TemporalExtent != nil {
+		times = append(times, p.TemporalExtent.Begin, p.TemporalExtent.End)
+	}
+	for _, t := range times {
+		if t != nil {
+			return strconv.Itoa(t.Year())
+		}
+	}
+	return dataCiteUnavailable
+}
+
+// dataCiteBound formats a bound of a date range, empty if open
+func dataCiteBound(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
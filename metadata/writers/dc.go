@@ -0,0 +1,133 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package writers
+
+import (
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// Namespaces of the csw:Record Dublin Core encoding
+const (
+	NamespaceCSW2 = "http://www.opengis.net/cat/csw/2.0.2"
+	NamespaceCSW3 = "http://www.opengis.net/cat/csw/3.0"
+	NamespaceDC   = "http://purl.org/dc/elements/1.1/"
+	NamespaceDCT  = "http://purl.org/dc/terms/"
+	NamespaceOWS  = "http://www.opengis.net/ows"
+)
+
+// DublinCore renders a full csw:Record (CSW 2.0.2) document
+func DublinCore(rec *metadata.Record) Element {
+	return CSWRecord(rec, "full").
+		Attr("xmlns:csw", NamespaceCSW2).
+		Attr("xmlns:dc", NamespaceDC).
+		Attr("xmlns:dct", NamespaceDCT).
+		Attr("xmlns:ows", NamespaceOWS)
+}
+
+// CSWRecord renders a csw:BriefRecord, csw:SummaryRecord or csw:Record
+// (full) in Dublin Core.  Namespaces (csw, dc, dct and ows) are left
+// to the enclosing document
+func CSWRecord(rec *metadata.Record, elementSet string) Element {
+	p := &rec.Properties
+	var record Element
+
+	switch elementSet {
+	case "brief":
+		record = El("csw:BriefRecord", "")
+	case "summary":
+		record = El("csw:SummaryRecord", "")
+	default:
+		record = El("csw:Record", "")
+	}
+
+	add := func(name string, value string) {
+		if value != "" {
+			record.Children = append(record.Children, El(name, value))
+		}
+	}
+	addTime := func(name string, t *time.Time) {
+		if t != nil {
+			add(name, t.Format(time.RFC3339))
+		}
+	}
+
+	add("dc:identifier", rec.Identifier)
+	add("dc:title", p.Title)
+	add("dc:type", p.Type)
+
+	if elementSet != "brief" {
+		for _, ks := range p.KeywordsSets {
+			for _, k := range ks.Keyword {
+				add("dc:subject", k)
+			}
+		}
+		for _, link := range rec.Links {
+			if link.Type != "" {
+				add("dc:format", link.Type)
+				break
+			}
+		}
+		addTime("dct:modified", p.Modified)
+		add("dct:abstract", p.Abstract)
+	}
+
+	if elementSet == "full" {
+		for _, c := range p.Contacts {
+			add("dc:"+contactRole(c), c.Value)
+		}
+		add("dc:language", p.Language)
+		addTime("dct:created", p.Created)
+		add("dc:rights", p.License)
+		if te := p.TemporalExtent; te != nil && (te.Begin != nil || te.End != nil) {
+			add("dct:temporal", IntervalBound(te.Begin)+"/"+IntervalBound(te.End))
+		} else {
+			addTime("dc:date", p.Datetime)
+		}
+		for _, link := range rec.Links {
+			reference := El("dct:references", link.URL)
+			if link.Protocol != "" {
+				reference = reference.Attr("scheme", link.Protocol)
+			}
+			record.Children = append(record.Children, reference)
+		}
+	}
+
+	if bbox, ok := recordBBox(rec); ok {
+		record.Children = append(record.Children, El("ows:BoundingBox", "",
+			El("ows:LowerCorner", formatFloat(bbox[1])+" "+formatFloat(bbox[0])),
+			El("ows:UpperCorner", formatFloat(bbox[3])+" "+formatFloat(bbox[2]))).
+			Attr("crs", "urn:ogc:def:crs:EPSG::4326").
+			Attr("dimensions", "2"))
+		record.Children = append(record.Children, El("ows:WGS84BoundingBox", "",
+			El("ows:LowerCorner", formatFloat(bbox[0])+" "+formatFloat(bbox[1])),
+			El("ows:UpperCorner", formatFloat(bbox[2])+" "+formatFloat(bbox[3]))).
+			Attr("crs", "urn:ogc:def:crs:OGC:2:84").
+			Attr("dimensions", "2"))
+	}
+	return record
+}
@@ -0,0 +1,173 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package writers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// NamespaceDCAT is the namespace of DCAT
+const NamespaceDCAT = "http://www.w3.org/ns/dcat#"
+
+// dcatContext is the JSON-LD context of DCAT-AP documents
+var dcatContext = map[string]string{
+	"dcat":  NamespaceDCAT,
+	"dct":   NamespaceDCT,
+	"foaf":  "http://xmlns.com/foaf/0.1/",
+	"gsp":   "http://www.opengis.net/ont/geosparql#",
+	"rdfs":  "http://www.w3.org/2000/01/rdf-schema#",
+	"vcard": "http://www.w3.org/2006/vcard/ns#",
+	"xsd":   "http://www.w3.org/2001/XMLSchema#",
+}
+
+// DCAT renders a DCAT-AP dcat:Dataset as JSON-LD
+func DCAT(rec *metadata.Record) interface{} {
+	p := &rec.Properties
+
+	dataset := map[string]interface{}{
+		"@context":       dcatContext,
+		"@type":          "dcat:Dataset",
+		"dct:identifier": rec.Identifier,
+	}
+	if isURL(rec.Identifier) {
+		dataset["@id"] = rec.Identifier
+	}
+	set := func(name string, value string) {
+		if value != "" {
+			dataset[name] = value
+		}
+	}
+	setTime := func(name string, t *time.Time) {
+		if t != nil {
+			dataset[name] = dcatDateTime(t)
+		}
+	}
+
+	set("dct:title", p.Title)
+	set("dct:description", p.Abstract)
+	set("dct:language", p.Language)
+	setTime("dct:issued", p.Created)
+	setTime("dct:modified", p.Modified)
+
+	var keywords []string
+	for _, ks := range p.KeywordsSets {
+		keywords = append(keywords, ks.Keyword...)
+	}
+	if len(keywords) > 0 {
+		dataset["dcat:keyword"] = keywords
+	}
+
+	if p.License != "" {
+		if isURL(p.License) {
+			dataset["dct:license"] = map[string]interface{}{"@id": p.License}
+		} else {
+			dataset["dct:license"] = map[string]interface{}{"@type": "dct:LicenseDocument", "rdfs:label": p.License}
+		}
+	}
+
+	var creators, contactPoints []interface{}
+	for _, c := range p.Contacts {
+		switch contactRole(c) {
+		case "creator":
+			creators = append(creators, map[string]interface{}{"@type": "foaf:Agent", "foaf:name": c.Value})
+		case "publisher":
+			if _, ok := dataset["dct:publisher"]; !ok {
+				dataset["dct:publisher"] = map[string]interface{}{"@type": "foaf:Agent", "foaf:name": c.Value}
+			}
+		default:
+			contactPoints = append(contactPoints, map[string]interface{}{"@type": "vcard:Organization", "vcard:fn": c.Value})
+		}
+	}
+	if len(creators) > 0 {
+		dataset["dct:creator"] = creators
+	}
+	if len(contactPoints) > 0 {
+		dataset["dcat:contactPoint"] = contactPoints
+	}
+
+	if bbox, ok := recordBBox(rec); ok {
+		wkt := fmt.Sprintf("POLYGON((%[1]s %[2]s,%[3]s %[2]s,%[3]s %[4]s,%[1]s %[4]s,%[1]s %[2]s))",
+			formatFloat(bbox[0]), formatFloat(bbox[1]), formatFloat(bbox[2]), formatFloat(bbox[3]))
+		dataset["dct:spatial"] = map[string]interface{}{
+			"@type":     "dct:Location",
+			"dcat:bbox": map[string]interface{}{"@type": "gsp:wktLiteral", "@value": wkt},
+		}
+	}
+
+	var start, end *time.Time
+	if te := p.TemporalExtent; te != nil {
+		start, end = te.Begin, te.End
+	} else {
+		start, end = p.Datetime, p.Datetime
+	}
+	if start != nil || end != nil {
+		period := map[string]interface{}{"@type": "dct:PeriodOfTime"}
+		if start != nil {
+			period["dcat:startDate"] = dcatDateTime(start)
+		}
+		if end != nil {
+			period["dcat:endDate"] = dcatDateTime(end)
+		}
+		dataset["dct:temporal"] = period
+	}
+
+	var distributions []interface{}
+	for _, link := range append(append([]metadata.Link(nil), rec.Links...), rec.Assets...) {
+		if link.URL == "" {
+			continue
+		}
+		distribution := map[string]interface{}{
+			"@type":          "dcat:Distribution",
+			"dcat:accessURL": map[string]interface{}{"@id": link.URL},
+		}
+		if link.Name != "" {
+			distribution["dct:title"] = link.Name
+		}
+		if link.Description != "" {
+			distribution["dct:description"] = link.Description
+		}
+		if link.Type != "" {
+			distribution["dcat:mediaType"] = link.Type
+		}
+		if link.Protocol != "" {
+			distribution["dct:format"] = link.Protocol
+		}
+		distributions = append(distributions, distribution)
+	}
+	if len(distributions) > 0 {
+		dataset["dcat:distribution"] = distributions
+	}
+
+	return dataset
+}
+
+// dcatDateTime renders an xsd:dateTime literal
+func dcatDateTime(t *time.Time) map[string]interface{} {
+	return map[string]interface{}{"@type": "xsd:dateTime", "@value": t.UTC().Format(time.RFC3339)}
+}
@@ -0,0 +1,218 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package writers
+
+import (
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// Namespaces of the ISO 19139 encoding
+const (
+	NamespaceGMD   = "http://www.isotc211.org/2005/gmd"
+	NamespaceGCO   = "http://www.isotc211.org/2005/gco"
+	NamespaceGML   = "http://www.opengis.net/gml/3.2"
+	NamespaceXLink = "http://www.w3.org/1999/xlink"
+)
+
+// isoCodeLists is the location of the ISO 19139 code lists
+const isoCodeLists = "http://standards.iso.org/iso/19139/resources/gmxCodelists.xml"
+
+// ISO19139 renders a gmd:MD_Metadata document
+func ISO19139(rec *metadata.Record) Element {
+	p := &rec.Properties
+
+	md := El("gmd:MD_Metadata", "").
+		Attr("xmlns:gmd", NamespaceGMD).
+		Attr("xmlns:gco", NamespaceGCO).
+		Attr("xmlns:gml", NamespaceGML).
+		Attr("xmlns:xlink", NamespaceXLink)
+	add := func(e *Element, children ...Element) {
+		e.Children = append(e.Children, children...)
+	}
+
+	add(&md, El("gmd:fileIdentifier", "", isoString(rec.Identifier)))
+	if p.Language != "" {
+		add(&md, El("gmd:language", "", isoCode("LanguageCode", p.Language)))
+	}
+	if collection := rec.Collection(); collection != "" {
+		add(&md, El("gmd:parentIdentifier", "", isoString(collection)))
+	}
+	scope := p.Type
+	if scope == "" {
+		scope = "dataset"
+	}
+	add(&md, El("gmd:hierarchyLevel", "", isoCode("MD_ScopeCode", scope)))
+	if len(p.Contacts) > 0 {
+		add(&md, El("gmd:contact", "", isoResponsibleParty(p.Contacts[0])))
+	}
+	dateStamp := p.Modified
+	if dateStamp == nil {
+		dateStamp = p.Created
+	}
+	if dateStamp == nil {
+		dateStamp = &p.Geocatalogo.Inserted
+	}
+	add(&md, El("gmd:dateStamp", "", El("gco:DateTime", dateStamp.UTC().Format(time.RFC3339))))
+
+	// identification
+	citation := El("gmd:CI_Citation", "", El("gmd:title", "", isoString(p.Title)))
+	dates := p.Dates
+	if len(dates) == 0 && p.Created != nil {
+		dates = []metadata.Date{{Type: "creation", Value: p.Created.Format(time.RFC3339)}}
+	}
+	for _, d := range dates {
+		add(&citation, El("gmd:date", "", El("gmd:CI_Date", "",
+			El("gmd:date", "", isoDateElement(d.Value)),
+			El("gmd:dateType", "", isoCode("CI_DateTypeCode", d.Type)))))
+	}
+
+	identification := El("gmd:MD_DataIdentification", "",
+		El("gmd:citation", "", citation),
+		El("gmd:abstract", "", isoString(p.Abstract)))
+	for _, c := range p.Contacts {
+		add(&identification, El("gmd:pointOfContact", "", isoResponsibleParty(c)))
+	}
+	var topics []string
+	for _, ks := range p.KeywordsSets {
+		if ks.Type == "isoTopicCategory" {
+			topics = append(topics, ks.Keyword...)
+			continue
+		}
+		keywords := El("gmd:MD_Keywords", "")
+		for _, k := range ks.Keyword {
+			add(&keywords, El("gmd:keyword", "", isoString(k)))
+		}
+		if ks.Type != "" {
+			add(&keywords, El("gmd:type", "", isoCode("MD_KeywordTypeCode", ks.Type)))
+		}
+		if ks.Thesaurus != "" {
+			add(&keywords, El("gmd:thesaurusName", "", El("gmd:CI_Citation", "",
+				El("gmd:title", "", isoString(ks.Thesaurus)))))
+		}
+		add(&identification, El("gmd:descriptiveKeywords", "", keywords))
+	}
+	if p.License != "" {
+		add(&identification, El("gmd:resourceConstraints", "", El("gmd:MD_LegalConstraints", "",
+			El("gmd:useConstraints", "", isoCode("MD_RestrictionCode", "otherRestrictions")),
+			El("gmd:otherConstraints", "", isoString(p.License)))))
+	}
+	if p.Language != "" {
+		add(&identification, El("gmd:language", "", isoCode("LanguageCode", p.Language)))
+	}
+	for _, topic := range topics {
+		add(&identification, El("gmd:topicCategory", "", El("gmd:MD_TopicCategoryCode", topic)))
+	}
+
+	extent := El("gmd:EX_Extent", "")
+	if bbox, ok := recordBBox(rec); ok {
+		add(&extent, El("gmd:geographicElement", "", El("gmd:EX_GeographicBoundingBox", "",
+			El("gmd:westBoundLongitude", "", El("gco:Decimal", formatFloat(bbox[0]))),
+			El("gmd:eastBoundLongitude", "", El("gco:Decimal", formatFloat(bbox[2]))),
+			El("gmd:southBoundLatitude", "", El("gco:Decimal", formatFloat(bbox[1]))),
+			El("gmd:northBoundLatitude", "", El("gco:Decimal", formatFloat(bbox[3]))))))
+	}
+	if te := p.TemporalExtent; te != nil && (te.Begin != nil || te.End != nil) {
+		add(&extent, El("gmd:temporalElement", "", El("gmd:EX_TemporalExtent", "", El("gmd:extent", "",
+			El("gml:TimePeriod", "",
+				isoTimePosition("gml:beginPosition", te.Begin),
+				isoTimePosition("gml:endPosition", te.End)).Attr("gml:id", "T1")))))
+	} else if p.Datetime != nil {
+		add(&extent, El("gmd:temporalElement", "", El("gmd:EX_TemporalExtent", "", El("gmd:extent", "",
+			El("gml:TimeInstant", "",
+				isoTimePosition("gml:timePosition", p.Datetime)).Attr("gml:id", "T1")))))
+	}
+	if len(extent.Children) > 0 {
+		add(&identification, El("gmd:extent", "", extent))
+	}
+	add(&md, El("gmd:identificationInfo", "", identification))
+
+	// distribution
+	options := El("gmd:MD_DigitalTransferOptions", "")
+	for _, link := range append(append([]metadata.Link(nil), rec.Links...), rec.Assets...) {
+		if link.URL == "" {
+			continue
+		}
+		resource := El("gmd:CI_OnlineResource", "", El("gmd:linkage", "", El("gmd:URL", link.URL)))
+		if link.Protocol != "" {
+			add(&resource, El("gmd:protocol", "", isoString(link.Protocol)))
+		}
+		if link.Name != "" {
+			add(&resource, El("gmd:name", "", isoString(link.Name)))
+		}
+		if link.Description != "" {
+			add(&resource, El("gmd:description", "", isoString(link.Description)))
+		}
+		add(&options, El("gmd:onLine", "", resource))
+	}
+	if len(options.Children) > 0 {
+		add(&md, El("gmd:distributionInfo", "", El("gmd:MD_Distribution", "",
+			El("gmd:transferOptions", "", options))))
+	}
+	return md
+}
+
+// isoString renders a gco:CharacterString
+func isoString(value string) Element {
+	return El("gco:CharacterString", value)
+}
+
+// isoCode renders a codelist value
+func isoCode(codeList string, value string) Element {
+	return El("gmd:"+codeList, value).
+		Attr("codeList", isoCodeLists+"#"+codeList).
+		Attr("codeListValue", value)
+}
+
+// isoResponsibleParty renders a contact as a gmd:CI_ResponsibleParty
+func isoResponsibleParty(c metadata.Contact) Element {
+	role := c.Type
+	if role == "" {
+		role = "pointOfContact"
+	}
+	return El("gmd:CI_ResponsibleParty", "",
+		El("gmd:organisationName", "", isoString(c.Value)),
+		El("gmd:role", "", isoCode("CI_RoleCode", role)))
+}
+
+// isoDateElement renders a gco:Date, or a gco:DateTime if the value
+// has a time
+func isoDateElement(value string) Element {
+	if len(value) > len("2006-01-02") {
+		return El("gco:DateTime", value)
+	}
+	return El("gco:Date", value)
+}
+
+// isoTimePosition renders a GML time position, of unknown position if
+// nil
+func isoTimePosition(name string, t *time.Time) Element {
+	if t == nil {
+		return El(name, "").Attr("indeterminatePosition", "unknown")
+	}
+	return El(name, t.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,212 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+// Package writers renders metadata records in external metadata formats
+// (crosswalks), complementing metadata/parsers
+package writers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// Element is a generic XML element, whose names carry their namespace
+// prefix (e.g. gmd:MD_Metadata)
+type Element struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Text     string     `xml:",chardata"`
+	Children []Element
+}
+
+// El creates an element with text and/or children
+func El(name string, text string, children ...Element) Element {
+	return Element{XMLName: xml.Name{Local: name}, Text: text, Children: children}
+}
+
+// Attr adds an attribute to an element
+func (e Element) Attr(name string, value string) Element {
+	e.Attrs = append(e.Attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+	return e
+}
+
+// Writer describes an output format
+type Writer struct {
+	// Name identifies the format (e.g. for the format parameter)
+	Name string
+	// Schema is the URI of the format (e.g. for the CSW outputSchema)
+	Schema    string
+	MediaType string
+	// Extension is the file extension of documents (e.g. .xml)
+	Extension string
+	// Element renders a record as the root element of an XML document,
+	// declaring its namespaces (XML formats)
+	Element func(rec *metadata.Record) Element
+	// Document renders a record as a JSON document (JSON formats)
+	Document func(rec *metadata.Record) interface{}
+}
+
+// registry lists the writers
+var registry = []Writer{
+	{
+		Name:      "dc",
+		Schema:    NamespaceCSW2,
+		MediaType: "application/xml",
+		Extension: ".xml",
+		Element:   DublinCore,
+	},
+	{
+		Name:      "iso19139",
+		Schema:    NamespaceGMD,
+		MediaType: "application/xml",
+		Extension: ".xml",
+		Element:   ISO19139,
+	},
+	{
+		Name:      "dcat",
+		Schema:    NamespaceDCAT,
+		MediaType: "application/ld+json",
+		Extension: ".jsonld",
+		Document:  DCAT,
+	},
+	{
+		Name:      "datacite",
+		Schema:    NamespaceDataCite,
+		MediaType: "application/xml",
+		Extension: ".xml",
+		Element:   DataCite,
+	},
+}
+
+// Writers lists the writers
+func Writers() []Writer {
+	return append([]Writer(nil), registry...)
+}
+
+// Names lists the names of the writers
+func Names() []string {
+	var names []string
+	for _, w := range registry {
+		names = append(names, w.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the writer of a name
+func Lookup(name string) (Writer, bool) {
+	for _, w := range registry {
+		if w.Name == name {
+			return w, true
+		}
+	}
+	return Writer{}, false
+}
+
+// LookupSchema returns the writer of a schema URI
+func LookupSchema(schema string) (Writer, bool) {
+	for _, w := range registry {
+		if w.Schema == schema {
+			return w, true
+		}
+	}
+	return Writer{}, false
+}
+
+// IsXML tells whether a writer renders XML
+func (w Writer) IsXML() bool {
+	return w.Element != nil
+}
+
+// Marshal renders a record as a document, indented if pretty
+func (w Writer) Marshal(rec *metadata.Record, pretty bool) ([]byte, error) {
+	var data []byte
+	var err error
+
+	switch {
+	case w.Element != nil:
+		if pretty {
+			data, err = xml.MarshalIndent(w.Element(rec), "", "    ")
+		} else {
+			data, err = xml.Marshal(w.Element(rec))
+		}
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(xml.Header), data...), nil
+	case w.Document != nil:
+		if pretty {
+			return json.MarshalIndent(w.Document(rec), "", "    ")
+		}
+		return json.Marshal(w.Document(rec))
+	}
+	return nil, fmt.Errorf("writer %s renders nothing", w.Name)
+}
+
+// contactRole maps the role of a contact to a Dublin Core role:
+// creator, publisher or contributor
+func contactRole(c metadata.Contact) string {
+	switch strings.ToLower(c.Type) {
+	case "creator", "author", "originator":
+		return "creator"
+	case "publisher":
+		return "publisher"
+	}
+	return "contributor"
+}
+
+// recordBBox returns the bounding box of a record (or the bounds of its
+// geometry), false if it has none
+func recordBBox(rec *metadata.Record) ([4]float64, bool) {
+	bbox := rec.BoundingBox
+	if bbox == ([4]float64{}) && !rec.Geometry.IsEmpty() {
+		bbox = rec.Geometry.Bounds()
+	}
+	return bbox, bbox != ([4]float64{})
+}
+
+// IntervalBound formats a bound of a time interval, ".." if open
+func IntervalBound(t *time.Time) string {
+	if t == nil {
+		return ".."
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// isURL tells whether a value is an absolute http(s) URL
+func isURL(value string) bool {
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
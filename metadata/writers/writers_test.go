@@ -0,0 +1,231 @@
+package writers_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/parsers"
+	"github.com/go-spatial/geocatalogo/metadata/writers"
+)
+
+func testRecord(t *testing.T) metadata.Record {
+	source, err := ioutil.ReadFile("../parsers/testdata/inspire.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := parsers.ParseISORecord(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+func TestLookup(t *testing.T) {
+	if !reflect.DeepEqual(writers.Names(), []string{"datacite", "dc", "dcat", "iso19139"}) {
+		t.Errorf("unexpected writers %v", writers.Names())
+	}
+	w, ok := writers.LookupSchema(writers.NamespaceGMD)
+	if !ok || w.Name != "iso19139" || !w.IsXML() {
+		t.Errorf("unexpected writer of %s: %+v", writers.NamespaceGMD, w)
+	}
+	if w, ok := writers.Lookup("dcat"); !ok || w.IsXML() || w.MediaType != "application/ld+json" {
+		t.Errorf("unexpected dcat writer %+v", w)
+	}
+	if _, ok := writers.Lookup("marc"); ok {
+		t.Error("unexpected writer marc")
+	}
+}
+
+func TestISO19139RoundTrip(t *testing.T) {
+	rec := testRecord(t)
+	w, _ := writers.Lookup("iso19139")
+	data, err := w.Marshal(&rec, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsers.IsISORecord(data) {
+		t.Fatalf("not an ISO record:\n%s", data)
+	}
+	parsed, err := parsers.ParseISORecord(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, q := rec.Properties, parsed.Properties
+	if parsed.Identifier != rec.Identifier || q.Title != p.Title || q.Abstract != p.Abstract ||
+		q.Language != p.Language || q.License != p.License || q.Type != p.Type {
+		t.Errorf("expected %+v, got %+v", p, q)
+	}
+	if parsed.BoundingBox != rec.BoundingBox {
+		t.Errorf("expected bbox %v, got %v", rec.BoundingBox, parsed.BoundingBox)
+	}
+	if !reflect.DeepEqual(q.KeywordsSets, p.KeywordsSets) {
+		t.Errorf("expected keywords %+v, got %+v", p.KeywordsSets, q.KeywordsSets)
+	}
+	if !reflect.DeepEqual(q.Contacts, p.Contacts) {
+		t.Errorf("expected contacts %+v, got %+v", p.Contacts, q.Contacts)
+	}
+	if !reflect.DeepEqual(q.Dates, p.Dates) || !q.Created.Equal(*p.Created) || !q.Modified.Equal(*p.Modified) {
+		t.Errorf("expected dates %+v, got %+v", p.Dates, q.Dates)
+	}
+	if !q.TemporalExtent.Begin.Equal(*p.TemporalExtent.Begin) || (p.TemporalExtent.End == nil) != (q.TemporalExtent.End == nil) {
+		t.Errorf("expected temporal extent %+v, got %+v", p.TemporalExtent, q.TemporalExtent)
+	}
+	if !reflect.DeepEqual(parsed.Links, rec.Links) {
+		t.Errorf("expected links %+v, got %+v", rec.Links, parsed.Links)
+	}
+}
+
+func TestDublinCore(t *testing.T) {
+	rec := testRecord(t)
+	w, _ := writers.Lookup("dc")
+	data, err := w.Marshal(&rec, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := parsers.ParseCSWRecord(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Identifier != rec.Identifier || parsed.Properties.Title != rec.Properties.Title || parsed.BoundingBox != rec.BoundingBox {
+		t.Errorf("unexpected record %+v", parsed)
+	}
+
+	brief := writers.CSWRecord(&rec, "brief")
+	if brief.XMLName.Local != "csw:BriefRecord" || len(brief.Attrs) != 0 {
+		t.Errorf("unexpected brief record %+v", brief.XMLName)
+	}
+	for _, child := range brief.Children {
+		if child.XMLName.Local == "dct:abstract" {
+			t.Error("unexpected abstract in brief record")
+		}
+	}
+}
+
+func TestDCAT(t *testing.T) {
+	begin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	rec := metadata.Record{
+		Identifier:  "https://example.org/datasets/1",
+		BoundingBox: [4]float64{-10, 40, 5, 55},
+	}
+	rec.Properties.Title = "Dataset"
+	rec.Properties.License = "https://creativecommons.org/licenses/by/4.0/"
+	rec.Properties.KeywordsSets = []metadata.Keywords{{Keyword: []string{"a", "b"}}, {Keyword: []string{"c"}}}
+	rec.Properties.Contacts = []metadata.Contact{{Type: "publisher", Value: "Agency"}, {Type: "author", Value: "Someone"}}
+	rec.Properties.TemporalExtent = &metadata.Temporal{Begin: &begin}
+	rec.Links = []metadata.Link{{URL: "https://example.org/data.zip", Type: "application/zip", Name: "Data"}}
+
+	w, _ := writers.Lookup("dcat")
+	data, err := w.Marshal(&rec, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dataset struct {
+		Id       string   `json:"@id"`
+		Type     string   `json:"@type"`
+		Title    string   `json:"dct:title"`
+		Keywords []string `json:"dcat:keyword"`
+		License  struct {
+			Id string `json:"@id"`
+		} `json:"dct:license"`
+		Publisher struct {
+			Name string `json:"foaf:name"`
+		} `json:"dct:publisher"`
+		Creators []struct {
+			Name string `json:"foaf:name"`
+		} `json:"dct:creator"`
+		Spatial struct {
+			BBox struct {
+				Value string `json:"@value"`
+			} `json:"dcat:bbox"`
+		} `json:"dct:spatial"`
+		Temporal      map[string]interface{} `json:"dct:temporal"`
+		Distributions []struct {
+			AccessURL struct {
+				Id string `json:"@id"`
+			} `json:"dcat:accessURL"`
+			MediaType string `json:"dcat:mediaType"`
+		} `json:"dcat:distribution"`
+	}
+	if err := json.Unmarshal(data, &dataset); err != nil {
+		t.Fatal(err)
+	}
+	if dataset.Id != rec.Identifier || dataset.Type != "dcat:Dataset" || dataset.Title != "Dataset" {
+		t.Errorf("unexpected dataset %s", data)
+	}
+	if !reflect.DeepEqual(dataset.Keywords, []string{"a", "b", "c"}) || dataset.License.Id != rec.Properties.License {
+		t.Errorf("unexpected keywords or license %s", data)
+	}
+	if dataset.Publisher.Name != "Agency" || len(dataset.Creators) != 1 || dataset.Creators[0].Name != "Someone" {
+		t.Errorf("unexpected agents %s", data)
+	}
+	if dataset.Spatial.BBox.Value != "POLYGON((-10 40,5 40,5 55,-10 55,-10 40))" {
+		t.Errorf("unexpected bbox %q", dataset.Spatial.BBox.Value)
+	}
+	if _, ok := dataset.Temporal["dcat:startDate"]; !ok {
+		t.Errorf("unexpected temporal %v", dataset.Temporal)
+	}
+	if _, ok := dataset.Temporal["dcat:endDate"]; ok {
+		t.Errorf("unexpected end date %v", dataset.Temporal)
+	}
+	if len(dataset.Distributions) != 1 || dataset.Distributions[0].AccessURL.Id != "https://example.org/data.zip" || dataset.Distributions[0].MediaType != "application/zip" {
+		t.Errorf("unexpected distributions %s", data)
+	}
+}
+
+func TestDataCite(t *testing.T) {
+	type resource struct {
+		XMLName              xml.Name
+		Identifier           string   `xml:"identifier"`
+		Creators             []string `xml:"creators>creator>creatorName"`
+		Titles               []string `xml:"titles>title"`
+		Publisher            string   `xml:"publisher"`
+		PublicationYear      string   `xml:"publicationYear"`
+		ResourceType         string   `xml:"resourceType"`
+		Subjects             []string `xml:"subjects>subject"`
+		AlternateIdentifiers []string `xml:"alternateIdentifiers>alternateIdentifier"`
+		West                 string   `xml:"geoLocations>geoLocation>geoLocationBox>westBoundLongitude"`
+	}
+
+	rec := testRecord(t)
+	w, _ := writers.Lookup("datacite")
+	data, err := w.Marshal(&rec, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r resource
+	if err := xml.Unmarshal(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.XMLName.Space != writers.NamespaceDataCite || r.XMLName.Local != "resource" {
+		t.Errorf("unexpected root %v", r.XMLName)
+	}
+	if r.Identifier != "" || !reflect.DeepEqual(r.AlternateIdentifiers, []string{rec.Identifier}) {
+		t.Errorf("expected alternate identifier %s, got %q %v", rec.Identifier, r.Identifier, r.AlternateIdentifiers)
+	}
+	if len(r.Titles) != 1 || r.Titles[0] != rec.Properties.Title || r.PublicationYear != "2012" {
+		t.Errorf("unexpected titles or year %v %s", r.Titles, r.PublicationYear)
+	}
+	if len(r.Creators) != 1 || r.Publisher == "" || len(r.Subjects) == 0 || r.West != "-31.27" {
+		t.Errorf("unexpected resource %+v", r)
+	}
+
+	rec.Identifier = "https://doi.org/10.5066/F7VX0DSK"
+	data, _ = w.Marshal(&rec, false)
+	r = resource{}
+	if err := xml.Unmarshal(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Identifier != "10.5066/F7VX0DSK" || len(r.AlternateIdentifiers) != 0 {
+		t.Errorf("expected DOI identifier, got %q %v", r.Identifier, r.AlternateIdentifiers)
+	}
+	if !strings.Contains(string(data), `identifierType="DOI"`) {
+		t.Errorf("expected DOI identifier type in %s", data)
+	}
+}
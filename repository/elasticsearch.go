@@ -28,8 +28,10 @@ package repository
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"reflect"
 	"strings"
@@ -53,9 +55,11 @@ type Elasticsearch struct {
 	Username  string
 	Password  string
 	Mappings  map[string]string
-	Index     elastic.Client
+	Index     *elastic.Client
 	IndexName string
 	TypeName  string
+	BatchSize int
+	Retries   int
 }
 
 func createClient(repo *config.Repository) (*elastic.Client, error) {
@@ -115,7 +119,7 @@ func New(cfg config.Config, log *logrus.Logger) error {
 	createIndex, err := client.CreateIndex(indexName).Body(tpl.String()).Do(ctx)
 	if err != nil {
 		errorText := fmt.Sprintf("Cannot create repository: %v\n", err)
-		log.Errorf(errorText)
+		log.Error(errorText)
 		return errors.New(errorText)
 	}
 	if !createIndex.Acknowledged {
@@ -144,6 +148,11 @@ func Open(cfg config.Config, log *logrus.Logger) (Elasticsearch, error) {
 		Mappings:  cfg.Repository.Mappings,
 		IndexName: getIndexName(cfg.Repository.URL),
 		TypeName:  getTypeName(cfg.Repository.URL),
+		BatchSize: cfg.Repository.BatchSize,
+		Retries:   cfg.Repository.Retries,
+	}
+	if s.BatchSize <= 0 {
+		s.BatchSize = DefaultBatchSize
 	}
 	log.Debug("IndexName: " + s.IndexName)
 	log.Debug("TypeName: " + s.TypeName)
@@ -153,7 +162,7 @@ func Open(cfg config.Config, log *logrus.Logger) (Elasticsearch, error) {
 		return s, err
 	}
 
-	s.Index = *client
+	s.Index = client
 
 	return s, nil
 }
@@ -175,89 +184,272 @@ func (r *Elasticsearch) Insert(record metadata.Record) error {
 	return nil
 }
 
-// Update updates a record in the repository
-func (r *Elasticsearch) Update() bool {
-	return true
+// BulkInsert inserts records into the repository using the _bulk API,
+// in batches of BatchSize.  Items rejected with a retriable status are
+// resent up to Retries times with exponential backoff
+func (r *Elasticsearch) BulkInsert(records []metadata.Record) (BulkResult, error) {
+	var result BulkResult
+
+	for start := 0; start < len(records); start += r.BatchSize {
+		end := start + r.BatchSize
+		if end > len(records) {
+			end = len(records)
+		}
+		batchResult, err := r.bulkInsertBatch(records[start:end])
+		result.Add(batchResult)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (r *Elasticsearch) bulkInsertBatch(records []metadata.Record) (BulkResult, error) {
+	var result BulkResult
+	ctx := context.Background()
+	backoff := elastic.NewExponentialBackoff(100*time.Millisecond, 10*time.Second)
+	now := time.Now()
+	pending := records
+
+	for attempt := 0; len(pending) > 0; attempt++ {
+		var retry []metadata.Record
+
+		bulk := r.Index.Bulk()
+		for _, record := range pending {
+			record.Properties.Geocatalogo.Inserted = now
+			bulk.Add(elastic.NewBulkIndexRequest().
+				Index(r.IndexName).
+				Type(r.TypeName).
+				Id(record.Identifier).
+				Doc(record))
+		}
+
+		response, err := bulk.Do(ctx)
+		if err != nil {
+			if attempt < r.Retries {
+				wait, _ := backoff.Next(attempt)
+				time.Sleep(wait)
+				continue
+			}
+			for _, record := range pending {
+				result.Errors = append(result.Errors, BulkItemError{Identifier: record.Identifier, Reason: err.Error()})
+			}
+			return result, err
+		}
+
+		for i, item := range response.Items {
+			status := item["index"]
+			if status == nil {
+				continue
+			}
+			if status.Status >= 200 && status.Status <= 299 {
+				result.Indexed++
+				continue
+			}
+			if isRetriable(status.Status) && attempt < r.Retries {
+				retry = append(retry, pending[i])
+				continue
+			}
+			reason := fmt.Sprintf("status %d", status.Status)
+			if status.Error != nil {
+				reason = status.Error.Reason
+			}
+			result.Errors = append(result.Errors, BulkItemError{Identifier: status.Id, Reason: reason})
+		}
+
+		if len(retry) > 0 {
+			wait, _ := backoff.Next(attempt)
+			time.Sleep(wait)
+		}
+		pending = retry
+	}
+	return result, nil
+}
+
+// isRetriable reports whether a bulk item status indicates a transient failure
+func isRetriable(status int) bool {
+	return status == 429 || status == 502 || status == 503 || status == 504
+}
+
+// Update replaces an existing record in the repository, preserving
+// its original insertion time
+func (r *Elasticsearch) Update(record metadata.Record) error {
+	var existing metadata.Record
+	ctx := context.Background()
+
+	result, err := r.Index.Get().
+		Index(r.IndexName).
+		Type(r.TypeName).
+		Id(record.Identifier).
+		Do(ctx)
+
+	if elastic.IsNotFound(err) {
+		return fmt.Errorf("%w: %s", ErrNotFound, record.Identifier)
+	}
+	if err != nil {
+		return err
+	}
+	if result.Source != nil {
+		if err := json.Unmarshal(*result.Source, &existing); err != nil {
+			return err
+		}
+	}
+
+	record.Properties.Geocatalogo.Inserted = existing.Properties.Geocatalogo.Inserted
+	_, err = r.Index.Index().
+		Index(r.IndexName).
+		Type(r.TypeName).
+		Id(record.Identifier).
+		BodyJson(record).
+		Refresh("true").
+		Do(ctx)
+
+	return err
+}
+
+// Delete deletes records by identifier from the repository
+func (r *Elasticsearch) Delete(identifiers []string) error {
+	var missing []string
+	ctx := context.Background()
+
+	if len(identifiers) == 0 {
+		return nil
+	}
+
+	bulk := r.Index.Bulk().Refresh("true")
+	for _, id := range identifiers {
+		bulk.Add(elastic.NewBulkDeleteRequest().
+			Index(r.IndexName).
+			Type(r.TypeName).
+			Id(id))
+	}
+
+	response, err := bulk.Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range response.Failed() {
+		if item.Status == 404 {
+			missing = append(missing, item.Id)
+		} else if item.Error != nil {
+			return fmt.Errorf("cannot delete %s: %s", item.Id, item.Error.Reason)
+		} else {
+			return fmt.Errorf("cannot delete %s: status %d", item.Id, item.Status)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, strings.Join(missing, ","))
+	}
+	return nil
 }
 
-// Delete deletes a record from the repository
-func (r *Elasticsearch) Delete() bool {
-	return true
+// DeleteByQuery deletes all records matching any of the given collections
+// and/or sources from the repository, returning the number of deleted records
+func (r *Elasticsearch) DeleteByQuery(collections []string, sources []string) (int, error) {
+	ctx := context.Background()
+
+	if len(collections) == 0 && len(sources) == 0 {
+		return 0, errors.New("one of collections or sources is required")
+	}
+
+	query := elastic.NewBoolQuery()
+
+	if len(collections) > 0 {
+		query = query.Must(collectionQuery(collections...))
+	}
+	if len(sources) > 0 {
+		s := make([]interface{}, len(sources))
+		for i, src := range sources {
+			s[i] = src
+		}
+		query = query.Must(elastic.NewTermsQuery("properties._geocatalogo.source.keyword", s...))
+	}
+
+	response, err := r.Index.DeleteByQuery(r.IndexName).
+		Type(r.TypeName).
+		Query(query).
+		Refresh("true").
+		Do(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return int(response.Deleted), nil
 }
 
 // Query performs a search against the repository
-func (r *Elasticsearch) Query(collections []string, term string, bbox []float64, timeVal []time.Time, from int, size int, sr *search.Results) error {
+func (r *Elasticsearch) Query(ctx context.Context, q search.Query, sr *search.Results) error {
 	var mr metadata.Record
 	//	var query elastic.Query
-	ctx := context.Background()
 
 	query := elastic.NewBoolQuery()
 
-	if term == "" {
+	if q.Term == "" {
 		query = query.Must(elastic.NewMatchAllQuery())
 	} else {
-		query = query.Must(elastic.NewQueryStringQuery(term))
+		query = query.Must(elastic.NewQueryStringQuery(q.Term))
 	}
-	if len(timeVal) > 0 {
-		if len(timeVal) == 1 { // exact match
-			query = query.Must(elastic.NewTermQuery("properties.product_info.acquisition_date", timeVal[0]))
-		} else if len(timeVal) == 2 { // range
+	if len(q.Time) > 0 {
+		if len(q.Time) == 1 { // exact match
+			query = query.Must(elastic.NewTermQuery("properties.product_info.acquisition_date", q.Time[0]))
+		} else if len(q.Time) == 2 { // range
 			rangeQuery := elastic.NewRangeQuery("properties.product_info.acquisition_date").
-				From(timeVal[0]).
-				To(timeVal[1])
+				From(q.Time[0]).
+				To(q.Time[1])
 			query = query.Must(rangeQuery)
 		}
 	}
-	if len(bbox) == 4 {
-		// workaround for issuing a RawStringQuery until
-		// GeoShape queries are supported (https://github.com/olivere/elastic/pull/276)
-		var tpl bytes.Buffer
-		vars := map[string]interface{}{
-			"bbox":  bbox,
-			"field": "geometry",
+	if q.Intersects != nil || len(q.BBox) == 4 {
+		relation, err := search.ParseRelation(q.Relation)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidQuery, err)
+		}
+		var shape interface{}
+		if q.Intersects != nil {
+			shape = q.Intersects
+		} else {
+			shape = envelopeShape([4]float64{q.BBox[0], q.BBox[1], q.BBox[2], q.BBox[3]})
+		}
+		geoQuery, err := geoShapeQuery("geometry", shape, relation)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidQuery, err)
 		}
-		rawStringQueryTemplate, _ := template.New("geo_shape_query").Parse(`{   
-          "geo_shape": {
-            "{{ .field }}": {
-              "shape": {
-                "type": "envelope",
-                "coordinates": [
-                  [   
-                    {{ index .bbox 0 }}, 
-                    {{ index .bbox 1 }}
-                  ],  
-                  [   
-                    {{ index .bbox 2 }}, 
-                    {{ index .bbox 3 }}
-                  ]   
-                ]
-              },
-              "relation": "within"
-            }   
-          }   
-        }`)
-		rawStringQueryTemplate.Execute(&tpl, vars)
-
-		query = query.Must(elastic.NewRawStringQuery(tpl.String()))
+		query = query.Must(geoQuery)
 	}
-	if len(collections) > 0 {
-		c := make([]interface{}, len(collections))
-		for i, s := range collections {
-			c[i] = s
+	if len(q.Collections) > 0 {
+		query = query.Must(collectionQuery(q.Collections...))
+	}
+	if q.Filter != nil {
+		filterQuery, err := cql2Query(q.Filter)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidQuery, err)
 		}
-		query = query.Must(elastic.NewTermsQuery("properties.product_info.collection", c...))
+		query = query.Filter(filterQuery)
 	}
 
 	//src, err := query.Source()
 	//data, err := json.Marshal(src)
 	//fmt.Println(string(data))
 
-	searchResult, err := r.Index.Search().
+	searchService := r.Index.Search().
 		Index(r.IndexName).
 		Type(r.TypeName).
-		From(from).
-		Size(size).
-		Query(query).Do(ctx)
+		From(q.From).
+		Size(q.Size).
+		Query(query)
+
+	if len(q.SortBy) > 0 {
+		for _, sf := range q.SortBy {
+			searchService = searchService.SortBy(sortField(sf))
+		}
+		// tie-breaker for deterministic paging
+		searchService = searchService.SortBy(elastic.NewFieldSort("id.keyword").Asc())
+	}
+
+	searchResult, err := searchService.Do(ctx)
 
 	if err != nil {
 		fmt.Println(err)
@@ -267,10 +459,10 @@ func (r *Elasticsearch) Query(collections []string, term string, bbox []float64,
 
 	sr.ElapsedTime = int(searchResult.TookInMillis)
 	sr.Matches = int(searchResult.TotalHits())
-	sr.Returned = size
-	sr.NextRecord = size + 1
+	sr.Returned = q.Size
+	sr.NextRecord = q.Size + 1
 
-	if sr.Matches < size {
+	if sr.Matches < q.Size {
 		sr.Returned = sr.Matches
 		sr.NextRecord = 0
 	}
@@ -312,6 +504,184 @@ func (r *Elasticsearch) Get(identifiers []string, sr *search.Results) error {
 	return nil
 }
 
+// collectionIndex returns the name of the ES Index holding collections
+func (r *Elasticsearch) collectionIndex() string {
+	return r.IndexName + "-collections"
+}
+
+// PutCollection adds or replaces a collection
+func (r *Elasticsearch) PutCollection(collection metadata.Collection) error {
+	_, err := r.Index.Index().
+		Index(r.collectionIndex()).
+		Type(r.TypeName).
+		Id(collection.Identifier).
+		BodyJson(collection).
+		Refresh("true").
+		Do(context.Background())
+
+	return err
+}
+
+// DeleteCollection removes a collection.  Records in the collection are
+// kept
+func (r *Elasticsearch) DeleteCollection(id string) error {
+	_, err := r.Index.Delete().
+		Index(r.collectionIndex()).
+		Type(r.TypeName).
+		Id(id).
+		Refresh("true").
+		Do(context.Background())
+
+	if elastic.IsNotFound(err) {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return err
+}
+
+// GetCollection retrieves a collection
+func (r *Elasticsearch) GetCollection(id string) (metadata.Collection, error) {
+	var collection metadata.Collection
+
+	result, err := r.Index.Get().
+		Index(r.collectionIndex()).
+		Type(r.TypeName).
+		Id(id).
+		Do(context.Background())
+
+	if elastic.IsNotFound(err) {
+		return collection, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return collection, err
+	}
+	if result.Source != nil {
+		if err := json.Unmarshal(*result.Source, &collection); err != nil {
+			return collection, err
+		}
+	}
+	return collection, nil
+}
+
+// ListCollections lists all collections, ordered by identifier
+func (r *Elasticsearch) ListCollections() ([]metadata.Collection, error) {
+	var mc metadata.Collection
+	collections := []metadata.Collection{}
+
+	searchResult, err := r.Index.Search().
+		Index(r.collectionIndex()).
+		Type(r.TypeName).
+		Query(elastic.NewMatchAllQuery()).
+		SortBy(elastic.NewFieldSort("id.keyword").Asc()).
+		Size(10000).
+		Do(context.Background())
+
+	if elastic.IsNotFound(err) { // no collection stored yet
+		return collections, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range searchResult.Each(reflect.TypeOf(mc)) {
+		if t, ok := item.(metadata.Collection); ok {
+			collections = append(collections, t)
+		}
+	}
+	return collections, nil
+}
+
+// CollectionExtent computes the extent of the records in a collection
+func (r *Elasticsearch) CollectionExtent(id string) (metadata.Extent, error) {
+	var extent metadata.Extent
+	var mr metadata.Record
+	ctx := context.Background()
+
+	query := collectionQuery(id)
+
+	scroll := r.Index.Scroll(r.IndexName).
+		Type(r.TypeName).
+		Query(query).
+		FetchSourceContext(elastic.NewFetchSourceContext(true).
+			Include("bbox", "geometry", "properties.datetime", "properties.temporal_extent")).
+		Size(r.BatchSize)
+	defer scroll.Clear(ctx)
+
+	for {
+		result, err := scroll.Do(ctx)
+		if err == io.EOF {
+			return extent, nil
+		}
+		if err != nil {
+			return extent, err
+		}
+		for _, item := range result.Each(reflect.TypeOf(mr)) {
+			if t, ok := item.(metadata.Record); ok {
+				extent.Include(&t)
+			}
+		}
+	}
+}
+
+// collectionQuery matches the records of any of the given collections,
+// as metadata.Record.Collection: properties.collection, else
+// properties.product_info.collection
+func collectionQuery(collections ...string) elastic.Query {
+	c := make([]interface{}, len(collections))
+	for i, s := range collections {
+		c[i] = s
+	}
+	return elastic.NewBoolQuery().
+		Should(elastic.NewTermsQuery("properties.collection.keyword", c...)).
+		Should(elastic.NewBoolQuery().
+			Must(elastic.NewTermsQuery("properties.product_info.collection", c...)).
+			MustNot(elastic.NewExistsQuery("properties.collection"))).
+		MinimumNumberShouldMatch(1)
+}
+
+// geoShapeQuery generates a geo_shape query.  Raw queries are used
+// until GeoShape queries are supported (https://github.com/olivere/elastic/pull/276)
+func geoShapeQuery(field string, shape interface{}, relation string) (elastic.Query, error) {
+	source := map[string]interface{}{
+		"geo_shape": map[string]interface{}{
+			field: map[string]interface{}{
+				"shape":    shape,
+				"relation": relation,
+			},
+		},
+	}
+	data, err := json.Marshal(source)
+	if err != nil {
+		return nil, err
+	}
+	return elastic.NewRawStringQuery(string(data)), nil
+}
+
+// envelopeShape generates an ES envelope shape for a bounding box.
+// Bounding boxes crossing the antimeridian are split into a MultiPolygon
+func envelopeShape(bbox [4]float64) interface{} {
+	if bbox[0] > bbox[2] {
+		return metadata.NewEnvelope(bbox)
+	}
+	return map[string]interface{}{
+		"type":        "envelope",
+		"coordinates": [][2]float64{{bbox[0], bbox[3]}, {bbox[2], bbox[1]}},
+	}
+}
+
+// sortField generates an ES sort for a SortField.  String properties are
+// sorted on their keyword sub-field, as created by dynamic mapping
+func sortField(sf search.SortField) elastic.Sorter {
+	field := sf.Field
+	if _, t, ok := metadata.ResolveField(sf.Field); ok && t.Kind() == reflect.String {
+		field = field + ".keyword"
+	}
+	fs := elastic.NewFieldSort(field).Missing("_last")
+	if sf.Descending {
+		return fs.Desc()
+	}
+	return fs.Asc()
+}
+
 // getTypeName returns the name of the ES Index
 func getIndexName(url string) string {
 	tokens := strings.Split(url, "/")
@@ -323,3 +693,9 @@ func getTypeName(url string) string {
 	tokens := strings.Split(url, "/")
 	return tokens[len(tokens)-1]
 }
+
+// SpatialOperators lists the CQL2 spatial operators supported in
+// filters, those of geo_shape queries
+func (r *Elasticsearch) SpatialOperators() []string {
+	return esSpatialOperators
+}
@@ -0,0 +1,379 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/olivere/elastic.v6"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/search/cql2"
+)
+
+// inverses of spatial and temporal predicates, used to put the
+// property operand first
+var inverseOps = map[string]string{
+	"s_within": "s_contains", "s_contains": "s_within",
+	"t_after": "t_before", "t_before": "t_after",
+	"t_contains": "t_during", "t_during": "t_contains",
+	"t_finishedby": "t_finishes", "t_finishes": "t_finishedby",
+	"t_meets": "t_metby", "t_metby": "t_meets",
+	"t_overlappedby": "t_overlaps", "t_overlaps": "t_overlappedby",
+	"t_startedby": "t_starts", "t_starts": "t_startedby",
+	"<": ">", ">": "<", "<=": ">=", ">=": "<=",
+}
+
+func inverse(op string) string {
+	if inv, ok := inverseOps[op]; ok {
+		return inv
+	}
+	return op
+}
+
+// cql2Query translates a CQL2 expression into an ES query
+func cql2Query(e cql2.Expr) (elastic.Query, error) {
+	switch n := e.(type) {
+	case cql2.And, cql2.Or:
+		var args []cql2.Expr
+		if and, ok := n.(cql2.And); ok {
+			args = and.Args
+		} else {
+			args = n.(cql2.Or).Args
+		}
+		query := elastic.NewBoolQuery()
+		for _, a := range args {
+			q, err := cql2Query(a)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := n.(cql2.And); ok {
+				query = query.Filter(q)
+			} else {
+				query = query.Should(q)
+			}
+		}
+		if _, ok := n.(cql2.Or); ok {
+			query = query.MinimumNumberShouldMatch(1)
+		}
+		return query, nil
+	case cql2.Not:
+		q, err := cql2Query(n.Arg)
+		if err != nil {
+			return nil, err
+		}
+		return elastic.NewBoolQuery().MustNot(q), nil
+	case cql2.Bool:
+		if n.Value {
+			return elastic.NewMatchAllQuery(), nil
+		}
+		return matchNone(), nil
+	case cql2.Comparison:
+		op := n.Op
+		field, value, err := propertyAndValue(n.Left, n.Right)
+		if err != nil {
+			if field, value, err = propertyAndValue(n.Right, n.Left); err != nil {
+				return nil, err
+			}
+			op = inverse(op)
+		}
+		switch op {
+		case "=":
+			return elastic.NewTermQuery(keywordField(field), value), nil
+		case "<>":
+			return elastic.NewBoolQuery().
+				Filter(elastic.NewExistsQuery(field)).
+				MustNot(elastic.NewTermQuery(keywordField(field), value)), nil
+		case "<":
+			return elastic.NewRangeQuery(keywordField(field)).Lt(value), nil
+		case ">":
+			return elastic.NewRangeQuery(keywordField(field)).Gt(value), nil
+		case "<=":
+			return elastic.NewRangeQuery(keywordField(field)).Lte(value), nil
+		case ">=":
+			return elastic.NewRangeQuery(keywordField(field)).Gte(value), nil
+		}
+	case cql2.Like:
+		field, err := esField(n.Value)
+		if err != nil {
+			return nil, err
+		}
+		return elastic.NewWildcardQuery(keywordField(field), wildcardPattern(n.Pattern)), nil
+	case cql2.In:
+		field, err := esField(n.Value)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]interface{}, len(n.List))
+		for i, item := range n.List {
+			if values[i], err = esValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return elastic.NewTermsQuery(keywordField(field), values...), nil
+	case cql2.Between:
+		field, err := esField(n.Value)
+		if err != nil {
+			return nil, err
+		}
+		low, err := esValue(n.Low)
+		if err != nil {
+			return nil, err
+		}
+		high, err := esValue(n.High)
+		if err != nil {
+			return nil, err
+		}
+		return elastic.NewRangeQuery(keywordField(field)).Gte(low).Lte(high), nil
+	case cql2.IsNull:
+		field, err := esField(n.Value)
+		if err != nil {
+			return nil, err
+		}
+		return elastic.NewBoolQuery().MustNot(elastic.NewExistsQuery(field)), nil
+	case cql2.Spatial:
+		return spatialQuery(n)
+	case cql2.Temporal:
+		return temporalQuery(n)
+	}
+	return nil, fmt.Errorf("unsupported filter expression %T", e)
+}
+
+// esField returns the document field of a property operand
+func esField(o cql2.Operand) (string, error) {
+	p, ok := o.(cql2.Property)
+	if !ok {
+		return "", fmt.Errorf("expected a property, found %T", o)
+	}
+	path, ok := cql2.ResolveProperty(p.Name)
+	if !ok {
+		return "", fmt.Errorf("unknown queryable %q", p.Name)
+	}
+	return path, nil
+}
+
+// esValue returns the query value of a literal operand
+func esValue(o cql2.Operand) (interface{}, error) {
+	switch t := o.(type) {
+	case cql2.Literal:
+		return t.Value, nil
+	case cql2.Timestamp:
+		return t.Time.Format(time.RFC3339Nano), nil
+	}
+	return nil, fmt.Errorf("expected a literal, found %T", o)
+}
+
+func propertyAndValue(p cql2.Operand, v cql2.Operand) (string, interface{}, error) {
+	field, err := esField(p)
+	if err != nil {
+		return "", nil, err
+	}
+	value, err := esValue(v)
+	if err != nil {
+		return "", nil, err
+	}
+	return field, value, nil
+}
+
+// keywordField targets the keyword sub-field of string properties, as
+// created by dynamic mapping
+func keywordField(field string) string {
+	if _, t, ok := metadata.ResolveField(field); ok && t.Kind() == reflect.String {
+		return field + ".keyword"
+	}
+	return field
+}
+
+// wildcardPattern converts a LIKE pattern to an ES wildcard pattern
+func wildcardPattern(pattern string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			if r == '*' || r == '?' || r == '\\' {
+				b.WriteRune('\\')
+			}
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteRune('*')
+		case r == '_':
+			b.WriteRune('?')
+		case r == '*' || r == '?':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func matchNone() elastic.Query {
+	return elastic.NewBoolQuery().MustNot(elastic.NewMatchAllQuery())
+}
+
+// esSpatialOperators lists the spatial predicates supported by ES
+// geo_shape queries
+var esSpatialOperators = []string{"s_intersects", "s_within", "s_contains", "s_disjoint"}
+
+// spatialQuery translates a spatial predicate to a geo_shape query.  ES
+// supports the intersects, disjoint, within and contains relations
+func spatialQuery(n cql2.Spatial) (elastic.Query, error) {
+	op := n.Op
+	field, err := esField(n.Left)
+	geom, ok := n.Right.(cql2.Geometry)
+	if err != nil || !ok {
+		if field, err = esField(n.Right); err != nil {
+			return nil, err
+		}
+		if geom, ok = n.Left.(cql2.Geometry); !ok {
+			return nil, fmt.Errorf("%s requires a property and a geometry", op)
+		}
+		op = inverse(op)
+	}
+	if field == "bbox" {
+		field = "geometry"
+	}
+
+	if !contains(esSpatialOperators, op) {
+		return nil, fmt.Errorf("%s is not supported by the elasticsearch backend", n.Op)
+	}
+	relation := strings.TrimPrefix(op, "s_")
+
+	var shape interface{} = geom.Geometry
+	if geom.BBox != nil {
+		shape = envelopeShape(*geom.BBox)
+	}
+	return geoShapeQuery(field, shape, relation)
+}
+
+// temporalQuery translates a temporal predicate to range queries.  The
+// property is an instant (e.g. datetime) or an interval with begin and
+// end fields (temporal_extent); the other operand is a literal
+func temporalQuery(n cql2.Temporal) (elastic.Query, error) {
+	op := n.Op
+	field, err := esField(n.Left)
+	literal := n.Right
+	if err != nil {
+		if field, err = esField(n.Right); err != nil {
+			return nil, err
+		}
+		literal = n.Left
+		op = inverse(op)
+	}
+
+	var s, e *time.Time
+	switch t := literal.(type) {
+	case cql2.Timestamp:
+		s, e = &t.Time, &t.Time
+	case cql2.Interval:
+		s, e = t.Start, t.End
+	default:
+		return nil, fmt.Errorf("%s requires a property and a temporal literal", n.Op)
+	}
+
+	start, end := field, field
+	if _, t, _ := metadata.ResolveField(field); t != nil && t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}) {
+		start, end = field+".begin", field+".end"
+	}
+
+	// bound compares a field with a literal bound; a nil start bound is
+	// the beginning and a nil end bound the end of time
+	bound := func(f string, cmp string, t *time.Time, isStart bool) elastic.Query {
+		if t == nil {
+			if isStart && cmp == ">" || !isStart && cmp == "<" {
+				return elastic.NewExistsQuery(f)
+			}
+			return matchNone()
+		}
+		v := t.Format(time.RFC3339Nano)
+		switch cmp {
+		case "<":
+			return elastic.NewRangeQuery(f).Lt(v)
+		case ">":
+			return elastic.NewRangeQuery(f).Gt(v)
+		case "<=":
+			return elastic.NewRangeQuery(f).Lte(v)
+		case ">=":
+			return elastic.NewRangeQuery(f).Gte(v)
+		}
+		return elastic.NewTermQuery(f, v)
+	}
+	all := func(queries ...elastic.Query) elastic.Query {
+		return elastic.NewBoolQuery().Filter(queries...)
+	}
+
+	switch op {
+	case "t_after":
+		return bound(start, ">", e, false), nil
+	case "t_before":
+		return bound(end, "<", s, true), nil
+	case "t_contains":
+		return all(bound(start, "<", s, true), bound(end, ">", e, false)), nil
+	case "t_disjoint":
+		return elastic.NewBoolQuery().
+			Should(bound(end, "<", s, true), bound(start, ">", e, false)).
+			MinimumNumberShouldMatch(1), nil
+	case "t_during":
+		return all(bound(start, ">", s, true), bound(end, "<", e, false)), nil
+	case "t_equals":
+		return all(bound(start, "=", s, true), bound(end, "=", e, false)), nil
+	case "t_finishedby":
+		return all(bound(start, "<", s, true), bound(end, "=", e, false)), nil
+	case "t_finishes":
+		return all(bound(start, ">", s, true), bound(end, "=", e, false)), nil
+	case "t_intersects":
+		// open bounds always hold, so only closed bounds constrain
+		queries := []elastic.Query{elastic.NewExistsQuery(start)}
+		if e != nil {
+			queries = append(queries, bound(start, "<=", e, false))
+		}
+		if s != nil {
+			queries = append(queries, bound(end, ">=", s, true))
+		}
+		return all(queries...), nil
+	case "t_meets":
+		return bound(end, "=", s, true), nil
+	case "t_metby":
+		return bound(start, "=", e, false), nil
+	case "t_overlappedby":
+		return all(bound(start, ">", s, true), bound(start, "<", e, false), bound(end, ">", e, false)), nil
+	case "t_overlaps":
+		return all(bound(start, "<", s, true), bound(end, ">", s, true), bound(end, "<", e, false)), nil
+	case "t_startedby":
+		return all(bound(start, "=", s, true), bound(end, ">", e, false)), nil
+	case "t_starts":
+		return all(bound(start, "=", s, true), bound(end, "<", e, false)), nil
+	}
+	return nil, fmt.Errorf("unsupported temporal predicate %s", n.Op)
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-spatial/geocatalogo/search/cql2"
+)
+
+func TestCQL2Query(t *testing.T) {
+	cases := map[string]string{
+		`title = 'Ottawa'`:                       `{"term":{"properties.title.keyword":"Ottawa"}}`,
+		`10 > eo:cloud_cover`:                    `{"range":{"properties.product_info.cloud_cover":{"from":null,"include_lower":true,"include_upper":false,"to":10}}}`,
+		`title LIKE 'Ott_wa%'`:                   `{"wildcard":{"properties.title.keyword":{"wildcard":"Ott?wa*"}}}`,
+		`license IS NULL`:                        `{"bool":{"must_not":{"exists":{"field":"properties.license"}}}}`,
+		`S_WITHIN(BBOX(0, 0, 1, 1), geometry)`:   `{"geo_shape":{"geometry":{"relation":"contains","shape":{"coordinates":[[0,1],[1,0]],"type":"envelope"}}}}`,
+		`T_BEFORE(datetime, DATE('2019-01-01'))`: `{"range":{"properties.datetime":{"from":null,"include_lower":true,"include_upper":false,"to":"2019-01-01T00:00:00Z"}}}`,
+	}
+	for text, expected := range cases {
+		e, err := cql2.ParseText(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := cql2Query(e)
+		if err != nil {
+			t.Errorf("%s: %v", text, err)
+			continue
+		}
+		src, _ := q.Source()
+		data, _ := json.Marshal(src)
+		if got := strings.TrimSpace(string(data)); got != expected {
+			t.Errorf("%s: expected %s, got %s", text, expected, got)
+		}
+	}
+
+	// only the advertised spatial operators are translated
+	supported := (&Elasticsearch{}).SpatialOperators()
+	for _, op := range (&Memory{}).SpatialOperators() {
+		e, _ := cql2.ParseText(strings.ToUpper(op) + `(geometry, POINT(1 1))`)
+		if _, err := cql2Query(e); (err == nil) != contains(supported, op) {
+			t.Errorf("%s: unexpected translation error %v", op, err)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// Persistence for the in-memory repository backend: an append-only
+// journal of changes plus compacted JSON snapshots
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package repository
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+const (
+	journalPut              = "put"
+	journalDelete           = "delete"
+	journalPutCollection    = "put_collection"
+	journalDeleteCollection = "delete_collection"
+)
+
+// journalEntry describes a single change to the repository
+type journalEntry struct {
+	Op          string               `json:"op"`
+	Record      *metadata.Record     `json:"record,omitempty"`
+	Collection  *metadata.Collection `json:"collection,omitempty"`
+	Identifiers []string             `json:"ids,omitempty"`
+}
+
+// journal provides an append-only log of repository changes,
+// stored as one JSON entry per line
+type journal struct {
+	path string
+	file *os.File
+}
+
+func openJournal(path string) (*journal, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &journal{path: path, file: f}, nil
+}
+
+// append writes entries to the journal and syncs them to disk
+func (j *journal) append(entries ...journalEntry) error {
+	var buf []byte
+
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+	if _, err := j.file.Write(buf); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// truncate empties the journal once its entries have been
+// compacted into a snapshot
+func (j *journal) truncate() error {
+	if err := j.file.Truncate(0); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+func (j *journal) close() error {
+	return j.file.Close()
+}
+
+// replayJournal applies the entries of the journal at path to records
+// and collections, returning the number of entries applied.  A missing
+// journal is not an error
+func replayJournal(path string, records map[string]metadata.Record, collections map[string]metadata.Collection) (int, error) {
+	count := 0
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	for lineno := 1; scanner.Scan(); lineno++ {
+		var entry journalEntry
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return count, fmt.Errorf("%s:%d: %v", path, lineno, err)
+		}
+		switch entry.Op {
+		case journalPut:
+			if entry.Record != nil {
+				records[entry.Record.Identifier] = withBoundingBox(*entry.Record)
+			}
+		case journalDelete:
+			for _, id := range entry.Identifiers {
+				delete(records, id)
+			}
+		case journalPutCollection:
+			if entry.Collection != nil {
+				collections[entry.Collection.Identifier] = *entry.Collection
+			}
+		case journalDeleteCollection:
+			for _, id := range entry.Identifiers {
+				delete(collections, id)
+			}
+		default:
+			return count, fmt.Errorf("%s:%d: unknown operation %q", path, lineno, entry.Op)
+		}
+		count++
+	}
+	return count, scanner.Err()
+}
+
+// writeSnapshot atomically replaces the file at path with a JSON array
+// of all records, ordered by identifier
+func writeSnapshot(path string, records map[string]metadata.Record) error {
+	ids := make([]string, 0, len(records))
+	for id := range records {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	list := make([]metadata.Record, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, records[id])
+	}
+
+	return writeJSONFile(path, list)
+}
+
+// writeCollections atomically replaces the file at path with a JSON
+// array of all collections, ordered by identifier
+func writeCollections(path string, collections map[string]metadata.Collection) error {
+	ids := make([]string, 0, len(collections))
+	for id := range collections {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	list := make([]metadata.Collection, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, collections[id])
+	}
+	return writeJSONFile(path, list)
+}
+
+// writeJSONFile atomically replaces the file at path with v encoded
+// as JSON
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
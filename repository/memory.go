@@ -1,17 +1,23 @@
 ///////////////////////////////////////////////////////////////////////////////
 //
 // In-memory repository backend for geocatalogo
-// Loads records from JSON file for quick local testing
+// Loads records from JSON file for quick local testing, optionally
+// persisting changes back to it
 //
 ///////////////////////////////////////////////////////////////////////////////
 
 package repository
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -19,13 +25,27 @@ import (
 	"github.com/go-spatial/geocatalogo/config"
 	"github.com/go-spatial/geocatalogo/metadata"
 	"github.com/go-spatial/geocatalogo/search"
+	"github.com/go-spatial/geocatalogo/search/cql2"
+	"github.com/go-spatial/geocatalogo/search/fulltext"
 )
 
 // Memory provides an in-memory object model for repository
 type Memory struct {
 	Type    string
 	Records map[string]metadata.Record
-	log     *logrus.Logger
+	// Collections are stored next to the records file
+	// (<file>.collections)
+	Collections map[string]metadata.Collection
+	log         *logrus.Logger
+	index       *rtree
+	text        *fulltext.Index
+	lang        string
+	mutex       sync.RWMutex
+	path        string
+	persist     bool
+	strict      bool
+	journal     *journal
+	done        chan struct{}
 }
 
 // NewMemory creates an in-memory repository
@@ -38,62 +58,409 @@ func NewMemory(cfg config.Config, log *logrus.Logger) error {
 	return nil
 }
 
-// OpenMemory loads an in-memory repository
+// OpenMemory loads an in-memory repository.  When persistence is enabled,
+// changes are appended to a journal next to the records file
+// (<file>.journal) and periodically compacted into the records file
 func OpenMemory(cfg config.Config, log *logrus.Logger) (*Memory, error) {
 	log.Debug("Loading in-memory repository from " + cfg.Repository.URL)
 
 	m := &Memory{
-		Type:    cfg.Repository.Type,
-		Records: make(map[string]metadata.Record),
-		log:     log,
+		Type:        cfg.Repository.Type,
+		Records:     make(map[string]metadata.Record),
+		Collections: make(map[string]metadata.Collection),
+		log:         log,
+		lang:        cfg.Server.Language,
+		persist:     cfg.Repository.Persist,
+		strict:      cfg.Repository.Strict,
 	}
 
 	// Load records from JSON file if URL is provided
 	if cfg.Repository.URL != "" && cfg.Repository.URL != "memory://" {
 		// URL format: file:///path/to/records.json
-		filePath := strings.TrimPrefix(cfg.Repository.URL, "file://")
+		m.path = strings.TrimPrefix(cfg.Repository.URL, "file://")
 
-		data, err := ioutil.ReadFile(filePath)
+		records, collections, err := m.load()
 		if err != nil {
-			log.Warnf("Could not load records from %s: %v", filePath, err)
-			return m, nil // Return empty repository, not an error
+			return nil, err
 		}
+		m.Records = records
+		m.Collections = collections
+	}
+	m.reindex()
 
-		var records []metadata.Record
-		if err := json.Unmarshal(data, &records); err != nil {
-			return nil, fmt.Errorf("failed to parse records JSON: %v", err)
+	if m.persist {
+		if m.path == "" {
+			return nil, errors.New("persistence requires a file:// repository URL")
 		}
-
-		for _, record := range records {
-			m.Records[record.Identifier] = record
+		j, err := openJournal(m.path + ".journal")
+		if err != nil {
+			return nil, fmt.Errorf("cannot open journal: %v", err)
 		}
+		m.journal = j
 
-		log.Infof("Loaded %d records from %s", len(m.Records), filePath)
+		if cfg.Repository.SnapshotInterval > 0 {
+			m.done = make(chan struct{})
+			go m.snapshotLoop(cfg.Repository.SnapshotInterval)
+		}
 	}
 
 	return m, nil
 }
 
+// load reads records from the records file, replaying the journal
+// when persistence is enabled
+func (m *Memory) load() (map[string]metadata.Record, map[string]metadata.Collection, error) {
+	records := make(map[string]metadata.Record)
+	collections := make(map[string]metadata.Collection)
+
+	data, err := ioutil.ReadFile(m.path)
+	if err != nil {
+		if m.persist && os.IsNotExist(err) {
+			m.log.Infof("%s does not exist, starting with an empty repository", m.path)
+		} else if m.strict {
+			return nil, nil, fmt.Errorf("could not load records from %s: %v", m.path, err)
+		} else {
+			m.log.Warnf("Could not load records from %s: %v", m.path, err)
+			return records, collections, nil // Return empty repository, not an error
+		}
+	} else {
+		var list []metadata.Record
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse records JSON: %v", err)
+		}
+
+		for _, record := range list {
+			records[record.Identifier] = withBoundingBox(record)
+		}
+		m.log.Infof("Loaded %d records from %s", len(records), m.path)
+	}
+
+	data, err = ioutil.ReadFile(m.path + ".collections")
+	if err == nil {
+		var list []metadata.Collection
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse collections JSON: %v", err)
+		}
+		for _, collection := range list {
+			collections[collection.Identifier] = collection
+		}
+	} else if !os.IsNotExist(err) {
+		if m.strict {
+			return nil, nil, fmt.Errorf("could not load collections from %s.collections: %v", m.path, err)
+		}
+		m.log.Warnf("Could not load collections from %s.collections: %v", m.path, err)
+	}
+
+	if m.persist {
+		count, err := replayJournal(m.path+".journal", records, collections)
+		if err != nil {
+			if m.strict {
+				return nil, nil, fmt.Errorf("could not replay journal: %v", err)
+			}
+			m.log.Warnf("Could not fully replay journal: %v", err)
+		}
+		if count > 0 {
+			m.log.Infof("Replayed %d journal entries", count)
+		}
+	}
+
+	return records, collections, nil
+}
+
+// Reload discards the in-memory state and reloads records from the
+// records file (and journal).  On failure the current records are kept
+func (m *Memory) Reload() error {
+	if m.path == "" {
+		return nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	records, collections, err := m.load()
+	if err != nil {
+		return err
+	}
+	m.Records = records
+	m.Collections = collections
+	m.reindex()
+	m.log.Infof("Reloaded %d records from %s", len(records), m.path)
+	return nil
+}
+
+// Snapshot compacts the journal by writing all records to the records
+// file and truncating the journal
+func (m *Memory) Snapshot() error {
+	if m.journal == nil {
+		return nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := writeSnapshot(m.path, m.Records); err != nil {
+		return fmt.Errorf("cannot write snapshot: %v", err)
+	}
+	if err := writeCollections(m.path+".collections", m.Collections); err != nil {
+		return fmt.Errorf("cannot write collections: %v", err)
+	}
+	if err := m.journal.truncate(); err != nil {
+		return fmt.Errorf("cannot truncate journal: %v", err)
+	}
+	m.log.Debugf("Wrote snapshot of %d records to %s", len(m.Records), m.path)
+	return nil
+}
+
+func (m *Memory) snapshotLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.Snapshot(); err != nil {
+				m.log.Error(err)
+			}
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// Close writes a final snapshot and releases the journal
+func (m *Memory) Close() error {
+	if m.journal == nil {
+		return nil
+	}
+	if m.done != nil {
+		close(m.done)
+	}
+	if err := m.Snapshot(); err != nil {
+		return err
+	}
+	err := m.journal.close()
+	m.journal = nil
+	return err
+}
+
+// record appends changes to the journal when persistence is enabled
+func (m *Memory) record(entries ...journalEntry) error {
+	if m.journal == nil || len(entries) == 0 {
+		return nil
+	}
+	return m.journal.append(entries...)
+}
+
+// textBoosts weights matches in record fields relative to the abstract
+var textBoosts = map[string]float64{
+	"title":      2,
+	"keywords":   1.5,
+	"abstract":   1,
+	"contacts":   1,
+	"identifier": 1,
+}
+
+// textFields returns the full text searchable fields of a record
+func textFields(record metadata.Record) map[string]string {
+	var keywords []string
+	var contacts []string
+
+	for _, ks := range record.Properties.KeywordsSets {
+		keywords = append(keywords, ks.Keyword...)
+	}
+	for _, c := range record.Properties.Contacts {
+		contacts = append(contacts, c.Value)
+	}
+
+	return map[string]string{
+		"title":      record.Properties.Title,
+		"abstract":   record.Properties.Abstract,
+		"keywords":   strings.Join(keywords, "\n"),
+		"contacts":   strings.Join(contacts, "\n"),
+		"identifier": record.Identifier,
+	}
+}
+
+// reindex rebuilds the spatial and full text indexes from all records
+func (m *Memory) reindex() {
+	m.index = newRTree()
+	m.text = fulltext.NewIndex(m.lang, textBoosts)
+	for id, record := range m.Records {
+		m.indexInsert(id, record.BoundingBox)
+		m.text.Add(id, record.Properties.Language, textFields(record))
+	}
+}
+
+// put stores a record, keeping the indexes current
+func (m *Memory) put(record metadata.Record) {
+	record = withBoundingBox(record)
+	if existing, ok := m.Records[record.Identifier]; ok {
+		m.indexDelete(existing.Identifier, existing.BoundingBox)
+	}
+	m.Records[record.Identifier] = record
+	m.indexInsert(record.Identifier, record.BoundingBox)
+	m.text.Add(record.Identifier, record.Properties.Language, textFields(record))
+}
+
+// remove deletes a record, keeping the indexes current
+func (m *Memory) remove(id string) {
+	if existing, ok := m.Records[id]; ok {
+		m.indexDelete(id, existing.BoundingBox)
+		m.text.Remove(id)
+		delete(m.Records, id)
+	}
+}
+
+// withBoundingBox derives a missing bounding box from the geometry
+func withBoundingBox(record metadata.Record) metadata.Record {
+	if record.BoundingBox == ([4]float64{}) && !record.Geometry.IsEmpty() {
+		record.BoundingBox = record.Geometry.Bounds()
+	}
+	return record
+}
+
+// recordGeometry returns the geometry of a record, or the envelope of
+// its bounding box if it has none
+func recordGeometry(record metadata.Record) *metadata.Geometry {
+	if record.Geometry.IsEmpty() && record.BoundingBox != ([4]float64{}) {
+		envelope := metadata.NewEnvelope(record.BoundingBox)
+		return &envelope
+	}
+	return &record.Geometry
+}
+
+// indexInsert adds a bounding box to the spatial index.  Bounding boxes
+// crossing the antimeridian are indexed as their two parts
+func (m *Memory) indexInsert(id string, bbox [4]float64) {
+	for _, part := range metadata.SplitBBox(bbox) {
+		m.index.Insert(id, part)
+	}
+}
+
+// indexDelete removes a bounding box from the spatial index
+func (m *Memory) indexDelete(id string, bbox [4]float64) {
+	for _, part := range metadata.SplitBBox(bbox) {
+		m.index.Delete(id, part)
+	}
+}
+
 // Insert adds a record to the in-memory repository
 func (m *Memory) Insert(record metadata.Record) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
 	record.Properties.Geocatalogo.Inserted = time.Now()
-	m.Records[record.Identifier] = record
+	record = withBoundingBox(record)
+	m.put(record)
 	m.log.Debugf("Inserted record %s", record.Identifier)
-	return nil
+	return m.record(journalEntry{Op: journalPut, Record: &record})
 }
 
-// Update updates a record in the repository
-func (m *Memory) Update() bool {
-	return true
+// BulkInsert adds a batch of records to the in-memory repository
+func (m *Memory) BulkInsert(records []metadata.Record) (BulkResult, error) {
+	var result BulkResult
+	var entries []journalEntry
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	for i := range records {
+		record := records[i]
+		if record.Identifier == "" {
+			result.Errors = append(result.Errors, BulkItemError{Reason: "missing identifier"})
+			continue
+		}
+		record.Properties.Geocatalogo.Inserted = now
+		record = withBoundingBox(record)
+		m.put(record)
+		entries = append(entries, journalEntry{Op: journalPut, Record: &record})
+		result.Indexed++
+	}
+	return result, m.record(entries...)
 }
 
-// Delete deletes a record from the repository
-func (m *Memory) Delete() bool {
-	return true
+// Update replaces an existing record in the repository, preserving
+// its original insertion time
+func (m *Memory) Update(record metadata.Record) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	existing, ok := m.Records[record.Identifier]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, record.Identifier)
+	}
+	record.Properties.Geocatalogo.Inserted = existing.Properties.Geocatalogo.Inserted
+	record = withBoundingBox(record)
+	m.put(record)
+	m.log.Debugf("Updated record %s", record.Identifier)
+	return m.record(journalEntry{Op: journalPut, Record: &record})
+}
+
+// Delete deletes records by identifier from the repository
+func (m *Memory) Delete(identifiers []string) error {
+	var missing []string
+	var deleted []string
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, id := range identifiers {
+		if _, ok := m.Records[id]; !ok {
+			missing = append(missing, id)
+			continue
+		}
+		m.remove(id)
+		deleted = append(deleted, id)
+		m.log.Debugf("Deleted record %s", id)
+	}
+
+	if len(deleted) > 0 {
+		if err := m.record(journalEntry{Op: journalDelete, Identifiers: deleted}); err != nil {
+			return err
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, strings.Join(missing, ","))
+	}
+	return nil
+}
+
+// DeleteByQuery deletes all records matching any of the given collections
+// and/or sources from the repository, returning the number of deleted records
+func (m *Memory) DeleteByQuery(collections []string, sources []string) (int, error) {
+	var deleted []string
+
+	if len(collections) == 0 && len(sources) == 0 {
+		return 0, errors.New("one of collections or sources is required")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for id, record := range m.Records {
+		if len(collections) > 0 && !contains(collections, record.Collection()) {
+			continue
+		}
+		if len(sources) > 0 && !contains(sources, record.Properties.Geocatalogo.Source) {
+			continue
+		}
+		m.remove(id)
+		deleted = append(deleted, id)
+	}
+
+	m.log.Debugf("Deleted %d records by query", len(deleted))
+	if len(deleted) > 0 {
+		return len(deleted), m.record(journalEntry{Op: journalDelete, Identifiers: deleted})
+	}
+	return 0, nil
 }
 
 // Get retrieves records by identifier(s)
 func (m *Memory) Get(identifiers []string, sr *search.Results) error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
 	sr.Records = []metadata.Record{}
 
 	for _, id := range identifiers {
@@ -110,19 +477,74 @@ func (m *Memory) Get(identifiers []string, sr *search.Results) error {
 }
 
 // Query performs a search against the in-memory repository
-func (m *Memory) Query(collections []string, term string, bbox []float64, timeVal []time.Time, from int, size int, sr *search.Results) error {
+func (m *Memory) Query(ctx context.Context, q search.Query, sr *search.Results) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
 	sr.Records = []metadata.Record{}
 	matches := []metadata.Record{}
 
-	// Search through all records
-	for _, record := range m.Records {
+	// The query geometry: the intersects geometry, or the bounding box
+	// ([minx, miny, maxx, maxy])
+	var geometry *metadata.Geometry
+	if q.Intersects != nil && !q.Intersects.IsEmpty() {
+		geometry = q.Intersects
+	} else if len(q.BBox) == 4 {
+		envelope := metadata.NewEnvelope([4]float64{q.BBox[0], q.BBox[1], q.BBox[2], q.BBox[3]})
+		geometry = &envelope
+	}
+	relation, err := search.ParseRelation(q.Relation)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidQuery, err)
+	}
+
+	// Unless looking for disjoint records, only records whose bounding
+	// box intersects that of the query geometry can match
+	var bbox []float64
+	if geometry != nil && relation != search.RelationDisjoint {
+		b := geometry.Bounds()
+		bbox = b[:]
+	}
+
+	// Search through all records, or only those matching the full text
+	// search term (in order of relevance) and/or intersecting the bounding
+	// box according to the spatial index
+	var records []metadata.Record
+	if q.Term != "" {
+		records = m.textCandidates(q.Term, bbox)
+	} else {
+		records = m.candidates(bbox)
+	}
+
+	var filter cql2.Expr
+	if q.Filter != nil {
+		filter = cql2.Compile(q.Filter)
+	}
+
+	for i, record := range records {
+		// check for cancellation periodically on large scans
+		if i%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
 		match := true
 
+		// Spatial filter
+		if geometry != nil {
+			match = recordGeometry(record).Relate(relation, geometry)
+		}
+
 		// Collection filter
-		if len(collections) > 0 {
+		if len(q.Collections) > 0 && match {
 			collectionMatch := false
-			for _, coll := range collections {
-				if record.Properties.Collection == coll {
+			for _, coll := range q.Collections {
+				if record.Collection() == coll {
 					collectionMatch = true
 					break
 				}
@@ -132,47 +554,19 @@ func (m *Memory) Query(collections []string, term string, bbox []float64, timeVa
 			}
 		}
 
-		// Text search (searches in title and abstract)
-		if term != "" && match {
-			termLower := strings.ToLower(term)
-			titleMatch := strings.Contains(strings.ToLower(record.Properties.Title), termLower)
-			abstractMatch := strings.Contains(strings.ToLower(record.Properties.Abstract), termLower)
-			idMatch := strings.Contains(strings.ToLower(record.Identifier), termLower)
-
-			if !titleMatch && !abstractMatch && !idMatch {
-				match = false
-			}
-		}
-
-		// Bounding box filter (simple overlap check)
-		if len(bbox) == 4 && match {
-			// bbox format: [minx, miny, maxx, maxy]
-			recordBBox := record.BoundingBox
-
-			// Check if bounding boxes overlap
-			overlap := !(bbox[2] < recordBBox[0] || // query max_x < record min_x
-				bbox[0] > recordBBox[2] || // query min_x > record max_x
-				bbox[3] < recordBBox[1] || // query max_y < record min_y
-				bbox[1] > recordBBox[3])   // query min_y > record max_y
-
-			if !overlap {
-				match = false
-			}
-		}
-
 		// Time filter
-		if len(timeVal) > 0 && match {
+		if len(q.Time) > 0 && match {
 			if record.Properties.Datetime != nil {
 				// Check if record datetime falls within query time range
-				if len(timeVal) == 1 {
+				if len(q.Time) == 1 {
 					// Exact time match (or close enough - within a day)
-					diff := record.Properties.Datetime.Sub(timeVal[0]).Hours()
+					diff := record.Properties.Datetime.Sub(q.Time[0]).Hours()
 					if diff < -24 || diff > 24 {
 						match = false
 					}
-				} else if len(timeVal) == 2 {
+				} else if len(q.Time) == 2 {
 					// Time range
-					if record.Properties.Datetime.Before(timeVal[0]) || record.Properties.Datetime.After(timeVal[1]) {
+					if record.Properties.Datetime.Before(q.Time[0]) || record.Properties.Datetime.After(q.Time[1]) {
 						match = false
 					}
 				}
@@ -182,26 +576,42 @@ func (m *Memory) Query(collections []string, term string, bbox []float64, timeVa
 			}
 		}
 
+		// CQL2 filter
+		if filter != nil && match {
+			match = cql2.Evaluate(filter, &record)
+		}
+
 		if match {
 			matches = append(matches, record)
 		}
 	}
 
+	// Sorting: by the requested fields, otherwise by relevance for full
+	// text queries (matches are already ordered) or by identifier, so
+	// that paging is deterministic
+	if len(q.SortBy) > 0 {
+		sortRecords(matches, q.SortBy)
+	} else if q.Term == "" {
+		sort.Slice(matches, func(i, j int) bool {
+			return matches[i].Identifier < matches[j].Identifier
+		})
+	}
+
 	// Pagination
 	sr.Matches = len(matches)
 
-	if from >= len(matches) {
+	if q.From >= len(matches) {
 		sr.Returned = 0
 		sr.NextRecord = 0
 		return nil
 	}
 
-	end := from + size
+	end := q.From + q.Size
 	if end > len(matches) {
 		end = len(matches)
 	}
 
-	sr.Records = matches[from:end]
+	sr.Records = matches[q.From:end]
 	sr.Returned = len(sr.Records)
 
 	if end < len(matches) {
@@ -210,20 +620,207 @@ func (m *Memory) Query(collections []string, term string, bbox []float64, timeVa
 		sr.NextRecord = 0
 	}
 
-	m.log.Debugf("Query found %d matches, returning %d from offset %d", sr.Matches, sr.Returned, from)
+	m.log.Debugf("Query found %d matches, returning %d from offset %d", sr.Matches, sr.Returned, q.From)
 
 	return nil
 }
 
+// sortRecords orders records by sort fields, then by identifier.
+// Records missing a sort field, or with an empty one, are ordered last
+func sortRecords(records []metadata.Record, sortby []search.SortField) {
+	keys := make(map[string][]interface{}, len(records))
+	for i := range records {
+		values := make([]interface{}, len(sortby))
+		for k, sf := range sortby {
+			if v, ok := records[i].Value(sf.Field); ok && v != "" {
+				values[k] = v
+			}
+		}
+		keys[records[i].Identifier] = values
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		a := keys[records[i].Identifier]
+		b := keys[records[j].Identifier]
+		for k, sf := range sortby {
+			switch {
+			case a[k] == nil && b[k] == nil:
+				continue
+			case a[k] == nil:
+				return false
+			case b[k] == nil:
+				return true
+			}
+			c := metadata.CompareValues(a[k], b[k])
+			if c == 0 {
+				continue
+			}
+			if sf.Descending {
+				return c > 0
+			}
+			return c < 0
+		}
+		return records[i].Identifier < records[j].Identifier
+	})
+}
+
+// candidates returns the records to be considered by a query, using the
+// spatial index to prefilter by bounding box when one is given
+func (m *Memory) candidates(bbox []float64) []metadata.Record {
+	var records []metadata.Record
+
+	if len(bbox) != 4 {
+		records = make([]metadata.Record, 0, len(m.Records))
+		for _, record := range m.Records {
+			records = append(records, record)
+		}
+		return records
+	}
+
+	// records crossing the antimeridian, or a query bbox which does,
+	// may match more than once
+	seen := make(map[string]bool)
+	for _, part := range metadata.SplitBBox([4]float64{bbox[0], bbox[1], bbox[2], bbox[3]}) {
+		m.index.Search(part, func(id string) bool {
+			if !seen[id] {
+				seen[id] = true
+				records = append(records, m.Records[id])
+			}
+			return true
+		})
+	}
+	return records
+}
+
+// textCandidates returns the records matching a full text query ordered
+// by relevance, optionally restricted to those intersecting bbox
+func (m *Memory) textCandidates(term string, bbox []float64) []metadata.Record {
+	var records []metadata.Record
+
+	for _, hit := range m.text.Search(term) {
+		record := m.Records[hit.ID]
+		if len(bbox) == 4 && !intersectsBBox(record.BoundingBox, [4]float64{bbox[0], bbox[1], bbox[2], bbox[3]}) {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
 // DeleteAll removes all records (for testing)
 func (m *Memory) DeleteAll() error {
-	count := len(m.Records)
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	ids := make([]string, 0, len(m.Records))
+	for id := range m.Records {
+		ids = append(ids, id)
+	}
 	m.Records = make(map[string]metadata.Record)
-	m.log.Infof("Deleted all %d records", count)
+	m.reindex()
+	m.log.Infof("Deleted all %d records", len(ids))
+	if len(ids) > 0 {
+		return m.record(journalEntry{Op: journalDelete, Identifiers: ids})
+	}
 	return nil
 }
 
 // Count returns the number of records
 func (m *Memory) Count() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
 	return len(m.Records)
 }
+
+// contains reports whether value is in list
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// intersectsBBox reports whether two bounding boxes, either of which may
+// cross the antimeridian, intersect
+func intersectsBBox(a, b [4]float64) bool {
+	for _, pa := range metadata.SplitBBox(a) {
+		for _, pb := range metadata.SplitBBox(b) {
+			if intersects(pa, pb) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PutCollection adds or replaces a collection
+func (m *Memory) PutCollection(collection metadata.Collection) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.Collections[collection.Identifier] = collection
+	return m.record(journalEntry{Op: journalPutCollection, Collection: &collection})
+}
+
+// DeleteCollection removes a collection.  Records in the collection are
+// kept
+func (m *Memory) DeleteCollection(id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.Collections[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.Collections, id)
+	return m.record(journalEntry{Op: journalDeleteCollection, Identifiers: []string{id}})
+}
+
+// GetCollection retrieves a collection
+func (m *Memory) GetCollection(id string) (metadata.Collection, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	collection, ok := m.Collections[id]
+	if !ok {
+		return collection, ErrNotFound
+	}
+	return collection, nil
+}
+
+// ListCollections lists all collections, ordered by identifier
+func (m *Memory) ListCollections() ([]metadata.Collection, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	collections := make([]metadata.Collection, 0, len(m.Collections))
+	for _, collection := range m.Collections {
+		collections = append(collections, collection)
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		return collections[i].Identifier < collections[j].Identifier
+	})
+	return collections, nil
+}
+
+// CollectionExtent computes the extent of the records in a collection
+func (m *Memory) CollectionExtent(id string) (metadata.Extent, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var extent metadata.Extent
+	for _, record := range m.Records {
+		if record.Collection() == id {
+			extent.Include(&record)
+		}
+	}
+	return extent, nil
+}
+
+// SpatialOperators lists the CQL2 spatial operators supported in
+// filters: all of them
+func (m *Memory) SpatialOperators() []string {
+	return []string{"s_intersects", "s_within", "s_contains", "s_disjoint", "s_equals", "s_touches", "s_crosses", "s_overlaps"}
+}
@@ -0,0 +1,587 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-spatial/geocatalogo/config"
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/repository"
+	"github.com/go-spatial/geocatalogo/search"
+	"github.com/go-spatial/geocatalogo/search/cql2"
+)
+
+func quietLogger() *logrus.Logger {
+	log := logrus.New()
+	log.SetLevel(logrus.PanicLevel)
+	return log
+}
+
+func newTestMemory(t *testing.T, records ...metadata.Record) *repository.Memory {
+	t.Helper()
+	cfg := config.Config{}
+	cfg.Repository.Type = "memory"
+	m, err := repository.OpenMemory(cfg, quietLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records {
+		if err := m.Insert(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+func testRecord(id string, collection string, source string) metadata.Record {
+	r := metadata.Record{Identifier: id, Type: "Feature"}
+	r.Properties.Title = "Record " + id
+	r.Properties.Collection = collection
+	r.Properties.Geocatalogo.Source = source
+	return r
+}
+
+func TestMemoryUpdate(t *testing.T) {
+	m := newTestMemory(t, testRecord("a", "c1", "local"))
+	inserted := m.Records["a"].Properties.Geocatalogo.Inserted
+
+	r := testRecord("a", "c1", "local")
+	r.Properties.Title = "Corrected"
+	if err := m.Update(r); err != nil {
+		t.Fatal(err)
+	}
+	if m.Records["a"].Properties.Title != "Corrected" {
+		t.Errorf("expected updated title, got %q", m.Records["a"].Properties.Title)
+	}
+	if !m.Records["a"].Properties.Geocatalogo.Inserted.Equal(inserted) {
+		t.Error("expected insertion time to be preserved")
+	}
+
+	err := m.Update(testRecord("missing", "c1", "local"))
+	if !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMemoryDelete(t *testing.T) {
+	var sr search.Results
+	m := newTestMemory(t, testRecord("a", "c1", "local"), testRecord("b", "c1", "local"))
+
+	if err := m.Delete([]string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	m.Get([]string{"a", "b"}, &sr)
+	if sr.Matches != 1 || sr.Records[0].Identifier != "b" {
+		t.Errorf("expected only b to remain, got %v", sr.Records)
+	}
+
+	err := m.Delete([]string{"a", "b"})
+	if !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if m.Count() != 0 {
+		t.Errorf("expected b to be deleted, %d records remain", m.Count())
+	}
+}
+
+func TestMemoryDeleteByQuery(t *testing.T) {
+	m := newTestMemory(t,
+		testRecord("a", "c1", "local"),
+		testRecord("b", "c1", "harvest"),
+		testRecord("c", "c2", "local"),
+	)
+
+	if _, err := m.DeleteByQuery(nil, nil); err == nil {
+		t.Error("expected error for empty query")
+	}
+
+	count, err := m.DeleteByQuery([]string{"c1"}, []string{"local"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || m.Count() != 2 {
+		t.Errorf("expected 1 deletion and 2 remaining, got %d and %d", count, m.Count())
+	}
+
+	count, _ = m.DeleteByQuery(nil, []string{"local", "harvest"})
+	if count != 2 || m.Count() != 0 {
+		t.Errorf("expected 2 deletions and 0 remaining, got %d and %d", count, m.Count())
+	}
+}
+
+func TestMemoryProductInfoCollection(t *testing.T) {
+	scene := testRecord("scene", "", "local")
+	scene.Properties.ProductInfo = &metadata.ProductInfo{Collection: "landsat-8"}
+	scene.Geometry = metadata.NewEnvelope([4]float64{-75, 45, -74, 46})
+	override := testRecord("override", "c1", "local")
+	override.Properties.ProductInfo = &metadata.ProductInfo{Collection: "landsat-8"}
+	m := newTestMemory(t, scene, override, testRecord("a", "c1", "local"))
+
+	var sr search.Results
+	m.Query(context.Background(), search.Query{Collections: []string{"landsat-8"}, Size: 10}, &sr)
+	if sr.Matches != 1 || sr.Records[0].Identifier != "scene" {
+		t.Errorf("expected scene in landsat-8, got %+v", sr.Records)
+	}
+	if extent, _ := m.CollectionExtent("landsat-8"); len(extent.Spatial.BBox) != 1 {
+		t.Errorf("expected the extent of scene, got %+v", extent)
+	}
+
+	count, err := m.DeleteByQuery([]string{"landsat-8"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || m.Count() != 2 {
+		t.Errorf("expected 1 deletion and 2 remaining, got %d and %d", count, m.Count())
+	}
+}
+
+func TestMemoryBulkInsert(t *testing.T) {
+	m := newTestMemory(t)
+
+	result, err := m.BulkInsert([]metadata.Record{
+		testRecord("a", "c1", "local"),
+		testRecord("", "c1", "local"),
+		testRecord("b", "c1", "local"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Indexed != 2 || len(result.Errors) != 1 {
+		t.Errorf("expected 2 indexed and 1 error, got %d and %d", result.Indexed, len(result.Errors))
+	}
+	if m.Count() != 2 {
+		t.Errorf("expected 2 records, got %d", m.Count())
+	}
+}
+
+func openTestMemory(t *testing.T, path string, persist bool, strict bool) (*repository.Memory, error) {
+	t.Helper()
+	cfg := config.Config{}
+	cfg.Repository.Type = "memory"
+	cfg.Repository.URL = "file://" + path
+	cfg.Repository.Persist = persist
+	cfg.Repository.Strict = strict
+	return repository.OpenMemory(cfg, quietLogger())
+}
+
+func TestMemoryPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.json")
+
+	m, err := openTestMemory(t, path, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Insert(testRecord("a", "c1", "local"))
+	m.Insert(testRecord("b", "c1", "local"))
+	m.Delete([]string{"a"})
+
+	// journal is replayed without a snapshot having been written
+	m2, err := openTestMemory(t, path, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m2.Count() != 1 || m2.Records["b"].Identifier != "b" {
+		t.Errorf("expected journal replay to yield record b, got %v", m2.Records)
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	journal, err := ioutil.ReadFile(path + ".journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(journal) != 0 {
+		t.Errorf("expected journal to be compacted, got %q", journal)
+	}
+
+	var records []metadata.Record
+	data, _ := ioutil.ReadFile(path)
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Identifier != "b" {
+		t.Errorf("expected snapshot to contain record b, got %v", records)
+	}
+}
+
+func TestMemoryPersistenceBoundingBox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.json")
+	geometry := func(id string, x float64) metadata.Record {
+		r := testRecord(id, "c1", "local")
+		r.Geometry = metadata.NewPoint(x, 45)
+		return r
+	}
+
+	m, err := openTestMemory(t, path, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Insert(geometry("a", -75))
+	m.BulkInsert([]metadata.Record{geometry("b", -74)})
+	m.Insert(testRecord("c", "c1", "local"))
+	m.Update(geometry("c", -73))
+
+	query := func(m *repository.Memory) int {
+		var sr search.Results
+		m.Query(context.Background(), search.Query{BBox: []float64{-76, 44, -72, 46}, Size: 10}, &sr)
+		return sr.Matches
+	}
+	if n := query(m); n != 3 {
+		t.Fatalf("expected 3 matches before restart, got %d", n)
+	}
+
+	// bounding boxes derived from geometries survive journal replay, and
+	// the snapshot written on close
+	for _, restart := range []string{"journal", "snapshot"} {
+		m2, err := openTestMemory(t, path, true, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := query(m2); n != 3 {
+			t.Errorf("%s: expected 3 matches after restart, got %d", restart, n)
+		}
+		if b := m2.Records["a"].BoundingBox; b != [4]float64{-75, 45, -75, 45} {
+			t.Errorf("%s: unexpected bounding box %v", restart, b)
+		}
+		if err := m.Close(); err != nil {
+			t.Fatal(err)
+		}
+		m = m2
+	}
+	m.Close()
+}
+
+func TestMemoryStrict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.json")
+
+	if _, err := openTestMemory(t, path, false, true); err == nil {
+		t.Error("expected error loading missing file in strict mode")
+	}
+	if _, err := openTestMemory(t, path, false, false); err != nil {
+		t.Errorf("expected missing file to be tolerated, got %v", err)
+	}
+	if _, err := openTestMemory(t, path, true, true); err != nil {
+		t.Errorf("expected missing file to be created when persisting, got %v", err)
+	}
+}
+
+func TestMemoryReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.json")
+	ioutil.WriteFile(path, []byte(`[{"id": "a"}]`), 0644)
+
+	m, err := openTestMemory(t, path, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ioutil.WriteFile(path, []byte(`[{"id": "b"}, {"id": "c"}]`), 0644)
+	if err := m.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Count() != 2 {
+		t.Errorf("expected 2 records after reload, got %d", m.Count())
+	}
+
+	ioutil.WriteFile(path, []byte(`not json`), 0644)
+	if err := m.Reload(); err == nil {
+		t.Error("expected error reloading invalid file")
+	}
+	if m.Count() != 2 {
+		t.Errorf("expected records to be kept after failed reload, got %d", m.Count())
+	}
+}
+
+func TestMemoryFullTextQuery(t *testing.T) {
+	var sr search.Results
+
+	a := testRecord("a", "c1", "local")
+	a.Properties.Title = "Flood zones"
+	a.Properties.Abstract = "Flood hazard areas in California"
+	b := testRecord("b", "c1", "local")
+	b.Properties.Title = "California wildfire perimeters"
+	c := testRecord("c", "c2", "local")
+	c.Properties.Title = "Global wildfires"
+	c.Properties.Contacts = []metadata.Contact{{Value: "California Fire Service"}}
+	m := newTestMemory(t, a, b, c)
+
+	m.Query(context.Background(), search.Query{Term: "wildfire california", Size: 10}, &sr)
+	if sr.Matches != 3 || sr.Records[0].Identifier != "b" {
+		t.Errorf("expected 3 matches with b first, got %v", sr.Records)
+	}
+
+	m.Query(context.Background(), search.Query{Collections: []string{"c2"}, Term: "california", Size: 10}, &sr)
+	if sr.Matches != 1 || sr.Records[0].Identifier != "c" {
+		t.Errorf("expected contact match on c, got %v", sr.Records)
+	}
+}
+
+func TestMemorySortAndPaging(t *testing.T) {
+	var records []metadata.Record
+	for i, id := range []string{"d", "b", "e", "a", "c"} {
+		r := testRecord(id, "c1", "local")
+		datetime := time.Date(2019, 1, i%3+1, 0, 0, 0, 0, time.UTC)
+		r.Properties.Datetime = &datetime
+		records = append(records, r)
+	}
+	records = append(records, testRecord("f", "c1", "local")) // no datetime
+	m := newTestMemory(t, records...)
+
+	sortby, _ := search.ParseSortBy("-properties.datetime,id")
+	var got []string
+	for from := 0; from < 6; from += 2 {
+		var sr search.Results
+		m.Query(context.Background(), search.Query{SortBy: sortby, From: from, Size: 2}, &sr)
+		for _, r := range sr.Records {
+			got = append(got, r.Identifier)
+		}
+	}
+	expected := []string{"e", "b", "c", "a", "d", "f"}
+	if strings.Join(got, "") != strings.Join(expected, "") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	// without sort fields, paging is ordered by identifier
+	var sr search.Results
+	m.Query(context.Background(), search.Query{From: 1, Size: 2}, &sr)
+	if sr.Records[0].Identifier != "b" || sr.Records[1].Identifier != "c" {
+		t.Errorf("expected b, c, got %v", sr.Records)
+	}
+
+	// empty values are missing, and ordered last
+	untitled := testRecord("0", "c1", "local")
+	untitled.Properties.Title = ""
+	m = newTestMemory(t, untitled, testRecord("y", "c1", "local"), testRecord("x", "c1", "local"))
+	for _, sortby := range []string{"title", "-title"} {
+		fields, _ := search.ParseSortBy(sortby)
+		var sr search.Results
+		m.Query(context.Background(), search.Query{SortBy: fields, Size: 3}, &sr)
+		if last := sr.Records[2].Identifier; last != "0" {
+			t.Errorf("%s: expected untitled record last, got %s", sortby, last)
+		}
+	}
+}
+
+func TestMemoryFilter(t *testing.T) {
+	a := testRecord("a", "c1", "local")
+	a.BoundingBox = [4]float64{0, 0, 10, 10}
+	a.Properties.ProductInfo = &metadata.ProductInfo{CloudCover: 5}
+	b := testRecord("b", "c1", "local")
+	b.BoundingBox = [4]float64{20, 20, 30, 30}
+	b.Properties.ProductInfo = &metadata.ProductInfo{CloudCover: 50}
+	c := testRecord("c", "c2", "local")
+	c.BoundingBox = [4]float64{5, 5, 25, 25}
+	m := newTestMemory(t, a, b, c)
+
+	cases := map[string]string{
+		`eo:cloud_cover < 10`:                                   "a",
+		`eo:cloud_cover IS NULL`:                                "c",
+		`S_INTERSECTS(geometry, BBOX(8, 8, 21, 21))`:            "abc",
+		`S_WITHIN(geometry, BBOX(-1, -1, 26, 26)) AND id > 'a'`: "c",
+		`collection = 'c1' AND NOT title LIKE '%a'`:             "b",
+	}
+	for text, expected := range cases {
+		filter, err := cql2.ParseText(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sr search.Results
+		m.Query(context.Background(), search.Query{Filter: filter, Size: 10}, &sr)
+		var got string
+		for _, r := range sr.Records {
+			got += r.Identifier
+		}
+		if got != expected {
+			t.Errorf("%s: expected %s, got %s", text, expected, got)
+		}
+	}
+}
+
+func TestMemoryQueryCancelled(t *testing.T) {
+	m := newTestMemory(t, testRecord("a", "c1", "local"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var sr search.Results
+	if err := m.Query(ctx, search.Query{Size: 10}, &sr); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestMemoryGeometries(t *testing.T) {
+	point := testRecord("point", "c1", "local")
+	point.Geometry = metadata.NewPoint(-75.7, 45.4)
+	fiji := testRecord("fiji", "c1", "local")
+	fiji.Geometry = metadata.NewPolygon([]metadata.Position{{176, -20}, {-178, -20}, {-178, -15}, {176, -15}, {176, -20}})
+	m := newTestMemory(t, point, fiji)
+
+	if b := m.Records["fiji"].BoundingBox; b != [4]float64{176, -20, -178, -15} {
+		t.Errorf("expected bounding box derived from geometry, got %v", b)
+	}
+
+	cases := map[string][]float64{
+		"point": {-76, 45, -75, 46},
+		"fiji":  {-179, -18, -170, -16},
+	}
+	for expected, bbox := range cases {
+		var sr search.Results
+		m.Query(context.Background(), search.Query{BBox: bbox, Size: 10}, &sr)
+		if sr.Matches != 1 || sr.Records[0].Identifier != expected {
+			t.Errorf("%v: expected %s, got %v", bbox, expected, sr.Records)
+		}
+	}
+
+	// a query bbox crossing the antimeridian
+	var sr search.Results
+	m.Query(context.Background(), search.Query{BBox: []float64{170, -30, -179, 0}, Size: 10}, &sr)
+	if sr.Matches != 1 || sr.Records[0].Identifier != "fiji" {
+		t.Errorf("expected fiji, got %v", sr.Records)
+	}
+
+	// query bboxes inside the footprint, either side of the antimeridian,
+	// and around it
+	relations := []struct {
+		bbox     []float64
+		relation string
+	}{
+		{[]float64{177, -18, 178, -16}, search.RelationIntersects},
+		{[]float64{-179.5, -18, -178.5, -16}, search.RelationIntersects},
+		{[]float64{177, -18, 178, -16}, search.RelationContains},
+		{[]float64{170, -30, -170, 0}, search.RelationWithin},
+	}
+	for _, r := range relations {
+		var sr search.Results
+		m.Query(context.Background(), search.Query{BBox: r.bbox, Relation: r.relation, Size: 10}, &sr)
+		if sr.Matches != 1 || sr.Records[0].Identifier != "fiji" {
+			t.Errorf("%s %v: expected fiji, got %v", r.relation, r.bbox, sr.Records)
+		}
+	}
+}
+
+func TestMemoryIntersects(t *testing.T) {
+	point := testRecord("point", "c1", "local")
+	point.Geometry = metadata.NewPoint(8, 8)
+	lshape := testRecord("lshape", "c1", "local")
+	lshape.Geometry = metadata.NewPolygon([]metadata.Position{{0, 0}, {10, 0}, {10, 2}, {2, 2}, {2, 10}, {0, 10}, {0, 0}})
+	far := testRecord("far", "c1", "local")
+	far.Geometry = metadata.NewPoint(50, 50)
+	m := newTestMemory(t, point, lshape, far)
+
+	// a triangle covering the point and the inner corner of the L, but
+	// none of the L itself
+	triangle := metadata.NewPolygon([]metadata.Position{{5, 5}, {9, 5}, {9, 9}, {5, 5}})
+	cases := map[string]string{
+		search.RelationIntersects: "point",
+		search.RelationWithin:     "point",
+		search.RelationContains:   "",
+		search.RelationDisjoint:   "farlshape",
+	}
+	for relation, expected := range cases {
+		var sr search.Results
+		if err := m.Query(context.Background(), search.Query{Intersects: &triangle, Relation: relation, Size: 10}, &sr); err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		for _, r := range sr.Records {
+			got += r.Identifier
+		}
+		if got != expected {
+			t.Errorf("%s: expected %q, got %q", relation, expected, got)
+		}
+	}
+
+	var sr search.Results
+	if err := m.Query(context.Background(), search.Query{Intersects: &triangle, Relation: "touches"}, &sr); !errors.Is(err, repository.ErrInvalidQuery) {
+		t.Errorf("expected ErrInvalidQuery for an unsupported relation, got %v", err)
+	}
+}
+
+func TestMemoryCollections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.json")
+
+	m, err := openTestMemory(t, path, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.PutCollection(metadata.Collection{Identifier: "c2", Title: "Second"})
+	m.PutCollection(metadata.Collection{Identifier: "c1", Title: "First"})
+	m.PutCollection(metadata.Collection{Identifier: "c1", Title: "Replaced"})
+
+	collections, err := m.ListCollections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collections) != 2 || collections[0].Identifier != "c1" || collections[0].Title != "Replaced" {
+		t.Errorf("expected collections c1 (replaced), c2, got %v", collections)
+	}
+
+	if err := m.DeleteCollection("c2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.GetCollection("c2"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for deleted collection, got %v", err)
+	}
+	if err := m.DeleteCollection("c2"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting missing collection, got %v", err)
+	}
+
+	// collections are replayed from the journal and kept in the snapshot
+	m2, err := openTestMemory(t, path, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, err := m2.GetCollection("c1"); err != nil || c.Title != "Replaced" {
+		t.Errorf("expected journal replay to yield collection c1, got %v (%v)", c, err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	m3, err := openTestMemory(t, path, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collections, _ := m3.ListCollections(); len(collections) != 1 {
+		t.Errorf("expected snapshot to contain 1 collection, got %v", collections)
+	}
+}
+
+func TestMemoryCollectionExtent(t *testing.T) {
+	t1 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	a := testRecord("a", "c1", "local")
+	a.Geometry = metadata.NewEnvelope([4]float64{0, 0, 10, 10})
+	a.Properties.Datetime = &t1
+	b := testRecord("b", "c1", "local")
+	b.Geometry = metadata.NewPoint(20, -5)
+	b.Properties.Datetime = &t2
+	c := testRecord("c", "c2", "local")
+	c.Geometry = metadata.NewPoint(100, 50)
+
+	m := newTestMemory(t, a, b, c)
+
+	extent, err := m.CollectionExtent("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(extent.Spatial.BBox) != 1 || extent.Spatial.BBox[0] != [4]float64{0, -5, 20, 10} {
+		t.Errorf("expected bbox [0 -5 20 10], got %v", extent.Spatial.BBox)
+	}
+	if len(extent.Temporal.Interval) != 1 || !extent.Temporal.Interval[0][0].Equal(t1) || !extent.Temporal.Interval[0][1].Equal(t2) {
+		t.Errorf("expected interval %s/%s, got %v", t1, t2, extent.Temporal.Interval)
+	}
+
+	extent, _ = m.CollectionExtent("missing")
+	if !extent.IsEmpty() {
+		t.Errorf("expected empty extent, got %v", extent)
+	}
+}
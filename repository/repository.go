@@ -7,17 +7,67 @@
 package repository
 
 import (
-	"time"
+	"context"
+	"errors"
 
 	"github.com/go-spatial/geocatalogo/metadata"
 	"github.com/go-spatial/geocatalogo/search"
 )
 
+// ErrNotFound is returned when an operation targets records which do
+// not exist in the repository
+var ErrNotFound = errors.New("record not found")
+
+// ErrInvalidQuery is returned when a query cannot be run by the
+// repository, e.g. a filter using an unsupported operator
+var ErrInvalidQuery = errors.New("invalid query")
+
+// DefaultBatchSize is the number of records sent per bulk request when
+// not set in configuration
+const DefaultBatchSize = 500
+
+// BulkItemError describes a record which could not be inserted
+// as part of a bulk request
+type BulkItemError struct {
+	Identifier string `json:"id"`
+	Reason     string `json:"reason"`
+}
+
+// BulkResult summarizes the outcome of a bulk request
+type BulkResult struct {
+	Indexed int             `json:"indexed"`
+	Errors  []BulkItemError `json:"errors,omitempty"`
+}
+
+// Add merges another BulkResult into b
+func (b *BulkResult) Add(other BulkResult) {
+	b.Indexed += other.Indexed
+	b.Errors = append(b.Errors, other.Errors...)
+}
+
 // Repository defines the interface that all backend implementations must satisfy
 type Repository interface {
 	Insert(record metadata.Record) error
-	Update() bool
-	Delete() bool
-	Query(collections []string, term string, bbox []float64, timeVal []time.Time, from int, size int, sr *search.Results) error
+	BulkInsert(records []metadata.Record) (BulkResult, error)
+	Update(record metadata.Record) error
+	Delete(identifiers []string) error
+	DeleteByQuery(collections []string, sources []string) (int, error)
+	Query(ctx context.Context, q search.Query, sr *search.Results) error
 	Get(identifiers []string, sr *search.Results) error
+
+	PutCollection(collection metadata.Collection) error
+	DeleteCollection(id string) error
+	GetCollection(id string) (metadata.Collection, error)
+	ListCollections() ([]metadata.Collection, error)
+	// CollectionExtent computes the extent of the records in a collection
+	CollectionExtent(id string) (metadata.Extent, error)
+	// SpatialOperators lists the CQL2 spatial operators (e.g.
+	// s_intersects) supported in filters
+	SpatialOperators() []string
+}
+
+// Reloader is implemented by repositories which can reload their
+// contents from their backing store
+type Reloader interface {
+	Reload() error
 }
@@ -0,0 +1,286 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// R-tree spatial index for the in-memory repository backend
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package repository
+
+import (
+	"math"
+)
+
+const (
+	rtreeMaxEntries = 16
+	rtreeMinEntries = 6
+)
+
+// rtreeEntry is either a leaf entry referencing a record identifier,
+// or a branch entry referencing a child node
+type rtreeEntry struct {
+	bbox  [4]float64
+	id    string
+	child *rtreeNode
+}
+
+type rtreeNode struct {
+	entries []rtreeEntry
+}
+
+// rtreeOrphan is an entry removed from an underfull node, pending
+// reinsertion at its original level
+type rtreeOrphan struct {
+	entry rtreeEntry
+	level int
+}
+
+// rtree provides a Guttman R-tree with quadratic splits over
+// minx,miny,maxx,maxy bounding boxes keyed by record identifier
+type rtree struct {
+	root   *rtreeNode
+	height int
+	size   int
+}
+
+func newRTree() *rtree {
+	return &rtree{root: &rtreeNode{}}
+}
+
+// Len returns the number of indexed identifiers
+func (t *rtree) Len() int {
+	return t.size
+}
+
+// Insert adds an identifier with its bounding box to the index
+func (t *rtree) Insert(id string, bbox [4]float64) {
+	t.insertAt(rtreeEntry{bbox: bbox, id: id}, 0)
+	t.size++
+}
+
+// Delete removes an identifier with its bounding box from the index,
+// reporting whether it was found
+func (t *rtree) Delete(id string, bbox [4]float64) bool {
+	var orphans []rtreeOrphan
+
+	if !t.remove(t.root, t.height, id, bbox, &orphans) {
+		return false
+	}
+	t.size--
+
+	for _, o := range orphans {
+		t.insertAt(o.entry, o.level)
+	}
+	for t.height > 0 && len(t.root.entries) == 1 {
+		t.root = t.root.entries[0].child
+		t.height--
+	}
+	return true
+}
+
+// Search calls fn for each identifier whose bounding box intersects
+// bbox, stopping early if fn returns false
+func (t *rtree) Search(bbox [4]float64, fn func(id string) bool) {
+	t.search(t.root, t.height, bbox, fn)
+}
+
+func (t *rtree) search(n *rtreeNode, level int, bbox [4]float64, fn func(id string) bool) bool {
+	for _, e := range n.entries {
+		if !intersects(e.bbox, bbox) {
+			continue
+		}
+		if level == 0 {
+			if !fn(e.id) {
+				return false
+			}
+		} else if !t.search(e.child, level-1, bbox, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *rtree) insertAt(e rtreeEntry, level int) {
+	split := t.insert(t.root, t.height, e, level)
+	if split != nil {
+		t.root = &rtreeNode{entries: []rtreeEntry{
+			{bbox: t.root.bounds(), child: t.root},
+			{bbox: split.bounds(), child: split},
+		}}
+		t.height++
+	}
+}
+
+// insert adds e to the subtree rooted at n, returning the new sibling
+// of n if n had to be split
+func (t *rtree) insert(n *rtreeNode, nodeLevel int, e rtreeEntry, level int) *rtreeNode {
+	if nodeLevel == level {
+		n.entries = append(n.entries, e)
+	} else {
+		i := n.chooseSubtree(e.bbox)
+		child := n.entries[i].child
+		split := t.insert(child, nodeLevel-1, e, level)
+		n.entries[i].bbox = child.bounds()
+		if split != nil {
+			n.entries = append(n.entries, rtreeEntry{bbox: split.bounds(), child: split})
+		}
+	}
+	if len(n.entries) > rtreeMaxEntries {
+		return n.split()
+	}
+	return nil
+}
+
+// remove deletes id from the subtree rooted at n, collecting the entries
+// of nodes which become underfull for reinsertion
+func (t *rtree) remove(n *rtreeNode, nodeLevel int, id string, bbox [4]float64, orphans *[]rtreeOrphan) bool {
+	if nodeLevel == 0 {
+		for i, e := range n.entries {
+			if e.id == id {
+				n.entries = append(n.entries[:i], n.entries[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+	for i, e := range n.entries {
+		if !intersects(e.bbox, bbox) {
+			continue
+		}
+		child := e.child
+		if !t.remove(child, nodeLevel-1, id, bbox, orphans) {
+			continue
+		}
+		if len(child.entries) < rtreeMinEntries {
+			n.entries = append(n.entries[:i], n.entries[i+1:]...)
+			for _, ce := range child.entries {
+				*orphans = append(*orphans, rtreeOrphan{entry: ce, level: nodeLevel - 1})
+			}
+		} else {
+			n.entries[i].bbox = child.bounds()
+		}
+		return true
+	}
+	return false
+}
+
+// chooseSubtree returns the entry needing least enlargement to include
+// bbox, resolving ties by smallest area
+func (n *rtreeNode) chooseSubtree(bbox [4]float64) int {
+	best := 0
+	bestEnlargement := math.Inf(1)
+	bestArea := math.Inf(1)
+
+	for i, e := range n.entries {
+		a := area(e.bbox)
+		enlargement := area(union(e.bbox, bbox)) - a
+		if enlargement < bestEnlargement || (enlargement == bestEnlargement && a < bestArea) {
+			best = i
+			bestEnlargement = enlargement
+			bestArea = a
+		}
+	}
+	return best
+}
+
+// split divides the entries of n between n and a new sibling using
+// Guttman's quadratic split
+func (n *rtreeNode) split() *rtreeNode {
+	entries := n.entries
+
+	// pick the pair of seeds wasting the most area
+	seed1, seed2 := 0, 1
+	worst := math.Inf(-1)
+	for i := 0; i < len(entries); i++ {
+		for j := i + 1; j < len(entries); j++ {
+			d := area(union(entries[i].bbox, entries[j].bbox)) - area(entries[i].bbox) - area(entries[j].bbox)
+			if d > worst {
+				worst = d
+				seed1, seed2 = i, j
+			}
+		}
+	}
+
+	group1 := []rtreeEntry{entries[seed1]}
+	group2 := []rtreeEntry{entries[seed2]}
+	bbox1 := entries[seed1].bbox
+	bbox2 := entries[seed2].bbox
+
+	remaining := make([]rtreeEntry, 0, len(entries)-2)
+	for i, e := range entries {
+		if i != seed1 && i != seed2 {
+			remaining = append(remaining, e)
+		}
+	}
+
+	for len(remaining) > 0 {
+		if len(group1)+len(remaining) == rtreeMinEntries {
+			group1 = append(group1, remaining...)
+			break
+		}
+		if len(group2)+len(remaining) == rtreeMinEntries {
+			group2 = append(group2, remaining...)
+			break
+		}
+
+		// pick the entry with the greatest preference for one group
+		next := 0
+		maxDiff := math.Inf(-1)
+		var d1, d2 float64
+		for i, e := range remaining {
+			e1 := area(union(bbox1, e.bbox)) - area(bbox1)
+			e2 := area(union(bbox2, e.bbox)) - area(bbox2)
+			if diff := math.Abs(e1 - e2); diff > maxDiff {
+				maxDiff = diff
+				next = i
+				d1, d2 = e1, e2
+			}
+		}
+		e := remaining[next]
+		remaining = append(remaining[:next], remaining[next+1:]...)
+
+		toFirst := d1 < d2 ||
+			(d1 == d2 && area(bbox1) < area(bbox2)) ||
+			(d1 == d2 && area(bbox1) == area(bbox2) && len(group1) <= len(group2))
+		if toFirst {
+			group1 = append(group1, e)
+			bbox1 = union(bbox1, e.bbox)
+		} else {
+			group2 = append(group2, e)
+			bbox2 = union(bbox2, e.bbox)
+		}
+	}
+
+	n.entries = group1
+	return &rtreeNode{entries: group2}
+}
+
+// bounds returns the bounding box of all entries of n
+func (n *rtreeNode) bounds() [4]float64 {
+	if len(n.entries) == 0 {
+		return [4]float64{}
+	}
+	b := n.entries[0].bbox
+	for _, e := range n.entries[1:] {
+		b = union(b, e.bbox)
+	}
+	return b
+}
+
+// intersects reports whether two bounding boxes overlap (touching
+// boxes are considered to overlap)
+func intersects(a, b [4]float64) bool {
+	return !(b[2] < a[0] || b[0] > a[2] || b[3] < a[1] || b[1] > a[3])
+}
+
+func union(a, b [4]float64) [4]float64 {
+	return [4]float64{
+		math.Min(a[0], b[0]),
+		math.Min(a[1], b[1]),
+		math.Max(a[2], b[2]),
+		math.Max(a[3], b[3]),
+	}
+}
+
+func area(b [4]float64) float64 {
+	return (b[2] - b[0]) * (b[3] - b[1])
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/search"
+)
+
+func testLogger() *logrus.Logger {
+	log := logrus.New()
+	log.SetLevel(logrus.PanicLevel)
+	return log
+}
+
+// randomBBox returns a bounding box of up to size degrees within the world
+func randomBBox(r *rand.Rand, size float64) [4]float64 {
+	minx := r.Float64()*(360-size) - 180
+	miny := r.Float64()*(180-size) - 90
+	return [4]float64{minx, miny, minx + r.Float64()*size, miny + r.Float64()*size}
+}
+
+func linearSearch(boxes map[string][4]float64, bbox [4]float64) []string {
+	var ids []string
+	for id, b := range boxes {
+		if intersects(b, bbox) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func treeSearch(t *rtree, bbox [4]float64) []string {
+	var ids []string
+	t.Search(bbox, func(id string) bool {
+		ids = append(ids, id)
+		return true
+	})
+	sort.Strings(ids)
+	return ids
+}
+
+func TestRTreeMatchesLinearScan(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tree := newRTree()
+	boxes := make(map[string][4]float64)
+
+	for i := 0; i < 2000; i++ {
+		id := fmt.Sprintf("r%d", i)
+		boxes[id] = randomBBox(r, 10)
+		tree.Insert(id, boxes[id])
+	}
+	for i := 0; i < 2000; i += 3 {
+		id := fmt.Sprintf("r%d", i)
+		if !tree.Delete(id, boxes[id]) {
+			t.Fatalf("could not delete %s", id)
+		}
+		delete(boxes, id)
+	}
+	if tree.Delete("r0", [4]float64{0, 0, 0, 0}) {
+		t.Error("expected deleting a missing identifier to fail")
+	}
+	if tree.Len() != len(boxes) {
+		t.Fatalf("expected %d entries, got %d", len(boxes), tree.Len())
+	}
+
+	for i := 0; i < 200; i++ {
+		q := randomBBox(r, 40)
+		expected := linearSearch(boxes, q)
+		got := treeSearch(tree, q)
+		if fmt.Sprint(expected) != fmt.Sprint(got) {
+			t.Fatalf("query %v: expected %d results, got %d", q, len(expected), len(got))
+		}
+	}
+}
+
+func TestMemoryQueryUsesIndex(t *testing.T) {
+	var sr search.Results
+	m := &Memory{Records: make(map[string]metadata.Record), log: testLogger()}
+	m.reindex()
+
+	for i, b := range [][4]float64{{0, 0, 10, 10}, {20, 20, 30, 30}, {-180, -90, 180, 90}} {
+		record := metadata.Record{Identifier: fmt.Sprintf("r%d", i), BoundingBox: b}
+		m.put(record)
+	}
+	// replacing a record must move it in the index
+	m.put(metadata.Record{Identifier: "r0", BoundingBox: [4]float64{40, 40, 50, 50}})
+
+	m.Query(context.Background(), search.Query{BBox: []float64{1, 1, 2, 2}, Size: 10}, &sr)
+	if sr.Matches != 1 || sr.Records[0].Identifier != "r2" {
+		t.Errorf("expected only r2 to match, got %v", sr.Records)
+	}
+}
+
+func benchmarkRecords(n int) *Memory {
+	r := rand.New(rand.NewSource(1))
+	m := &Memory{Records: make(map[string]metadata.Record), log: testLogger()}
+	m.reindex()
+	for i := 0; i < n; i++ {
+		m.put(metadata.Record{Identifier: fmt.Sprintf("r%d", i), BoundingBox: randomBBox(r, 2)})
+	}
+	return m
+}
+
+func BenchmarkBBoxLinearScan(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000} {
+		m := benchmarkRecords(n)
+		q := [4]float64{-10, -10, 10, 10}
+		b.Run(fmt.Sprintf("records=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				count := 0
+				for _, record := range m.Records {
+					if intersects(record.BoundingBox, q) {
+						count++
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkBBoxRTree(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000} {
+		m := benchmarkRecords(n)
+		q := [4]float64{-10, -10, 10, 10}
+		b.Run(fmt.Sprintf("records=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				count := 0
+				m.index.Search(q, func(id string) bool {
+					count++
+					return true
+				})
+			}
+		})
+	}
+}
+
+func BenchmarkRTreeInsert(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	tree := newRTree()
+	for i := 0; i < b.N; i++ {
+		tree.Insert(fmt.Sprintf("r%d", i), randomBBox(r, 2))
+	}
+}
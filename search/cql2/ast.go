@@ -0,0 +1,231 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+// Package cql2 provides parsing and evaluation of OGC Common Query
+// Language (CQL2) filters, in both CQL2-Text and CQL2-JSON encodings
+package cql2
+
+import (
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// Expr is a CQL2 boolean expression
+type Expr interface {
+	expr()
+}
+
+// Operand is a CQL2 scalar, temporal, spatial or array value
+type Operand interface {
+	operand()
+}
+
+// And is a conjunction of expressions
+type And struct {
+	Args []Expr
+}
+
+// Or is a disjunction of expressions
+type Or struct {
+	Args []Expr
+}
+
+// Not negates an expression
+type Not struct {
+	Arg Expr
+}
+
+// Bool is a boolean literal used as an expression
+type Bool struct {
+	Value bool
+}
+
+// Comparison compares two operands with one of =, <>, <, >, <=, >=
+type Comparison struct {
+	Op    string
+	Left  Operand
+	Right Operand
+}
+
+// Like matches an operand against a pattern, where % matches any
+// sequence of characters and _ any single character
+type Like struct {
+	Value   Operand
+	Pattern string
+}
+
+// In tests whether an operand equals any of a list of operands
+type In struct {
+	Value Operand
+	List  []Operand
+}
+
+// Between tests whether an operand lies within an inclusive range
+type Between struct {
+	Value Operand
+	Low   Operand
+	High  Operand
+}
+
+// IsNull tests whether an operand has no value
+type IsNull struct {
+	Value Operand
+}
+
+// Spatial applies a spatial predicate (s_intersects, s_within, ...)
+type Spatial struct {
+	Op    string
+	Left  Operand
+	Right Operand
+}
+
+// Temporal applies a temporal predicate (t_intersects, t_during, ...)
+type Temporal struct {
+	Op    string
+	Left  Operand
+	Right Operand
+}
+
+func (And) expr()        {}
+func (Or) expr()         {}
+func (Not) expr()        {}
+func (Bool) expr()       {}
+func (Comparison) expr() {}
+func (Like) expr()       {}
+func (In) expr()         {}
+func (Between) expr()    {}
+func (IsNull) expr()     {}
+func (Spatial) expr()    {}
+func (Temporal) expr()   {}
+
+// Property references a queryable by name
+type Property struct {
+	Name string
+}
+
+// Literal is a string, number (float64) or boolean value
+type Literal struct {
+	Value interface{}
+}
+
+// Timestamp is a temporal instant
+type Timestamp struct {
+	Time time.Time
+}
+
+// Interval is a temporal interval; a nil bound is open ("..")
+type Interval struct {
+	Start *time.Time
+	End   *time.Time
+}
+
+// Geometry is a geometry literal
+type Geometry struct {
+	metadata.Geometry
+	// BBox is set for bbox literals
+	BBox *[4]float64
+}
+
+func (Property) operand()  {}
+func (Literal) operand()   {}
+func (Timestamp) operand() {}
+func (Interval) operand()  {}
+func (Geometry) operand()  {}
+
+// Spatial predicates
+var spatialOps = map[string]bool{
+	"s_intersects": true, "s_equals": true, "s_disjoint": true, "s_touches": true,
+	"s_within": true, "s_overlaps": true, "s_crosses": true, "s_contains": true,
+}
+
+// Temporal predicates
+var temporalOps = map[string]bool{
+	"t_after": true, "t_before": true, "t_contains": true, "t_disjoint": true,
+	"t_during": true, "t_equals": true, "t_finishedby": true, "t_finishes": true,
+	"t_intersects": true, "t_meets": true, "t_metby": true, "t_overlappedby": true,
+	"t_overlaps": true, "t_startedby": true, "t_starts": true,
+}
+
+var comparisonOps = map[string]bool{
+	"=": true, "<>": true, "<": true, ">": true, "<=": true, ">=": true,
+}
+
+// Bounds returns the envelope (minx, miny, maxx, maxy) of a geometry
+func (g Geometry) Bounds() [4]float64 {
+	if g.BBox != nil {
+		return *g.BBox
+	}
+	return g.Geometry.Bounds()
+}
+
+// Properties returns the names of all properties referenced by an
+// expression
+func Properties(e Expr) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	add := func(operands ...Operand) {
+		for _, o := range operands {
+			if p, ok := o.(Property); ok && !seen[p.Name] {
+				seen[p.Name] = true
+				names = append(names, p.Name)
+			}
+		}
+	}
+
+	var walk func(e Expr)
+	walk = func(e Expr) {
+		switch n := e.(type) {
+		case And:
+			for _, a := range n.Args {
+				walk(a)
+			}
+		case Or:
+			for _, a := range n.Args {
+				walk(a)
+			}
+		case Not:
+			walk(n.Arg)
+		case Comparison:
+			add(n.Left, n.Right)
+		case Like:
+			add(n.Value)
+		case In:
+			add(n.Value)
+			add(n.List...)
+		case Between:
+			add(n.Value, n.Low, n.High)
+		case IsNull:
+			add(n.Value)
+		case Spatial:
+			add(n.Left, n.Right)
+		case Temporal:
+			add(n.Left, n.Right)
+		}
+	}
+	walk(e)
+	return names
+}
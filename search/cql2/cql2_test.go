@@ -0,0 +1,156 @@
+package cql2_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/search/cql2"
+)
+
+func TestTextAndJSONEquivalence(t *testing.T) {
+	cases := []struct {
+		text string
+		json string
+	}{
+		{
+			`eo:cloud_cover < 10 AND NOT title LIKE 'Fire%'`,
+			`{"op": "and", "args": [
+				{"op": "<", "args": [{"property": "eo:cloud_cover"}, 10]},
+				{"op": "not", "args": [{"op": "like", "args": [{"property": "title"}, "Fire%"]}]}]}`,
+		},
+		{
+			`platform IN ('landsat-8', 'sentinel-2') OR eo:cloud_cover BETWEEN -1 AND 5.5`,
+			`{"op": "or", "args": [
+				{"op": "in", "args": [{"property": "platform"}, ["landsat-8", "sentinel-2"]]},
+				{"op": "between", "args": [{"property": "eo:cloud_cover"}, -1, 5.5]}]}`,
+		},
+		{
+			`S_INTERSECTS(geometry, POLYGON((0 0, 10 0, 10 10, 0 10, 0 0)))`,
+			`{"op": "s_intersects", "args": [{"property": "geometry"},
+				{"type": "Polygon", "coordinates": [[[0, 0], [10, 0], [10, 10], [0, 10], [0, 0]]]}]}`,
+		},
+		{
+			`T_DURING(datetime, INTERVAL('2019-01-01T00:00:00Z', '..'))`,
+			`{"op": "t_during", "args": [{"property": "datetime"}, {"interval": ["2019-01-01T00:00:00Z", ".."]}]}`,
+		},
+		{
+			`"title" IS NOT NULL`,
+			`{"op": "not", "args": [{"op": "isNull", "args": [{"property": "title"}]}]}`,
+		},
+	}
+	for _, c := range cases {
+		fromText, err := cql2.ParseText(c.text)
+		if err != nil {
+			t.Errorf("%s: %v", c.text, err)
+			continue
+		}
+		fromJSON, err := cql2.ParseJSON([]byte(c.json))
+		if err != nil {
+			t.Errorf("%s: %v", c.json, err)
+			continue
+		}
+		if !reflect.DeepEqual(fromText, fromJSON) {
+			t.Errorf("%s: text %#v differs from JSON %#v", c.text, fromText, fromJSON)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{
+		`title =`,
+		`title LIKE 5`,
+		`(title = 'a'`,
+		`title = 'a' title = 'b'`,
+		`S_INTERSECTS(geometry)`,
+		`POINT(1)`,
+	} {
+		if _, err := cql2.ParseText(text); err == nil {
+			t.Errorf("%s: expected error", text)
+		}
+	}
+	for _, js := range []string{
+		`{"op": "frobnicate", "args": []}`,
+		`{"op": "=", "args": [{"property": "a"}]}`,
+		`[1, 2]`,
+	} {
+		if _, err := cql2.ParseJSON([]byte(js)); err == nil {
+			t.Errorf("%s: expected error", js)
+		}
+	}
+	if _, err := cql2.Parse(`title = 'a'`, "cql-text"); err == nil {
+		t.Error("expected error for unsupported filter-lang")
+	}
+}
+
+func testRecord() *metadata.Record {
+	dt := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	r := &metadata.Record{Identifier: "LC08_1", BoundingBox: [4]float64{10, 10, 20, 20}}
+	r.Properties.Title = "Landsat scene over Ottawa"
+	r.Properties.Datetime = &dt
+	r.Properties.Collection = "landsat-8-l1"
+	r.Properties.ProductInfo = &metadata.ProductInfo{Platform: "landsat-8", CloudCover: 7.5, Path: 16}
+	return r
+}
+
+func TestEvaluate(t *testing.T) {
+	cases := map[string]bool{
+		`eo:cloud_cover < 10`:                                      true,
+		`eo:cloud_cover >= 10`:                                     false,
+		`landsat:path = 16 AND platform = 'landsat-8'`:             true,
+		`title LIKE '%Ottawa'`:                                     true,
+		`title LIKE 'Landsat_scene%'`:                              true,
+		`title NOT LIKE '%Ottawa'`:                                 false,
+		`platform IN ('sentinel-2', 'landsat-8')`:                  true,
+		`platform NOT IN ('sentinel-2')`:                           true,
+		`eo:cloud_cover BETWEEN 5 AND 8`:                           true,
+		`license IS NULL`:                                          true,
+		`title IS NULL`:                                            false,
+		`id = 'LC08_1' OR id = 'other'`:                            true,
+		`collection <> 'landsat-8-l1'`:                             false,
+		`datetime > TIMESTAMP('2019-01-01T00:00:00Z')`:             true,
+		`datetime < DATE('2019-01-01')`:                            false,
+		`datetime = '2019-06-01T12:00:00Z'`:                        true,
+		`S_INTERSECTS(geometry, BBOX(15, 15, 30, 30))`:             true,
+		`S_INTERSECTS(geometry, POINT(50 50))`:                     false,
+		`S_WITHIN(geometry, BBOX(0, 0, 40, 40))`:                   true,
+		`S_CONTAINS(geometry, POINT(12 12))`:                       true,
+		`S_DISJOINT(geometry, POINT(50 50))`:                       true,
+		`S_TOUCHES(geometry, LINESTRING(20 0, 20 40))`:             true,
+		`T_INTERSECTS(datetime, INTERVAL('2019-01-01', '..'))`:     true,
+		`T_DURING(datetime, INTERVAL('2019-01-01', '2019-12-31'))`: true,
+		`T_BEFORE(datetime, TIMESTAMP('2020-01-01T00:00:00Z'))`:    true,
+		`T_AFTER(datetime, DATE('2020-01-01'))`:                    false,
+		`T_EQUALS(datetime, TIMESTAMP('2019-06-01T12:00:00Z'))`:    true,
+		`T_DISJOINT(datetime, INTERVAL('..', '2019-01-01'))`:       true,
+		`NOT (eo:cloud_cover > 5 AND platform = 'landsat-8')`:      false,
+		`unknown = 1`: false,
+		`TRUE`:        true,
+	}
+	r := testRecord()
+	for text, expected := range cases {
+		e, err := cql2.ParseText(text)
+		if err != nil {
+			t.Errorf("%s: %v", text, err)
+			continue
+		}
+		if got := cql2.Evaluate(e, r); got != expected {
+			t.Errorf("%s: expected %v, got %v", text, expected, got)
+		}
+		if got := cql2.Evaluate(cql2.Compile(e), r); got != expected {
+			t.Errorf("%s: expected %v compiled, got %v", text, expected, got)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	e, _ := cql2.ParseText(`eo:cloud_cover < 10 AND properties.title = 'x'`)
+	if err := cql2.Validate(e); err != nil {
+		t.Error(err)
+	}
+	e, _ = cql2.ParseText(`eo:cloud_cover < 10 AND nosuchfield = 'x'`)
+	if err := cql2.Validate(e); err == nil {
+		t.Error("expected error for unknown queryable")
+	}
+}
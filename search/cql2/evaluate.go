@@ -0,0 +1,454 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package cql2
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// aliases maps STAC queryable names to their place in the Record model
+var aliases = map[string]string{
+	"eo:cloud_cover": "properties.product_info.cloud_cover",
+	"gsd":            "properties.product_info.gsd",
+	"platform":       "properties.product_info.platform",
+	"instruments":    "properties.product_info.sensor_id",
+	"landsat:path":   "properties.product_info.path",
+	"landsat:row":    "properties.product_info.row",
+}
+
+// ResolveProperty maps a queryable name onto a dotted path in the
+// Record model (see metadata.ResolveField)
+func ResolveProperty(name string) (string, bool) {
+	if alias, ok := aliases[name]; ok {
+		name = alias
+	}
+	switch name {
+	case "geometry", "bbox":
+		return name, true
+	}
+	canonical, _, ok := metadata.ResolveField(name)
+	return canonical, ok
+}
+
+// Validate checks that all properties referenced by an expression are
+// known queryables
+func Validate(e Expr) error {
+	for _, name := range Properties(e) {
+		if _, ok := ResolveProperty(name); !ok {
+			return fmt.Errorf("unknown queryable %q", name)
+		}
+	}
+	return nil
+}
+
+// compiledLike is a Like with its pattern compiled, see Compile
+type compiledLike struct {
+	Like
+	re *regexp.Regexp
+}
+
+func (compiledLike) expr() {}
+
+// Compile prepares an expression for evaluation against many records,
+// compiling LIKE patterns once.  The result is for Evaluate only
+func Compile(e Expr) Expr {
+	switch n := e.(type) {
+	case And:
+		args := make([]Expr, len(n.Args))
+		for i, a := range n.Args {
+			args[i] = Compile(a)
+		}
+		return And{Args: args}
+	case Or:
+		args := make([]Expr, len(n.Args))
+		for i, a := range n.Args {
+			args[i] = Compile(a)
+		}
+		return Or{Args: args}
+	case Not:
+		return Not{Arg: Compile(n.Arg)}
+	case Like:
+		return compiledLike{Like: n, re: likePattern(n.Pattern)}
+	}
+	return e
+}
+
+// Evaluate reports whether a record matches an expression.  Predicates
+// on absent values are false.  Expressions evaluated against many
+// records should be compiled first (see Compile).  s_equals, s_touches,
+// s_overlaps and s_crosses are evaluated on the envelopes of their
+// operands
+func Evaluate(e Expr, r *metadata.Record) bool {
+	switch n := e.(type) {
+	case And:
+		for _, a := range n.Args {
+			if !Evaluate(a, r) {
+				return false
+			}
+		}
+		return true
+	case Or:
+		for _, a := range n.Args {
+			if Evaluate(a, r) {
+				return true
+			}
+		}
+		return false
+	case Not:
+		return !Evaluate(n.Arg, r)
+	case Bool:
+		return n.Value
+	case Comparison:
+		left, ok1 := value(n.Left, r)
+		right, ok2 := value(n.Right, r)
+		if !ok1 || !ok2 {
+			return false
+		}
+		c := compare(left, right)
+		switch n.Op {
+		case "=":
+			return c == 0
+		case "<>":
+			return c != 0
+		case "<":
+			return c < 0
+		case ">":
+			return c > 0
+		case "<=":
+			return c <= 0
+		case ">=":
+			return c >= 0
+		}
+	case Like:
+		v, ok := value(n.Value, r)
+		if !ok {
+			return false
+		}
+		return likePattern(n.Pattern).MatchString(stringValue(v))
+	case compiledLike:
+		v, ok := value(n.Value, r)
+		if !ok {
+			return false
+		}
+		return n.re.MatchString(stringValue(v))
+	case In:
+		v, ok := value(n.Value, r)
+		if !ok {
+			return false
+		}
+		for _, item := range n.List {
+			if iv, ok := value(item, r); ok && compare(v, iv) == 0 {
+				return true
+			}
+		}
+		return false
+	case Between:
+		v, ok1 := value(n.Value, r)
+		low, ok2 := value(n.Low, r)
+		high, ok3 := value(n.High, r)
+		if !ok1 || !ok2 || !ok3 {
+			return false
+		}
+		return compare(v, low) >= 0 && compare(v, high) <= 0
+	case IsNull:
+		v, ok := value(n.Value, r)
+		return !ok || v == ""
+	case Spatial:
+		switch n.Op {
+		case "s_intersects", "s_disjoint", "s_within", "s_contains":
+			a, ok1 := geometry(n.Left, r)
+			b, ok2 := geometry(n.Right, r)
+			if !ok1 || !ok2 {
+				return false
+			}
+			return a.Relate(strings.TrimPrefix(n.Op, "s_"), b)
+		}
+		a, ok1 := envelope(n.Left, r)
+		b, ok2 := envelope(n.Right, r)
+		if !ok1 || !ok2 {
+			return false
+		}
+		return spatialRelation(n.Op, a, b)
+	case Temporal:
+		a, ok1 := interval(n.Left, r)
+		b, ok2 := interval(n.Right, r)
+		if !ok1 || !ok2 {
+			return false
+		}
+		return temporalRelation(n.Op, a, b)
+	}
+	return false
+}
+
+// value resolves an operand to a scalar value
+func value(o Operand, r *metadata.Record) (interface{}, bool) {
+	switch t := o.(type) {
+	case Property:
+		path, ok := ResolveProperty(t.Name)
+		if !ok {
+			return nil, false
+		}
+		return r.Value(path)
+	case Literal:
+		return t.Value, true
+	case Timestamp:
+		return t.Time, true
+	}
+	return nil, false
+}
+
+// compare orders two values, coercing strings to times when compared
+// with a time
+func compare(a, b interface{}) int {
+	if s, ok := a.(string); ok {
+		if _, isTime := b.(time.Time); isTime {
+			if t, err := parseInstant(s); err == nil {
+				a = t
+			}
+		}
+	}
+	if s, ok := b.(string); ok {
+		if _, isTime := a.(time.Time); isTime {
+			if t, err := parseInstant(s); err == nil {
+				b = t
+			}
+		}
+	}
+	return metadata.CompareValues(a, b)
+}
+
+func stringValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// likePattern compiles a LIKE pattern to an anchored regular
+// expression.  A backslash escapes the following character
+func likePattern(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^(?s:")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteString(".*")
+		case r == '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString(")$")
+	return regexp.MustCompile(b.String())
+}
+
+// geometry resolves a spatial operand to a geometry.  Records without
+// a geometry are represented by their bounding box
+func geometry(o Operand, r *metadata.Record) (*metadata.Geometry, bool) {
+	switch t := o.(type) {
+	case Geometry:
+		return &t.Geometry, !t.Geometry.IsEmpty()
+	case Property:
+		path, _ := ResolveProperty(t.Name)
+		if path == "geometry" && !r.Geometry.IsEmpty() {
+			return &r.Geometry, true
+		}
+		if (path == "geometry" || path == "bbox") && r.BoundingBox != ([4]float64{}) {
+			envelope := metadata.NewEnvelope(r.BoundingBox)
+			return &envelope, true
+		}
+	}
+	return nil, false
+}
+
+// envelope resolves a spatial operand to its bounding box
+func envelope(o Operand, r *metadata.Record) ([4]float64, bool) {
+	switch t := o.(type) {
+	case Geometry:
+		b := t.Bounds()
+		return b, b[0] <= b[2] && b[1] <= b[3]
+	case Property:
+		path, _ := ResolveProperty(t.Name)
+		if path == "geometry" || path == "bbox" {
+			return r.BoundingBox, true
+		}
+	}
+	return [4]float64{}, false
+}
+
+// spatialRelation applies a spatial predicate to two envelopes, either
+// of which may cross the antimeridian
+func spatialRelation(op string, a, b [4]float64) bool {
+	var intersects, interiors bool
+	for _, pa := range metadata.SplitBBox(a) {
+		for _, pb := range metadata.SplitBBox(b) {
+			intersects = intersects || pa[0] <= pb[2] && pa[2] >= pb[0] && pa[1] <= pb[3] && pa[3] >= pb[1]
+			interiors = interiors || pa[0] < pb[2] && pa[2] > pb[0] && pa[1] < pb[3] && pa[3] > pb[1]
+		}
+	}
+	within := covers(b, a)
+	contains := covers(a, b)
+
+	switch op {
+	case "s_intersects":
+		return intersects
+	case "s_disjoint":
+		return !intersects
+	case "s_within":
+		return within
+	case "s_contains":
+		return contains
+	case "s_equals":
+		return a == b
+	case "s_touches":
+		return intersects && !interiors
+	case "s_overlaps", "s_crosses":
+		return interiors && !within && !contains
+	}
+	return false
+}
+
+// covers reports whether every part of envelope b lies within a part
+// of envelope a
+func covers(a, b [4]float64) bool {
+	for _, pb := range metadata.SplitBBox(b) {
+		covered := false
+		for _, pa := range metadata.SplitBBox(a) {
+			if pb[0] >= pa[0] && pb[2] <= pa[2] && pb[1] >= pa[1] && pb[3] <= pa[3] {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// timeInterval is a closed interval; open bounds are represented by
+// the zero time (start) or a far future time (end)
+type timeInterval struct {
+	start, end time.Time
+}
+
+var endOfTime = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+
+// interval resolves a temporal operand to an interval
+func interval(o Operand, r *metadata.Record) (timeInterval, bool) {
+	switch t := o.(type) {
+	case Interval:
+		ti := timeInterval{end: endOfTime}
+		if t.Start != nil {
+			ti.start = *t.Start
+		}
+		if t.End != nil {
+			ti.end = *t.End
+		}
+		return ti, true
+	case Timestamp:
+		return timeInterval{t.Time, t.Time}, true
+	case Literal:
+		if s, ok := t.Value.(string); ok {
+			if ts, err := parseInstant(s); err == nil {
+				return timeInterval{ts, ts}, true
+			}
+		}
+	case Property:
+		v, ok := value(t, r)
+		if !ok {
+			return timeInterval{}, false
+		}
+		switch tv := v.(type) {
+		case time.Time:
+			return timeInterval{tv, tv}, true
+		case metadata.Temporal:
+			if tv.Begin == nil && tv.End == nil {
+				return timeInterval{}, false
+			}
+			ti := timeInterval{end: endOfTime}
+			if tv.Begin != nil {
+				ti.start = *tv.Begin
+			}
+			if tv.End != nil {
+				ti.end = *tv.End
+			}
+			return ti, true
+		}
+	}
+	return timeInterval{}, false
+}
+
+// temporalRelation applies a temporal predicate to two intervals, each
+// given as start and end (equal for instants)
+func temporalRelation(op string, a, b timeInterval) bool {
+	as, ae, bs, be := a.start, a.end, b.start, b.end
+	switch op {
+	case "t_after":
+		return as.After(be)
+	case "t_before":
+		return ae.Before(bs)
+	case "t_contains":
+		return as.Before(bs) && ae.After(be)
+	case "t_disjoint":
+		return ae.Before(bs) || as.After(be)
+	case "t_during":
+		return as.After(bs) && ae.Before(be)
+	case "t_equals":
+		return as.Equal(bs) && ae.Equal(be)
+	case "t_finishedby":
+		return as.Before(bs) && ae.Equal(be)
+	case "t_finishes":
+		return as.After(bs) && ae.Equal(be)
+	case "t_intersects":
+		return !as.After(be) && !ae.Before(bs)
+	case "t_meets":
+		return ae.Equal(bs)
+	case "t_metby":
+		return as.Equal(be)
+	case "t_overlappedby":
+		return as.After(bs) && as.Before(be) && ae.After(be)
+	case "t_overlaps":
+		return as.Before(bs) && ae.After(bs) && ae.Before(be)
+	case "t_startedby":
+		return as.Equal(bs) && ae.After(be)
+	case "t_starts":
+		return as.Equal(bs) && ae.Before(be)
+	}
+	return false
+}
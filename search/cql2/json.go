@@ -0,0 +1,300 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package cql2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+// ParseJSON parses a CQL2-JSON filter expression
+func ParseJSON(data []byte) (Expr, error) {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid CQL2-JSON: %v", err)
+	}
+	return decodeExpr(normalizeNumbers(v))
+}
+
+// normalizeNumbers converts json.Number values to float64
+func normalizeNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		f, _ := t.Float64()
+		return f
+	case []interface{}:
+		for i := range t {
+			t[i] = normalizeNumbers(t[i])
+		}
+	case map[string]interface{}:
+		for k := range t {
+			t[k] = normalizeNumbers(t[k])
+		}
+	}
+	return v
+}
+
+func decodeExpr(v interface{}) (Expr, error) {
+	if b, ok := v.(bool); ok {
+		return Bool{Value: b}, nil
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an operation object, found %v", v)
+	}
+	opValue, ok := obj["op"].(string)
+	if !ok {
+		return nil, fmt.Errorf("operation object missing op")
+	}
+	args, ok := obj["args"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("operation %q missing args", opValue)
+	}
+	op := strings.ToLower(opValue)
+
+	arity := func(n int) error {
+		if len(args) != n {
+			return fmt.Errorf("operation %q requires %d arguments, found %d", op, n, len(args))
+		}
+		return nil
+	}
+
+	switch {
+	case op == "and" || op == "or":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("operation %q requires at least 2 arguments", op)
+		}
+		exprs := make([]Expr, len(args))
+		for i, a := range args {
+			e, err := decodeExpr(a)
+			if err != nil {
+				return nil, err
+			}
+			exprs[i] = e
+		}
+		if op == "and" {
+			return And{Args: exprs}, nil
+		}
+		return Or{Args: exprs}, nil
+	case op == "not":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		e, err := decodeExpr(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return Not{Arg: e}, nil
+	case op == "isnull":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		o, err := decodeOperand(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return IsNull{Value: o}, nil
+	case op == "like":
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		o, err := decodeOperand(args[0])
+		if err != nil {
+			return nil, err
+		}
+		pattern, ok := args[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("like requires a string pattern")
+		}
+		return Like{Value: o, Pattern: pattern}, nil
+	case op == "between":
+		if len(args) == 2 {
+			// older drafts encode the bounds as an array
+			if bounds, ok := args[1].([]interface{}); ok && len(bounds) == 2 {
+				args = []interface{}{args[0], bounds[0], bounds[1]}
+			}
+		}
+		if err := arity(3); err != nil {
+			return nil, err
+		}
+		operands, err := decodeOperands(args)
+		if err != nil {
+			return nil, err
+		}
+		return Between{Value: operands[0], Low: operands[1], High: operands[2]}, nil
+	case op == "in":
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		o, err := decodeOperand(args[0])
+		if err != nil {
+			return nil, err
+		}
+		items, ok := args[1].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("in requires a list")
+		}
+		list, err := decodeOperands(items)
+		if err != nil {
+			return nil, err
+		}
+		return In{Value: o, List: list}, nil
+	case comparisonOps[op], spatialOps[op], temporalOps[op]:
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		operands, err := decodeOperands(args)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case spatialOps[op]:
+			return Spatial{Op: op, Left: operands[0], Right: operands[1]}, nil
+		case temporalOps[op]:
+			return Temporal{Op: op, Left: operands[0], Right: operands[1]}, nil
+		}
+		return Comparison{Op: op, Left: operands[0], Right: operands[1]}, nil
+	}
+	return nil, fmt.Errorf("unsupported operation %q", opValue)
+}
+
+func decodeOperands(args []interface{}) ([]Operand, error) {
+	operands := make([]Operand, len(args))
+	for i, a := range args {
+		o, err := decodeOperand(a)
+		if err != nil {
+			return nil, err
+		}
+		operands[i] = o
+	}
+	return operands, nil
+}
+
+func decodeOperand(v interface{}) (Operand, error) {
+	switch t := v.(type) {
+	case string, float64, bool:
+		return Literal{Value: t}, nil
+	case map[string]interface{}:
+		if name, ok := t["property"].(string); ok {
+			return Property{Name: name}, nil
+		}
+		if s, ok := t["timestamp"].(string); ok {
+			ts, err := parseInstant(s)
+			if err != nil {
+				return nil, err
+			}
+			return Timestamp{Time: ts}, nil
+		}
+		if s, ok := t["date"].(string); ok {
+			ts, err := parseInstant(s)
+			if err != nil {
+				return nil, err
+			}
+			return Timestamp{Time: ts}, nil
+		}
+		if bounds, ok := t["interval"].([]interface{}); ok {
+			return decodeInterval(bounds)
+		}
+		if values, ok := t["bbox"].([]interface{}); ok {
+			nums := make([]float64, len(values))
+			for i, n := range values {
+				f, ok := n.(float64)
+				if !ok {
+					return nil, fmt.Errorf("bbox requires numbers")
+				}
+				nums[i] = f
+			}
+			return bboxGeometry(nums)
+		}
+		if _, ok := t["type"].(string); ok {
+			return decodeGeometry(t)
+		}
+		if _, ok := t["op"]; ok {
+			return nil, fmt.Errorf("nested operation %v is not a valid operand", t["op"])
+		}
+	}
+	return nil, fmt.Errorf("unsupported operand %v", v)
+}
+
+func decodeInterval(bounds []interface{}) (Operand, error) {
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("interval requires 2 bounds")
+	}
+	var result [2]*time.Time
+	for i, b := range bounds {
+		var s string
+		switch t := b.(type) {
+		case string:
+			s = t
+		case map[string]interface{}:
+			if ts, ok := t["timestamp"].(string); ok {
+				s = ts
+			} else if d, ok := t["date"].(string); ok {
+				s = d
+			}
+		}
+		if s == ".." {
+			continue
+		}
+		ts, err := parseInstant(s)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = &ts
+	}
+	return Interval{Start: result[0], End: result[1]}, nil
+}
+
+func decodeGeometry(obj map[string]interface{}) (Geometry, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return Geometry{}, err
+	}
+	var g metadata.Geometry
+	if err := json.Unmarshal(data, &g); err != nil {
+		return Geometry{}, err
+	}
+	return Geometry{Geometry: g}, nil
+}
+
+// Parse parses a filter in the given language, which is one of
+// cql2-text or cql2-json (an empty language defaults to cql2-text)
+func Parse(filter string, lang string) (Expr, error) {
+	switch strings.ToLower(lang) {
+	case "", "cql2-text":
+		return ParseText(filter)
+	case "cql2-json":
+		return ParseJSON([]byte(filter))
+	}
+	return nil, fmt.Errorf("unsupported filter-lang %q", lang)
+}
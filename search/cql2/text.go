@@ -0,0 +1,565 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package cql2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokQuotedIdent
+	tokString
+	tokNumber
+	tokPunct
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	pos   int
+	value float64
+}
+
+// ParseText parses a CQL2-Text filter expression
+func ParseText(s string) (Expr, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &textParser{source: []rune(s), tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	return e, nil
+}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	runes := []rune(s)
+	i := 0
+
+	// a sign starts a number only where an operand is expected
+	expectOperand := func() bool {
+		if len(tokens) == 0 {
+			return true
+		}
+		last := tokens[len(tokens)-1]
+		if last.kind == tokPunct {
+			return last.text != ")"
+		}
+		if last.kind == tokIdent {
+			return isKeyword(last.text)
+		}
+		return false
+	}
+
+	for i < len(runes) {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'':
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						b.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokString, text: b.String(), pos: start})
+		case r == '"':
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated identifier at position %d", start)
+			}
+			tokens = append(tokens, token{kind: tokQuotedIdent, text: string(runes[start+1 : i]), pos: start})
+			i++
+		case unicode.IsDigit(r) || r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]) ||
+			(r == '-' || r == '+') && i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') && expectOperand():
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E' ||
+				(runes[i] == '-' || runes[i] == '+') && (runes[i-1] == 'e' || runes[i-1] == 'E')) {
+				i++
+			}
+			text := string(runes[start:i])
+			v, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", text, start)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, pos: start, value: v})
+		case unicode.IsLetter(r) || r == '_':
+			i++
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) ||
+				runes[i] == '_' || runes[i] == ':' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start:i]), pos: start})
+		case r == '<' || r == '>':
+			i++
+			if i < len(runes) && (runes[i] == '=' || r == '<' && runes[i] == '>') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokPunct, text: string(runes[start:i]), pos: start})
+		case strings.ContainsRune("(),=", r):
+			i++
+			tokens = append(tokens, token{kind: tokPunct, text: string(r), pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, start)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
+
+var keywords = map[string]bool{
+	"and": true, "or": true, "not": true, "like": true, "in": true,
+	"between": true, "is": true, "null": true,
+}
+
+func isKeyword(s string) bool {
+	return keywords[strings.ToLower(s)]
+}
+
+type textParser struct {
+	source []rune
+	tokens []token
+	pos    int
+}
+
+func (p *textParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *textParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *textParser) errorf(t token, format string, args ...interface{}) error {
+	return fmt.Errorf("position %d: %s", t.pos, fmt.Sprintf(format, args...))
+}
+
+// keyword reports whether the next token is the given keyword and
+// consumes it if so
+func (p *textParser) keyword(k string) bool {
+	t := p.peek()
+	if t.kind == tokIdent && strings.EqualFold(t.text, k) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *textParser) punct(s string) bool {
+	t := p.peek()
+	if t.kind == tokPunct && t.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *textParser) expect(s string) error {
+	if !p.punct(s) {
+		t := p.peek()
+		return p.errorf(t, "expected %q, found %q", s, t.text)
+	}
+	return nil
+}
+
+func (p *textParser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	args := []Expr{left}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, right)
+	}
+	if len(args) == 1 {
+		return left, nil
+	}
+	return Or{Args: args}, nil
+}
+
+func (p *textParser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	args := []Expr{left}
+	for p.keyword("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, right)
+	}
+	if len(args) == 1 {
+		return left, nil
+	}
+	return And{Args: args}, nil
+}
+
+func (p *textParser) parseNot() (Expr, error) {
+	if p.keyword("not") {
+		e, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Arg: e}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *textParser) parsePrimary() (Expr, error) {
+	t := p.peek()
+
+	if t.kind == tokPunct && t.text == "(" {
+		// a parenthesised boolean expression
+		p.pos++
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+
+	if t.kind == tokIdent {
+		name := strings.ToLower(t.text)
+		if spatialOps[name] || temporalOps[name] {
+			p.pos++
+			if err := p.expect("("); err != nil {
+				return nil, err
+			}
+			left, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			if spatialOps[name] {
+				return Spatial{Op: name, Left: left, Right: right}, nil
+			}
+			return Temporal{Op: name, Left: left, Right: right}, nil
+		}
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return p.parsePredicate(left)
+}
+
+func (p *textParser) parsePredicate(left Operand) (Expr, error) {
+	t := p.peek()
+
+	if t.kind == tokPunct && comparisonOps[t.text] {
+		p.pos++
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return Comparison{Op: t.text, Left: left, Right: right}, nil
+	}
+
+	if p.keyword("is") {
+		negate := p.keyword("not")
+		if !p.keyword("null") {
+			return nil, p.errorf(p.peek(), "expected NULL")
+		}
+		return negated(IsNull{Value: left}, negate), nil
+	}
+
+	negate := p.keyword("not")
+	switch {
+	case p.keyword("like"):
+		pt := p.next()
+		if pt.kind != tokString {
+			return nil, p.errorf(pt, "LIKE requires a string pattern")
+		}
+		return negated(Like{Value: left, Pattern: pt.text}, negate), nil
+	case p.keyword("between"):
+		low, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword("and") {
+			return nil, p.errorf(p.peek(), "expected AND in BETWEEN")
+		}
+		high, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return negated(Between{Value: left, Low: low, High: high}, negate), nil
+	case p.keyword("in"):
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		var list []Operand
+		for {
+			o, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, o)
+			if !p.punct(",") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return negated(In{Value: left, List: list}, negate), nil
+	}
+
+	if negate {
+		return nil, p.errorf(p.peek(), "expected LIKE, BETWEEN or IN after NOT")
+	}
+	if l, ok := left.(Literal); ok {
+		if b, ok := l.Value.(bool); ok {
+			return Bool{Value: b}, nil
+		}
+	}
+	return nil, p.errorf(t, "expected a predicate, found %q", t.text)
+}
+
+func negated(e Expr, negate bool) Expr {
+	if negate {
+		return Not{Arg: e}
+	}
+	return e
+}
+
+func (p *textParser) parseOperand() (Operand, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return Literal{Value: t.text}, nil
+	case tokNumber:
+		return Literal{Value: t.value}, nil
+	case tokQuotedIdent:
+		return Property{Name: t.text}, nil
+	case tokIdent:
+		name := strings.ToLower(t.text)
+		switch name {
+		case "true", "false":
+			return Literal{Value: name == "true"}, nil
+		case "date", "timestamp":
+			if !p.punct("(") {
+				break
+			}
+			s := p.next()
+			if s.kind != tokString {
+				return nil, p.errorf(s, "%s requires a string", strings.ToUpper(name))
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			ts, err := parseInstant(s.text)
+			if err != nil {
+				return nil, p.errorf(s, "%v", err)
+			}
+			return Timestamp{Time: ts}, nil
+		case "interval":
+			if !p.punct("(") {
+				break
+			}
+			var bounds [2]*time.Time
+			for i := range bounds {
+				if i > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				b, err := p.parseIntervalBound()
+				if err != nil {
+					return nil, err
+				}
+				bounds[i] = b
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return Interval{Start: bounds[0], End: bounds[1]}, nil
+		case "bbox":
+			if !p.punct("(") {
+				break
+			}
+			var values []float64
+			for {
+				n := p.next()
+				if n.kind != tokNumber {
+					return nil, p.errorf(n, "BBOX requires numbers")
+				}
+				values = append(values, n.value)
+				if !p.punct(",") {
+					break
+				}
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return bboxGeometry(values)
+		}
+		if wktTypes[name] && p.peek().kind == tokPunct && p.peek().text == "(" {
+			return p.parseWKT(t)
+		}
+		if isKeyword(name) {
+			return nil, p.errorf(t, "unexpected keyword %q", t.text)
+		}
+		return Property{Name: t.text}, nil
+	}
+	return nil, p.errorf(t, "expected a value, found %q", t.text)
+}
+
+func (p *textParser) parseIntervalBound() (*time.Time, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokString && t.text == "..":
+		return nil, nil
+	case t.kind == tokString:
+		ts, err := parseInstant(t.text)
+		if err != nil {
+			return nil, p.errorf(t, "%v", err)
+		}
+		return &ts, nil
+	case t.kind == tokIdent && (strings.EqualFold(t.text, "date") || strings.EqualFold(t.text, "timestamp")):
+		p.pos--
+		o, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		ts := o.(Timestamp).Time
+		return &ts, nil
+	}
+	return nil, p.errorf(t, "invalid interval bound %q", t.text)
+}
+
+// wktTypes are the WKT geometry tags
+var wktTypes = map[string]bool{
+	"point":              true,
+	"linestring":         true,
+	"polygon":            true,
+	"multipoint":         true,
+	"multilinestring":    true,
+	"multipolygon":       true,
+	"geometrycollection": true,
+}
+
+// parseWKT parses a WKT geometry literal starting at token t, its tag,
+// with metadata.ParseWKT
+func (p *textParser) parseWKT(t token) (Operand, error) {
+	depth := 0
+	for {
+		n := p.next()
+		switch {
+		case n.kind == tokEOF:
+			return nil, p.errorf(n, "unterminated geometry")
+		case n.kind == tokPunct && n.text == "(":
+			depth++
+		case n.kind == tokPunct && n.text == ")":
+			depth--
+		}
+		if depth == 0 && n.kind == tokPunct && n.text == ")" {
+			g, err := metadata.ParseWKT(string(p.source[t.pos : n.pos+1]))
+			if err != nil {
+				return nil, p.errorf(t, "%v", err)
+			}
+			return Geometry{Geometry: g}, nil
+		}
+	}
+}
+
+func bboxGeometry(values []float64) (Geometry, error) {
+	var b [4]float64
+	switch len(values) {
+	case 4:
+		copy(b[:], values)
+	case 6:
+		b = [4]float64{values[0], values[1], values[3], values[4]}
+	default:
+		return Geometry{}, fmt.Errorf("bbox requires 4 or 6 numbers, found %d", len(values))
+	}
+	return Geometry{Geometry: metadata.NewEnvelope(b), BBox: &b}, nil
+}
+
+// parseInstant parses a CQL2 date or timestamp
+func parseInstant(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date or timestamp %q", s)
+}
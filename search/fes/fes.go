@@ -0,0 +1,515 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+// Package fes translates OGC Filter Encoding (1.1 and 2.0) filters and
+// CSW queryables to CQL2 expressions
+package fes
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/search/cql2"
+)
+
+// AnyText is the property standing for the full text of a record
+// (csw:AnyText).  It is not a queryable of the backends: see
+// ExtractAnyText
+const AnyText = "csw:AnyText"
+
+// queryables maps CSW queryables (without namespace prefix, lowercase)
+// to record properties
+var queryables = map[string]string{
+	"anytext":          AnyText,
+	"subject":          AnyText,
+	"keywords":         AnyText,
+	"identifier":       "id",
+	"title":            "properties.title",
+	"abstract":         "properties.abstract",
+	"description":      "properties.abstract",
+	"type":             "properties.type",
+	"modified":         "properties.modified",
+	"date":             "properties.modified",
+	"revisiondate":     "properties.modified",
+	"created":          "properties.created",
+	"creationdate":     "properties.created",
+	"language":         "properties.language",
+	"rights":           "properties.license",
+	"license":          "properties.license",
+	"boundingbox":      "geometry",
+	"spatial":          "geometry",
+	"envelope":         "geometry",
+	"tempextent_begin": "properties.temporal_extent.begin",
+	"tempextent_end":   "properties.temporal_extent.end",
+}
+
+// Queryables lists the CSW queryables, with their namespace prefixes
+var Queryables = []string{
+	"csw:AnyText", "dc:identifier", "dc:title", "dct:abstract", "dc:subject",
+	"dc:type", "dct:modified", "dc:date", "dc:language", "dc:rights",
+	"ows:BoundingBox", "dct:spatial", "apiso:Identifier", "apiso:Title",
+	"apiso:Abstract", "apiso:Subject", "apiso:Type", "apiso:Modified",
+	"apiso:RevisionDate", "apiso:CreationDate", "apiso:Language",
+	"apiso:TempExtent_begin", "apiso:TempExtent_end",
+}
+
+// ResolveQueryable resolves a CSW queryable (e.g. dc:title,
+// apiso:Modified) or a record property (e.g. properties.title) to a
+// record property, or to AnyText
+func ResolveQueryable(name string) (string, bool) {
+	local := name
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		local = name[i+1:]
+	}
+	if path, ok := queryables[strings.ToLower(local)]; ok {
+		return path, true
+	}
+	if path, ok := cql2.ResolveProperty(name); ok {
+		return path, true
+	}
+	return "", false
+}
+
+// comparisonOps maps Filter Encoding comparison operators to CQL2
+var comparisonOps = map[string]string{
+	"PropertyIsEqualTo":              "=",
+	"PropertyIsNotEqualTo":           "<>",
+	"PropertyIsLessThan":             "<",
+	"PropertyIsGreaterThan":          ">",
+	"PropertyIsLessThanOrEqualTo":    "<=",
+	"PropertyIsGreaterThanOrEqualTo": ">=",
+}
+
+// spatialOps maps Filter Encoding spatial operators to CQL2
+var spatialOps = map[string]string{
+	"BBOX":       "s_intersects",
+	"Intersects": "s_intersects",
+	"Within":     "s_within",
+	"Contains":   "s_contains",
+	"Disjoint":   "s_disjoint",
+	"Equals":     "s_equals",
+	"Touches":    "s_touches",
+	"Crosses":    "s_crosses",
+	"Overlaps":   "s_overlaps",
+}
+
+// SpatialOperators lists the Filter Encoding spatial operators whose
+// CQL2 equivalent is one of the given operators (e.g. s_intersects)
+func SpatialOperators(supported []string) []string {
+	var operators []string
+	for _, op := range []string{"BBOX", "Intersects", "Within", "Contains", "Disjoint", "Equals", "Touches", "Crosses", "Overlaps"} {
+		for _, s := range supported {
+			if spatialOps[op] == s {
+				operators = append(operators, op)
+				break
+			}
+		}
+	}
+	return operators
+}
+
+// node is a generic XML element; namespaces are ignored
+type node struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",chardata"`
+	Nodes   []node     `xml:",any"`
+}
+
+func (n *node) attr(names ...string) string {
+	for _, name := range names {
+		for _, a := range n.Attrs {
+			if a.Name.Local == name {
+				return a.Value
+			}
+		}
+	}
+	return ""
+}
+
+func (n *node) child(name string) *node {
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Local == name {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+func (n *node) text() string {
+	return strings.TrimSpace(n.Content)
+}
+
+// ParseFilter parses an ogc:Filter (Filter Encoding 1.1) or fes:Filter
+// (Filter Encoding 2.0) into a CQL2 expression, resolving CSW
+// queryables to record properties
+func ParseFilter(data []byte) (cql2.Expr, error) {
+	var root node
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("invalid filter: %v", err)
+	}
+	if root.XMLName.Local != "Filter" {
+		return nil, fmt.Errorf("expected Filter, found %s", root.XMLName.Local)
+	}
+
+	// identifier filters list any number of ids
+	var ids []cql2.Operand
+	for _, n := range root.Nodes {
+		switch n.XMLName.Local {
+		case "FeatureId", "GmlObjectId", "ResourceId", "RecordId":
+			id := n.attr("fid", "id", "rid")
+			if id == "" {
+				id = n.text()
+			}
+			ids = append(ids, cql2.Literal{Value: id})
+		}
+	}
+	if len(ids) > 0 {
+		return cql2.In{Value: cql2.Property{Name: "id"}, List: ids}, nil
+	}
+
+	if len(root.Nodes) != 1 {
+		return nil, fmt.Errorf("Filter requires exactly one operator, found %d", len(root.Nodes))
+	}
+	return expression(&root.Nodes[0])
+}
+
+// expression translates a Filter Encoding operator
+func expression(n *node) (cql2.Expr, error) {
+	op := n.XMLName.Local
+
+	switch op {
+	case "And", "Or":
+		var args []cql2.Expr
+		for i := range n.Nodes {
+			arg, err := expression(&n.Nodes[i])
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+		if len(args) < 2 {
+			return nil, fmt.Errorf("%s requires at least two operands", op)
+		}
+		if op == "And" {
+			return cql2.And{Args: args}, nil
+		}
+		return cql2.Or{Args: args}, nil
+	case "Not":
+		if len(n.Nodes) != 1 {
+			return nil, fmt.Errorf("Not requires one operand")
+		}
+		arg, err := expression(&n.Nodes[0])
+		if err != nil {
+			return nil, err
+		}
+		return cql2.Not{Arg: arg}, nil
+	case "PropertyIsLike":
+		property, err := propertyOperand(n)
+		if err != nil {
+			return nil, err
+		}
+		literal := n.child("Literal")
+		if literal == nil {
+			return nil, fmt.Errorf("PropertyIsLike requires a Literal")
+		}
+		pattern := likePattern(literal.text(), n.attr("wildCard"), n.attr("singleChar"), n.attr("escapeChar", "escape"))
+		return cql2.Like{Value: property, Pattern: pattern}, nil
+	case "PropertyIsNull", "PropertyIsNil":
+		property, err := propertyOperand(n)
+		if err != nil {
+			return nil, err
+		}
+		return cql2.IsNull{Value: property}, nil
+	case "PropertyIsBetween":
+		property, err := propertyOperand(n)
+		if err != nil {
+			return nil, err
+		}
+		low, high := n.child("LowerBoundary"), n.child("UpperBoundary")
+		if low == nil || high == nil || low.child("Literal") == nil || high.child("Literal") == nil {
+			return nil, fmt.Errorf("PropertyIsBetween requires LowerBoundary and UpperBoundary literals")
+		}
+		return cql2.Between{
+			Value: property,
+			Low:   literalOperand(property.Name, low.child("Literal").text()),
+			High:  literalOperand(property.Name, high.child("Literal").text()),
+		}, nil
+	}
+
+	if cmp, ok := comparisonOps[op]; ok {
+		property, err := propertyOperand(n)
+		if err != nil {
+			return nil, err
+		}
+		literal := n.child("Literal")
+		if literal == nil {
+			return nil, fmt.Errorf("%s requires a Literal", op)
+		}
+		return cql2.Comparison{Op: cmp, Left: property, Right: literalOperand(property.Name, literal.text())}, nil
+	}
+
+	if sop, ok := spatialOps[op]; ok {
+		property := cql2.Property{Name: "geometry"}
+		if propertyName(n) != nil {
+			var err error
+			if property, err = propertyOperand(n); err != nil {
+				return nil, err
+			}
+		}
+		for i := range n.Nodes {
+			if n.Nodes[i].XMLName.Local == "PropertyName" || n.Nodes[i].XMLName.Local == "ValueReference" {
+				continue
+			}
+			geometry, err := gmlGeometry(&n.Nodes[i])
+			if err != nil {
+				return nil, err
+			}
+			return cql2.Spatial{Op: sop, Left: property, Right: geometry}, nil
+		}
+		return nil, fmt.Errorf("%s requires a geometry", op)
+	}
+
+	return nil, fmt.Errorf("unsupported filter operator %s", op)
+}
+
+// propertyName returns the PropertyName (1.1) or ValueReference (2.0)
+// of an operator
+func propertyName(n *node) *node {
+	if p := n.child("PropertyName"); p != nil {
+		return p
+	}
+	return n.child("ValueReference")
+}
+
+func propertyOperand(n *node) (cql2.Property, error) {
+	p := propertyName(n)
+	if p == nil {
+		return cql2.Property{}, fmt.Errorf("%s requires a PropertyName", n.XMLName.Local)
+	}
+	path, ok := ResolveQueryable(p.text())
+	if !ok {
+		return cql2.Property{}, fmt.Errorf("unknown queryable %q", p.text())
+	}
+	return cql2.Property{Name: path}, nil
+}
+
+// literalOperand types a literal after the property it is compared
+// with: numbers for numeric properties, strings otherwise
+func literalOperand(property string, value string) cql2.Operand {
+	if _, t, ok := metadata.ResolveField(property); ok {
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				return cql2.Literal{Value: f}
+			}
+		}
+	}
+	return cql2.Literal{Value: value}
+}
+
+// likePattern translates a PropertyIsLike pattern to a CQL2 LIKE pattern
+func likePattern(pattern, wildCard, singleChar, escapeChar string) string {
+	if wildCard == "" {
+		wildCard = "*"
+	}
+	if singleChar == "" {
+		singleChar = "?"
+	}
+	if escapeChar == "" {
+		escapeChar = "\\"
+	}
+
+	var b strings.Builder
+	escaped := false
+	for _, r := range pattern {
+		c := string(r)
+		switch {
+		case escaped:
+			if c == "%" || c == "_" || c == "\\" {
+				b.WriteRune('\\')
+			}
+			b.WriteString(c)
+			escaped = false
+		case c == escapeChar:
+			escaped = true
+		case c == wildCard:
+			b.WriteRune('%')
+		case c == singleChar:
+			b.WriteRune('_')
+		case c == "%" || c == "_" || c == "\\":
+			b.WriteRune('\\')
+			b.WriteString(c)
+		default:
+			b.WriteString(c)
+		}
+	}
+	return b.String()
+}
+
+// ExtractAnyText removes the AnyText predicates of an expression,
+// returning the remaining expression (nil if none) and the text they
+// search for.  AnyText predicates are supported at the top level of an
+// expression or of a conjunction
+func ExtractAnyText(e cql2.Expr) (cql2.Expr, string, error) {
+	if term, ok := anyTextTerm(e); ok {
+		return nil, term, nil
+	}
+
+	and, ok := e.(cql2.And)
+	if !ok {
+		return e, "", checkAnyText(e)
+	}
+
+	var args []cql2.Expr
+	var terms []string
+	for _, arg := range and.Args {
+		if term, ok := anyTextTerm(arg); ok {
+			terms = append(terms, term)
+			continue
+		}
+		if err := checkAnyText(arg); err != nil {
+			return nil, "", err
+		}
+		args = append(args, arg)
+	}
+
+	term := strings.Join(terms, " ")
+	switch len(args) {
+	case 0:
+		return nil, term, nil
+	case 1:
+		return args[0], term, nil
+	}
+	return cql2.And{Args: args}, term, nil
+}
+
+// anyTextTerm returns the text searched by an AnyText predicate
+func anyTextTerm(e cql2.Expr) (string, bool) {
+	switch n := e.(type) {
+	case cql2.Like:
+		if p, ok := n.Value.(cql2.Property); ok && isAnyText(p.Name) {
+			replacer := strings.NewReplacer("\\%", "%", "\\_", "_", "%", " ", "_", " ")
+			return strings.TrimSpace(replacer.Replace(n.Pattern)), true
+		}
+	case cql2.Comparison:
+		if p, ok := n.Left.(cql2.Property); ok && isAnyText(p.Name) && n.Op == "=" {
+			if l, ok := n.Right.(cql2.Literal); ok {
+				return fmt.Sprint(l.Value), true
+			}
+		}
+	}
+	return "", false
+}
+
+// checkAnyText reports AnyText predicates which cannot be extracted
+func checkAnyText(e cql2.Expr) error {
+	for _, name := range cql2.Properties(e) {
+		if isAnyText(name) {
+			return fmt.Errorf("%s is only supported in conjunctions", AnyText)
+		}
+	}
+	return nil
+}
+
+func isAnyText(name string) bool {
+	path, ok := ResolveQueryable(name)
+	return ok && path == AnyText
+}
+
+// ParseCQL parses a CQL_TEXT constraint, resolving CSW queryables to
+// record properties
+func ParseCQL(text string) (cql2.Expr, error) {
+	e, err := cql2.ParseText(text)
+	if err != nil {
+		return nil, err
+	}
+	return resolveExpr(e)
+}
+
+// resolveExpr resolves the queryables referenced by an expression
+func resolveExpr(e cql2.Expr) (cql2.Expr, error) {
+	var err error
+	resolveArgs := func(args []cql2.Expr) []cql2.Expr {
+		resolved := make([]cql2.Expr, len(args))
+		for i, arg := range args {
+			if err == nil {
+				resolved[i], err = resolveExpr(arg)
+			}
+		}
+		return resolved
+	}
+	resolve := func(o cql2.Operand) cql2.Operand {
+		p, ok := o.(cql2.Property)
+		if !ok || err != nil {
+			return o
+		}
+		path, found := ResolveQueryable(p.Name)
+		if !found {
+			err = fmt.Errorf("unknown queryable %q", p.Name)
+			return o
+		}
+		return cql2.Property{Name: path}
+	}
+
+	switch n := e.(type) {
+	case cql2.And:
+		e = cql2.And{Args: resolveArgs(n.Args)}
+	case cql2.Or:
+		e = cql2.Or{Args: resolveArgs(n.Args)}
+	case cql2.Not:
+		var arg cql2.Expr
+		arg, err = resolveExpr(n.Arg)
+		e = cql2.Not{Arg: arg}
+	case cql2.Comparison:
+		e = cql2.Comparison{Op: n.Op, Left: resolve(n.Left), Right: resolve(n.Right)}
+	case cql2.Like:
+		e = cql2.Like{Value: resolve(n.Value), Pattern: n.Pattern}
+	case cql2.In:
+		list := make([]cql2.Operand, len(n.List))
+		for i, o := range n.List {
+			list[i] = resolve(o)
+		}
+		e = cql2.In{Value: resolve(n.Value), List: list}
+	case cql2.Between:
+		e = cql2.Between{Value: resolve(n.Value), Low: resolve(n.Low), High: resolve(n.High)}
+	case cql2.IsNull:
+		e = cql2.IsNull{Value: resolve(n.Value)}
+	case cql2.Spatial:
+		e = cql2.Spatial{Op: n.Op, Left: resolve(n.Left), Right: resolve(n.Right)}
+	case cql2.Temporal:
+		e = cql2.Temporal{Op: n.Op, Left: resolve(n.Left), Right: resolve(n.Right)}
+	}
+	return e, err
+}
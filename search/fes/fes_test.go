@@ -0,0 +1,163 @@
+package fes_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/search/cql2"
+	"github.com/go-spatial/geocatalogo/search/fes"
+)
+
+func TestParseFilter(t *testing.T) {
+	envelope := [4]float64{-10, 40, 5, 50}
+	cases := []struct {
+		filter   string
+		expected cql2.Expr
+	}{
+		{
+			`<ogc:Filter xmlns:ogc="http://www.opengis.net/ogc">
+				<ogc:And>
+					<ogc:PropertyIsLike wildCard="*" singleChar="?" escapeChar="\">
+						<ogc:PropertyName>dc:title</ogc:PropertyName>
+						<ogc:Literal>Fire*_?</ogc:Literal>
+					</ogc:PropertyIsLike>
+					<ogc:PropertyIsGreaterThanOrEqualTo>
+						<ogc:PropertyName>apiso:Modified</ogc:PropertyName>
+						<ogc:Literal>2019-01-01</ogc:Literal>
+					</ogc:PropertyIsGreaterThanOrEqualTo>
+				</ogc:And>
+			</ogc:Filter>`,
+			cql2.And{Args: []cql2.Expr{
+				cql2.Like{Value: cql2.Property{Name: "properties.title"}, Pattern: `Fire%\__`},
+				cql2.Comparison{Op: ">=", Left: cql2.Property{Name: "properties.modified"}, Right: cql2.Literal{Value: "2019-01-01"}},
+			}},
+		},
+		{
+			`<fes:Filter xmlns:fes="http://www.opengis.net/fes/2.0">
+				<fes:PropertyIsLessThan>
+					<fes:ValueReference>properties.product_info.cloud_cover</fes:ValueReference>
+					<fes:Literal>10</fes:Literal>
+				</fes:PropertyIsLessThan>
+			</fes:Filter>`,
+			cql2.Comparison{Op: "<", Left: cql2.Property{Name: "properties.product_info.cloud_cover"}, Right: cql2.Literal{Value: 10.0}},
+		},
+		{
+			// urn:ogc:def:crs:EPSG::4326 is latitude, longitude
+			`<ogc:Filter xmlns:ogc="http://www.opengis.net/ogc" xmlns:gml="http://www.opengis.net/gml">
+				<ogc:BBOX>
+					<ogc:PropertyName>ows:BoundingBox</ogc:PropertyName>
+					<gml:Envelope srsName="urn:ogc:def:crs:EPSG::4326">
+						<gml:lowerCorner>40 -10</gml:lowerCorner>
+						<gml:upperCorner>50 5</gml:upperCorner>
+					</gml:Envelope>
+				</ogc:BBOX>
+			</ogc:Filter>`,
+			cql2.Spatial{Op: "s_intersects", Left: cql2.Property{Name: "geometry"},
+				Right: cql2.Geometry{Geometry: metadata.NewEnvelope(envelope), BBox: &envelope}},
+		},
+		{
+			`<ogc:Filter xmlns:ogc="http://www.opengis.net/ogc" xmlns:gml="http://www.opengis.net/gml">
+				<ogc:Not>
+					<ogc:Within>
+						<ogc:PropertyName>ows:BoundingBox</ogc:PropertyName>
+						<gml:Polygon>
+							<gml:exterior><gml:LinearRing>
+								<gml:posList>0 0 10 0 10 10 0 10 0 0</gml:posList>
+							</gml:LinearRing></gml:exterior>
+						</gml:Polygon>
+					</ogc:Within>
+				</ogc:Not>
+			</ogc:Filter>`,
+			cql2.Not{Arg: cql2.Spatial{Op: "s_within", Left: cql2.Property{Name: "geometry"},
+				Right: cql2.Geometry{Geometry: metadata.NewPolygon([]metadata.Position{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}})}}},
+		},
+		{
+			`<ogc:Filter xmlns:ogc="http://www.opengis.net/ogc">
+				<ogc:FeatureId fid="a"/><ogc:FeatureId fid="b"/>
+			</ogc:Filter>`,
+			cql2.In{Value: cql2.Property{Name: "id"}, List: []cql2.Operand{cql2.Literal{Value: "a"}, cql2.Literal{Value: "b"}}},
+		},
+		{
+			`<ogc:Filter xmlns:ogc="http://www.opengis.net/ogc">
+				<ogc:PropertyIsBetween>
+					<ogc:PropertyName>apiso:TempExtent_begin</ogc:PropertyName>
+					<ogc:LowerBoundary><ogc:Literal>2000-01-01</ogc:Literal></ogc:LowerBoundary>
+					<ogc:UpperBoundary><ogc:Literal>2010-01-01</ogc:Literal></ogc:UpperBoundary>
+				</ogc:PropertyIsBetween>
+			</ogc:Filter>`,
+			cql2.Between{Value: cql2.Property{Name: "properties.temporal_extent.begin"},
+				Low: cql2.Literal{Value: "2000-01-01"}, High: cql2.Literal{Value: "2010-01-01"}},
+		},
+	}
+	for _, c := range cases {
+		e, err := fes.ParseFilter([]byte(c.filter))
+		if err != nil {
+			t.Errorf("%s: %v", c.filter, err)
+			continue
+		}
+		if !reflect.DeepEqual(e, c.expected) {
+			t.Errorf("expected %#v, got %#v", c.expected, e)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	cases := []string{
+		`not xml`,
+		`<Query/>`,
+		`<Filter><PropertyIsEqualTo><PropertyName>dc:unknown</PropertyName><Literal>a</Literal></PropertyIsEqualTo></Filter>`,
+		`<Filter><PropertyIsEqualTo><PropertyName>dc:title</PropertyName></PropertyIsEqualTo></Filter>`,
+		`<Filter><And><PropertyIsNull><PropertyName>dc:title</PropertyName></PropertyIsNull></And></Filter>`,
+		`<Filter><BBOX><Envelope><lowerCorner>0 0</lowerCorner></Envelope></BBOX></Filter>`,
+		`<Filter><Beyond><PropertyName>ows:BoundingBox</PropertyName></Beyond></Filter>`,
+	}
+	for _, c := range cases {
+		if _, err := fes.ParseFilter([]byte(c)); err == nil {
+			t.Errorf("expected error for %s", c)
+		}
+	}
+}
+
+func TestParseCQL(t *testing.T) {
+	e, err := fes.ParseCQL(`dc:title LIKE 'Fire%' AND apiso:Type = 'dataset'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := cql2.And{Args: []cql2.Expr{
+		cql2.Like{Value: cql2.Property{Name: "properties.title"}, Pattern: "Fire%"},
+		cql2.Comparison{Op: "=", Left: cql2.Property{Name: "properties.type"}, Right: cql2.Literal{Value: "dataset"}},
+	}}
+	if !reflect.DeepEqual(e, expected) {
+		t.Errorf("expected %#v, got %#v", expected, e)
+	}
+	if _, err := fes.ParseCQL(`dc:unknown = 'a'`); err == nil {
+		t.Error("expected error for unknown queryable")
+	}
+}
+
+func TestExtractAnyText(t *testing.T) {
+	title := cql2.Comparison{Op: "=", Left: cql2.Property{Name: "properties.title"}, Right: cql2.Literal{Value: "a"}}
+	anyText := cql2.Like{Value: cql2.Property{Name: fes.AnyText}, Pattern: "%birds%"}
+
+	e, term, err := fes.ExtractAnyText(anyText)
+	if err != nil || e != nil || term != "birds" {
+		t.Errorf("expected term birds only, got %v %q (%v)", e, term, err)
+	}
+
+	e, term, err = fes.ExtractAnyText(cql2.And{Args: []cql2.Expr{anyText, title}})
+	if err != nil || !reflect.DeepEqual(e, title) || term != "birds" {
+		t.Errorf("expected title filter and term birds, got %v %q (%v)", e, term, err)
+	}
+
+	if _, _, err = fes.ExtractAnyText(cql2.Or{Args: []cql2.Expr{anyText, title}}); err == nil {
+		t.Error("expected error for AnyText in a disjunction")
+	}
+}
+
+func TestSpatialOperators(t *testing.T) {
+	operators := fes.SpatialOperators([]string{"s_intersects", "s_within", "s_contains", "s_disjoint"})
+	if expected := []string{"BBOX", "Intersects", "Within", "Contains", "Disjoint"}; !reflect.DeepEqual(operators, expected) {
+		t.Errorf("expected %v, got %v", expected, operators)
+	}
+}
@@ -0,0 +1,206 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package fes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/search/cql2"
+)
+
+// latLonCRS lists the identifiers of EPSG:4326 whose axis order is
+// latitude, longitude.  The short form EPSG:4326 is longitude, latitude
+var latLonCRS = map[string]bool{
+	"urn:ogc:def:crs:EPSG::4326":                 true,
+	"urn:ogc:def:crs:EPSG:6.6:4326":              true,
+	"urn:x-ogc:def:crs:EPSG:4326":                true,
+	"urn:x-ogc:def:crs:EPSG:6.6:4326":            true,
+	"http://www.opengis.net/def/crs/EPSG/0/4326": true,
+}
+
+// gmlGeometry translates a GML Envelope, Box, Point, LineString or
+// Polygon
+func gmlGeometry(n *node) (cql2.Geometry, error) {
+	latLon := latLonCRS[n.attr("srsName")]
+
+	switch n.XMLName.Local {
+	case "Envelope", "Box":
+		var positions []metadata.Position
+		var err error
+		if lower, upper := n.child("lowerCorner"), n.child("upperCorner"); lower != nil && upper != nil {
+			positions, err = parsePositions(lower.text()+" "+upper.text(), 2, latLon)
+		} else {
+			positions, err = nodePositions(n, latLon)
+		}
+		if err != nil {
+			return cql2.Geometry{}, err
+		}
+		if len(positions) != 2 {
+			return cql2.Geometry{}, fmt.Errorf("%s requires two corners", n.XMLName.Local)
+		}
+		b := [4]float64{positions[0][0], positions[0][1], positions[1][0], positions[1][1]}
+		return cql2.Geometry{Geometry: metadata.NewEnvelope(b), BBox: &b}, nil
+	case "Point":
+		positions, err := nodePositions(n, latLon)
+		if err != nil {
+			return cql2.Geometry{}, err
+		}
+		if len(positions) != 1 {
+			return cql2.Geometry{}, fmt.Errorf("Point requires one position")
+		}
+		return cql2.Geometry{Geometry: metadata.NewPoint(positions[0][0], positions[0][1])}, nil
+	case "LineString":
+		positions, err := nodePositions(n, latLon)
+		if err != nil {
+			return cql2.Geometry{}, err
+		}
+		if len(positions) < 2 {
+			return cql2.Geometry{}, fmt.Errorf("LineString requires at least two positions")
+		}
+		return cql2.Geometry{Geometry: metadata.NewLineString(positions...)}, nil
+	case "Polygon":
+		var rings [][]metadata.Position
+		for _, boundary := range n.Nodes {
+			switch boundary.XMLName.Local {
+			case "exterior", "outerBoundaryIs", "interior", "innerBoundaryIs":
+			default:
+				continue
+			}
+			ring := boundary.child("LinearRing")
+			if ring == nil {
+				return cql2.Geometry{}, fmt.Errorf("Polygon boundaries require a LinearRing")
+			}
+			positions, err := nodePositions(ring, latLon)
+			if err != nil {
+				return cql2.Geometry{}, err
+			}
+			if len(positions) < 4 {
+				return cql2.Geometry{}, fmt.Errorf("LinearRing requires at least four positions")
+			}
+			if boundary.XMLName.Local == "exterior" || boundary.XMLName.Local == "outerBoundaryIs" {
+				rings = append([][]metadata.Position{positions}, rings...)
+			} else {
+				rings = append(rings, positions)
+			}
+		}
+		if len(rings) == 0 {
+			return cql2.Geometry{}, fmt.Errorf("Polygon requires an exterior")
+		}
+		return cql2.Geometry{Geometry: metadata.NewPolygon(rings...)}, nil
+	}
+	return cql2.Geometry{}, fmt.Errorf("unsupported geometry %s", n.XMLName.Local)
+}
+
+// nodePositions reads the positions of a geometry from its posList,
+// pos or coordinates children
+func nodePositions(n *node, latLon bool) ([]metadata.Position, error) {
+	if posList := n.child("posList"); posList != nil {
+		dimension := 2
+		if d, err := strconv.Atoi(posList.attr("srsDimension")); err == nil && d > 0 {
+			dimension = d
+		}
+		return parsePositions(posList.text(), dimension, latLon)
+	}
+	if coordinates := n.child("coordinates"); coordinates != nil {
+		cs, ts := coordinates.attr("cs"), coordinates.attr("ts")
+		if cs == "" {
+			cs = ","
+		}
+		var positions []metadata.Position
+		var tuples []string
+		if ts == "" {
+			tuples = strings.Fields(coordinates.text())
+		} else {
+			tuples = strings.Split(coordinates.text(), ts)
+		}
+		for _, tuple := range tuples {
+			p, err := parsePositions(strings.Replace(strings.TrimSpace(tuple), cs, " ", -1), 0, latLon)
+			if err != nil {
+				return nil, err
+			}
+			positions = append(positions, p...)
+		}
+		return positions, nil
+	}
+
+	var positions []metadata.Position
+	for _, child := range n.Nodes {
+		if child.XMLName.Local == "pos" || child.XMLName.Local == "coord" {
+			text := child.text()
+			if child.XMLName.Local == "coord" {
+				var x, y string
+				if c := child.child("X"); c != nil {
+					x = c.text()
+				}
+				if c := child.child("Y"); c != nil {
+					y = c.text()
+				}
+				text = x + " " + y
+			}
+			p, err := parsePositions(text, 0, latLon)
+			if err != nil {
+				return nil, err
+			}
+			positions = append(positions, p...)
+		}
+	}
+	if len(positions) == 0 {
+		return nil, fmt.Errorf("%s has no coordinates", n.XMLName.Local)
+	}
+	return positions, nil
+}
+
+// parsePositions parses whitespace separated ordinates into positions
+// of the given dimension, or a single position if dimension is 0
+func parsePositions(text string, dimension int, latLon bool) ([]metadata.Position, error) {
+	var ordinates []float64
+	for _, field := range strings.Fields(text) {
+		f, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coordinate %q", field)
+		}
+		ordinates = append(ordinates, f)
+	}
+	if dimension == 0 {
+		dimension = len(ordinates)
+	}
+	if dimension < 2 || len(ordinates)%dimension != 0 {
+		return nil, fmt.Errorf("invalid coordinates %q", text)
+	}
+
+	var positions []metadata.Position
+	for i := 0; i < len(ordinates); i += dimension {
+		if latLon {
+			positions = append(positions, metadata.Position{ordinates[i+1], ordinates[i]})
+		} else {
+			positions = append(positions, metadata.Position{ordinates[i], ordinates[i+1]})
+		}
+	}
+	return positions, nil
+}
@@ -0,0 +1,242 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+// Package fulltext provides text analysis and an inverted index with
+// BM25 relevance ranking
+package fulltext
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Token is an analyzed term and its position within a text
+type Token struct {
+	Term     string
+	Position int
+}
+
+// Analyzer tokenizes, normalizes, filters stopwords from and stems text
+// for a given language
+type Analyzer struct {
+	Language  string
+	stopwords map[string]bool
+	stem      func(string) string
+}
+
+var analyzers = map[string]*Analyzer{
+	"en": {Language: "en", stopwords: wordSet(englishStopwords), stem: stemPorter},
+	"fr": {Language: "fr", stopwords: wordSet(frenchStopwords), stem: stemFrench},
+	"es": {Language: "es", stopwords: wordSet(spanishStopwords), stem: stemSpanish},
+	"de": {Language: "de", stopwords: wordSet(germanStopwords), stem: stemGerman},
+}
+
+// standardAnalyzer tokenizes and lowercases text without stopwords
+// or stemming, for languages without specific support
+var standardAnalyzer = &Analyzer{Language: "", stopwords: map[string]bool{}, stem: func(s string) string { return s }}
+
+// languageCodes maps ISO 639-2 codes and names to ISO 639-1 codes
+var languageCodes = map[string]string{
+	"eng": "en", "english": "en",
+	"fre": "fr", "fra": "fr", "french": "fr",
+	"spa": "es", "spanish": "es",
+	"ger": "de", "deu": "de", "german": "de",
+}
+
+// NormalizeLanguage returns the ISO 639-1 code of a language given as
+// ISO 639-1/639-2, a language tag (en-US) or an English name
+func NormalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if i := strings.IndexAny(language, "-_"); i > 0 {
+		language = language[:i]
+	}
+	if code, ok := languageCodes[language]; ok {
+		return code
+	}
+	return language
+}
+
+// AnalyzerFor returns the Analyzer for a language, or a standard
+// analyzer (lowercasing only) for unsupported languages
+func AnalyzerFor(language string) *Analyzer {
+	if a, ok := analyzers[NormalizeLanguage(language)]; ok {
+		return a
+	}
+	return standardAnalyzer
+}
+
+// Analyze splits text into lowercased, stemmed tokens.  Stopwords are
+// dropped but still advance the position, so that phrases spanning
+// stopwords match with the same gaps
+func (a *Analyzer) Analyze(text string) []Token {
+	var tokens []Token
+
+	for position, word := range Tokenize(text) {
+		if a.stopwords[word] {
+			continue
+		}
+		tokens = append(tokens, Token{Term: a.stem(word), Position: position})
+	}
+	return tokens
+}
+
+// IsStopword reports whether a lowercased word is a stopword
+func (a *Analyzer) IsStopword(word string) bool {
+	return a.stopwords[word]
+}
+
+// Tokenize splits text on anything other than letters and digits
+// and lowercases the resulting words
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// englishStopwords are the default English stopwords of Lucene/Elasticsearch
+const englishStopwords = `a an and are as at be but by for if in into is it
+no not of on or such that the their then there these they this to was will with`
+
+const frenchStopwords = `au aux avec ce ces dans de des du elle en et eux il je
+la le les leur lui ma mais me meme même mes moi mon ne nos notre nous on ou où par
+pas pour qu que qui sa se ses son sur ta te tes toi ton tu un une vos votre vous
+c d j l à m n s t y été est sont`
+
+const spanishStopwords = `de la que el en y a los del se las por un para con no
+una su al lo como mas más pero sus le ya o este si sí porque esta entre cuando muy
+sin sobre tambien también me hasta hay donde quien desde todo nos durante todos
+uno les ni contra otros ese eso ante ellos e esto antes algunos unos otro otras otra`
+
+const germanStopwords = `aber alle allem als also am an auch auf aus bei bin bis
+bist da damit dann der den des dem die das dass daß du durch ein eine einem einen
+einer eines er es für hat hatte ich ihr im in ist ja kann kein mit nach nicht noch
+nur oder sich sie sind so über um und uns unter vom von vor war was wenn wer wie
+wir wird zu zum zur`
+
+// stemFrench implements a light French stemmer (after J. Savoy),
+// removing plural and feminine inflections
+func stemFrench(word string) string {
+	r := []rune(word)
+	n := len(r)
+	if n > 4 && strings.HasSuffix(word, "aux") {
+		return string(r[:n-3]) + "al"
+	}
+	if n > 3 && (r[n-1] == 's' || r[n-1] == 'x') {
+		r = r[:n-1]
+		n--
+	}
+	if n > 3 && r[n-1] == 'e' {
+		r = r[:n-1]
+		n--
+	}
+	if n > 3 && r[n-1] == 'é' {
+		r = r[:n-1]
+		n--
+	}
+	if n > 3 && r[n-1] == r[n-2] && !isVowel(r[n-1]) {
+		r = r[:n-1]
+	}
+	return string(r)
+}
+
+// stemSpanish implements a light Spanish stemmer removing plural
+// and gender inflections
+func stemSpanish(word string) string {
+	r := []rune(foldAccents(word))
+	n := len(r)
+	switch {
+	case n > 5 && strings.HasSuffix(string(r), "ces"):
+		r = append(r[:n-3], 'z')
+		n -= 2
+	case n > 4 && strings.HasSuffix(string(r), "es") && !isVowel(r[n-3]):
+		r = r[:n-2]
+		n -= 2
+	case n > 3 && r[n-1] == 's':
+		r = r[:n-1]
+		n--
+	}
+	if n > 3 && (r[n-1] == 'a' || r[n-1] == 'o' || r[n-1] == 'e') {
+		r = r[:n-1]
+	}
+	return string(r)
+}
+
+// stemGerman implements a light German stemmer (after Lucene's
+// GermanLightStemmer), folding umlauts and removing inflectional suffixes
+func stemGerman(word string) string {
+	r := []rune(foldAccents(strings.Replace(word, "ß", "ss", -1)))
+	n := len(r)
+
+	sEnding := func(c rune) bool { return strings.ContainsRune("bdfghklmnrt", c) }
+	stEnding := func(c rune) bool { return strings.ContainsRune("bdfghklmnt", c) }
+	ends := func(suffix string) bool { return strings.HasSuffix(string(r[:n]), suffix) }
+
+	switch {
+	case n > 5 && ends("ern"):
+		n -= 3
+	case n > 4 && (ends("em") || ends("en") || ends("er") || ends("es")):
+		n -= 2
+	case n > 3 && ends("e"):
+		n--
+	case n > 3 && ends("s") && sEnding(r[n-2]):
+		n--
+	}
+
+	switch {
+	case n > 5 && ends("est"):
+		n -= 3
+	case n > 4 && (ends("er") || ends("en")):
+		n -= 2
+	case n > 4 && ends("st") && stEnding(r[n-3]):
+		n -= 2
+	}
+	return string(r[:n])
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aeiouyàâäéèêëîïôöùûüáíóú", r)
+}
+
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ñ", "n", "ç", "c",
+)
+
+// foldAccents replaces accented latin characters with their base letter
+func foldAccents(word string) string {
+	return accents.Replace(word)
+}
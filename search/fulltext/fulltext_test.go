@@ -0,0 +1,126 @@
+package fulltext_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/geocatalogo/search/fulltext"
+)
+
+func TestEnglishStemming(t *testing.T) {
+	a := fulltext.AnalyzerFor("en-US")
+	cases := map[string]string{
+		"caresses":    "caress",
+		"ponies":      "poni",
+		"cats":        "cat",
+		"agreed":      "agre",
+		"hopping":     "hop",
+		"filing":      "file",
+		"happy":       "happi",
+		"relational":  "relat",
+		"conditional": "condit",
+		"generalize":  "gener",
+		"wildfires":   "wildfir",
+		"flooding":    "flood",
+		"adjustment":  "adjust",
+		"controlling": "control",
+	}
+	for word, stem := range cases {
+		tokens := a.Analyze(word)
+		if len(tokens) != 1 || tokens[0].Term != stem {
+			t.Errorf("%s: expected %q, got %v", word, stem, tokens)
+		}
+	}
+}
+
+func TestAnalyzeStopwordsAndPositions(t *testing.T) {
+	tokens := fulltext.AnalyzerFor("en").Analyze("State of the Art, mapping")
+	expected := []fulltext.Token{{Term: "state", Position: 0}, {Term: "art", Position: 3}, {Term: "map", Position: 4}}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("expected %v, got %v", expected, tokens)
+	}
+
+	tokens = fulltext.AnalyzerFor("fre").Analyze("les données")
+	if len(tokens) != 1 || tokens[0].Term != "don" {
+		t.Errorf("expected French stopword removal and stemming, got %v", tokens)
+	}
+}
+
+func ids(hits []fulltext.Hit) []string {
+	var result []string
+	for _, h := range hits {
+		result = append(result, h.ID)
+	}
+	return result
+}
+
+func testIndex() *fulltext.Index {
+	ix := fulltext.NewIndex("en", map[string]float64{"title": 2})
+	ix.Add("fire-ca", "en", map[string]string{
+		"title":    "California wildfire perimeters",
+		"abstract": "Wildfire perimeters for the state of California",
+	})
+	ix.Add("fire-global", "en", map[string]string{
+		"title":    "Global wildfires",
+		"abstract": "Active fire detections worldwide",
+	})
+	ix.Add("flood-ca", "", map[string]string{
+		"title":    "California flood zones",
+		"abstract": "Flood hazard areas",
+	})
+	ix.Add("incendies", "fr", map[string]string{
+		"title": "Feux de forêt en Californie",
+	})
+	return ix
+}
+
+func TestSearchRanking(t *testing.T) {
+	ix := testIndex()
+
+	hits := ix.Search("wildfire california")
+	if got := ids(hits); !reflect.DeepEqual(got, []string{"fire-ca", "fire-global", "flood-ca"}) {
+		t.Errorf("unexpected ranking: %v", got)
+	}
+	if hits[0].Score <= hits[1].Score {
+		t.Errorf("expected descending scores, got %v", hits)
+	}
+}
+
+func TestSearchOperators(t *testing.T) {
+	ix := testIndex()
+
+	cases := map[string][]string{
+		`"wildfire perimeters"`:         {"fire-ca"},
+		`"perimeters wildfire"`:         nil,
+		`"state of california"`:         {"fire-ca"},
+		`"state of the california"`:     nil,
+		`wild*`:                         {"fire-ca", "fire-global"},
+		`+california +flood`:            {"flood-ca"},
+		`california AND wildfire`:       {"fire-ca"},
+		`california -wildfire`:          {"flood-ca"},
+		`california NOT flood`:          {"fire-ca"},
+		`forêts`:                        {"incendies"},
+		`the`:                           nil,
+		`FLOODING`:                      {"flood-ca"},
+		`"california wildfire" +global`: {"fire-global"},
+		`+"california wildfire" global`: {"fire-ca"},
+	}
+	for query, expected := range cases {
+		if got := ids(ix.Search(query)); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected %v, got %v", query, expected, got)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	ix := testIndex()
+	ix.Remove("fire-ca")
+	ix.Add("flood-ca", "en", map[string]string{"title": "Coastal flood zones"})
+
+	if got := ids(ix.Search("california")); len(got) != 0 {
+		t.Errorf("expected no matches after removal and replacement, got %v", got)
+	}
+	if ix.Len() != 3 {
+		t.Errorf("expected 3 documents, got %d", ix.Len())
+	}
+}
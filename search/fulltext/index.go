@@ -0,0 +1,373 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package fulltext
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// BM25 parameters, as used by Elasticsearch
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// fieldPosting holds the positions of a term within a document field
+type fieldPosting struct {
+	field     string
+	positions []int
+}
+
+// document holds the per-document statistics needed for scoring
+// and removal
+type document struct {
+	language string
+	lengths  map[string]int
+	keys     []string
+}
+
+// Index provides an inverted index over documents made up of named text
+// fields.  Each document is analyzed according to its language, and
+// terms are keyed by language so that queries can be analyzed once per
+// indexed language
+type Index struct {
+	defaultLanguage string
+	boosts          map[string]float64
+	postings        map[string]map[string][]fieldPosting
+	docs            map[string]*document
+	fieldLengths    map[string]int
+	languages       map[string]int
+}
+
+// NewIndex creates an Index.  Documents without a (supported) language
+// are analyzed according to defaultLanguage.  boosts gives the relative
+// weight of each field (default 1)
+func NewIndex(defaultLanguage string, boosts map[string]float64) *Index {
+	return &Index{
+		defaultLanguage: defaultLanguage,
+		boosts:          boosts,
+		postings:        make(map[string]map[string][]fieldPosting),
+		docs:            make(map[string]*document),
+		fieldLengths:    make(map[string]int),
+		languages:       make(map[string]int),
+	}
+}
+
+// Len returns the number of indexed documents
+func (ix *Index) Len() int {
+	return len(ix.docs)
+}
+
+func (ix *Index) analyzer(language string) *Analyzer {
+	if a, ok := analyzers[NormalizeLanguage(language)]; ok {
+		return a
+	}
+	return AnalyzerFor(ix.defaultLanguage)
+}
+
+func termKey(language, term string) string {
+	return language + ":" + term
+}
+
+// Add indexes a document, replacing any existing document with the
+// same identifier
+func (ix *Index) Add(id string, language string, fields map[string]string) {
+	ix.Remove(id)
+
+	analyzer := ix.analyzer(language)
+	doc := &document{language: analyzer.Language, lengths: make(map[string]int)}
+
+	for field, text := range fields {
+		tokens := analyzer.Analyze(text)
+		if len(tokens) == 0 {
+			continue
+		}
+		doc.lengths[field] = len(tokens)
+		ix.fieldLengths[field] += len(tokens)
+
+		positions := make(map[string][]int)
+		for _, token := range tokens {
+			positions[token.Term] = append(positions[token.Term], token.Position)
+		}
+		for term, p := range positions {
+			key := termKey(doc.language, term)
+			if ix.postings[key] == nil {
+				ix.postings[key] = make(map[string][]fieldPosting)
+			}
+			if len(ix.postings[key][id]) == 0 {
+				doc.keys = append(doc.keys, key)
+			}
+			ix.postings[key][id] = append(ix.postings[key][id], fieldPosting{field: field, positions: p})
+		}
+	}
+
+	ix.docs[id] = doc
+	ix.languages[doc.language]++
+}
+
+// Remove removes a document from the index
+func (ix *Index) Remove(id string) {
+	doc, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for _, key := range doc.keys {
+		delete(ix.postings[key], id)
+		if len(ix.postings[key]) == 0 {
+			delete(ix.postings, key)
+		}
+	}
+	for field, length := range doc.lengths {
+		ix.fieldLengths[field] -= length
+	}
+	ix.languages[doc.language]--
+	if ix.languages[doc.language] == 0 {
+		delete(ix.languages, doc.language)
+	}
+	delete(ix.docs, id)
+}
+
+// Hit is a matching document and its relevance score
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Search evaluates a query (see Parse) against the index, returning
+// matching documents ordered by descending score, then identifier
+func (ix *Index) Search(query string) []Hit {
+	var hits []Hit
+
+	scores := ix.evaluate(Parse(query))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+func (ix *Index) evaluate(q Query) map[string]float64 {
+	var must []map[string]float64
+	var should []map[string]float64
+	var mustNot []map[string]float64
+
+	for _, c := range q.Clauses {
+		scores, ok := ix.clauseScores(c)
+		if !ok { // clause consists only of stopwords
+			continue
+		}
+		switch c.Occur {
+		case Must:
+			must = append(must, scores)
+		case MustNot:
+			mustNot = append(mustNot, scores)
+		default:
+			should = append(should, scores)
+		}
+	}
+
+	results := make(map[string]float64)
+
+	if len(must) > 0 {
+		for id, score := range must[0] {
+			results[id] = score
+		}
+		for _, scores := range must[1:] {
+			for id := range results {
+				if s, ok := scores[id]; ok {
+					results[id] += s
+				} else {
+					delete(results, id)
+				}
+			}
+		}
+		for _, scores := range should {
+			for id, s := range scores {
+				if _, ok := results[id]; ok {
+					results[id] += s
+				}
+			}
+		}
+	} else {
+		for _, scores := range should {
+			for id, s := range scores {
+				results[id] += s
+			}
+		}
+	}
+
+	for _, scores := range mustNot {
+		for id := range scores {
+			delete(results, id)
+		}
+	}
+	return results
+}
+
+// clauseScores scores all documents matching a clause across all indexed
+// languages.  ok is false if the clause analyzes to nothing
+func (ix *Index) clauseScores(c Clause) (map[string]float64, bool) {
+	scores := make(map[string]float64)
+	analyzed := false
+
+	for language := range ix.languages {
+		analyzer := standardAnalyzer
+		if a, ok := analyzers[language]; ok {
+			analyzer = a
+		}
+
+		if c.Prefix {
+			analyzed = true
+			ix.prefixScores(language, c.Terms[0], scores)
+			continue
+		}
+
+		tokens := analyzer.Analyze(strings.Join(c.Terms, " "))
+		if len(tokens) == 0 {
+			continue
+		}
+		analyzed = true
+
+		if len(tokens) == 1 {
+			key := termKey(language, tokens[0].Term)
+			for id, postings := range ix.postings[key] {
+				scores[id] += ix.bm25(key, id, postings, nil)
+			}
+		} else {
+			ix.phraseScores(language, tokens, scores)
+		}
+	}
+	return scores, analyzed
+}
+
+// prefixScores scores documents containing any term starting with prefix,
+// using the best scoring expansion for each document
+func (ix *Index) prefixScores(language string, prefix string, scores map[string]float64) {
+	keyPrefix := termKey(language, strings.ToLower(prefix))
+	for key, docs := range ix.postings {
+		if !strings.HasPrefix(key, keyPrefix) {
+			continue
+		}
+		for id, postings := range docs {
+			if s := ix.bm25(key, id, postings, nil); s > scores[id] {
+				scores[id] = s
+			}
+		}
+	}
+}
+
+// phraseScores scores documents containing all tokens at their relative
+// positions within the same field
+func (ix *Index) phraseScores(language string, tokens []Token, scores map[string]float64) {
+	keys := make([]string, len(tokens))
+	for i, token := range tokens {
+		keys[i] = termKey(language, token.Term)
+		if len(ix.postings[keys[i]]) == 0 {
+			return
+		}
+	}
+
+	for id, first := range ix.postings[keys[0]] {
+		frequencies := make(map[string]int)
+		for _, fp := range first {
+			for _, start := range fp.positions {
+				if ix.phraseAt(keys, tokens, id, fp.field, start) {
+					frequencies[fp.field]++
+				}
+			}
+		}
+		if len(frequencies) == 0 {
+			continue
+		}
+		for _, key := range keys {
+			scores[id] += ix.bm25(key, id, nil, frequencies)
+		}
+	}
+}
+
+// phraseAt reports whether the phrase occurs in a document field with its
+// first token at position start
+func (ix *Index) phraseAt(keys []string, tokens []Token, id string, field string, start int) bool {
+	for i := 1; i < len(keys); i++ {
+		want := start + tokens[i].Position - tokens[0].Position
+		found := false
+		for _, fp := range ix.postings[keys[i]][id] {
+			if fp.field != field {
+				continue
+			}
+			for _, p := range fp.positions {
+				if p == want {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// bm25 computes the BM25 score of a term for a document, summed over
+// fields weighted by their boosts.  Term frequencies are taken from
+// postings, or from frequencies (per field) if given
+func (ix *Index) bm25(key string, id string, postings []fieldPosting, frequencies map[string]int) float64 {
+	n := float64(len(ix.docs))
+	df := float64(len(ix.postings[key]))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+	doc := ix.docs[id]
+
+	if frequencies == nil {
+		frequencies = make(map[string]int, len(postings))
+		for _, fp := range postings {
+			frequencies[fp.field] += len(fp.positions)
+		}
+	}
+
+	score := 0.0
+	for field, freq := range frequencies {
+		tf := float64(freq)
+		avg := float64(ix.fieldLengths[field]) / n
+		norm := 1 - bm25B
+		if avg > 0 {
+			norm += bm25B * float64(doc.lengths[field]) / avg
+		}
+		boost := 1.0
+		if b, ok := ix.boosts[field]; ok {
+			boost = b
+		}
+		score += boost * idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+	}
+	return score
+}
@@ -0,0 +1,261 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package fulltext
+
+// stemPorter implements the Porter stemming algorithm for English
+// (M.F. Porter, 1980, "An algorithm for suffix stripping").
+// Words which are not plain lowercase ASCII are returned unchanged
+func stemPorter(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	p := &porter{b: []byte(word)}
+	p.step1a()
+	p.step1b()
+	p.step1c()
+	p.step2()
+	p.step3()
+	p.step4()
+	p.step5()
+	return string(p.b)
+}
+
+type porter struct {
+	b []byte
+}
+
+// isConsonant reports whether b[i] is a consonant
+func (p *porter) isConsonant(i int) bool {
+	switch p.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !p.isConsonant(i-1)
+	}
+	return true
+}
+
+// measure returns the number of VC sequences in b[:n]
+func (p *porter) measure(n int) int {
+	m := 0
+	i := 0
+	for i < n && p.isConsonant(i) {
+		i++
+	}
+	for i < n {
+		for i < n && !p.isConsonant(i) {
+			i++
+		}
+		if i >= n {
+			break
+		}
+		for i < n && p.isConsonant(i) {
+			i++
+		}
+		m++
+	}
+	return m
+}
+
+// hasVowel reports whether b[:n] contains a vowel
+func (p *porter) hasVowel(n int) bool {
+	for i := 0; i < n; i++ {
+		if !p.isConsonant(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleConsonant reports whether b[:n] ends with a double consonant
+func (p *porter) doubleConsonant(n int) bool {
+	return n >= 2 && p.b[n-1] == p.b[n-2] && p.isConsonant(n-1)
+}
+
+// cvc reports whether b[:n] ends consonant-vowel-consonant, where the
+// final consonant is not w, x or y
+func (p *porter) cvc(n int) bool {
+	if n < 3 || !p.isConsonant(n-1) || p.isConsonant(n-2) || !p.isConsonant(n-3) {
+		return false
+	}
+	switch p.b[n-1] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+func (p *porter) endsWith(suffix string) bool {
+	return len(p.b) >= len(suffix) && string(p.b[len(p.b)-len(suffix):]) == suffix
+}
+
+// replace substitutes suffix (which b must end with) with replacement
+func (p *porter) replace(suffix, replacement string) {
+	p.b = append(p.b[:len(p.b)-len(suffix)], replacement...)
+}
+
+// replaceFirst applies the first rule whose suffix matches if the stem
+// measure exceeds min.  Only the longest matching suffix is considered
+func (p *porter) replaceFirst(rules [][2]string, min int) {
+	for _, rule := range rules {
+		if p.endsWith(rule[0]) {
+			if p.measure(len(p.b)-len(rule[0])) > min {
+				p.replace(rule[0], rule[1])
+			}
+			return
+		}
+	}
+}
+
+func (p *porter) step1a() {
+	switch {
+	case p.endsWith("sses"):
+		p.replace("sses", "ss")
+	case p.endsWith("ies"):
+		p.replace("ies", "i")
+	case p.endsWith("ss"):
+	case p.endsWith("s"):
+		p.replace("s", "")
+	}
+}
+
+func (p *porter) step1b() {
+	if p.endsWith("eed") {
+		if p.measure(len(p.b)-3) > 0 {
+			p.replace("eed", "ee")
+		}
+		return
+	}
+
+	removed := false
+	for _, suffix := range []string{"ed", "ing"} {
+		if p.endsWith(suffix) && p.hasVowel(len(p.b)-len(suffix)) {
+			p.replace(suffix, "")
+			removed = true
+			break
+		}
+	}
+	if !removed {
+		return
+	}
+
+	n := len(p.b)
+	switch {
+	case p.endsWith("at"), p.endsWith("bl"), p.endsWith("iz"):
+		p.b = append(p.b, 'e')
+	case p.doubleConsonant(n):
+		switch p.b[n-1] {
+		case 'l', 's', 'z':
+		default:
+			p.b = p.b[:n-1]
+		}
+	case p.measure(n) == 1 && p.cvc(n):
+		p.b = append(p.b, 'e')
+	}
+}
+
+func (p *porter) step1c() {
+	if p.endsWith("y") && p.hasVowel(len(p.b)-1) {
+		p.replace("y", "i")
+	}
+}
+
+var porterStep2 = longestFirst([][2]string{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+	{"izer", "ize"}, {"bli", "ble"}, {"alli", "al"}, {"entli", "ent"},
+	{"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+	{"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"},
+	{"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+	{"logi", "log"},
+})
+
+var porterStep3 = longestFirst([][2]string{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+	{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+})
+
+var porterStep4 = longestFirst([][2]string{
+	{"al", ""}, {"ance", ""}, {"ence", ""}, {"er", ""}, {"ic", ""},
+	{"able", ""}, {"ible", ""}, {"ant", ""}, {"ement", ""}, {"ment", ""},
+	{"ent", ""}, {"ion", ""}, {"ou", ""}, {"ism", ""}, {"ate", ""},
+	{"iti", ""}, {"ous", ""}, {"ive", ""}, {"ize", ""},
+})
+
+func (p *porter) step2() {
+	p.replaceFirst(porterStep2, 0)
+}
+
+func (p *porter) step3() {
+	p.replaceFirst(porterStep3, 0)
+}
+
+func (p *porter) step4() {
+	for _, rule := range porterStep4 {
+		if !p.endsWith(rule[0]) {
+			continue
+		}
+		n := len(p.b) - len(rule[0])
+		if p.measure(n) > 1 {
+			if rule[0] != "ion" || (n > 0 && (p.b[n-1] == 's' || p.b[n-1] == 't')) {
+				p.replace(rule[0], "")
+			}
+		}
+		return
+	}
+}
+
+func (p *porter) step5() {
+	n := len(p.b)
+	if p.endsWith("e") {
+		m := p.measure(n - 1)
+		if m > 1 || (m == 1 && !p.cvc(n-1)) {
+			p.b = p.b[:n-1]
+		}
+	}
+	n = len(p.b)
+	if p.measure(n) > 1 && p.doubleConsonant(n) && p.b[n-1] == 'l' {
+		p.b = p.b[:n-1]
+	}
+}
+
+// longestFirst orders rules so that longer suffixes are matched before
+// their shorter tails (e.g. "ement" before "ment" before "ent")
+func longestFirst(rules [][2]string) [][2]string {
+	sorted := make([][2]string, len(rules))
+	copy(sorted, rules)
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && len(sorted[j][0]) > len(sorted[j-1][0]); j-- {
+			sorted[j], sorted[j-1] = sorted[j-1], sorted[j]
+		}
+	}
+	return sorted
+}
@@ -0,0 +1,129 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package fulltext
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Occurrence of a clause in a query
+const (
+	Should = iota
+	Must
+	MustNot
+)
+
+// Clause is a term, phrase or prefix query component
+type Clause struct {
+	Occur  int
+	Terms  []string
+	Prefix bool
+}
+
+// Query is a parsed full text query
+type Query struct {
+	Clauses []Clause
+}
+
+// Parse parses a query in a subset of the Lucene query string syntax:
+// whitespace separated terms match any (OR), "quoted phrases" match
+// consecutive terms, a trailing * matches terms by prefix, + or AND
+// requires a term and - or NOT excludes it
+func Parse(query string) Query {
+	var q Query
+	var occur = Should
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '+':
+			occur = Must
+			i++
+			continue
+		case r == '-':
+			occur = MustNot
+			i++
+			continue
+		}
+
+		var text string
+		phrase := false
+		if r == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			text = string(runes[i+1 : end])
+			phrase = true
+			i = end + 1
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) {
+				end++
+			}
+			text = string(runes[i:end])
+			i = end
+		}
+
+		if !phrase {
+			switch text {
+			case "AND":
+				// AND makes both the previous and next clause required
+				if len(q.Clauses) > 0 && q.Clauses[len(q.Clauses)-1].Occur == Should {
+					q.Clauses[len(q.Clauses)-1].Occur = Must
+				}
+				occur = Must
+				continue
+			case "OR":
+				continue
+			case "NOT":
+				occur = MustNot
+				continue
+			}
+		}
+
+		c := Clause{Occur: occur, Terms: strings.Fields(text)}
+		occur = Should
+
+		if !phrase && strings.HasSuffix(text, "*") {
+			prefix := strings.ToLower(strings.TrimRight(text, "*"))
+			if prefix == "" || len(Tokenize(prefix)) != 1 {
+				continue
+			}
+			c.Terms = []string{Tokenize(prefix)[0]}
+			c.Prefix = true
+		}
+		if len(c.Terms) > 0 {
+			q.Clauses = append(q.Clauses, c)
+		}
+	}
+	return q
+}
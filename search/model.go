@@ -26,9 +26,42 @@
 package search
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
 	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/search/cql2"
 )
 
+// Query provides the search criteria.  Empty criteria do not constrain
+// the search
+type Query struct {
+	// Collections restricts results to any of the given collections
+	Collections []string
+	// Term is a full text search term
+	Term string
+	// BBox is a bounding box (minx, miny, maxx, maxy)
+	BBox []float64
+	// Intersects is a query geometry
+	Intersects *metadata.Geometry
+	// Relation is the spatial relation of record geometries to BBox or
+	// Intersects: one of the Relation constants (default intersects)
+	Relation string
+	// Time is a time instant, or a range of two times
+	Time []time.Time
+	// Filter is a CQL2 filter expression
+	Filter cql2.Expr
+	// SortBy orders results; by default results are ordered by
+	// relevance or identifier
+	SortBy []SortField
+	// From is the offset of the first result
+	From int
+	// Size is the maximum number of results
+	Size int
+}
+
 // Results provides the search result structure
 type Results struct {
 	ElapsedTime int
@@ -38,8 +71,101 @@ type Results struct {
 	Records     []metadata.Record
 }
 
+// Spatial relations of record geometries to a query geometry
+const (
+	RelationIntersects = "intersects"
+	RelationWithin     = "within"
+	RelationContains   = "contains"
+	RelationDisjoint   = "disjoint"
+)
+
+// ParseRelation validates a spatial relation; empty defaults to
+// intersects
+func ParseRelation(relation string) (string, error) {
+	switch r := strings.ToLower(strings.TrimSpace(relation)); r {
+	case "":
+		return RelationIntersects, nil
+	case RelationIntersects, RelationWithin, RelationContains, RelationDisjoint:
+		return r, nil
+	}
+	return "", fmt.Errorf("unsupported spatial relation %q (should be intersects, within, contains or disjoint)", relation)
+}
+
 // Exception provides the error messaging structure
 type Exception struct {
 	Code        int
 	Description string
 }
+
+// SortField provides a sort key, as a dotted path of JSON property
+// names of metadata.Record (e.g. properties.datetime)
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+// ParseSortBy parses a comma-separated list of sort keys, each either
+// prefixed with + (ascending, default) or - (descending), e.g.
+// -properties.datetime,id, or suffixed with :A or :D (CSW), e.g.
+// title:D.  Fields are validated against the metadata.Record model
+func ParseSortBy(sortby string) ([]SortField, error) {
+	var fields []SortField
+
+	for _, token := range strings.Split(sortby, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		sf := SortField{}
+		switch {
+		case strings.HasPrefix(token, "-"):
+			sf.Descending = true
+			token = token[1:]
+		case strings.HasPrefix(token, "+"):
+			token = token[1:]
+		}
+		if i := strings.LastIndex(token, ":"); i > 0 {
+			switch strings.ToUpper(token[i+1:]) {
+			case "A", "ASC":
+				token = token[:i]
+			case "D", "DESC":
+				sf.Descending = true
+				token = token[:i]
+			}
+		}
+		field, err := NewSortField(token, sf.Descending)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// NewSortField validates a field against the metadata.Record model
+// and returns its SortField.  Only scalar fields (text, numbers,
+// booleans and times) are sortable
+func NewSortField(field string, descending bool) (SortField, error) {
+	canonical, t, ok := metadata.ResolveField(strings.TrimSpace(field))
+	if !ok {
+		return SortField{}, fmt.Errorf("invalid sort field: %s", field)
+	}
+	if !sortable(t) {
+		return SortField{}, fmt.Errorf("sort field %s is not sortable", field)
+	}
+	return SortField{Field: canonical, Descending: descending}, nil
+}
+
+// sortable reports whether values of a type can be ordered
+func sortable(t reflect.Type) bool {
+	if t == reflect.TypeOf(time.Time{}) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
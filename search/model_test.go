@@ -0,0 +1,38 @@
+package search_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/geocatalogo/search"
+)
+
+func TestParseSortBy(t *testing.T) {
+	cases := map[string][]search.SortField{
+		"-properties.datetime,id": {{Field: "properties.datetime", Descending: true}, {Field: "id"}},
+		"+title":                  {{Field: "properties.title"}},
+		"title:D, product_info.cloud_cover:A": {
+			{Field: "properties.title", Descending: true},
+			{Field: "properties.product_info.cloud_cover"},
+		},
+		"": nil,
+	}
+	for sortby, expected := range cases {
+		fields, err := search.ParseSortBy(sortby)
+		if err != nil {
+			t.Errorf("%s: %v", sortby, err)
+		}
+		if !reflect.DeepEqual(fields, expected) {
+			t.Errorf("%s: expected %v, got %v", sortby, expected, fields)
+		}
+	}
+
+	if _, err := search.ParseSortBy("-properties.unknown"); err == nil {
+		t.Error("expected error for unknown field")
+	}
+	for _, field := range []string{"geometry", "bbox", "properties.keywords", "properties.product_info"} {
+		if _, err := search.ParseSortBy(field); err == nil {
+			t.Errorf("expected error for non-scalar field %s", field)
+		}
+	}
+}
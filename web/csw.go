@@ -0,0 +1,1037 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+// Package web - simple HTTP Wrapper
+package web
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-spatial/geocatalogo"
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/writers"
+	"github.com/go-spatial/geocatalogo/repository"
+	"github.com/go-spatial/geocatalogo/search"
+	"github.com/go-spatial/geocatalogo/search/cql2"
+	"github.com/go-spatial/geocatalogo/search/fes"
+)
+
+// CSW versions
+const (
+	CSW2 string = "2.0.2"
+	CSW3 string = "3.0.0"
+)
+
+// CSW namespaces
+const (
+	namespaceCSW2  = "http://www.opengis.net/cat/csw/2.0.2"
+	namespaceCSW3  = "http://www.opengis.net/cat/csw/3.0"
+	namespaceOWS1  = "http://www.opengis.net/ows"
+	namespaceOWS2  = "http://www.opengis.net/ows/2.0"
+	namespaceOGC   = "http://www.opengis.net/ogc"
+	namespaceFES   = "http://www.opengis.net/fes/2.0"
+	namespaceDC    = "http://purl.org/dc/elements/1.1/"
+	namespaceDCT   = "http://purl.org/dc/terms/"
+	namespaceXLink = "http://www.w3.org/1999/xlink"
+	namespaceXSD   = "http://www.w3.org/2001/XMLSchema"
+	namespaceGML   = "http://www.opengis.net/gml"
+)
+
+// cswDomainSample is the number of records from which the values of a
+// property are listed by GetDomain
+const cswDomainSample = 1000
+
+// cswRequestMaxSize is the maximum size of an XML (POST) request
+const cswRequestMaxSize = 32 << 20
+
+// cswElementSets lists the supported element set names
+var cswElementSets = []string{"brief", "summary", "full"}
+
+// cswResultTypes lists the supported result types
+var cswResultTypes = []string{"hits", "results"}
+
+// cswOperations lists the supported operations by version
+var cswOperations = map[string][]string{
+	CSW2: {"GetCapabilities", "DescribeRecord", "GetRecords", "GetRecordById", "GetDomain"},
+	CSW3: {"GetCapabilities", "GetRecords", "GetRecordById", "GetDomain"},
+}
+
+// cswTransactionOperations lists the operations which modify the
+// catalogue, available when transactions are enabled
+var cswTransactionOperations = []string{"Transaction", "Harvest"}
+
+// cswException is an OWS exception
+type cswException struct {
+	Code    string
+	Locator string
+	Text    string
+}
+
+func (e *cswException) Error() string {
+	return e.Text
+}
+
+// cswStatus returns the HTTP status of an exception (OWS Common 2.0);
+// CSW 2.0.2 exceptions are reported with 200, except authorization
+// failures
+func (e *cswException) status(version string) int {
+	if e.Code == "AuthorizationFailed" {
+		return 401
+	}
+	if version == CSW2 {
+		return 200
+	}
+	switch e.Code {
+	case "MissingParameterValue", "InvalidParameterValue", "VersionNegotiationFailed":
+		return 400
+	case "OperationNotSupported":
+		return 501
+	case "NotFound":
+		return 404
+	}
+	return 500
+}
+
+// cswQueryError converts a failed query to an exception
+func cswQueryError(err error) *cswException {
+	if errors.Is(err, repository.ErrInvalidQuery) {
+		return invalidParameter("Constraint", "%s", err)
+	}
+	return &cswException{"NoApplicableCode", "", fmt.Sprintf("query error: %s", err)}
+}
+
+func missingParameter(name string) *cswException {
+	return &cswException{"MissingParameterValue", name, fmt.Sprintf("missing %s parameter", name)}
+}
+
+func invalidParameter(name string, format string, args ...interface{}) *cswException {
+	return &cswException{"InvalidParameterValue", name, fmt.Sprintf(format, args...)}
+}
+
+// CSWRequest provides a CSW request, decoded from its KVP or XML
+// encoding
+type CSWRequest struct {
+	Service         string
+	Version         string
+	Request         string
+	AcceptVersions  []string
+	TypeNames       []string
+	ElementSetName  string
+	OutputSchema    string
+	OutputFormat    string
+	ResultType      string
+	StartPosition   int
+	MaxRecords      int
+	Term            string
+	Constraint      cql2.Expr
+	SortBy          []search.SortField
+	Ids             []string
+	ParameterName   string
+	PropertyName    string
+	RequestId       string
+	Actions         []CSWAction
+	Source          string
+	ResourceType    string
+	ResourceFormat  string
+	ResponseHandler string
+	HarvestInterval string
+}
+
+// cswXMLRequest provides the XML encoding of CSW requests
+type cswXMLRequest struct {
+	XMLName        xml.Name
+	Service        string   `xml:"service,attr"`
+	RequestId      string   `xml:"requestId,attr"`
+	Version        string   `xml:"version,attr"`
+	ResultType     string   `xml:"resultType,attr"`
+	OutputSchema   string   `xml:"outputSchema,attr"`
+	OutputFormat   string   `xml:"outputFormat,attr"`
+	StartPosition  string   `xml:"startPosition,attr"`
+	MaxRecords     string   `xml:"maxRecords,attr"`
+	AcceptVersions []string `xml:"AcceptVersions>Version"`
+	Query          *struct {
+		TypeNames      string            `xml:"typeNames,attr"`
+		ElementSetName string            `xml:"ElementSetName"`
+		Constraint     *cswXMLConstraint `xml:"Constraint"`
+		SortBy         []struct {
+			PropertyName   string `xml:"PropertyName"`
+			ValueReference string `xml:"ValueReference"`
+			SortOrder      string `xml:"SortOrder"`
+		} `xml:"SortBy>SortProperty"`
+	} `xml:"Query"`
+	Ids             []string `xml:"Id"`
+	ElementSetName  string   `xml:"ElementSetName"`
+	TypeName        []string `xml:"TypeName"`
+	ParameterName   string   `xml:"ParameterName"`
+	PropertyName    string   `xml:"PropertyName"`
+	Source          string   `xml:"Source"`
+	ResourceType    string   `xml:"ResourceType"`
+	ResourceFormat  string   `xml:"ResourceFormat"`
+	ResponseHandler string   `xml:"ResponseHandler"`
+	HarvestInterval string   `xml:"HarvestInterval"`
+}
+
+// cswXMLConstraint provides the XML encoding of a constraint, either an
+// OGC Filter or CQL text
+type cswXMLConstraint struct {
+	Inner   []byte `xml:",innerxml"`
+	CqlText string `xml:"CqlText"`
+}
+
+// expr parses a constraint into a CQL2 expression
+func (c *cswXMLConstraint) expr() (cql2.Expr, error) {
+	if text := strings.TrimSpace(c.CqlText); text != "" {
+		return fes.ParseCQL(text)
+	}
+	return fes.ParseFilter(c.Inner)
+}
+
+// ParseCSWKVP decodes a CSW request from its KVP encoding; parameter
+// names are case insensitive
+func ParseCSWKVP(values map[string][]string) (CSWRequest, error) {
+	kvp := make(map[string]string)
+	for k, v := range values {
+		if len(v) > 0 {
+			kvp[strings.ToLower(k)] = v[0]
+		}
+	}
+
+	req := CSWRequest{
+		Service:         kvp["service"],
+		Version:         kvp["version"],
+		Request:         kvp["request"],
+		ElementSetName:  kvp["elementsetname"],
+		OutputSchema:    kvp["outputschema"],
+		OutputFormat:    kvp["outputformat"],
+		ResultType:      kvp["resulttype"],
+		Term:            kvp["q"],
+		ParameterName:   kvp["parametername"],
+		PropertyName:    kvp["propertyname"],
+		Source:          kvp["source"],
+		ResourceType:    kvp["resourcetype"],
+		ResourceFormat:  kvp["resourceformat"],
+		ResponseHandler: kvp["responsehandler"],
+		HarvestInterval: kvp["harvestinterval"],
+	}
+	if v := kvp["acceptversions"]; v != "" {
+		req.AcceptVersions = strings.Split(v, ",")
+	}
+	for _, name := range []string{"typenames", "typename"} {
+		if v := kvp[name]; v != "" {
+			req.TypeNames = strings.Split(v, ",")
+		}
+	}
+	if v := kvp["id"]; v != "" {
+		req.Ids = strings.Split(v, ",")
+	} else if v := kvp["recordids"]; v != "" {
+		req.Ids = strings.Split(v, ",")
+	}
+
+	var err error
+	if req.StartPosition, err = cswInteger(kvp["startposition"], "startPosition", 1); err != nil {
+		return req, err
+	}
+	if req.MaxRecords, err = cswInteger(kvp["maxrecords"], "maxRecords", 10); err != nil {
+		return req, err
+	}
+
+	if v := kvp["constraint"]; v != "" {
+		switch strings.ToUpper(kvp["constraintlanguage"]) {
+		case "FILTER":
+			req.Constraint, err = fes.ParseFilter([]byte(v))
+		case "CQL_TEXT":
+			req.Constraint, err = fes.ParseCQL(v)
+		case "":
+			return req, missingParameter("constraintLanguage")
+		default:
+			return req, invalidParameter("constraintLanguage", "unsupported constraint language %s", kvp["constraintlanguage"])
+		}
+		if err != nil {
+			return req, invalidParameter("constraint", "invalid constraint: %s", err)
+		}
+	}
+
+	if v := kvp["sortby"]; v != "" {
+		if req.SortBy, err = cswSortBy(strings.Split(v, ",")); err != nil {
+			return req, err
+		}
+	}
+	return req, nil
+}
+
+// ParseCSWXML decodes a CSW request from its XML encoding
+func ParseCSWXML(data []byte) (CSWRequest, error) {
+	var x cswXMLRequest
+	if err := xml.Unmarshal(data, &x); err != nil {
+		return CSWRequest{}, &cswException{"NoApplicableCode", "", fmt.Sprintf("invalid XML request: %s", err)}
+	}
+
+	req := CSWRequest{
+		Service:         x.Service,
+		Version:         x.Version,
+		Request:         x.XMLName.Local,
+		AcceptVersions:  x.AcceptVersions,
+		ElementSetName:  x.ElementSetName,
+		OutputSchema:    x.OutputSchema,
+		OutputFormat:    x.OutputFormat,
+		ResultType:      x.ResultType,
+		Ids:             x.Ids,
+		TypeNames:       x.TypeName,
+		ParameterName:   x.ParameterName,
+		PropertyName:    x.PropertyName,
+		RequestId:       x.RequestId,
+		Source:          strings.TrimSpace(x.Source),
+		ResourceType:    strings.TrimSpace(x.ResourceType),
+		ResourceFormat:  strings.TrimSpace(x.ResourceFormat),
+		ResponseHandler: strings.TrimSpace(x.ResponseHandler),
+		HarvestInterval: strings.TrimSpace(x.HarvestInterval),
+	}
+
+	var err error
+	if req.StartPosition, err = cswInteger(x.StartPosition, "startPosition", 1); err != nil {
+		return req, err
+	}
+	if req.MaxRecords, err = cswInteger(x.MaxRecords, "maxRecords", 10); err != nil {
+		return req, err
+	}
+	if req.Request == "Transaction" {
+		if req.Actions, err = parseCSWTransaction(data); err != nil {
+			return req, err
+		}
+	}
+
+	if q := x.Query; q != nil {
+		if q.TypeNames != "" {
+			req.TypeNames = strings.Fields(q.TypeNames)
+		}
+		req.ElementSetName = strings.TrimSpace(q.ElementSetName)
+		if c := q.Constraint; c != nil {
+			if req.Constraint, err = c.expr(); err != nil {
+				return req, invalidParameter("Constraint", "invalid constraint: %s", err)
+			}
+		}
+		var tokens []string
+		for _, sp := range q.SortBy {
+			name := strings.TrimSpace(sp.PropertyName + sp.ValueReference)
+			switch strings.ToUpper(strings.TrimSpace(sp.SortOrder)) {
+			case "DESC":
+				tokens = append(tokens, name+":D")
+			default:
+				tokens = append(tokens, name+":A")
+			}
+		}
+		if len(tokens) > 0 {
+			if req.SortBy, err = cswSortBy(tokens); err != nil {
+				return req, err
+			}
+		}
+	}
+	return req, nil
+}
+
+// cswInteger parses a positive integer parameter
+func cswInteger(value string, name string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	i, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || i < 0 {
+		return 0, invalidParameter(name, "%s must be a non-negative integer", name)
+	}
+	return i, nil
+}
+
+// cswSortBy resolves sort keys (queryable:A or queryable:D)
+func cswSortBy(tokens []string) ([]search.SortField, error) {
+	var fields []search.SortField
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		descending := false
+		if i := strings.LastIndex(token, ":"); i > 0 {
+			switch strings.ToUpper(token[i+1:]) {
+			case "A", "ASC":
+				token = token[:i]
+			case "D", "DESC":
+				token, descending = token[:i], true
+			}
+		}
+		path, ok := fes.ResolveQueryable(token)
+		if !ok || path == fes.AnyText {
+			return nil, invalidParameter("sortBy", "cannot sort by %s", token)
+		}
+		field, err := search.NewSortField(path, descending)
+		if err != nil {
+			return nil, invalidParameter("sortBy", "%s", err)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// negotiateVersion selects the version of a request.  GetCapabilities
+// negotiates by AcceptVersions, defaulting to the latest version
+func negotiateVersion(req *CSWRequest) error {
+	if req.Request == "GetCapabilities" && req.Version == "" {
+		if len(req.AcceptVersions) == 0 {
+			req.Version = CSW3
+			return nil
+		}
+		for _, v := range req.AcceptVersions {
+			if version := cswVersion(strings.TrimSpace(v)); version != "" {
+				req.Version = version
+				return nil
+			}
+		}
+		return &cswException{"VersionNegotiationFailed", "acceptVersions", "no supported version (2.0.2, 3.0.0) in acceptVersions"}
+	}
+	if req.Version == "" {
+		req.Version = CSW3
+		return nil
+	}
+	version := cswVersion(req.Version)
+	if version == "" {
+		return invalidParameter("version", "unsupported version %s", req.Version)
+	}
+	req.Version = version
+	return nil
+}
+
+func cswVersion(v string) string {
+	switch v {
+	case "2.0.2":
+		return CSW2
+	case "3.0.0", "3.0":
+		return CSW3
+	}
+	return ""
+}
+
+// cswNamespace returns the CSW namespace of a version
+func cswNamespace(version string) string {
+	if version == CSW2 {
+		return namespaceCSW2
+	}
+	return namespaceCSW3
+}
+
+// CSWHandler provides the CSW 2.0.2 and 3.0 KVP (GET) and XML (POST)
+// bindings
+func CSWHandler(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatalogue) {
+	var req CSWRequest
+	var err error
+
+	if r.Method == "POST" {
+		var body []byte
+		body, err = ioutil.ReadAll(http.MaxBytesReader(w, r.Body, cswRequestMaxSize))
+		if err != nil {
+			err = &cswException{"NoApplicableCode", "request", fmt.Sprintf("could not read request: %s", err)}
+		} else {
+			req, err = ParseCSWXML(body)
+		}
+	} else {
+		req, err = ParseCSWKVP(r.URL.Query())
+	}
+	if err == nil {
+		err = validateCSWRequest(&req)
+	}
+	if err == nil && contains(cswTransactionOperations, req.Request) {
+		err = authorizeTransaction(r, cat)
+	}
+	if err != nil {
+		emitCSWException(w, cat, req.Version, err)
+		return
+	}
+
+	var response writers.Element
+	switch req.Request {
+	case "GetCapabilities":
+		response = cswCapabilities(cat, req.Version)
+	case "DescribeRecord":
+		response = cswDescribeRecord(req.Version)
+	case "GetRecords":
+		response, err = cswGetRecords(r, cat, &req)
+	case "GetRecordById":
+		response, err = cswGetRecordById(cat, &req)
+	case "GetDomain":
+		response, err = cswGetDomain(r, cat, &req)
+	case "Transaction":
+		response, err = cswTransaction(r, cat, &req)
+	case "Harvest":
+		response, err = cswHarvest(r, cat, &req)
+	}
+	if err != nil {
+		emitCSWException(w, cat, req.Version, err)
+		return
+	}
+	emitCSW(w, cat, 200, response)
+	return
+}
+
+// validateCSWRequest checks the common parameters of a request
+func validateCSWRequest(req *CSWRequest) error {
+	if req.Request == "" {
+		return missingParameter("request")
+	}
+	if req.Service == "" && req.Request != "GetCapabilities" {
+		req.Service = "CSW"
+	}
+	if req.Service != "" && req.Service != "CSW" {
+		return invalidParameter("service", "unsupported service %s", req.Service)
+	}
+	if err := negotiateVersion(req); err != nil {
+		return err
+	}
+
+	supported := false
+	for _, op := range append(cswOperations[req.Version], cswTransactionOperations...) {
+		if strings.EqualFold(op, req.Request) {
+			req.Request, supported = op, true
+		}
+	}
+	if !supported {
+		return &cswException{"OperationNotSupported", "request", fmt.Sprintf("operation %s not supported by CSW %s", req.Request, req.Version)}
+	}
+
+	for _, typeName := range req.TypeNames {
+		if typeName != "csw:Record" && typeName != "Record" {
+			return invalidParameter("typeNames", "unsupported type %s (should be csw:Record)", typeName)
+		}
+	}
+	if req.OutputSchema != "" && !contains(cswOutputSchemas(req.Version), req.OutputSchema) {
+		return invalidParameter("outputSchema", "unsupported output schema %s", req.OutputSchema)
+	}
+	if req.OutputFormat != "" && !strings.HasPrefix(req.OutputFormat, "application/xml") && req.OutputFormat != "text/xml" {
+		return invalidParameter("outputFormat", "unsupported output format %s", req.OutputFormat)
+	}
+	if req.ElementSetName != "" && !contains(cswElementSets, req.ElementSetName) {
+		return invalidParameter("ElementSetName", "unsupported element set %s", req.ElementSetName)
+	}
+	if req.ResultType != "" && !contains(cswResultTypes, req.ResultType) {
+		return invalidParameter("resultType", "unsupported result type %s", req.ResultType)
+	}
+	return nil
+}
+
+// cswGetRecords searches records
+func cswGetRecords(r *http.Request, cat *geocatalogo.GeoCatalogue, req *CSWRequest) (writers.Element, error) {
+	if req.StartPosition < 1 {
+		return writers.Element{}, invalidParameter("startPosition", "startPosition must be at least 1")
+	}
+	if cat.Config.Server.Limit > 0 && req.MaxRecords > cat.Config.Server.Limit {
+		req.MaxRecords = cat.Config.Server.Limit
+	}
+	elementSet := req.ElementSetName
+	if elementSet == "" {
+		elementSet = "summary"
+	}
+
+	query := search.Query{
+		Term:   req.Term,
+		SortBy: req.SortBy,
+		From:   req.StartPosition - 1,
+		Size:   req.MaxRecords,
+	}
+	if req.Constraint != nil {
+		filter, term, err := fes.ExtractAnyText(req.Constraint)
+		if err != nil {
+			return writers.Element{}, invalidParameter("constraint", "%s", err)
+		}
+		query.Filter = filter
+		query.Term = strings.TrimSpace(query.Term + " " + term)
+	}
+	if req.ResultType == "hits" {
+		query.Size = 0
+	}
+
+	results, err := cat.Query(r.Context(), query)
+	if err != nil {
+		return writers.Element{}, cswQueryError(err)
+	}
+
+	returned := len(results.Records)
+	nextRecord := req.StartPosition + returned
+	status := "subset"
+	if nextRecord > results.Matches {
+		nextRecord, status = 0, "complete"
+	}
+	if results.Matches == 0 {
+		status = "none"
+	}
+
+	recordSchema := cswNamespace(req.Version)
+	if _, ok := cswOutputSchema(req.OutputSchema); ok {
+		recordSchema = req.OutputSchema
+	}
+	searchResults := writers.El("csw:SearchResults", "").
+		Attr("numberOfRecordsMatched", strconv.Itoa(results.Matches)).
+		Attr("numberOfRecordsReturned", strconv.Itoa(returned)).
+		Attr("nextRecord", strconv.Itoa(nextRecord)).
+		Attr("recordSchema", recordSchema).
+		Attr("elementSet", elementSet)
+	if req.Version == CSW3 {
+		searchResults = searchResults.Attr("status", status)
+	}
+	for _, rec := range results.Records {
+		searchResults.Children = append(searchResults.Children, cswRecord(&rec, req, elementSet))
+	}
+
+	response := writers.El("csw:GetRecordsResponse", "",
+		writers.El("csw:SearchStatus", "").Attr("timestamp", time.Now().UTC().Format(time.RFC3339)),
+		searchResults)
+	response = withNamespaces(response.Attr("version", req.Version), req.Version)
+	return response, nil
+}
+
+// cswGetRecordById retrieves records by identifier
+func cswGetRecordById(cat *geocatalogo.GeoCatalogue, req *CSWRequest) (writers.Element, error) {
+	if len(req.Ids) == 0 {
+		return writers.Element{}, missingParameter("id")
+	}
+	elementSet := req.ElementSetName
+	if elementSet == "" {
+		elementSet = "summary"
+	}
+
+	results := cat.Get(req.Ids)
+
+	if req.Version == CSW3 {
+		// the response is the record itself
+		if len(results.Records) == 0 {
+			return writers.Element{}, &cswException{"NotFound", "id", fmt.Sprintf("record not found: %s", strings.Join(req.Ids, ","))}
+		}
+		if _, ok := cswOutputSchema(req.OutputSchema); ok {
+			return cswRecord(&results.Records[0], req, elementSet), nil
+		}
+		return withNamespaces(Record2CSW(&results.Records[0], elementSet), req.Version), nil
+	}
+
+	response := writers.El("csw:GetRecordByIdResponse", "")
+	for _, rec := range results.Records {
+		response.Children = append(response.Children, cswRecord(&rec, req, elementSet))
+	}
+	return withNamespaces(response, req.Version), nil
+}
+
+// cswDescribeRecord describes the csw:Record type (CSW 2.0.2)
+func cswDescribeRecord(version string) writers.Element {
+	schema := writers.El("xs:schema", "",
+		writers.El("xs:include", "").Attr("schemaLocation", "http://schemas.opengis.net/csw/2.0.2/record.xsd")).
+		Attr("xmlns:xs", namespaceXSD).
+		Attr("targetNamespace", namespaceCSW2).
+		Attr("elementFormDefault", "qualified")
+
+	response := writers.El("csw:DescribeRecordResponse", "",
+		writers.El("csw:SchemaComponent", "", schema).
+			Attr("targetNamespace", namespaceCSW2).
+			Attr("schemaLanguage", "http://www.w3.org/XML/Schema"))
+	return withNamespaces(response, version)
+}
+
+// cswParameterDomains lists the values of request parameters
+var cswParameterDomains = map[string][]string{
+	"getrecords.resulttype":     cswResultTypes,
+	"getrecords.elementsetname": cswElementSets,
+	"getrecords.typenames":      {"csw:Record"},
+	"getrecords.outputschema":   {namespaceCSW2, namespaceCSW3},
+	"getrecords.outputformat":   {"application/xml"},
+	"getrecords.constraintlanguage": {
+		"FILTER", "CQL_TEXT",
+	},
+	"getrecordbyid.elementsetname": cswElementSets,
+	"getrecordbyid.outputschema":   {namespaceCSW2, namespaceCSW3},
+	"describerecord.typename":      {"csw:Record"},
+}
+
+// cswGetDomain lists the values of a request parameter, or of a
+// queryable across (a sample of) the records
+func cswGetDomain(r *http.Request, cat *geocatalogo.GeoCatalogue, req *CSWRequest) (writers.Element, error) {
+	domain := writers.El("csw:DomainValues", "").Attr("type", "csw:Record")
+
+	switch {
+	case req.ParameterName != "":
+		values, ok := cswParameterDomains[strings.ToLower(req.ParameterName)]
+		if !ok {
+			return writers.Element{}, invalidParameter("parameterName", "unknown parameter %s", req.ParameterName)
+		}
+		domain.Children = append(domain.Children, writers.El("csw:ParameterName", req.ParameterName), listOfValues(values))
+	case req.PropertyName != "":
+		path, ok := fes.ResolveQueryable(req.PropertyName)
+		if !ok || path == fes.AnyText || path == "geometry" {
+			return writers.Element{}, invalidParameter("propertyName", "unsupported property %s", req.PropertyName)
+		}
+		results, err := cat.Query(r.Context(), search.Query{Size: cswDomainSample})
+		if err != nil {
+			return writers.Element{}, cswQueryError(err)
+		}
+		seen := make(map[string]bool)
+		var values []string
+		for _, rec := range results.Records {
+			v, ok := rec.Value(path)
+			if !ok {
+				continue
+			}
+			s := fmt.Sprint(v)
+			if t, isTime := v.(time.Time); isTime {
+				s = t.Format(time.RFC3339)
+			}
+			if s != "" && !seen[s] {
+				seen[s] = true
+				values = append(values, s)
+			}
+		}
+		sort.Strings(values)
+		domain.Children = append(domain.Children, writers.El("csw:PropertyName", req.PropertyName), listOfValues(values))
+	default:
+		return writers.Element{}, missingParameter("parameterName")
+	}
+
+	return withNamespaces(writers.El("csw:GetDomainResponse", "", domain), req.Version), nil
+}
+
+func listOfValues(values []string) writers.Element {
+	list := writers.El("csw:ListOfValues", "")
+	for _, v := range values {
+		list.Children = append(list.Children, writers.El("csw:Value", v))
+	}
+	return list
+}
+
+// Record2CSW generates a csw:BriefRecord, csw:SummaryRecord or
+// csw:Record (full) in Dublin Core
+func Record2CSW(rec *metadata.Record, elementSet string) writers.Element {
+	return writers.CSWRecord(rec, elementSet)
+}
+
+// cswRecord generates a record in the outputSchema of a request,
+// Dublin Core by default
+func cswRecord(rec *metadata.Record, req *CSWRequest, elementSet string) writers.Element {
+	if w, ok := cswOutputSchema(req.OutputSchema); ok {
+		return w.Element(rec)
+	}
+	return Record2CSW(rec, elementSet)
+}
+
+// cswOutputSchema returns the writer of an outputSchema other than
+// csw:Record
+func cswOutputSchema(schema string) (writers.Writer, bool) {
+	if schema == namespaceCSW2 || schema == namespaceCSW3 {
+		return writers.Writer{}, false
+	}
+	w, ok := writers.LookupSchema(schema)
+	return w, ok && w.IsXML()
+}
+
+// cswOutputSchemas lists the output schemas of a CSW version
+func cswOutputSchemas(version string) []string {
+	schemas := []string{cswNamespace(version)}
+	for _, w := range writers.Writers() {
+		if _, ok := cswOutputSchema(w.Schema); ok {
+			schemas = append(schemas, w.Schema)
+		}
+	}
+	return schemas
+}
+
+func formatCorner(a, b float64) string {
+	return strconv.FormatFloat(a, 'f', -1, 64) + " " + strconv.FormatFloat(b, 'f', -1, 64)
+}
+
+// withNamespaces declares the namespaces of a CSW version on a root
+// element
+func withNamespaces(e writers.Element, version string) writers.Element {
+	ows := namespaceOWS2
+	if version == CSW2 {
+		ows = namespaceOWS1
+	}
+	return e.
+		Attr("xmlns:csw", cswNamespace(version)).
+		Attr("xmlns:dc", namespaceDC).
+		Attr("xmlns:dct", namespaceDCT).
+		Attr("xmlns:ows", ows)
+}
+
+// emitCSWException emits an OWS exception report
+func emitCSWException(w http.ResponseWriter, cat *geocatalogo.GeoCatalogue, version string, err error) {
+	exception, ok := err.(*cswException)
+	if !ok {
+		exception = &cswException{"NoApplicableCode", "", err.Error()}
+	}
+	ows, reportVersion := namespaceOWS2, "2.0.0"
+	if version == CSW2 {
+		ows, reportVersion = namespaceOWS1, "1.2.0"
+	}
+
+	e := writers.El("ows:Exception", "", writers.El("ows:ExceptionText", exception.Text)).
+		Attr("exceptionCode", exception.Code)
+	if exception.Locator != "" {
+		e = e.Attr("locator", exception.Locator)
+	}
+	report := writers.El("ows:ExceptionReport", "", e).
+		Attr("xmlns:ows", ows).
+		Attr("version", reportVersion).
+		Attr("xml:lang", "en")
+
+	if exception.Code == "AuthorizationFailed" {
+		if cat.Config.Server.Username != "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="geocatalogo"`)
+		} else {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="geocatalogo"`)
+		}
+	}
+	emitCSW(w, cat, exception.status(version), report)
+}
+
+// emitCSW emits an XML response
+func emitCSW(w http.ResponseWriter, cat *geocatalogo.GeoCatalogue, status int, e writers.Element) {
+	emitXML(w, cat, status, "application/xml; charset=UTF-8", e)
+}
+
+// emitXML emits an XML document of the given content type
+func emitXML(w http.ResponseWriter, cat *geocatalogo.GeoCatalogue, status int, contentType string, e writers.Element) {
+	var data []byte
+	if cat.Config.Server.PrettyPrint {
+		data, _ = xml.MarshalIndent(e, "", "    ")
+	} else {
+		data, _ = xml.Marshal(e)
+	}
+	w.Header().Set("Content-Type", contentType)
+	if cat.Config.Server.CORS == true {
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	}
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "%s%s", xml.Header, data)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// cswCapabilities describes the service
+func cswCapabilities(cat *geocatalogo.GeoCatalogue, version string) writers.Element {
+	md := cat.Config.Metadata
+	url := cat.Config.Server.URL + "/csw"
+
+	keywords := writers.El("ows:Keywords", "")
+	for _, k := range md.Identification.Keywords {
+		keywords.Children = append(keywords.Children, writers.El("ows:Keyword", k))
+	}
+	if md.Identification.KeywordsType != "" {
+		keywords.Children = append(keywords.Children, writers.El("ows:Type", md.Identification.KeywordsType))
+	}
+
+	identification := writers.El("ows:ServiceIdentification", "",
+		writers.El("ows:Title", md.Identification.Title),
+		writers.El("ows:Abstract", md.Identification.Abstract),
+		keywords,
+		writers.El("ows:ServiceType", "CSW").Attr("codeSpace", "OGC"),
+		writers.El("ows:ServiceTypeVersion", CSW2),
+		writers.El("ows:ServiceTypeVersion", CSW3),
+		writers.El("ows:Fees", md.Identification.Fees),
+		writers.El("ows:AccessConstraints", md.Identification.AccessConstraints))
+
+	c := md.Contact
+	provider := writers.El("ows:ServiceProvider", "",
+		writers.El("ows:ProviderName", md.Provider.Name),
+		writers.El("ows:ProviderSite", "").Attr("xlink:type", "simple").Attr("xlink:href", md.Provider.URL),
+		writers.El("ows:ServiceContact", "",
+			writers.El("ows:IndividualName", c.Name),
+			writers.El("ows:PositionName", c.Position),
+			writers.El("ows:ContactInfo", "",
+				writers.El("ows:Phone", "", writers.El("ows:Voice", c.Phone), writers.El("ows:Facsimile", c.Fax)),
+				writers.El("ows:Address", "",
+					writers.El("ows:DeliveryPoint", c.Address),
+					writers.El("ows:City", c.City),
+					writers.El("ows:AdministrativeArea", c.StateOrProvince),
+					writers.El("ows:PostalCode", c.PostalCode),
+					writers.El("ows:Country", c.Country),
+					writers.El("ows:ElectronicMailAddress", c.Email)),
+				writers.El("ows:OnlineResource", "").Attr("xlink:type", "simple").Attr("xlink:href", c.URL),
+				writers.El("ows:HoursOfService", c.Hours),
+				writers.El("ows:ContactInstructions", c.Instructions)),
+			writers.El("ows:Role", c.Role)))
+
+	// parameter lists the allowed values of a parameter or constraint
+	parameter := func(name string, element string, values ...string) writers.Element {
+		var list []writers.Element
+		for _, v := range values {
+			list = append(list, writers.El("ows:Value", v))
+		}
+		if version == CSW3 {
+			return writers.El(element, "", writers.El("ows:AllowedValues", "", list...)).Attr("name", name)
+		}
+		return writers.El(element, "", list...).Attr("name", name)
+	}
+	get := writers.El("ows:Get", "").Attr("xlink:type", "simple").Attr("xlink:href", url)
+	post := writers.El("ows:Post", "").Attr("xlink:type", "simple").Attr("xlink:href", url)
+	dcp := writers.El("ows:DCP", "", writers.El("ows:HTTP", "", get, post))
+	outputSchemas := cswOutputSchemas(version)
+
+	supported := cswOperations[version]
+	if transactionsEnabled(cat) {
+		supported = append(supported, cswTransactionOperations...)
+	}
+
+	operations := writers.El("ows:OperationsMetadata", "")
+	for _, op := range supported {
+		operation := writers.El("ows:Operation", "", dcp).Attr("name", op)
+		switch op {
+		case "GetCapabilities":
+			operation.Children = append(operation.Children,
+				parameter("sections", "ows:Parameter", "ServiceIdentification", "ServiceProvider", "OperationsMetadata", "Filter_Capabilities"))
+		case "DescribeRecord":
+			operation.Children = append(operation.Children,
+				parameter("typeName", "ows:Parameter", "csw:Record"),
+				parameter("outputFormat", "ows:Parameter", "application/xml"),
+				parameter("schemaLanguage", "ows:Parameter", "http://www.w3.org/XML/Schema"))
+		case "GetRecords":
+			operation.Children = append(operation.Children,
+				parameter("typeNames", "ows:Parameter", "csw:Record"),
+				parameter("outputFormat", "ows:Parameter", "application/xml"),
+				parameter("outputSchema", "ows:Parameter", outputSchemas...),
+				parameter("resultType", "ows:Parameter", cswResultTypes...),
+				parameter("ElementSetName", "ows:Parameter", cswElementSets...),
+				parameter("CONSTRAINTLANGUAGE", "ows:Parameter", "FILTER", "CQL_TEXT"),
+				parameter("SupportedDublinCoreQueryables", "ows:Constraint", fes.Queryables...))
+		case "GetRecordById":
+			operation.Children = append(operation.Children,
+				parameter("outputFormat", "ows:Parameter", "application/xml"),
+				parameter("outputSchema", "ows:Parameter", outputSchemas...),
+				parameter("ElementSetName", "ows:Parameter", cswElementSets...))
+		case "GetDomain":
+			var names []string
+			for name := range cswParameterDomains {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			operation.Children = append(operation.Children,
+				parameter("ParameterName", "ows:Parameter", names...))
+		case "Transaction":
+			operation = writers.El("ows:Operation", "",
+				writers.El("ows:DCP", "", writers.El("ows:HTTP", "", post)),
+				parameter("TransactionSchemas", "ows:Parameter", cswResourceTypes...)).Attr("name", op)
+		case "Harvest":
+			operation.Children = append(operation.Children,
+				parameter("ResourceType", "ows:Parameter", cswResourceTypes...))
+		}
+		operations.Children = append(operations.Children, operation)
+	}
+	operations.Children = append(operations.Children,
+		parameter("service", "ows:Parameter", "CSW"),
+		parameter("version", "ows:Parameter", CSW2, CSW3),
+		parameter("PostEncoding", "ows:Constraint", "XML"))
+
+	capabilities := writers.El("csw:Capabilities", "",
+		identification, provider, operations, filterCapabilities(version, fes.SpatialOperators(cat.Repository.SpatialOperators())))
+	capabilities = withNamespaces(capabilities, version).
+		Attr("xmlns:xlink", namespaceXLink).
+		Attr("version", version)
+	if version == CSW2 {
+		return capabilities.Attr("xmlns:ogc", namespaceOGC).Attr("xmlns:gml", namespaceGML)
+	}
+	return capabilities.Attr("xmlns:fes", namespaceFES).Attr("xmlns:gml", namespaceGML+"/3.2")
+}
+
+// filterCapabilities describes the supported Filter Encoding operators,
+// with the spatial operators supported by the repository
+func filterCapabilities(version string, spatial []string) writers.Element {
+	geometries := []string{"gml:Envelope", "gml:Point", "gml:LineString", "gml:Polygon"}
+
+	if version == CSW2 {
+		operands := writers.El("ogc:GeometryOperands", "")
+		for _, g := range geometries {
+			operands.Children = append(operands.Children, writers.El("ogc:GeometryOperand", g))
+		}
+		operators := writers.El("ogc:SpatialOperators", "")
+		for _, op := range spatial {
+			operators.Children = append(operators.Children, writers.El("ogc:SpatialOperator", "").Attr("name", op))
+		}
+		comparison := writers.El("ogc:ComparisonOperators", "")
+		for _, op := range []string{"EqualTo", "NotEqualTo", "LessThan", "GreaterThan", "LessThanEqualTo", "GreaterThanEqualTo", "Like", "Between", "NullCheck"} {
+			comparison.Children = append(comparison.Children, writers.El("ogc:ComparisonOperator", op))
+		}
+		return writers.El("ogc:Filter_Capabilities", "",
+			writers.El("ogc:Spatial_Capabilities", "", operands, operators),
+			writers.El("ogc:Scalar_Capabilities", "", writers.El("ogc:LogicalOperators", ""), comparison),
+			writers.El("ogc:Id_Capabilities", "", writers.El("ogc:EID", ""), writers.El("ogc:FID", "")))
+	}
+
+	conformance := writers.El("fes:Conformance", "")
+	for _, c := range []struct {
+		name  string
+		value bool
+	}{
+		{"ImplementsQuery", true},
+		{"ImplementsAdHocQuery", true},
+		{"ImplementsFunctions", false},
+		{"ImplementsResourceId", true},
+		{"ImplementsMinStandardFilter", true},
+		{"ImplementsStandardFilter", true},
+		{"ImplementsMinSpatialFilter", true},
+		{"ImplementsSpatialFilter", true},
+		{"ImplementsMinTemporalFilter", false},
+		{"ImplementsTemporalFilter", false},
+		{"ImplementsVersionNav", false},
+		{"ImplementsSorting", true},
+		{"ImplementsExtendedOperators", false},
+	} {
+		value := "FALSE"
+		if c.value {
+			value = "TRUE"
+		}
+		conformance.Children = append(conformance.Children,
+			writers.El("fes:Constraint", "", writers.El("ows:NoValues", ""), writers.El("ows:DefaultValue", value)).Attr("name", c.name))
+	}
+	operands := writers.El("fes:GeometryOperands", "")
+	for _, g := range geometries {
+		operands.Children = append(operands.Children, writers.El("fes:GeometryOperand", "").Attr("name", g))
+	}
+	operators := writers.El("fes:SpatialOperators", "")
+	for _, op := range spatial {
+		operators.Children = append(operators.Children, writers.El("fes:SpatialOperator", "").Attr("name", op))
+	}
+	comparison := writers.El("fes:ComparisonOperators", "")
+	for _, op := range []string{"PropertyIsEqualTo", "PropertyIsNotEqualTo", "PropertyIsLessThan", "PropertyIsGreaterThan", "PropertyIsLessThanOrEqualTo", "PropertyIsGreaterThanOrEqualTo", "PropertyIsLike", "PropertyIsBetween", "PropertyIsNull"} {
+		comparison.Children = append(comparison.Children, writers.El("fes:ComparisonOperator", "").Attr("name", op))
+	}
+	return writers.El("fes:Filter_Capabilities", "",
+		conformance,
+		writers.El("fes:Id_Capabilities", "", writers.El("fes:ResourceIdentifier", "").Attr("name", "fes:ResourceId")),
+		writers.El("fes:Scalar_Capabilities", "", writers.El("fes:LogicalOperators", ""), comparison),
+		writers.El("fes:Spatial_Capabilities", "", operands, operators))
+}
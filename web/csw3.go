@@ -27,26 +27,33 @@
 package web
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/go-spatial/geocatalogo"
+	"github.com/go-spatial/geocatalogo/repository"
 	"github.com/go-spatial/geocatalogo/search"
+	"github.com/go-spatial/geocatalogo/search/cql2"
 	"github.com/gorilla/mux"
 )
 
+// cswSortByPrefixes strips the namespace prefixes of CSW queryables
+// (e.g. dc:title:D) from sortBy values
+var cswSortByPrefixes = strings.NewReplacer("dc:", "", "dct:", "", "apiso:", "")
+
 // CSW3OpenSearchHandler provides a default HTTP API
 func CSW3OpenSearchHandler(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatalogue) {
 	var q string
 	var recordids []string
 	var bbox []float64
-	var timeVal []time.Time
+	var filter cql2.Expr
 	var startPosition int
 	var maxRecords = 10
 	var value []string
 	var collections []string
+	var sortby []search.SortField
 	var results search.Results
 
 	kvp := make(map[string][]string)
@@ -55,6 +62,15 @@ func CSW3OpenSearchHandler(w http.ResponseWriter, r *http.Request, cat *geocatal
 		kvp[strings.ToLower(k)] = v
 	}
 
+	format, err := openSearchFormat(r, kvp)
+	if err != nil {
+		exception := search.Exception{
+			Code:        20002,
+			Description: "ERROR: " + err.Error()}
+		EmitResponseNotOK(w, cat.Config.Server.MimeType, cat.Config.Server.PrettyPrint, &exception)
+		return
+	}
+
 	value, _ = kvp["startposition"]
 	if len(value) > 0 {
 		startPosition, _ = strconv.Atoi(value[0])
@@ -70,45 +86,130 @@ func CSW3OpenSearchHandler(w http.ResponseWriter, r *http.Request, cat *geocatal
 		q = value[0]
 	}
 
+	value, _ = kvp["sortby"]
+	if len(value) > 0 {
+		var err error
+		sortby, err = search.ParseSortBy(cswSortByPrefixes.Replace(value[0]))
+		if err != nil {
+			exception := search.Exception{
+				Code:        20003,
+				Description: "ERROR: " + err.Error()}
+			EmitResponseNotOK(w, cat.Config.Server.MimeType, cat.Config.Server.PrettyPrint, &exception)
+			return
+		}
+	}
+
 	value, _ = kvp["recordids"]
 	if len(value) > 0 {
 		recordids = strings.Split(value[0], ",")
 	}
 
-	if q == "" && len(recordids) < 1 {
+	// OpenSearch Geo (geo:box, or bbox) and Time (time:start, time:end)
+	// extensions
+	for _, name := range []string{"geo:box", "bbox"} {
+		value, _ = kvp[name]
+		if len(value) > 0 {
+			bbox, err = parseOpenSearchBox(value[0])
+			if err != nil {
+				exception := search.Exception{
+					Code:        20002,
+					Description: "ERROR: " + err.Error()}
+				EmitResponseNotOK(w, cat.Config.Server.MimeType, cat.Config.Server.PrettyPrint, &exception)
+				return
+			}
+			break
+		}
+	}
+
+	start, _ := kvp["time:start"]
+	end, _ := kvp["time:end"]
+	if len(start) > 0 || len(end) > 0 {
+		interval := openBound(start) + "/" + openBound(end)
+		operand, err := parseRecordsDatetime(interval)
+		if err != nil {
+			exception := search.Exception{
+				Code:        20002,
+				Description: "ERROR: time:start/time:end " + err.Error()}
+			EmitResponseNotOK(w, cat.Config.Server.MimeType, cat.Config.Server.PrettyPrint, &exception)
+			return
+		}
+		filter = datetimeFilter(operand)
+	}
+
+	if q == "" && len(recordids) < 1 && bbox == nil && filter == nil {
 		exception := search.Exception{
 			Code:        20001,
-			Description: "ERROR: one of q or recordids are required"}
+			Description: "ERROR: one of q, recordids, geo:box or time:start/time:end is required"}
 		EmitResponseNotOK(w, cat.Config.Server.MimeType, cat.Config.Server.PrettyPrint, &exception)
 		return
 	}
 
-	if q != "" && len(recordids) > 0 {
+	if len(recordids) > 0 && (q != "" || bbox != nil || filter != nil) {
 		exception := search.Exception{
 			Code:        20002,
-			Description: "ERROR: q and recordids are mutually exclusive"}
+			Description: "ERROR: recordids and search parameters are mutually exclusive"}
 		EmitResponseNotOK(w, cat.Config.Server.MimeType, cat.Config.Server.PrettyPrint, &exception)
 		return
 	}
 
-	if q != "" {
-		results = cat.Search(collections, q, bbox, timeVal, startPosition, maxRecords)
-	}
-
 	if len(recordids) > 0 {
 		results = cat.Get(recordids)
+	} else {
+		results, err = cat.Query(r.Context(), search.Query{
+			Collections: collections,
+			Term:        q,
+			BBox:        bbox,
+			Filter:      filter,
+			SortBy:      sortby,
+			From:        startPosition,
+			Size:        maxRecords,
+		})
+		if err != nil {
+			status := 500
+			if errors.Is(err, repository.ErrInvalidQuery) {
+				status = 400
+			}
+			exception := search.Exception{
+				Code:        20004,
+				Description: "ERROR: " + err.Error()}
+			emitResponseError(w, cat.Config.Server.MimeType, cat.Config.Server.PrettyPrint, status, &exception)
+			return
+		}
 	}
 
-	EmitResponseOK(w, cat.Config.Server.MimeType, cat.Config.Server.PrettyPrint, &results)
+	switch format {
+	case openSearchAtom, openSearchRSS:
+		feed := openSearchFeed(r, cat, format, &results, q, startPosition, maxRecords)
+		emitXML(w, cat, 200, format+"; charset=UTF-8", feed)
+	default:
+		EmitResponseOK(w, cat.Config.Server.MimeType, cat.Config.Server.PrettyPrint, &results)
+	}
 
 	return
 }
 
-// CSW3OpenSearchRouter provides CSW 3 OpenSearch Routing
+// CSW3OpenSearchRouter provides CSW 3 OpenSearch Routing, with its
+// description at /opensearch.xml, and the CSW 2.0.2/3.0 bindings at
+// /csw, or at / for requests with a request parameter or an XML body
 func CSW3OpenSearchRouter(cat *geocatalogo.GeoCatalogue) *mux.Router {
 	router := mux.NewRouter()
 	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		for k := range r.URL.Query() {
+			if strings.ToLower(k) == "request" {
+				CSWHandler(w, r, cat)
+				return
+			}
+		}
 		CSW3OpenSearchHandler(w, r, cat)
 	}).Methods("GET")
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		CSWHandler(w, r, cat)
+	}).Methods("POST")
+	router.HandleFunc("/csw", func(w http.ResponseWriter, r *http.Request) {
+		CSWHandler(w, r, cat)
+	}).Methods("GET", "POST")
+	router.HandleFunc("/opensearch.xml", func(w http.ResponseWriter, r *http.Request) {
+		OpenSearchDescriptionHandler(w, r, cat)
+	}).Methods("GET")
 	return router
 }
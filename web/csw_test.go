@@ -0,0 +1,297 @@
+package web_test
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-spatial/geocatalogo"
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/web"
+)
+
+func newCSWCatalogue(t *testing.T) *geocatalogo.GeoCatalogue {
+	t.Helper()
+	record := func(id string, title string, resourceType string, bbox [4]float64) metadata.Record {
+		rec := testRecord(id, "")
+		rec.Properties.Title = title
+		rec.Properties.Type = resourceType
+		rec.Geometry = metadata.NewEnvelope(bbox)
+		return rec
+	}
+	return newCatalogue(t,
+		record("rec-1", "Rivers", "dataset", [4]float64{-75, 45, -74, 46}),
+		record("rec-2", "Lakes", "dataset", [4]float64{10, 10, 11, 11}),
+		record("rec-3", "Roads", "service", [4]float64{-75, 45, -74, 46}))
+}
+
+// getCSW sends a KVP request
+func getCSW(t *testing.T, cat *geocatalogo.GeoCatalogue, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	web.CSW3OpenSearchRouter(cat).ServeHTTP(w, httptest.NewRequest("GET", "/csw?"+query, nil))
+	return w
+}
+
+func decodeCSW(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("%s: %s", err, w.Body)
+	}
+}
+
+type searchResults struct {
+	Matched  int    `xml:"numberOfRecordsMatched,attr"`
+	Returned int    `xml:"numberOfRecordsReturned,attr"`
+	Next     int    `xml:"nextRecord,attr"`
+	Status   string `xml:"status,attr"`
+	Schema   string `xml:"recordSchema,attr"`
+	Records  []struct {
+		XMLName    xml.Name
+		Identifier string `xml:"identifier"`
+	} `xml:",any"`
+}
+
+func getRecords(t *testing.T, w *httptest.ResponseRecorder) searchResults {
+	t.Helper()
+	var response struct {
+		XMLName xml.Name
+		Results searchResults `xml:"SearchResults"`
+	}
+	decodeCSW(t, w, &response)
+	if response.XMLName.Local != "GetRecordsResponse" {
+		t.Fatalf("unexpected response %s", w.Body)
+	}
+	return response.Results
+}
+
+func identifiers(r searchResults) []string {
+	var ids []string
+	for _, rec := range r.Records {
+		ids = append(ids, rec.Identifier)
+	}
+	return ids
+}
+
+func TestCSWGetCapabilities(t *testing.T) {
+	cat := newCSWCatalogue(t)
+
+	var capabilities struct {
+		XMLName    xml.Name
+		Version    string `xml:"version,attr"`
+		Operations []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"OperationsMetadata>Operation"`
+		SpatialOperators []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"Filter_Capabilities>Spatial_Capabilities>SpatialOperators>SpatialOperator"`
+	}
+	names := func(list []struct {
+		Name string `xml:"name,attr"`
+	}) []string {
+		var names []string
+		for _, n := range list {
+			names = append(names, n.Name)
+		}
+		return names
+	}
+	spatial := []string{"BBOX", "Intersects", "Within", "Contains", "Disjoint", "Equals", "Touches", "Crosses", "Overlaps"}
+
+	decodeCSW(t, getCSW(t, cat, "service=CSW&request=GetCapabilities"), &capabilities)
+	if capabilities.XMLName.Space != "http://www.opengis.net/cat/csw/3.0" || capabilities.Version != "3.0.0" {
+		t.Errorf("expected CSW 3.0.0 capabilities, got %v %s", capabilities.XMLName, capabilities.Version)
+	}
+	if ops := names(capabilities.Operations); !reflect.DeepEqual(ops, []string{"GetCapabilities", "GetRecords", "GetRecordById", "GetDomain", "Transaction", "Harvest"}) {
+		t.Errorf("unexpected operations %v", ops)
+	}
+	if ops := names(capabilities.SpatialOperators); !reflect.DeepEqual(ops, spatial) {
+		t.Errorf("unexpected spatial operators %v", ops)
+	}
+
+	capabilities.Operations, capabilities.SpatialOperators = nil, nil
+	decodeCSW(t, getCSW(t, cat, "service=CSW&request=GetCapabilities&acceptVersions=1.0.0,2.0.2"), &capabilities)
+	if capabilities.XMLName.Space != "http://www.opengis.net/cat/csw/2.0.2" || capabilities.Version != "2.0.2" {
+		t.Errorf("expected CSW 2.0.2 capabilities, got %v %s", capabilities.XMLName, capabilities.Version)
+	}
+	if ops := names(capabilities.Operations); len(ops) < 2 || ops[1] != "DescribeRecord" {
+		t.Errorf("unexpected operations %v", ops)
+	}
+	if ops := names(capabilities.SpatialOperators); !reflect.DeepEqual(ops, spatial) {
+		t.Errorf("unexpected spatial operators %v", ops)
+	}
+}
+
+func TestCSWDescribeRecord(t *testing.T) {
+	cat := newCSWCatalogue(t)
+
+	var response struct {
+		XMLName xml.Name
+		Schema  struct {
+			TargetNamespace string `xml:"targetNamespace,attr"`
+			Language        string `xml:"schemaLanguage,attr"`
+		} `xml:"SchemaComponent"`
+	}
+	decodeCSW(t, getCSW(t, cat, "service=CSW&version=2.0.2&request=DescribeRecord&typeName=csw:Record"), &response)
+	if response.XMLName.Local != "DescribeRecordResponse" ||
+		response.Schema.TargetNamespace != "http://www.opengis.net/cat/csw/2.0.2" || response.Schema.Language != "http://www.w3.org/XML/Schema" {
+		t.Errorf("unexpected response %+v", response)
+	}
+}
+
+func TestCSWGetRecords(t *testing.T) {
+	cat := newCSWCatalogue(t)
+
+	// paging, sorted by title
+	results := getRecords(t, getCSW(t, cat, "service=CSW&version=3.0.0&request=GetRecords&typeNames=csw:Record&sortBy=dc:title:A&maxRecords=2"))
+	if results.Matched != 3 || results.Returned != 2 || results.Next != 3 || results.Status != "subset" ||
+		!reflect.DeepEqual(identifiers(results), []string{"rec-2", "rec-1"}) {
+		t.Errorf("unexpected first page %+v", results)
+	}
+	results = getRecords(t, getCSW(t, cat, "service=CSW&version=3.0.0&request=GetRecords&typeNames=csw:Record&sortBy=dc:title:A&maxRecords=2&startPosition=3"))
+	if results.Matched != 3 || results.Returned != 1 || results.Next != 0 || results.Status != "complete" ||
+		!reflect.DeepEqual(identifiers(results), []string{"rec-3"}) {
+		t.Errorf("unexpected last page %+v", results)
+	}
+	results = getRecords(t, getCSW(t, cat, "service=CSW&version=2.0.2&request=GetRecords&typeNames=csw:Record&resultType=hits"))
+	if results.Matched != 3 || results.Returned != 0 || len(results.Records) != 0 {
+		t.Errorf("unexpected hits %+v", results)
+	}
+
+	// CQL constraint, with the element set name
+	constraint := url.QueryEscape("dc:type = 'dataset'")
+	results = getRecords(t, getCSW(t, cat, "service=CSW&version=2.0.2&request=GetRecords&typeNames=csw:Record&elementSetName=brief&constraintLanguage=CQL_TEXT&constraint="+constraint))
+	if results.Matched != 2 || len(results.Records) != 2 || results.Records[0].XMLName.Local != "BriefRecord" ||
+		results.Schema != "http://www.opengis.net/cat/csw/2.0.2" {
+		t.Errorf("unexpected CQL results %+v", results)
+	}
+
+	// Filter constraint (XML), with an output schema
+	results = getRecords(t, postCSW(t, cat, "", `<csw:GetRecords xmlns:csw="http://www.opengis.net/cat/csw/3.0" xmlns:fes="http://www.opengis.net/fes/2.0"
+ service="CSW" version="3.0.0" outputSchema="http://www.isotc211.org/2005/gmd">
+<csw:Query typeNames="csw:Record"><csw:ElementSetName>full</csw:ElementSetName>
+<csw:Constraint version="2.0.0"><fes:Filter><fes:And>
+<fes:BBOX><fes:ValueReference>ows:BoundingBox</fes:ValueReference>
+<gml:Envelope xmlns:gml="http://www.opengis.net/gml/3.2" srsName="urn:ogc:def:crs:EPSG::4326"><gml:lowerCorner>45 -76</gml:lowerCorner><gml:upperCorner>47 -73</gml:upperCorner></gml:Envelope></fes:BBOX>
+<fes:PropertyIsEqualTo><fes:ValueReference>dc:type</fes:ValueReference><fes:Literal>dataset</fes:Literal></fes:PropertyIsEqualTo>
+</fes:And></fes:Filter></csw:Constraint>
+</csw:Query></csw:GetRecords>`))
+	if results.Matched != 1 || len(results.Records) != 1 || results.Records[0].XMLName.Local != "MD_Metadata" ||
+		results.Schema != "http://www.isotc211.org/2005/gmd" {
+		t.Errorf("unexpected Filter results %+v", results)
+	}
+}
+
+func TestCSWGetRecordById(t *testing.T) {
+	cat := newCSWCatalogue(t)
+
+	var record struct {
+		XMLName    xml.Name
+		Identifier string `xml:"identifier"`
+		Title      string `xml:"title"`
+	}
+	decodeCSW(t, getCSW(t, cat, "service=CSW&version=3.0.0&request=GetRecordById&id=rec-1&elementSetName=full"), &record)
+	if record.XMLName.Space != "http://www.opengis.net/cat/csw/3.0" || record.XMLName.Local != "Record" ||
+		record.Identifier != "rec-1" || record.Title != "Rivers" {
+		t.Errorf("unexpected record %+v", record)
+	}
+
+	var response struct {
+		XMLName xml.Name
+		Records []struct {
+			XMLName    xml.Name
+			Identifier string `xml:"identifier"`
+		} `xml:",any"`
+	}
+	decodeCSW(t, getCSW(t, cat, "service=CSW&version=2.0.2&request=GetRecordById&id=rec-1,rec-3,missing"), &response)
+	if response.XMLName.Local != "GetRecordByIdResponse" || len(response.Records) != 2 ||
+		response.Records[0].XMLName.Local != "SummaryRecord" || response.Records[1].Identifier != "rec-3" {
+		t.Errorf("unexpected response %+v", response)
+	}
+}
+
+func TestCSWGetDomain(t *testing.T) {
+	cat := newCSWCatalogue(t)
+
+	var response struct {
+		Domain struct {
+			ParameterName string   `xml:"ParameterName"`
+			PropertyName  string   `xml:"PropertyName"`
+			Values        []string `xml:"ListOfValues>Value"`
+		} `xml:"DomainValues"`
+	}
+	decodeCSW(t, getCSW(t, cat, "service=CSW&version=3.0.0&request=GetDomain&parameterName=GetRecords.resultType"), &response)
+	if response.Domain.ParameterName != "GetRecords.resultType" || !reflect.DeepEqual(response.Domain.Values, []string{"hits", "results"}) {
+		t.Errorf("unexpected parameter domain %+v", response.Domain)
+	}
+
+	response.Domain.Values = nil
+	decodeCSW(t, getCSW(t, cat, "service=CSW&version=3.0.0&request=GetDomain&propertyName=dc:type"), &response)
+	if response.Domain.PropertyName != "dc:type" || !reflect.DeepEqual(response.Domain.Values, []string{"dataset", "service"}) {
+		t.Errorf("unexpected property domain %+v", response.Domain)
+	}
+}
+
+func TestCSWExceptions(t *testing.T) {
+	cat := newCSWCatalogue(t)
+
+	tests := []struct {
+		query   string
+		status  int
+		code    string
+		locator string
+	}{
+		{"service=CSW&version=3.0.0", 400, "MissingParameterValue", "request"},
+		{"service=WMS&version=3.0.0&request=GetRecords", 400, "InvalidParameterValue", "service"},
+		{"service=CSW&version=1.0.0&request=GetRecords", 400, "InvalidParameterValue", "version"},
+		{"service=CSW&request=GetCapabilities&acceptVersions=1.0.0", 400, "VersionNegotiationFailed", "acceptVersions"},
+		{"service=CSW&version=3.0.0&request=DescribeRecord", 501, "OperationNotSupported", "request"},
+		{"service=CSW&version=3.0.0&request=GetRecords&typeNames=gmd:MD_Metadata", 400, "InvalidParameterValue", "typeNames"},
+		{"service=CSW&version=3.0.0&request=GetRecords&outputSchema=urn:unknown", 400, "InvalidParameterValue", "outputSchema"},
+		{"service=CSW&version=3.0.0&request=GetRecords&elementSetName=huge", 400, "InvalidParameterValue", "ElementSetName"},
+		{"service=CSW&version=3.0.0&request=GetRecords&maxRecords=-1", 400, "InvalidParameterValue", "maxRecords"},
+		{"service=CSW&version=3.0.0&request=GetRecords&sortBy=ows:BoundingBox:A", 400, "InvalidParameterValue", "sortBy"},
+		{"service=CSW&version=3.0.0&request=GetRecords&constraint=dc:type%3D'dataset'", 400, "MissingParameterValue", "constraintLanguage"},
+		{"service=CSW&version=3.0.0&request=GetRecords&constraintLanguage=CQL_TEXT&constraint=dc:type%3D", 400, "InvalidParameterValue", "constraint"},
+		{"service=CSW&version=3.0.0&request=GetRecordById", 400, "MissingParameterValue", "id"},
+		{"service=CSW&version=3.0.0&request=GetRecordById&id=missing", 404, "NotFound", "id"},
+		{"service=CSW&version=3.0.0&request=GetDomain", 400, "MissingParameterValue", "parameterName"},
+		{"service=CSW&version=3.0.0&request=GetDomain&parameterName=GetRecords.unknown", 400, "InvalidParameterValue", "parameterName"},
+		// CSW 2.0.2 exceptions are reported with 200
+		{"service=CSW&version=2.0.2&request=GetRecords&elementSetName=huge", 200, "InvalidParameterValue", "ElementSetName"},
+		{"service=CSW&version=2.0.2&request=GetRecordById", 200, "MissingParameterValue", "id"},
+	}
+	for _, test := range tests {
+		w := getCSW(t, cat, test.query)
+		var report struct {
+			XMLName   xml.Name
+			Exception struct {
+				Code    string `xml:"exceptionCode,attr"`
+				Locator string `xml:"locator,attr"`
+				Text    string `xml:"ExceptionText"`
+			} `xml:"Exception"`
+		}
+		if err := xml.Unmarshal(w.Body.Bytes(), &report); err != nil || report.XMLName.Local != "ExceptionReport" {
+			t.Errorf("%s: expected an ExceptionReport, got %s", test.query, w.Body)
+			continue
+		}
+		if w.Code != test.status || report.Exception.Code != test.code || report.Exception.Locator != test.locator {
+			t.Errorf("%s: expected %d %s at %s, got %d %+v", test.query, test.status, test.code, test.locator, w.Code, report.Exception)
+		}
+	}
+
+	// failed queries
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	web.CSW3OpenSearchRouter(cat).ServeHTTP(w, httptest.NewRequest("GET", "/csw?service=CSW&version=3.0.0&request=GetRecords", nil).WithContext(ctx))
+	if w.Code != 500 || !strings.Contains(w.Body.String(), `exceptionCode="NoApplicableCode"`) {
+		t.Errorf("expected a 500 NoApplicableCode for a failed query, got %d: %s", w.Code, w.Body)
+	}
+}
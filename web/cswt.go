@@ -0,0 +1,540 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/go-spatial/geocatalogo"
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/parsers"
+	"github.com/go-spatial/geocatalogo/metadata/writers"
+	"github.com/go-spatial/geocatalogo/search"
+	"github.com/go-spatial/geocatalogo/search/cql2"
+	"github.com/go-spatial/geocatalogo/search/fes"
+)
+
+// cswResourceTypes lists the schemas of the documents which can be
+// inserted or harvested
+var cswResourceTypes = []string{namespaceCSW2, namespaceCSW3, parsers.NamespaceGMD, parsers.NamespaceMDB}
+
+// cswTransactionPage is the number of records selected at a time by the
+// constraint of an Update or Delete
+const cswTransactionPage = 500
+
+// cswHarvestMaxSize is the maximum size of a harvested document
+const cswHarvestMaxSize = 32 << 20
+
+// cswHarvestClient fetches harvested documents
+var cswHarvestClient = &http.Client{Timeout: 60 * time.Second}
+
+// CSWAction provides an Insert, Update or Delete action of a
+// Transaction
+type CSWAction struct {
+	Type       string
+	Handle     string
+	Documents  [][]byte
+	Properties []CSWRecordProperty
+	Constraint cql2.Expr
+}
+
+// CSWRecordProperty provides a property set by an Update action
+type CSWRecordProperty struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+// parseCSWTransaction decodes the actions of a Transaction.  Inserted
+// and replacing documents are kept verbatim, with the namespaces
+// declared by their ancestors
+func parseCSWTransaction(data []byte) ([]CSWAction, error) {
+	var actions []CSWAction
+	var scopes []map[string]string
+
+	invalid := func(format string, args ...interface{}) error {
+		return &cswException{"NoApplicableCode", "Transaction", fmt.Sprintf(format, args...)}
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	depth := 0
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, invalid("invalid XML request: %s", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			switch depth {
+			case 1:
+				scopes = append(scopes, namespaceDeclarations(t))
+			case 2:
+				if t.Name.Local != "Insert" && t.Name.Local != "Update" && t.Name.Local != "Delete" {
+					return nil, invalid("unexpected element %s", t.Name.Local)
+				}
+				action := CSWAction{Type: t.Name.Local}
+				for _, a := range t.Attr {
+					if a.Name.Local == "handle" {
+						action.Handle = a.Value
+					}
+				}
+				actions = append(actions, action)
+				scopes = append(scopes, namespaceDeclarations(t))
+			case 3:
+				action := &actions[len(actions)-1]
+				switch {
+				case t.Name.Local == "Constraint" && action.Type != "Insert":
+					var c cswXMLConstraint
+					if err := decoder.DecodeElement(&c, &t); err != nil {
+						return nil, invalid("invalid constraint: %s", err)
+					}
+					if action.Constraint, err = c.expr(); err != nil {
+						return nil, invalidParameter("Constraint", "invalid constraint: %s", err)
+					}
+				case t.Name.Local == "RecordProperty" && action.Type == "Update":
+					var p CSWRecordProperty
+					if err := decoder.DecodeElement(&p, &t); err != nil {
+						return nil, invalid("invalid RecordProperty: %s", err)
+					}
+					action.Properties = append(action.Properties, p)
+				case action.Type != "Delete":
+					if err := decoder.Skip(); err != nil {
+						return nil, invalid("invalid XML request: %s", err)
+					}
+					document, err := withDeclarations(data[offset:decoder.InputOffset()], scopes)
+					if err != nil {
+						return nil, invalid("invalid document: %s", err)
+					}
+					action.Documents = append(action.Documents, document)
+				default:
+					return nil, invalid("unexpected element %s in Delete", t.Name.Local)
+				}
+				// the element has been consumed up to its end
+				depth--
+			}
+		case xml.EndElement:
+			depth--
+			if depth < len(scopes) {
+				scopes = scopes[:depth]
+			}
+		}
+	}
+	return actions, nil
+}
+
+// namespaceDeclarations returns the prefixes (empty for the default
+// namespace) declared by an element
+func namespaceDeclarations(e xml.StartElement) map[string]string {
+	declarations := make(map[string]string)
+	for _, a := range e.Attr {
+		switch {
+		case a.Name.Space == "xmlns":
+			declarations[a.Name.Local] = a.Value
+		case a.Name.Space == "" && a.Name.Local == "xmlns":
+			declarations[""] = a.Value
+		}
+	}
+	return declarations
+}
+
+// withDeclarations declares the namespaces of enclosing elements on
+// the root element of a document, unless it redeclares them
+func withDeclarations(document []byte, scopes []map[string]string) ([]byte, error) {
+	token, err := xml.NewDecoder(bytes.NewReader(document)).Token()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := token.(xml.StartElement)
+	if !ok {
+		return nil, fmt.Errorf("no root element")
+	}
+	own := namespaceDeclarations(root)
+
+	inScope := make(map[string]string)
+	for _, scope := range scopes {
+		for prefix, url := range scope {
+			inScope[prefix] = url
+		}
+	}
+	var declarations bytes.Buffer
+	for prefix, url := range inScope {
+		if _, ok := own[prefix]; ok {
+			continue
+		}
+		if prefix == "" {
+			declarations.WriteString(` xmlns="`)
+		} else {
+			declarations.WriteString(` xmlns:` + prefix + `="`)
+		}
+		xml.EscapeText(&declarations, []byte(url))
+		declarations.WriteString(`"`)
+	}
+
+	// insert after the name of the root element
+	end := bytes.IndexAny(document, " \t\r\n/>")
+	if end < 0 {
+		return nil, fmt.Errorf("invalid root element")
+	}
+	var result bytes.Buffer
+	result.Write(document[:end])
+	result.Write(declarations.Bytes())
+	result.Write(document[end:])
+	return result.Bytes(), nil
+}
+
+// parseCSWDocument parses an inserted or harvested document, either a
+// csw:Record or ISO metadata, returning its schema
+func parseCSWDocument(data []byte) (metadata.Record, string, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	if err := decoder.Decode(&root); err != nil {
+		return metadata.Record{}, "", err
+	}
+
+	var rec metadata.Record
+	var err error
+	switch name := root.XMLName; {
+	case name.Local == "Record" && (name.Space == namespaceCSW2 || name.Space == namespaceCSW3):
+		rec, err = parsers.ParseCSWRecord(data)
+	case parsers.IsISORecord(data):
+		rec, err = parsers.ParseISORecord(data)
+	default:
+		return rec, "", fmt.Errorf("unsupported document type {%s}%s", name.Space, name.Local)
+	}
+	if err == nil && rec.Identifier == "" {
+		err = fmt.Errorf("document has no identifier")
+	}
+	return rec, root.XMLName.Space, err
+}
+
+// transactionsEnabled tells whether credentials protecting transactions
+// are configured
+func transactionsEnabled(cat *geocatalogo.GeoCatalogue) bool {
+	s := cat.Config.Server
+	return s.Token != "" || (s.Username != "" && s.Password != "")
+}
+
+// authorizeTransaction checks the HTTP Basic or Bearer credentials of a
+// request
+func authorizeTransaction(r *http.Request, cat *geocatalogo.GeoCatalogue) error {
+	if !transactionsEnabled(cat) {
+		return &cswException{"OperationNotSupported", "request", "transactions are not enabled"}
+	}
+	s := cat.Config.Server
+	equal := func(a, b string) bool {
+		return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+	}
+
+	if username, password, ok := r.BasicAuth(); ok {
+		if s.Username != "" && s.Password != "" && equal(username, s.Username) && equal(password, s.Password) {
+			return nil
+		}
+	} else if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		if s.Token != "" && equal(strings.TrimSpace(strings.TrimPrefix(auth, "Bearer ")), s.Token) {
+			return nil
+		}
+	}
+	return &cswException{"AuthorizationFailed", "Authorization", "valid credentials are required for transactions"}
+}
+
+// cswTransaction inserts, updates and deletes records.  All actions are
+// validated before any is applied
+func cswTransaction(r *http.Request, cat *geocatalogo.GeoCatalogue, req *CSWRequest) (writers.Element, error) {
+	if len(req.Actions) == 0 {
+		return writers.Element{}, &cswException{"MissingParameterValue", "Transaction", "no Insert, Update or Delete action"}
+	}
+
+	// records to insert or replace, by action
+	documents := make([][]metadata.Record, len(req.Actions))
+	for i, action := range req.Actions {
+		for _, document := range action.Documents {
+			rec, _, err := parseCSWDocument(document)
+			if err != nil {
+				return writers.Element{}, invalidParameter(action.Type, "invalid document: %s", err)
+			}
+			exists := len(cat.Get([]string{rec.Identifier}).Records) > 0
+			if action.Type == "Insert" && exists {
+				return writers.Element{}, invalidParameter(action.Type, "record %s already exists", rec.Identifier)
+			}
+			if action.Type == "Update" && !exists {
+				return writers.Element{}, &cswException{"NotFound", action.Type, fmt.Sprintf("record not found: %s", rec.Identifier)}
+			}
+			documents[i] = append(documents[i], rec)
+		}
+		switch {
+		case action.Type == "Insert" && len(action.Documents) == 0:
+			return writers.Element{}, &cswException{"MissingParameterValue", action.Type, "no document to insert"}
+		case action.Type == "Update" && len(action.Documents) == 0 && (len(action.Properties) == 0 || action.Constraint == nil):
+			return writers.Element{}, &cswException{"MissingParameterValue", action.Type, "an Update requires a record, or RecordProperty and Constraint"}
+		case action.Type == "Delete" && action.Constraint == nil:
+			return writers.Element{}, missingParameter("Constraint")
+		}
+		for _, p := range action.Properties {
+			path, ok := fes.ResolveQueryable(p.Name)
+			if !ok || path == fes.AnyText || path == "id" || path == "geometry" {
+				return writers.Element{}, invalidParameter("RecordProperty", "cannot update %s", p.Name)
+			}
+		}
+	}
+
+	var inserts []writers.Element
+	inserted, updated, deleted := 0, 0, 0
+	for i, action := range req.Actions {
+		switch action.Type {
+		case "Insert":
+			result := writers.El("csw:InsertResult", "")
+			if action.Handle != "" {
+				result = result.Attr("handleRef", action.Handle)
+			}
+			for _, rec := range documents[i] {
+				if !cat.Index(rec) {
+					return writers.Element{}, &cswException{"NoApplicableCode", action.Type, fmt.Sprintf("could not insert record %s", rec.Identifier)}
+				}
+				result.Children = append(result.Children, Record2CSW(&rec, "brief"))
+				inserted++
+			}
+			inserts = append(inserts, result)
+		case "Update":
+			records := documents[i]
+			if action.Constraint != nil {
+				selected, err := cswSelectRecords(r.Context(), cat, action.Constraint)
+				if err != nil {
+					return writers.Element{}, err
+				}
+				for _, rec := range selected {
+					for _, p := range action.Properties {
+						path, _ := fes.ResolveQueryable(p.Name)
+						if err := setRecordProperty(&rec, path, p.Value); err != nil {
+							return writers.Element{}, invalidParameter("RecordProperty", "invalid value of %s: %s", p.Name, err)
+						}
+					}
+					records = append(records, rec)
+				}
+			}
+			for _, rec := range records {
+				if !cat.Update(rec) {
+					return writers.Element{}, &cswException{"NoApplicableCode", action.Type, fmt.Sprintf("could not update record %s", rec.Identifier)}
+				}
+				updated++
+			}
+		case "Delete":
+			selected, err := cswSelectRecords(r.Context(), cat, action.Constraint)
+			if err != nil {
+				return writers.Element{}, err
+			}
+			var ids []string
+			for _, rec := range selected {
+				ids = append(ids, rec.Identifier)
+			}
+			if len(ids) > 0 && !cat.UnIndex(ids) {
+				return writers.Element{}, &cswException{"NoApplicableCode", action.Type, "could not delete records"}
+			}
+			deleted += len(ids)
+		}
+	}
+
+	response := transactionResponse(req, inserted, updated, deleted)
+	response.Children = append(response.Children, inserts...)
+	return withNamespaces(response, req.Version), nil
+}
+
+// cswHarvest fetches a remote document and inserts, or replaces, its
+// record
+func cswHarvest(r *http.Request, cat *geocatalogo.GeoCatalogue, req *CSWRequest) (writers.Element, error) {
+	switch {
+	case req.Source == "":
+		return writers.Element{}, missingParameter("Source")
+	case req.ResourceType == "" && req.Version == CSW2:
+		return writers.Element{}, missingParameter("ResourceType")
+	case req.ResourceType != "" && !contains(cswResourceTypes, req.ResourceType):
+		return writers.Element{}, invalidParameter("ResourceType", "unsupported resource type %s", req.ResourceType)
+	case req.ResourceFormat != "" && !strings.HasSuffix(req.ResourceFormat, "xml"):
+		return writers.Element{}, invalidParameter("ResourceFormat", "unsupported resource format %s", req.ResourceFormat)
+	case req.ResponseHandler != "":
+		return writers.Element{}, invalidParameter("ResponseHandler", "asynchronous harvesting is not supported")
+	case req.HarvestInterval != "":
+		return writers.Element{}, invalidParameter("HarvestInterval", "periodic harvesting is not supported")
+	}
+	if !strings.HasPrefix(req.Source, "http://") && !strings.HasPrefix(req.Source, "https://") {
+		return writers.Element{}, invalidParameter("Source", "unsupported source %s (should be an HTTP URL)", req.Source)
+	}
+
+	data, err := fetchDocument(r.Context(), req.Source)
+	if err != nil {
+		return writers.Element{}, &cswException{"NoApplicableCode", "Source", fmt.Sprintf("could not fetch %s: %s", req.Source, err)}
+	}
+	rec, resourceType, err := parseCSWDocument(data)
+	if err != nil {
+		return writers.Element{}, &cswException{"NoApplicableCode", "Source", fmt.Sprintf("could not parse %s: %s", req.Source, err)}
+	}
+	if req.ResourceType != "" && resourceType != req.ResourceType {
+		return writers.Element{}, invalidParameter("ResourceType", "%s is not of resource type %s", req.Source, req.ResourceType)
+	}
+	rec.Properties.Geocatalogo.Source = req.Source
+
+	inserted, updated := 0, 0
+	var inserts []writers.Element
+	if len(cat.Get([]string{rec.Identifier}).Records) > 0 {
+		if !cat.Update(rec) {
+			return writers.Element{}, &cswException{"NoApplicableCode", "Source", fmt.Sprintf("could not update record %s", rec.Identifier)}
+		}
+		updated++
+	} else {
+		if !cat.Index(rec) {
+			return writers.Element{}, &cswException{"NoApplicableCode", "Source", fmt.Sprintf("could not insert record %s", rec.Identifier)}
+		}
+		inserted++
+		inserts = append(inserts, writers.El("csw:InsertResult", "", Record2CSW(&rec, "brief")))
+	}
+
+	transaction := transactionResponse(req, inserted, updated, 0)
+	transaction.Children = append(transaction.Children, inserts...)
+	return withNamespaces(writers.El("csw:HarvestResponse", "", transaction), req.Version), nil
+}
+
+// fetchDocument retrieves a harvested document
+func fetchDocument(ctx context.Context, url string) ([]byte, error) {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := cswHarvestClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP status %s", response.Status)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(response.Body, cswHarvestMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > cswHarvestMaxSize {
+		return nil, fmt.Errorf("document larger than %d bytes", cswHarvestMaxSize)
+	}
+	return data, nil
+}
+
+// transactionResponse generates a csw:TransactionResponse summary
+func transactionResponse(req *CSWRequest, inserted int, updated int, deleted int) writers.Element {
+	summary := writers.El("csw:TransactionSummary", "",
+		writers.El("csw:totalInserted", strconv.Itoa(inserted)),
+		writers.El("csw:totalUpdated", strconv.Itoa(updated)),
+		writers.El("csw:totalDeleted", strconv.Itoa(deleted)))
+	if req.RequestId != "" {
+		summary = summary.Attr("requestId", req.RequestId)
+	}
+	return writers.El("csw:TransactionResponse", "", summary).Attr("version", req.Version)
+}
+
+// cswSelectRecords retrieves all records matching the constraint of an
+// Update or Delete
+func cswSelectRecords(ctx context.Context, cat *geocatalogo.GeoCatalogue, constraint cql2.Expr) ([]metadata.Record, error) {
+	filter, term, err := fes.ExtractAnyText(constraint)
+	if err != nil {
+		return nil, invalidParameter("Constraint", "%s", err)
+	}
+	var records []metadata.Record
+	for from := 0; ; from += cswTransactionPage {
+		results, err := cat.Query(ctx, search.Query{Term: term, Filter: filter, From: from, Size: cswTransactionPage})
+		if err != nil {
+			return nil, cswQueryError(err)
+		}
+		records = append(records, results.Records...)
+		if len(results.Records) < cswTransactionPage || len(records) >= results.Matches {
+			return records, nil
+		}
+	}
+}
+
+// setRecordProperty sets a record property (e.g. properties.title) from
+// its text value
+func setRecordProperty(rec *metadata.Record, path string, value string) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	keys := strings.Split(path, ".")
+	parent := doc
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := parent[key].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			parent[key] = child
+		}
+		parent = child
+	}
+	key := keys[len(keys)-1]
+
+	// the value is a string, unless only a number fits the property
+	candidates := []interface{}{value}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		candidates = append(candidates, f)
+	}
+	for _, candidate := range candidates {
+		if value == "" {
+			delete(parent, key)
+		} else {
+			parent[key] = candidate
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return err
+		}
+		var updated metadata.Record
+		if err = json.Unmarshal(data, &updated); err == nil {
+			*rec = updated
+			return nil
+		}
+	}
+	return err
+}
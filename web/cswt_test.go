@@ -0,0 +1,199 @@
+package web_test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-spatial/geocatalogo"
+	"github.com/go-spatial/geocatalogo/config"
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/web"
+)
+
+func newCatalogue(t *testing.T, records ...metadata.Record) *geocatalogo.GeoCatalogue {
+	t.Helper()
+	var cfg config.Config
+	cfg.Repository.Type = "memory"
+	cfg.Logging.Level = "ERROR"
+	cfg.Server.URL = "http://localhost:8000"
+	cfg.Server.Username = "admin"
+	cfg.Server.Password = "secret"
+	cfg.Server.Token = "token"
+	cat, err := geocatalogo.New(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range records {
+		if !cat.Index(rec) {
+			t.Fatalf("could not index %s", rec.Identifier)
+		}
+	}
+	return cat
+}
+
+func cswRecord(id string, title string) string {
+	return `<csw:Record xmlns:csw="http://www.opengis.net/cat/csw/2.0.2" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier>` + id + `</dc:identifier><dc:title>` + title + `</dc:title><dc:type>dataset</dc:type>
+</csw:Record>`
+}
+
+type transactionSummary struct {
+	Inserted int `xml:"TransactionSummary>totalInserted"`
+	Updated  int `xml:"TransactionSummary>totalUpdated"`
+	Deleted  int `xml:"TransactionSummary>totalDeleted"`
+}
+
+// postCSW posts an XML request, with the given Authorization header
+func postCSW(t *testing.T, cat *geocatalogo.GeoCatalogue, auth string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest("POST", "/csw", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/xml")
+	if auth != "" {
+		r.Header.Set("Authorization", auth)
+	}
+	w := httptest.NewRecorder()
+	web.CSW3OpenSearchRouter(cat).ServeHTTP(w, r)
+	return w
+}
+
+func transaction(t *testing.T, cat *geocatalogo.GeoCatalogue, actions string) transactionSummary {
+	t.Helper()
+	w := postCSW(t, cat, "Bearer token", `<csw:Transaction xmlns:csw="http://www.opengis.net/cat/csw/3.0" service="CSW" version="3.0.0">`+actions+`</csw:Transaction>`)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var response transactionSummary
+	if err := xml.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+func TestCSWTransactionAuthorization(t *testing.T) {
+	cat := newCatalogue(t)
+	insert := `<csw:Transaction xmlns:csw="http://www.opengis.net/cat/csw/3.0" service="CSW" version="3.0.0">
+<csw:Insert>` + cswRecord("rec-1", "Rivers") + `</csw:Insert></csw:Transaction>`
+
+	tests := map[string]string{
+		"":                       "Basic",
+		"Basic YWRtaW46d3Jvbmc=": "Basic",
+		"Bearer wrong":           "Basic",
+		"Basic YWRtaW46c2VjcmV0": "",
+		"Bearer token":           "",
+	}
+	for auth, challenge := range tests {
+		cat := newCatalogue(t)
+		w := postCSW(t, cat, auth, insert)
+		if challenge == "" {
+			if w.Code != 200 || len(cat.Get([]string{"rec-1"}).Records) != 1 {
+				t.Errorf("%q: expected the record to be inserted, got %d: %s", auth, w.Code, w.Body)
+			}
+			continue
+		}
+		if w.Code != 401 || !strings.Contains(w.Body.String(), `exceptionCode="AuthorizationFailed"`) {
+			t.Errorf("%q: expected 401 AuthorizationFailed, got %d: %s", auth, w.Code, w.Body)
+		}
+		if !strings.HasPrefix(w.Header().Get("WWW-Authenticate"), challenge) {
+			t.Errorf("%q: unexpected challenge %q", auth, w.Header().Get("WWW-Authenticate"))
+		}
+		if len(cat.Get([]string{"rec-1"}).Records) != 0 {
+			t.Errorf("%q: expected no record to be inserted", auth)
+		}
+	}
+
+	cat.Config.Server.Username, cat.Config.Server.Password, cat.Config.Server.Token = "", "", ""
+	if w := postCSW(t, cat, "Bearer token", insert); w.Code != 501 || !strings.Contains(w.Body.String(), "OperationNotSupported") {
+		t.Errorf("expected 501 OperationNotSupported, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestCSWTransaction(t *testing.T) {
+	cat := newCatalogue(t)
+
+	summary := transaction(t, cat, `<csw:Insert handle="rivers">`+cswRecord("rec-1", "Rivers")+cswRecord("rec-2", "Lakes")+`</csw:Insert>`)
+	if summary != (transactionSummary{Inserted: 2}) {
+		t.Errorf("unexpected insert summary %+v", summary)
+	}
+	if results := cat.Get([]string{"rec-1", "rec-2"}); len(results.Records) != 2 {
+		t.Fatalf("expected 2 inserted records, got %+v", results.Records)
+	}
+
+	summary = transaction(t, cat, `<csw:Update>
+<csw:RecordProperty><csw:Name>dc:title</csw:Name><csw:Value>Rivers and streams</csw:Value></csw:RecordProperty>
+<csw:Constraint version="1.1.0"><csw:CqlText>dc:identifier = 'rec-1'</csw:CqlText></csw:Constraint>
+</csw:Update>`)
+	if summary != (transactionSummary{Updated: 1}) {
+		t.Errorf("unexpected update summary %+v", summary)
+	}
+	if results := cat.Get([]string{"rec-1", "rec-2"}); len(results.Records) != 2 ||
+		results.Records[0].Properties.Title != "Rivers and streams" || results.Records[1].Properties.Title != "Lakes" {
+		t.Errorf("unexpected updated records %+v", results.Records)
+	}
+
+	summary = transaction(t, cat, `<csw:Delete>
+<csw:Constraint version="2.0.0"><fes:Filter xmlns:fes="http://www.opengis.net/fes/2.0">
+<fes:PropertyIsEqualTo><fes:ValueReference>dc:title</fes:ValueReference><fes:Literal>Lakes</fes:Literal></fes:PropertyIsEqualTo>
+</fes:Filter></csw:Constraint>
+</csw:Delete>`)
+	if summary != (transactionSummary{Deleted: 1}) {
+		t.Errorf("unexpected delete summary %+v", summary)
+	}
+	if results := cat.Get([]string{"rec-1", "rec-2"}); len(results.Records) != 1 || results.Records[0].Identifier != "rec-1" {
+		t.Errorf("expected only rec-1 to remain, got %+v", results.Records)
+	}
+}
+
+func TestCSWHarvest(t *testing.T) {
+	title := "Rivers"
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/record.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(cswRecord("rec-1", title)))
+	}))
+	defer source.Close()
+
+	cat := newCatalogue(t)
+	harvest := func(path string) *httptest.ResponseRecorder {
+		return postCSW(t, cat, "Bearer token", `<csw:Harvest xmlns:csw="http://www.opengis.net/cat/csw/2.0.2" service="CSW" version="2.0.2">
+<csw:Source>`+source.URL+path+`</csw:Source>
+<csw:ResourceType>http://www.opengis.net/cat/csw/2.0.2</csw:ResourceType>
+</csw:Harvest>`)
+	}
+	var response struct {
+		Transaction transactionSummary `xml:"TransactionResponse"`
+	}
+
+	w := harvest("/record.xml")
+	if err := xml.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%s: %s", err, w.Body)
+	}
+	if response.Transaction != (transactionSummary{Inserted: 1}) {
+		t.Errorf("unexpected harvest summary %+v: %s", response.Transaction, w.Body)
+	}
+	results := cat.Get([]string{"rec-1"})
+	if len(results.Records) != 1 || results.Records[0].Properties.Geocatalogo.Source != source.URL+"/record.xml" {
+		t.Fatalf("expected a harvested record, got %+v", results.Records)
+	}
+
+	title = "Rivers and streams"
+	w = harvest("/record.xml")
+	if err := xml.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%s: %s", err, w.Body)
+	}
+	if response.Transaction != (transactionSummary{Updated: 1}) {
+		t.Errorf("unexpected harvest summary %+v: %s", response.Transaction, w.Body)
+	}
+	if results := cat.Get([]string{"rec-1"}); len(results.Records) != 1 || results.Records[0].Properties.Title != title {
+		t.Errorf("expected the record to be replaced, got %+v", results.Records)
+	}
+
+	if w := harvest("/missing.xml"); !strings.Contains(w.Body.String(), "could not fetch") {
+		t.Errorf("expected a fetch error, got %s", w.Body)
+	}
+}
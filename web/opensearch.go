@@ -0,0 +1,300 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-spatial/geocatalogo"
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/writers"
+	"github.com/go-spatial/geocatalogo/search"
+)
+
+// OpenSearch response formats
+const (
+	openSearchJSON = "application/json"
+	openSearchAtom = "application/atom+xml"
+	openSearchRSS  = "application/rss+xml"
+)
+
+// OpenSearch namespaces
+const (
+	namespaceOpenSearch = "http://a9.com/-/spec/opensearch/1.1/"
+	namespaceGeo        = "http://a9.com/-/opensearch/extensions/geo/1.0/"
+	namespaceTime       = "http://a9.com/-/opensearch/extensions/time/1.0/"
+	namespaceAtom       = "http://www.w3.org/2005/Atom"
+	namespaceGeoRSS     = "http://www.georss.org/georss"
+)
+
+// openSearchTemplate is the query string of the search URLs; startIndex
+// is 0-based, as startposition
+const openSearchTemplate = "q={searchTerms}&startposition={startIndex?}&maxrecords={count?}" +
+	"&geo:box={geo:box?}&time:start={time:start?}&time:end={time:end?}&outputformat="
+
+// openSearchFormat selects the response format from the httpAccept or
+// outputFormat parameters, else from the Accept header
+func openSearchFormat(r *http.Request, kvp map[string][]string) (string, error) {
+	for _, name := range []string{"httpaccept", "outputformat"} {
+		value, _ := kvp[name]
+		if len(value) == 0 {
+			continue
+		}
+		format := strings.TrimSpace(strings.Split(value[0], ";")[0])
+		switch format {
+		case openSearchJSON, openSearchAtom, openSearchRSS:
+			return format, nil
+		}
+		return "", fmt.Errorf("unsupported %s %s (should be one of %s, %s, %s)", name, value[0], openSearchJSON, openSearchAtom, openSearchRSS)
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, openSearchAtom):
+		return openSearchAtom, nil
+	case strings.Contains(accept, openSearchRSS):
+		return openSearchRSS, nil
+	}
+	return openSearchJSON, nil
+}
+
+// parseOpenSearchBox parses a geo:box (west,south,east,north)
+func parseOpenSearchBox(value string) ([]float64, error) {
+	tokens := strings.Split(value, ",")
+	if len(tokens) != 4 {
+		return nil, fmt.Errorf("geo:box format error (should be west,south,east,north)")
+	}
+	var bbox []float64
+	for _, token := range tokens {
+		v, err := strconv.ParseFloat(strings.TrimSpace(token), 64)
+		if err != nil {
+			return nil, fmt.Errorf("geo:box format error: %s", err)
+		}
+		bbox = append(bbox, v)
+	}
+	return bbox, nil
+}
+
+// openBound returns a time:start or time:end value, or ".." if unset
+func openBound(value []string) string {
+	if len(value) == 0 || value[0] == "" {
+		return ".."
+	}
+	return value[0]
+}
+
+// OpenSearchDescriptionHandler provides the OpenSearch description
+// document of the catalogue
+func OpenSearchDescriptionHandler(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatalogue) {
+	md := cat.Config.Metadata
+	url := cat.Config.Server.URL
+
+	truncate := func(s string, n int) string {
+		if runes := []rune(s); len(runes) > n {
+			return string(runes[:n])
+		}
+		return s
+	}
+	language := cat.Config.Server.Language
+	if language == "" {
+		language = "*"
+	}
+
+	description := writers.El("OpenSearchDescription", "",
+		writers.El("ShortName", truncate(md.Identification.Title, 16)),
+		writers.El("LongName", truncate(md.Identification.Title, 48)),
+		writers.El("Description", truncate(md.Identification.Abstract, 1024)),
+		writers.El("Tags", truncate(strings.Join(md.Identification.Keywords, " "), 256)),
+		writers.El("Contact", md.Contact.Email))
+	for _, format := range []string{openSearchJSON, openSearchAtom, openSearchRSS} {
+		description.Children = append(description.Children, writers.El("Url", "").
+			Attr("type", format).
+			Attr("rel", "results").
+			Attr("indexOffset", "0").
+			Attr("template", url+"/?"+openSearchTemplate+format))
+	}
+	description.Children = append(description.Children,
+		writers.El("Url", "").
+			Attr("type", "application/opensearchdescription+xml").
+			Attr("rel", "self").
+			Attr("template", url+"/opensearch.xml"),
+		writers.El("Query", "").Attr("role", "example").Attr("searchTerms", exampleTerm(md.Identification.Keywords)),
+		writers.El("Attribution", md.Provider.Name),
+		writers.El("SyndicationRight", "open"),
+		writers.El("Language", language),
+		writers.El("InputEncoding", "UTF-8"),
+		writers.El("OutputEncoding", "UTF-8"))
+
+	description = description.
+		Attr("xmlns", namespaceOpenSearch).
+		Attr("xmlns:geo", namespaceGeo).
+		Attr("xmlns:time", namespaceTime)
+	emitXML(w, cat, 200, "application/opensearchdescription+xml; charset=UTF-8", description)
+}
+
+func exampleTerm(keywords []string) string {
+	if len(keywords) > 0 {
+		return keywords[0]
+	}
+	return "metadata"
+}
+
+// openSearchFeed generates an Atom feed or an RSS 2.0 channel of search
+// results, with GeoRSS geometries and time extension dates
+func openSearchFeed(r *http.Request, cat *geocatalogo.GeoCatalogue, format string, results *search.Results, q string, startIndex int, count int) writers.Element {
+	md := cat.Config.Metadata
+	url := cat.Config.Server.URL
+	self := url + r.URL.RequestURI()
+	descriptionURL := url + "/opensearch.xml"
+
+	counts := []writers.Element{
+		writers.El("opensearch:totalResults", strconv.Itoa(results.Matches)),
+		writers.El("opensearch:startIndex", strconv.Itoa(startIndex)),
+		writers.El("opensearch:itemsPerPage", strconv.Itoa(count)),
+		writers.El("opensearch:Query", "").Attr("role", "request").Attr("searchTerms", q).Attr("startIndex", strconv.Itoa(startIndex)),
+	}
+
+	if format == openSearchRSS {
+		channel := writers.El("channel", "",
+			writers.El("title", md.Identification.Title),
+			writers.El("link", url),
+			writers.El("description", md.Identification.Abstract),
+			writers.El("atom:link", "").Attr("rel", "self").Attr("href", self).Attr("type", openSearchRSS),
+			writers.El("atom:link", "").Attr("rel", "search").Attr("href", descriptionURL).Attr("type", "application/opensearchdescription+xml"))
+		channel.Children = append(channel.Children, counts...)
+		for _, rec := range results.Records {
+			channel.Children = append(channel.Children, record2RSSItem(url, &rec))
+		}
+		return writers.El("rss", "", channel).
+			Attr("version", "2.0").
+			Attr("xmlns:atom", namespaceAtom).
+			Attr("xmlns:opensearch", namespaceOpenSearch).
+			Attr("xmlns:georss", namespaceGeoRSS).
+			Attr("xmlns:dc", namespaceDC)
+	}
+
+	feed := writers.El("feed", "",
+		writers.El("title", md.Identification.Title),
+		writers.El("subtitle", md.Identification.Abstract),
+		writers.El("id", self),
+		writers.El("updated", time.Now().UTC().Format(time.RFC3339)),
+		writers.El("author", "", writers.El("name", md.Provider.Name), writers.El("uri", md.Provider.URL)),
+		writers.El("link", "").Attr("rel", "self").Attr("href", self).Attr("type", openSearchAtom),
+		writers.El("link", "").Attr("rel", "search").Attr("href", descriptionURL).Attr("type", "application/opensearchdescription+xml"))
+	feed.Children = append(feed.Children, counts...)
+	for _, rec := range results.Records {
+		feed.Children = append(feed.Children, record2AtomEntry(url, &rec))
+	}
+	return feed.
+		Attr("xmlns", namespaceAtom).
+		Attr("xmlns:opensearch", namespaceOpenSearch).
+		Attr("xmlns:georss", namespaceGeoRSS).
+		Attr("xmlns:dc", namespaceDC)
+}
+
+// record2AtomEntry generates an Atom entry of a record
+func record2AtomEntry(url string, rec *metadata.Record) writers.Element {
+	p := &rec.Properties
+	entry := writers.El("entry", "",
+		writers.El("id", recordURL(url, rec)),
+		writers.El("title", p.Title),
+		writers.El("updated", recordUpdated(rec).Format(time.RFC3339)),
+		writers.El("dc:identifier", rec.Identifier))
+	if p.Abstract != "" {
+		entry.Children = append(entry.Children, writers.El("summary", p.Abstract))
+	}
+	entry.Children = append(entry.Children,
+		writers.El("link", "").Attr("rel", "alternate").Attr("type", "application/json").Attr("href", recordURL(url, rec)),
+		writers.El("link", "").Attr("rel", "alternate").Attr("type", "application/xml").Attr("href", recordCSWURL(url, rec)))
+	for _, link := range rec.Links {
+		l := writers.El("link", "").Attr("rel", "related").Attr("href", link.URL)
+		if link.Type != "" {
+			l = l.Attr("type", link.Type)
+		}
+		if link.Name != "" {
+			l = l.Attr("title", link.Name)
+		}
+		entry.Children = append(entry.Children, l)
+	}
+	return appendGeoTime(entry, rec)
+}
+
+// record2RSSItem generates an RSS 2.0 item of a record
+func record2RSSItem(url string, rec *metadata.Record) writers.Element {
+	p := &rec.Properties
+	item := writers.El("item", "",
+		writers.El("title", p.Title),
+		writers.El("link", recordURL(url, rec)),
+		writers.El("description", p.Abstract),
+		writers.El("guid", rec.Identifier).Attr("isPermaLink", "false"),
+		writers.El("pubDate", recordUpdated(rec).Format(time.RFC1123Z)))
+	return appendGeoTime(item, rec)
+}
+
+// appendGeoTime adds the GeoRSS geometry and the time extension date
+// (instant or start/end) of a record
+func appendGeoTime(e writers.Element, rec *metadata.Record) writers.Element {
+	if !rec.Geometry.IsEmpty() {
+		b := rec.Geometry.Bounds()
+		if b[0] == b[2] && b[1] == b[3] {
+			e.Children = append(e.Children, writers.El("georss:point", formatCorner(b[1], b[0])))
+		} else {
+			e.Children = append(e.Children, writers.El("georss:box", formatCorner(b[1], b[0])+" "+formatCorner(b[3], b[2])))
+		}
+	}
+
+	p := &rec.Properties
+	switch {
+	case p.TemporalExtent != nil && (p.TemporalExtent.Begin != nil || p.TemporalExtent.End != nil):
+		e.Children = append(e.Children, writers.El("dc:date", writers.IntervalBound(p.TemporalExtent.Begin)+"/"+writers.IntervalBound(p.TemporalExtent.End)))
+	case p.Datetime != nil:
+		e.Children = append(e.Children, writers.El("dc:date", p.Datetime.UTC().Format(time.RFC3339)))
+	}
+	return e
+}
+
+// recordURL is the URL of the JSON representation of a record
+func recordURL(url string, rec *metadata.Record) string {
+	return url + "/?recordids=" + neturl.QueryEscape(rec.Identifier)
+}
+
+// recordCSWURL is the URL of the csw:Record representation of a record
+func recordCSWURL(url string, rec *metadata.Record) string {
+	return url + "/csw?service=CSW&version=3.0.0&request=GetRecordById&elementSetName=full&id=" + neturl.QueryEscape(rec.Identifier)
+}
+
+// recordUpdated is the last modification time of a record
+func recordUpdated(rec *metadata.Record) time.Time {
+	if rec.Properties.Modified != nil {
+		return rec.Properties.Modified.UTC()
+	}
+	return rec.Properties.Geocatalogo.Inserted.UTC()
+}
@@ -0,0 +1,141 @@
+package web_test
+
+import (
+	"encoding/xml"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-spatial/geocatalogo/web"
+)
+
+func TestOpenSearchDescription(t *testing.T) {
+	cat := newCatalogue(t)
+	cat.Config.Metadata.Identification.Title = "geocatalogo demo catalogue"
+	cat.Config.Metadata.Identification.Keywords = []string{"landsat", "sentinel"}
+	cat.Config.Metadata.Contact.Email = "info@example.org"
+
+	w := httptest.NewRecorder()
+	web.CSW3OpenSearchRouter(cat).ServeHTTP(w, httptest.NewRequest("GET", "/opensearch.xml", nil))
+	if w.Code != 200 || w.Header().Get("Content-Type") != "application/opensearchdescription+xml; charset=UTF-8" {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	var description struct {
+		XMLName   xml.Name
+		ShortName string `xml:"ShortName"`
+		Contact   string `xml:"Contact"`
+		Urls      []struct {
+			Type     string `xml:"type,attr"`
+			Rel      string `xml:"rel,attr"`
+			Template string `xml:"template,attr"`
+		} `xml:"Url"`
+		Query struct {
+			SearchTerms string `xml:"searchTerms,attr"`
+		} `xml:"Query"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &description); err != nil {
+		t.Fatal(err)
+	}
+	if description.XMLName.Space != "http://a9.com/-/spec/opensearch/1.1/" || description.XMLName.Local != "OpenSearchDescription" {
+		t.Errorf("unexpected root %v", description.XMLName)
+	}
+	if description.ShortName != "geocatalogo demo" || description.Contact != "info@example.org" || description.Query.SearchTerms != "landsat" {
+		t.Errorf("unexpected description %+v", description)
+	}
+
+	formats := []string{"application/json", "application/atom+xml", "application/rss+xml"}
+	if len(description.Urls) != 4 {
+		t.Fatalf("expected 4 URLs, got %+v", description.Urls)
+	}
+	for i, format := range formats {
+		u := description.Urls[i]
+		if u.Type != format || u.Rel != "results" ||
+			!strings.HasPrefix(u.Template, "http://localhost:8000/?q={searchTerms}&") || !strings.HasSuffix(u.Template, "&outputformat="+format) {
+			t.Errorf("unexpected %s URL %+v", format, u)
+		}
+	}
+	if self := description.Urls[3]; self.Rel != "self" || self.Template != "http://localhost:8000/opensearch.xml" {
+		t.Errorf("unexpected self URL %+v", self)
+	}
+}
+
+func TestOpenSearchFeeds(t *testing.T) {
+	cat := newCatalogue(t, testRecord("rec-1", ""), testRecord("rec-2", ""), testRecord("rec-3", ""))
+	router := web.CSW3OpenSearchRouter(cat)
+
+	type counts struct {
+		TotalResults int `xml:"totalResults"`
+		StartIndex   int `xml:"startIndex"`
+		ItemsPerPage int `xml:"itemsPerPage"`
+	}
+	type entry struct {
+		Title      string `xml:"title"`
+		Identifier string `xml:"identifier"`
+		GUID       string `xml:"guid"`
+		Box        string `xml:"box"`
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/?q=record&maxrecords=2&outputformat=application/atom%2Bxml", nil))
+	if w.Code != 200 || w.Header().Get("Content-Type") != "application/atom+xml; charset=UTF-8" {
+		t.Fatalf("unexpected Atom response %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	var feed struct {
+		XMLName xml.Name
+		counts
+		Links []struct {
+			Rel  string `xml:"rel,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Entries []entry `xml:"entry"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatal(err)
+	}
+	if feed.XMLName.Space != "http://www.w3.org/2005/Atom" || feed.XMLName.Local != "feed" {
+		t.Errorf("unexpected root %v", feed.XMLName)
+	}
+	if feed.counts != (counts{TotalResults: 3, ItemsPerPage: 2}) || len(feed.Entries) != 2 {
+		t.Errorf("expected 2 of 3 entries, got %+v", feed)
+	}
+	for _, e := range feed.Entries {
+		if e.Title != "Record "+e.Identifier || e.Box != "45 -75 46 -74" {
+			t.Errorf("unexpected entry %+v", e)
+		}
+	}
+	if len(feed.Links) != 2 || feed.Links[0].Rel != "self" || feed.Links[0].Href != "http://localhost:8000/?q=record&maxrecords=2&outputformat=application/atom%2Bxml" ||
+		feed.Links[1].Rel != "search" || feed.Links[1].Href != "http://localhost:8000/opensearch.xml" {
+		t.Errorf("unexpected feed links %+v", feed.Links)
+	}
+
+	r := httptest.NewRequest("GET", "/?q=record&startposition=2", nil)
+	r.Header.Set("Accept", "application/rss+xml")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Code != 200 || w.Header().Get("Content-Type") != "application/rss+xml; charset=UTF-8" {
+		t.Fatalf("unexpected RSS response %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	var rss struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			counts
+			Items []entry `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &rss); err != nil {
+		t.Fatal(err)
+	}
+	if rss.Version != "2.0" || rss.Channel.counts != (counts{TotalResults: 3, StartIndex: 2, ItemsPerPage: 10}) || len(rss.Channel.Items) != 1 {
+		t.Fatalf("expected the last of 3 items, got %+v", rss)
+	}
+	if item := rss.Channel.Items[0]; item.GUID == "" || item.Title != "Record "+item.GUID || item.Box != "45 -75 46 -74" {
+		t.Errorf("unexpected item %+v", item)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/?q=record&outputformat=text/html", nil))
+	if w.Code != 400 {
+		t.Errorf("expected 400 for an unsupported format, got %d: %s", w.Code, w.Body)
+	}
+}
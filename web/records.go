@@ -0,0 +1,622 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+// Package web - simple HTTP Wrapper
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-spatial/geocatalogo"
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/writers"
+	"github.com/go-spatial/geocatalogo/search"
+	"github.com/go-spatial/geocatalogo/search/cql2"
+	"github.com/gorilla/mux"
+)
+
+// RecordsCatalogId is the identifier of the collection covering all
+// records of the catalogue
+const RecordsCatalogId string = "metadata:main"
+
+// RecordsConformance lists the conformance classes implemented by the
+// OGC API - Records endpoints
+var RecordsConformance = []string{
+	"http://www.opengis.net/spec/ogcapi-common-1/1.0/conf/core",
+	"http://www.opengis.net/spec/ogcapi-common-2/1.0/conf/collections",
+	"http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/core",
+	"http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/record-core",
+	"http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/record-collection",
+	"http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/record-api",
+	"http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/sorting",
+	"http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/json",
+	"http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/oas30",
+	"http://www.opengis.net/spec/ogcapi-features-3/1.0/conf/filter",
+	"http://www.opengis.net/spec/ogcapi-features-3/1.0/conf/features-filter",
+	"http://www.opengis.net/spec/cql2/1.0/conf/cql2-text",
+	"http://www.opengis.net/spec/cql2/1.0/conf/cql2-json",
+	"http://www.opengis.net/spec/cql2/1.0/conf/basic-cql2",
+}
+
+// RecordsLandingPage provides the OGC API - Records landing page
+type RecordsLandingPage struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Links       []Link `json:"links"`
+}
+
+// RecordsCollection provides an OGC API - Records catalogue
+type RecordsCollection struct {
+	Id          string          `json:"id"`
+	Type        string          `json:"type"`
+	ItemType    string          `json:"itemType"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Keywords    []string        `json:"keywords,omitempty"`
+	License     string          `json:"license,omitempty"`
+	Extent      metadata.Extent `json:"extent"`
+	Links       []Link          `json:"links"`
+}
+
+// RecordsCollectionList provides the list of OGC API - Records catalogues
+type RecordsCollectionList struct {
+	Collections []RecordsCollection `json:"collections"`
+	Links       []Link              `json:"links"`
+}
+
+// RecordsTime provides the temporal extent of a record, either an
+// instant or an interval whose open bounds are ".."
+type RecordsTime struct {
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Interval  []string   `json:"interval,omitempty"`
+}
+
+// RecordsExternalId provides an identifier of a record in another scheme
+type RecordsExternalId struct {
+	Scheme string `json:"scheme,omitempty"`
+	Value  string `json:"value"`
+}
+
+// RecordsContact provides a party associated with a record
+type RecordsContact struct {
+	Name  string   `json:"name"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// RecordsProperties provides the core properties of a record
+type RecordsProperties struct {
+	Type        string              `json:"type,omitempty"`
+	Title       string              `json:"title,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Keywords    []string            `json:"keywords,omitempty"`
+	Language    string              `json:"language,omitempty"`
+	Created     *time.Time          `json:"created,omitempty"`
+	Updated     *time.Time          `json:"updated,omitempty"`
+	Contacts    []RecordsContact    `json:"contacts,omitempty"`
+	License     string              `json:"license,omitempty"`
+	ExternalIds []RecordsExternalId `json:"externalIds,omitempty"`
+}
+
+// RecordsFeature provides a record in its GeoJSON encoding
+type RecordsFeature struct {
+	Id         string             `json:"id"`
+	Type       string             `json:"type"`
+	ConformsTo []string           `json:"conformsTo,omitempty"`
+	Time       *RecordsTime       `json:"time"`
+	Geometry   *metadata.Geometry `json:"geometry"`
+	Properties RecordsProperties  `json:"properties"`
+	Links      []Link             `json:"links"`
+}
+
+// RecordsFeatureCollection provides a page of records
+type RecordsFeatureCollection struct {
+	Type           string           `json:"type"`
+	Features       []RecordsFeature `json:"features"`
+	NumberMatched  int              `json:"numberMatched"`
+	NumberReturned int              `json:"numberReturned"`
+	Links          []Link           `json:"links"`
+}
+
+// RecordsLanding provides the OGC API - Records landing page
+func RecordsLanding(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatalogue) {
+	url := cat.Config.Server.URL
+	lp := RecordsLandingPage{
+		Title:       cat.Config.Metadata.Identification.Title,
+		Description: cat.Config.Metadata.Identification.Abstract,
+		Links: []Link{
+			{Rel: "self", Type: "application/json", Title: "this document", Href: url + "/"},
+			{Rel: "service-desc", Type: "application/vnd.oai.openapi;version=3.0", Title: "API definition", Href: url + "/api?f=json"},
+			{Rel: "service-doc", Type: "text/html", Title: "API documentation", Href: url + "/api"},
+			{Rel: "conformance", Type: "application/json", Title: "conformance", Href: url + "/conformance"},
+			{Rel: "data", Type: "application/json", Title: "collections", Href: url + "/collections"},
+		},
+	}
+	jsonBytes := geocatalogo.Struct2JSON(&lp, cat.Config.Server.PrettyPrint)
+	geocatalogo.EmitResponse(cat, w, 200, jsonBytes)
+	return
+}
+
+// RecordsConformanceHandler provides the conformance classes of the API
+func RecordsConformanceHandler(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatalogue) {
+	jsonBytes := geocatalogo.Struct2JSON(&STACConformanceDeclaration{ConformsTo: RecordsConformance}, cat.Config.Server.PrettyPrint)
+	geocatalogo.EmitResponse(cat, w, 200, jsonBytes)
+	return
+}
+
+// RecordsOpenAPIHandler provides the OpenAPI document (f=json) or its
+// Swagger representation
+func RecordsOpenAPIHandler(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatalogue) {
+	data := map[string]interface{}{"config": cat.Config, "catalog": RecordsCatalogId}
+	if r.URL.Query().Get("f") == "json" {
+		content, _ := geocatalogo.RenderTemplate(RecordsOpenAPI, data)
+		w.Header().Set("Content-Type", "application/vnd.oai.openapi;version=3.0")
+		fmt.Fprintf(w, "%s", content)
+		return
+	}
+	content, _ := geocatalogo.RenderTemplate(SwaggerHTML, data)
+	w.Header().Set("Content-Type", "text/html")
+	fmt.Fprintf(w, "%s", content)
+	return
+}
+
+// RecordsCollections describes the catalogues: the whole catalogue
+// (RecordsCatalogId) and each stored collection, or the one given by
+// the collectionId path variable
+func RecordsCollections(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatalogue) {
+	url := cat.Config.Server.URL
+
+	if id, ok := mux.Vars(r)["collectionId"]; ok {
+		rc, err := recordsCollection(cat, id)
+		if err != nil {
+			emitCollectionError(w, cat, id, err)
+			return
+		}
+		jsonBytes := geocatalogo.Struct2JSON(rc, cat.Config.Server.PrettyPrint)
+		geocatalogo.EmitResponse(cat, w, 200, jsonBytes)
+		return
+	}
+
+	collections, err := cat.Collections()
+	if err != nil {
+		emitCollectionError(w, cat, "", err)
+		return
+	}
+
+	list := RecordsCollectionList{}
+	list.Collections = append(list.Collections, recordsCatalog(cat))
+	for _, collection := range collections {
+		if collection.Identifier != RecordsCatalogId {
+			list.Collections = append(list.Collections, Collection2RecordsCollection(url, collection))
+		}
+	}
+	list.Links = []Link{
+		{Rel: "self", Type: "application/json", Href: url + "/collections"},
+		{Rel: "root", Type: "application/json", Href: url + "/"},
+	}
+
+	jsonBytes := geocatalogo.Struct2JSON(list, cat.Config.Server.PrettyPrint)
+	geocatalogo.EmitResponse(cat, w, 200, jsonBytes)
+	return
+}
+
+// RecordsItems provides the records of a catalogue matching the q,
+// bbox, datetime, type, externalId, ids, filter and sortby parameters
+func RecordsItems(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatalogue) {
+	var query search.Query
+	var filters []cql2.Expr
+	var value string
+	limit := 10
+	offset := 0
+	url := cat.Config.Server.URL
+
+	id := mux.Vars(r)["collectionId"]
+	if _, err := recordsCollection(cat, id); err != nil {
+		emitCollectionError(w, cat, id, err)
+		return
+	}
+	if id != RecordsCatalogId {
+		query.Collections = []string{id}
+	}
+
+	kvp := r.URL.Query()
+
+	badRequest := func(description string) {
+		emitSTACException(w, cat, 400, "InvalidParameterValue", description)
+	}
+
+	if value = kvp.Get("q"); value != "" {
+		query.Term = strings.Join(strings.Split(value, ","), " ")
+	}
+
+	if value = kvp.Get("bbox"); value != "" {
+		tokens := strings.Split(value, ",")
+		if len(tokens) != 4 {
+			badRequest("bbox format error (should be minx,miny,maxx,maxy)")
+			return
+		}
+		for _, token := range tokens {
+			v, err := strconv.ParseFloat(strings.TrimSpace(token), 64)
+			if err != nil {
+				badRequest(fmt.Sprintf("bbox format error: %s", err))
+				return
+			}
+			query.BBox = append(query.BBox, v)
+		}
+	}
+
+	if value = kvp.Get("datetime"); value != "" {
+		operand, err := parseRecordsDatetime(value)
+		if err != nil {
+			badRequest(fmt.Sprintf("datetime error: %s", err))
+			return
+		}
+		filters = append(filters, datetimeFilter(operand))
+	}
+
+	if value = kvp.Get("type"); value != "" {
+		filters = append(filters, cql2.In{Value: cql2.Property{Name: "properties.type"}, List: literals(value)})
+	}
+
+	if value = kvp.Get("externalId"); value != "" {
+		list := literals(value)
+		filters = append(filters, cql2.Or{Args: []cql2.Expr{
+			cql2.In{Value: cql2.Property{Name: "properties.product_info.product_id"}, List: list},
+			cql2.In{Value: cql2.Property{Name: "properties.product_info.scene_id"}, List: list},
+		}})
+	}
+
+	if value = kvp.Get("ids"); value != "" {
+		filters = append(filters, cql2.In{Value: cql2.Property{Name: "id"}, List: literals(value)})
+	}
+
+	if value = kvp.Get("filter"); value != "" {
+		filterLang := kvp.Get("filter-lang")
+		if filterLang == "" {
+			filterLang = "cql2-text"
+		}
+		filter, err := cql2.Parse(value, filterLang)
+		if err == nil {
+			err = cql2.Validate(filter)
+		}
+		if err != nil {
+			badRequest(fmt.Sprintf("filter error: %s", err))
+			return
+		}
+		filters = append(filters, filter)
+	}
+
+	if value = kvp.Get("sortby"); value != "" {
+		sortby, err := search.ParseSortBy(value)
+		if err != nil {
+			badRequest(err.Error())
+			return
+		}
+		query.SortBy = sortby
+	}
+
+	if value = kvp.Get("limit"); value != "" {
+		v, err := strconv.Atoi(value)
+		if err != nil || v < 1 {
+			badRequest("limit must be a positive integer")
+			return
+		}
+		limit = v
+	}
+	if cat.Config.Server.Limit > 0 && limit > cat.Config.Server.Limit {
+		limit = cat.Config.Server.Limit
+	}
+
+	if value = kvp.Get("offset"); value != "" {
+		v, err := strconv.Atoi(value)
+		if err != nil || v < 0 {
+			badRequest("offset must be a non-negative integer")
+			return
+		}
+		offset = v
+	}
+
+	switch len(filters) {
+	case 0:
+	case 1:
+		query.Filter = filters[0]
+	default:
+		query.Filter = cql2.And{Args: filters}
+	}
+	query.From = offset
+	query.Size = limit
+
+	results, err := cat.Query(r.Context(), query)
+	if err != nil {
+		emitQueryError(w, cat, err)
+		return
+	}
+
+	itemsHref := fmt.Sprintf("%s/collections/%s/items", url, id)
+	fc := RecordsFeatureCollection{
+		Type:           "FeatureCollection",
+		Features:       []RecordsFeature{},
+		NumberMatched:  results.Matches,
+		NumberReturned: len(results.Records),
+	}
+	for _, rec := range results.Records {
+		fc.Features = append(fc.Features, Record2RecordsFeature(url, id, &rec))
+	}
+
+	pageHref := func(offset int) string {
+		params := r.URL.Query()
+		params.Set("offset", strconv.Itoa(offset))
+		params.Set("limit", strconv.Itoa(limit))
+		return itemsHref + "?" + params.Encode()
+	}
+	fc.Links = []Link{
+		{Rel: "self", Type: "application/geo+json", Href: pageHref(offset)},
+		{Rel: "collection", Type: "application/json", Href: fmt.Sprintf("%s/collections/%s", url, id)},
+	}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		fc.Links = append(fc.Links, Link{Rel: "prev", Type: "application/geo+json", Href: pageHref(prev)})
+	}
+	if offset+len(results.Records) < results.Matches {
+		fc.Links = append(fc.Links, Link{Rel: "next", Type: "application/geo+json", Href: pageHref(offset + limit)})
+	}
+
+	jsonBytes := geocatalogo.Struct2JSON(fc, cat.Config.Server.PrettyPrint)
+	geocatalogo.EmitResponse(cat, w, 200, jsonBytes)
+	return
+}
+
+// RecordsItem provides a single record of a catalogue
+func RecordsItem(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatalogue) {
+	vars := mux.Vars(r)
+	collectionId, recordId := vars["collectionId"], vars["recordId"]
+
+	results := cat.Get([]string{recordId})
+	if len(results.Records) == 0 ||
+		collectionId != RecordsCatalogId && results.Records[0].Collection() != collectionId {
+		emitSTACException(w, cat, 404, "NotFound", fmt.Sprintf("record not found: %s", recordId))
+		return
+	}
+
+	if format := r.URL.Query().Get("format"); format != "" {
+		emitRecordFormat(w, cat, &results.Records[0], format)
+		return
+	}
+
+	feature := Record2RecordsFeature(cat.Config.Server.URL, collectionId, &results.Records[0])
+	jsonBytes := geocatalogo.Struct2JSON(feature, cat.Config.Server.PrettyPrint)
+	geocatalogo.EmitResponse(cat, w, 200, jsonBytes)
+	return
+}
+
+// RecordsRouter provides OGC API - Records Routing
+func RecordsRouter(cat *geocatalogo.GeoCatalogue) *mux.Router {
+	router := mux.NewRouter()
+
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		RecordsLanding(w, r, cat)
+	}).Methods("GET")
+
+	router.HandleFunc("/conformance", func(w http.ResponseWriter, r *http.Request) {
+		RecordsConformanceHandler(w, r, cat)
+	}).Methods("GET")
+
+	router.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		RecordsOpenAPIHandler(w, r, cat)
+	}).Methods("GET")
+
+	router.HandleFunc("/collections", func(w http.ResponseWriter, r *http.Request) {
+		RecordsCollections(w, r, cat)
+	}).Methods("GET")
+
+	router.HandleFunc("/collections/{collectionId}", func(w http.ResponseWriter, r *http.Request) {
+		RecordsCollections(w, r, cat)
+	}).Methods("GET")
+
+	router.HandleFunc("/collections/{collectionId}/items", func(w http.ResponseWriter, r *http.Request) {
+		RecordsItems(w, r, cat)
+	}).Methods("GET")
+
+	router.HandleFunc("/collections/{collectionId}/items/{recordId}", func(w http.ResponseWriter, r *http.Request) {
+		RecordsItem(w, r, cat)
+	}).Methods("GET")
+
+	return router
+}
+
+// Collection2RecordsCollection generates an OGC API - Records catalogue
+func Collection2RecordsCollection(url string, c metadata.Collection) RecordsCollection {
+	href := fmt.Sprintf("%s/collections/%s", url, c.Identifier)
+	rc := RecordsCollection{
+		Id:          c.Identifier,
+		Type:        "Catalog",
+		ItemType:    "record",
+		Title:       c.Title,
+		Description: c.Description,
+		Keywords:    c.Keywords,
+		License:     c.License,
+		Extent:      c.Extent,
+		Links: []Link{
+			{Rel: "self", Type: "application/json", Href: href},
+			{Rel: "items", Type: "application/geo+json", Href: href + "/items"},
+			{Rel: "root", Type: "application/json", Href: url + "/"},
+		},
+	}
+	for _, link := range c.Links {
+		rc.Links = append(rc.Links, Link{Rel: "related", Type: link.Type, Title: link.Name, Href: link.URL})
+	}
+	return rc
+}
+
+// Record2RecordsFeature generates the GeoJSON encoding of a record
+func Record2RecordsFeature(url string, collectionId string, rec *metadata.Record) RecordsFeature {
+	p := rec.Properties
+	href := fmt.Sprintf("%s/collections/%s/items/%s", url, collectionId, rec.Identifier)
+
+	rf := RecordsFeature{
+		Id:         rec.Identifier,
+		Type:       "Feature",
+		ConformsTo: []string{"http://www.opengis.net/spec/ogcapi-records-1/1.0/req/record-core"},
+		Properties: RecordsProperties{
+			Type:        p.Type,
+			Title:       p.Title,
+			Description: p.Abstract,
+			Language:    p.Language,
+			Created:     p.Created,
+			Updated:     p.Modified,
+			License:     p.License,
+		},
+		Links: []Link{
+			{Rel: "self", Type: "application/geo+json", Href: href},
+			{Rel: "collection", Type: "application/json", Href: fmt.Sprintf("%s/collections/%s", url, collectionId)},
+		},
+	}
+	if !rec.Geometry.IsEmpty() {
+		geometry := rec.Geometry
+		rf.Geometry = &geometry
+	}
+
+	if p.Datetime != nil {
+		rf.Time = &RecordsTime{Timestamp: p.Datetime}
+	} else if te := p.TemporalExtent; te != nil && (te.Begin != nil || te.End != nil) {
+		rf.Time = &RecordsTime{Interval: []string{writers.IntervalBound(te.Begin), writers.IntervalBound(te.End)}}
+	}
+
+	for _, ks := range p.KeywordsSets {
+		rf.Properties.Keywords = append(rf.Properties.Keywords, ks.Keyword...)
+	}
+	for _, c := range p.Contacts {
+		contact := RecordsContact{Name: c.Value}
+		if c.Type != "" {
+			contact.Roles = []string{c.Type}
+		}
+		rf.Properties.Contacts = append(rf.Properties.Contacts, contact)
+	}
+	if pi := p.ProductInfo; pi != nil {
+		if pi.ProductIdentifier != "" {
+			rf.Properties.ExternalIds = append(rf.Properties.ExternalIds, RecordsExternalId{Scheme: "product_id", Value: pi.ProductIdentifier})
+		}
+		if pi.SceneIdentifier != "" {
+			rf.Properties.ExternalIds = append(rf.Properties.ExternalIds, RecordsExternalId{Scheme: "scene_id", Value: pi.SceneIdentifier})
+		}
+	}
+
+	for _, link := range rec.Links {
+		rf.Links = append(rf.Links, Link{Rel: "related", Type: link.Type, Title: link.Name, Href: link.URL})
+	}
+	for _, asset := range rec.Assets {
+		rf.Links = append(rf.Links, Link{Rel: "enclosure", Type: asset.Type, Title: asset.Name, Href: asset.URL})
+	}
+	return rf
+}
+
+// recordsCatalog describes the whole catalogue as a collection
+func recordsCatalog(cat *geocatalogo.GeoCatalogue) RecordsCollection {
+	rc := Collection2RecordsCollection(cat.Config.Server.URL, metadata.Collection{
+		Identifier:  RecordsCatalogId,
+		Title:       cat.Config.Metadata.Identification.Title,
+		Description: cat.Config.Metadata.Identification.Abstract,
+		Keywords:    cat.Config.Metadata.Identification.Keywords,
+	})
+	rc.Extent.Spatial.BBox = [][4]float64{{-180, -90, 180, 90}}
+	rc.Extent.Temporal.Interval = [][2]*time.Time{{nil, nil}}
+	return rc
+}
+
+// recordsCollection describes the catalogue with the given identifier
+func recordsCollection(cat *geocatalogo.GeoCatalogue, id string) (RecordsCollection, error) {
+	if id == RecordsCatalogId {
+		return recordsCatalog(cat), nil
+	}
+	collection, err := cat.Collection(id)
+	if err != nil {
+		return RecordsCollection{}, err
+	}
+	return Collection2RecordsCollection(cat.Config.Server.URL, collection), nil
+}
+
+// parseRecordsDatetime parses an instant or an interval (start/end),
+// whose open bounds are ".." or empty
+func parseRecordsDatetime(value string) (cql2.Operand, error) {
+	parse := func(s string) (*time.Time, error) {
+		if s == "" || s == ".." {
+			return nil, nil
+		}
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return &t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid instant %q (should be RFC3339)", s)
+	}
+
+	bounds := strings.Split(value, "/")
+	switch len(bounds) {
+	case 1:
+		t, err := parse(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		if t == nil {
+			return nil, fmt.Errorf("invalid instant %q", value)
+		}
+		return cql2.Timestamp{Time: *t}, nil
+	case 2:
+		start, err := parse(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parse(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		return cql2.Interval{Start: start, End: end}, nil
+	}
+	return nil, fmt.Errorf("invalid datetime %q", value)
+}
+
+// datetimeFilter matches records whose datetime or temporal extent
+// intersects an instant or interval
+func datetimeFilter(operand cql2.Operand) cql2.Expr {
+	return cql2.Or{Args: []cql2.Expr{
+		cql2.Temporal{Op: "t_intersects", Left: cql2.Property{Name: "properties.datetime"}, Right: operand},
+		cql2.Temporal{Op: "t_intersects", Left: cql2.Property{Name: "properties.temporal_extent"}, Right: operand},
+	}}
+}
+
+// literals splits a comma-separated list into CQL2 literals
+func literals(value string) []cql2.Operand {
+	var list []cql2.Operand
+	for _, v := range strings.Split(value, ",") {
+		list = append(list, cql2.Literal{Value: strings.TrimSpace(v)})
+	}
+	return list
+}
@@ -0,0 +1,492 @@
+///////////////////////////////////////////////////////////////////////////////
+//
+// The MIT License (MIT)
+// Copyright (c) 2019 Tom Kralidis
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+// IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE
+// USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+///////////////////////////////////////////////////////////////////////////////
+
+// Package web - Simple HTTP Wrapper
+package web
+
+// RecordsOpenAPI is the OpenAPI document template of the OGC API -
+// Records endpoints
+var RecordsOpenAPI = `openapi: 3.0.1
+info:
+  title: "{{ .config.Metadata.Identification.Title }}"
+  version: 1.0.0
+  description: >-
+    {{ .config.Metadata.Identification.Abstract }}
+  contact:
+    name: "{{ .config.Metadata.Provider.Name }}"
+    url: "{{ .config.Metadata.Provider.URL }}"
+  license:
+    name: "{{ .config.Metadata.License.Name }}"
+    url: "{{ .config.Metadata.License.URL }}"
+servers:
+  - url: "{{ .config.Server.URL }}"
+paths:
+  /:
+    get:
+      summary: Landing page
+      operationId: getLandingPage
+      tags:
+        - Capabilities
+      responses:
+        '200':
+          description: The landing page, linking to the API definition, conformance and catalogues.
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/landingPage'
+  /conformance:
+    get:
+      summary: Conformance classes implemented by the API
+      operationId: getConformanceDeclaration
+      tags:
+        - Capabilities
+      responses:
+        '200':
+          description: The conformance declaration.
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/conformance'
+  /collections:
+    get:
+      summary: Catalogues of the API
+      description: >-
+        The catalogue covering all records ({{ .catalog }}), followed by
+        one catalogue per collection.
+      operationId: getCollections
+      tags:
+        - Capabilities
+      responses:
+        '200':
+          description: The catalogues.
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/collections'
+  /collections/{collectionId}:
+    get:
+      summary: Describe a catalogue
+      operationId: describeCollection
+      tags:
+        - Capabilities
+      parameters:
+        - $ref: '#/components/parameters/collectionId'
+      responses:
+        '200':
+          description: The catalogue.
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/collection'
+        '404':
+          $ref: '#/components/responses/NotFound'
+  /collections/{collectionId}/items:
+    get:
+      summary: Search the records of a catalogue
+      operationId: getRecords
+      tags:
+        - Records
+      parameters:
+        - $ref: '#/components/parameters/collectionId'
+        - $ref: '#/components/parameters/q'
+        - $ref: '#/components/parameters/bbox'
+        - $ref: '#/components/parameters/datetime'
+        - $ref: '#/components/parameters/type'
+        - $ref: '#/components/parameters/externalId'
+        - $ref: '#/components/parameters/ids'
+        - $ref: '#/components/parameters/filter'
+        - $ref: '#/components/parameters/filter-lang'
+        - $ref: '#/components/parameters/sortby'
+        - $ref: '#/components/parameters/limit'
+        - $ref: '#/components/parameters/offset'
+      responses:
+        '200':
+          description: A page of records.
+          content:
+            application/geo+json:
+              schema:
+                $ref: '#/components/schemas/featureCollection'
+        '400':
+          $ref: '#/components/responses/InvalidParameter'
+        '404':
+          $ref: '#/components/responses/NotFound'
+  /collections/{collectionId}/items/{recordId}:
+    get:
+      summary: Retrieve a record of a catalogue
+      operationId: getRecord
+      tags:
+        - Records
+      parameters:
+        - $ref: '#/components/parameters/collectionId'
+        - $ref: '#/components/parameters/recordId'
+        - $ref: '#/components/parameters/format'
+      responses:
+        '200':
+          description: A record.
+          content:
+            application/geo+json:
+              schema:
+                $ref: '#/components/schemas/record'
+            application/xml:
+              schema:
+                type: string
+            application/ld+json:
+              schema:
+                type: object
+        '400':
+          $ref: '#/components/responses/InvalidParameter'
+        '404':
+          $ref: '#/components/responses/NotFound'
+components:
+  parameters:
+    collectionId:
+      name: collectionId
+      in: path
+      description: Identifier of a catalogue
+      required: true
+      schema:
+        type: string
+    recordId:
+      name: recordId
+      in: path
+      description: Identifier of a record
+      required: true
+      schema:
+        type: string
+    q:
+      name: q
+      in: query
+      description: Free text search terms (comma-separated)
+      required: false
+      schema:
+        type: string
+    bbox:
+      name: bbox
+      in: query
+      description: Bounding box (minx,miny,maxx,maxy)
+      required: false
+      style: form
+      explode: false
+      schema:
+        type: array
+        minItems: 4
+        maxItems: 4
+        items:
+          type: number
+    datetime:
+      name: datetime
+      in: query
+      description: >-
+        An instant or an interval (start/end), whose open bounds are ".."
+        or empty, in RFC 3339 format
+      required: false
+      schema:
+        type: string
+    type:
+      name: type
+      in: query
+      description: Resource types (comma-separated)
+      required: false
+      schema:
+        type: string
+    externalId:
+      name: externalId
+      in: query
+      description: External identifiers, i.e. product or scene identifiers (comma-separated)
+      required: false
+      schema:
+        type: string
+    ids:
+      name: ids
+      in: query
+      description: Record identifiers (comma-separated)
+      required: false
+      schema:
+        type: string
+    filter:
+      name: filter
+      in: query
+      description: CQL2 filter
+      required: false
+      schema:
+        type: string
+    filter-lang:
+      name: filter-lang
+      in: query
+      description: Filter language
+      required: false
+      schema:
+        type: string
+        enum:
+          - cql2-text
+          - cql2-json
+        default: cql2-text
+    sortby:
+      name: sortby
+      in: query
+      description: Sort fields (comma-separated, prefixed with - for descending)
+      required: false
+      schema:
+        type: string
+    limit:
+      name: limit
+      in: query
+      description: Number of records to return
+      required: false
+      schema:
+        type: integer
+        minimum: 1
+        default: 10
+    offset:
+      name: offset
+      in: query
+      description: Position of the first record to return
+      required: false
+      schema:
+        type: integer
+        minimum: 0
+        default: 0
+    format:
+      name: format
+      in: query
+      description: Output format of the record, instead of GeoJSON (dc, iso19139, dcat or datacite)
+      required: false
+      schema:
+        type: string
+        enum: [dc, iso19139, dcat, datacite]
+  responses:
+    NotFound:
+      description: The catalogue or record does not exist.
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/exception'
+    InvalidParameter:
+      description: A parameter is invalid.
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/exception'
+  schemas:
+    exception:
+      type: object
+      required:
+        - code
+      properties:
+        code:
+          type: string
+        description:
+          type: string
+    link:
+      type: object
+      required:
+        - href
+        - rel
+      properties:
+        href:
+          type: string
+        rel:
+          type: string
+        type:
+          type: string
+        title:
+          type: string
+    links:
+      type: array
+      items:
+        $ref: '#/components/schemas/link'
+    landingPage:
+      type: object
+      required:
+        - links
+      properties:
+        title:
+          type: string
+        description:
+          type: string
+        links:
+          $ref: '#/components/schemas/links'
+    conformance:
+      type: object
+      required:
+        - conformsTo
+      properties:
+        conformsTo:
+          type: array
+          items:
+            type: string
+    collection:
+      type: object
+      required:
+        - id
+        - type
+        - itemType
+        - links
+      properties:
+        id:
+          type: string
+        type:
+          type: string
+          enum:
+            - Catalog
+        itemType:
+          type: string
+          enum:
+            - record
+        title:
+          type: string
+        description:
+          type: string
+        keywords:
+          type: array
+          items:
+            type: string
+        license:
+          type: string
+        extent:
+          type: object
+        links:
+          $ref: '#/components/schemas/links'
+    collections:
+      type: object
+      required:
+        - collections
+        - links
+      properties:
+        collections:
+          type: array
+          items:
+            $ref: '#/components/schemas/collection'
+        links:
+          $ref: '#/components/schemas/links'
+    record:
+      type: object
+      required:
+        - id
+        - type
+        - time
+        - geometry
+        - properties
+        - links
+      properties:
+        id:
+          type: string
+        type:
+          type: string
+          enum:
+            - Feature
+        conformsTo:
+          type: array
+          items:
+            type: string
+        time:
+          type: object
+          nullable: true
+          properties:
+            timestamp:
+              type: string
+              format: date-time
+            interval:
+              type: array
+              minItems: 2
+              maxItems: 2
+              items:
+                type: string
+        geometry:
+          type: object
+          nullable: true
+        properties:
+          type: object
+          properties:
+            type:
+              type: string
+            title:
+              type: string
+            description:
+              type: string
+            keywords:
+              type: array
+              items:
+                type: string
+            language:
+              type: string
+            created:
+              type: string
+              format: date-time
+            updated:
+              type: string
+              format: date-time
+            contacts:
+              type: array
+              items:
+                type: object
+                properties:
+                  name:
+                    type: string
+                  roles:
+                    type: array
+                    items:
+                      type: string
+            license:
+              type: string
+            externalIds:
+              type: array
+              items:
+                type: object
+                required:
+                  - value
+                properties:
+                  scheme:
+                    type: string
+                  value:
+                    type: string
+        links:
+          $ref: '#/components/schemas/links'
+    featureCollection:
+      type: object
+      required:
+        - type
+        - features
+      properties:
+        type:
+          type: string
+          enum:
+            - FeatureCollection
+        features:
+          type: array
+          items:
+            $ref: '#/components/schemas/record'
+        numberMatched:
+          type: integer
+        numberReturned:
+          type: integer
+        links:
+          $ref: '#/components/schemas/links'
+tags:
+  - name: Capabilities
+    description: Essential characteristics of the API
+  - name: Records
+    description: Access to the records of a catalogue
+`
@@ -0,0 +1,81 @@
+package web_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/web"
+)
+
+func TestRecordsItemsPaging(t *testing.T) {
+	var records []metadata.Record
+	for _, id := range []string{"rec-1", "rec-2", "rec-3", "rec-4", "rec-5"} {
+		records = append(records, testRecord(id, "landsat"))
+	}
+	cat := newCatalogue(t, records...)
+	router := web.RecordsRouter(cat)
+
+	tests := []struct {
+		offset   string
+		returned int
+		links    map[string]string
+	}{
+		{"0", 2, map[string]string{"self": "0", "next": "2"}},
+		{"1", 2, map[string]string{"self": "1", "prev": "0", "next": "3"}},
+		{"2", 2, map[string]string{"self": "2", "prev": "0", "next": "4"}},
+		{"4", 1, map[string]string{"self": "4", "prev": "2"}},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/collections/metadata:main/items?q=record&limit=2&offset="+test.offset, nil))
+		if w.Code != 200 {
+			t.Fatalf("offset %s: expected 200, got %d: %s", test.offset, w.Code, w.Body)
+		}
+		var fc web.RecordsFeatureCollection
+		if err := json.Unmarshal(w.Body.Bytes(), &fc); err != nil {
+			t.Fatal(err)
+		}
+		if fc.NumberMatched != 5 || fc.NumberReturned != test.returned || len(fc.Features) != test.returned {
+			t.Errorf("offset %s: expected %d of 5 records, got %d of %d", test.offset, test.returned, fc.NumberReturned, fc.NumberMatched)
+		}
+
+		pages := make(map[string]string)
+		for _, link := range fc.Links {
+			if link.Rel == "collection" {
+				if link.Href != "http://localhost:8000/collections/metadata:main" {
+					t.Errorf("offset %s: unexpected collection link %s", test.offset, link.Href)
+				}
+				continue
+			}
+			u, err := url.Parse(link.Href)
+			if err != nil {
+				t.Fatal(err)
+			}
+			params := u.Query()
+			if u.Path != "/collections/metadata:main/items" || params.Get("limit") != "2" || params.Get("q") != "record" {
+				t.Errorf("offset %s: unexpected %s link %s", test.offset, link.Rel, link.Href)
+			}
+			pages[link.Rel] = params.Get("offset")
+		}
+		if !reflect.DeepEqual(pages, test.links) {
+			t.Errorf("offset %s: expected page links %v, got %v", test.offset, test.links, pages)
+		}
+	}
+}
+
+func TestRecordsItemsError(t *testing.T) {
+	cat := newCatalogue(t, testRecord("rec-1", "landsat"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w := httptest.NewRecorder()
+	web.RecordsRouter(cat).ServeHTTP(w, httptest.NewRequest("GET", "/collections/metadata:main/items", nil).WithContext(ctx))
+	if w.Code != 500 {
+		t.Errorf("expected 500 for a failed query, got %d: %s", w.Code, w.Body)
+	}
+}
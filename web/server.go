@@ -30,7 +30,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/go-spatial/geocatalogo"
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/metadata/writers"
 	"github.com/go-spatial/geocatalogo/search"
 )
 
@@ -51,10 +55,16 @@ func EmitResponseOK(w http.ResponseWriter, contentType string, prettyPrint bool,
 
 // EmitResponseNotOK provides HTTP response for unsuccessful requests
 func EmitResponseNotOK(w http.ResponseWriter, contentType string, prettyPrint bool, exception *search.Exception) {
+	emitResponseError(w, contentType, prettyPrint, 400, exception)
+}
+
+// emitResponseError provides HTTP response for failed requests, with
+// the given status
+func emitResponseError(w http.ResponseWriter, contentType string, prettyPrint bool, status int, exception *search.Exception) {
 	var jsonBytes []byte
 
 	w.Header().Set("Content-Type", contentType)
-	w.WriteHeader(400)
+	w.WriteHeader(status)
 
 	if prettyPrint == true {
 		jsonBytes, _ = json.MarshalIndent(exception, "", "    ")
@@ -64,3 +74,25 @@ func EmitResponseNotOK(w http.ResponseWriter, contentType string, prettyPrint bo
 	fmt.Fprintf(w, "%s", jsonBytes)
 	return
 }
+
+// emitRecordFormat provides a record in the output format of the format
+// parameter (see metadata/writers)
+func emitRecordFormat(w http.ResponseWriter, cat *geocatalogo.GeoCatalogue, rec *metadata.Record, format string) {
+	writer, ok := writers.Lookup(format)
+	if !ok {
+		emitSTACException(w, cat, 400, "InvalidParameterValue",
+			fmt.Sprintf("unsupported format %s (should be one of %s)", format, strings.Join(writers.Names(), ", ")))
+		return
+	}
+	data, err := writer.Marshal(rec, cat.Config.Server.PrettyPrint)
+	if err != nil {
+		emitSTACException(w, cat, 500, "ServerError", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", writer.MediaType+"; charset=UTF-8")
+	if cat.Config.Server.CORS == true {
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	}
+	w.Write(data)
+}
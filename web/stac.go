@@ -28,6 +28,7 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -37,7 +38,9 @@ import (
 
 	"github.com/go-spatial/geocatalogo"
 	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/repository"
 	"github.com/go-spatial/geocatalogo/search"
+	"github.com/go-spatial/geocatalogo/search/cql2"
 	"github.com/gorilla/mux"
 )
 
@@ -49,20 +52,57 @@ type STACSearch struct {
 	Datetime    string     `json:"datetime,omitempty"`
 	Collections []string   `json:"collections,omitempty"`
 	Bbox        [4]float64 `json:"bbox,omitempty"`
+	// Intersects is a GeoJSON geometry
+	Intersects json.RawMessage `json:"intersects,omitempty"`
+	Relation   string          `json:"relation,omitempty"`
+	SortBy     []STACSortBy    `json:"sortby,omitempty"`
+	Q          string          `json:"q,omitempty"`
+	// Filter is a CQL2-JSON object, or a CQL2-Text string
+	Filter     json.RawMessage `json:"filter,omitempty"`
+	FilterLang string          `json:"filter-lang,omitempty"`
+}
+
+// STACConformance lists the conformance classes implemented by the API
+var STACConformance = []string{
+	"https://api.stacspec.org/v1.0.0-rc.1/core",
+	"https://api.stacspec.org/v1.0.0-rc.1/collections",
+	"https://api.stacspec.org/v1.0.0-rc.1/item-search",
+	"https://api.stacspec.org/v1.0.0-rc.1/item-search#sort",
+	"https://api.stacspec.org/v1.0.0-rc.1/item-search#filter",
+	"http://www.opengis.net/spec/ogcapi-features-3/1.0/conf/filter",
+	"http://www.opengis.net/spec/ogcapi-features-3/1.0/conf/features-filter",
+	"http://www.opengis.net/spec/cql2/1.0/conf/cql2-text",
+	"http://www.opengis.net/spec/cql2/1.0/conf/cql2-json",
+	"http://www.opengis.net/spec/cql2/1.0/conf/basic-cql2",
+	"http://www.opengis.net/spec/cql2/1.0/conf/advanced-comparison-operators",
+	"http://www.opengis.net/spec/cql2/1.0/conf/basic-spatial-operators",
+	"http://www.opengis.net/spec/cql2/1.0/conf/temporal-operators",
+}
+
+// STACConformanceDeclaration provides the conformance document
+type STACConformanceDeclaration struct {
+	ConformsTo []string `json:"conformsTo"`
+}
+
+// STACSortBy provides a sort key of the STAC API sort extension
+type STACSortBy struct {
+	Field     string `json:"field"`
+	Direction string `json:"direction,omitempty"`
 }
 
 type Properties struct {
-	start    *time.Time `json:"start,omitempty"`
-	end      *time.Time `json:"end,omitempty"`
-	provider string     `json:"provider,omitempty"`
-	license  string     `json:"license,omitempty"`
+	Start    *time.Time `json:"start,omitempty"`
+	End      *time.Time `json:"end,omitempty"`
+	Provider string     `json:"provider,omitempty"`
+	License  string     `json:"license,omitempty"`
 }
 
 type Link struct {
-	Rel   string `json:"rel"`
-	Type  string `json:"type,omitempty"`
-	Title string `json:"title,omitempty"`
-	Href  string `json:"href"`
+	Rel   string   `json:"rel,omitempty"`
+	Type  string   `json:"type,omitempty"`
+	Title string   `json:"title,omitempty"`
+	Href  string   `json:"href"`
+	Roles []string `json:"roles,omitempty"`
 }
 
 type SearchMetadata struct {
@@ -78,14 +118,48 @@ type SearchMetadata struct {
 //}
 
 type STACItem struct {
-	Type        string              `json:"type,omitempty"`
-	Id          string              `json:"id,omitempty"`
-	StacVersion string              `json:"stac_version"`
-	BBox        [4]float64          `json:"bbox,omitempty"`
-	Geometry    metadata.Geometry   `json:"geometry,omitempty"`
-	Properties  metadata.Properties `json:"properties,omitempty"`
-	Links       []Link              `json:"links,omitempty"`
-	Assets      map[string]Link     `json:"assets,omitempty"`
+	Type           string             `json:"type,omitempty"`
+	Id             string             `json:"id,omitempty"`
+	StacVersion    string             `json:"stac_version"`
+	StacExtensions []string           `json:"stac_extensions,omitempty"`
+	BBox           [4]float64         `json:"bbox,omitempty"`
+	Geometry       metadata.Geometry  `json:"geometry,omitempty"`
+	Properties     STACItemProperties `json:"properties,omitempty"`
+	Links          []Link             `json:"links,omitempty"`
+	Assets         map[string]Link    `json:"assets,omitempty"`
+}
+
+// STACItemProperties provides the properties of a STAC Item, with the
+// properties of STAC extensions alongside the record properties
+type STACItemProperties struct {
+	metadata.Properties
+}
+
+// MarshalJSON encodes the record properties, and the properties of
+// extensions not clashing with them
+func (p STACItemProperties) MarshalJSON() ([]byte, error) {
+	properties := p.Properties
+	properties.Extensions = nil
+	data, err := json.Marshal(properties)
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for name, value := range p.Extensions {
+		if _, ok := m[name]; !ok {
+			m[name] = value
+		}
+	}
+	return json.Marshal(m)
+}
+
+// STACException provides a STAC API error
+type STACException struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
 }
 
 type STACFeatureCollection struct {
@@ -97,12 +171,34 @@ type STACFeatureCollection struct {
 	SearchMetadata SearchMetadata `json:"search:metadata"`
 }
 
+// STACCollection provides a STAC Collection
+type STACCollection struct {
+	Version     string                 `json:"stac_version"`
+	Type        string                 `json:"type"`
+	Id          string                 `json:"id"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description"`
+	Keywords    []string               `json:"keywords,omitempty"`
+	License     string                 `json:"license"`
+	Providers   []metadata.Provider    `json:"providers,omitempty"`
+	Extent      metadata.Extent        `json:"extent"`
+	Summaries   map[string]interface{} `json:"summaries,omitempty"`
+	Links       []Link                 `json:"links"`
+}
+
+// STACCollectionList provides the list of STAC Collections
+type STACCollectionList struct {
+	Collections []STACCollection `json:"collections"`
+	Links       []Link           `json:"links"`
+}
+
 type STACCatalogDefinition struct {
-	Version     string `json:"stac_version"`
-	Id          string `json:"id"`
-	Title       string `json:"title,omitempty"`
-	Description string `json:"description"`
-	Links       []Link `json:"links"`
+	Version     string   `json:"stac_version"`
+	Id          string   `json:"id"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description"`
+	ConformsTo  []string `json:"conformsTo,omitempty"`
+	Links       []Link   `json:"links"`
 }
 
 // STACAPIDescription provides the API description
@@ -114,6 +210,7 @@ func STACAPIDescription(w http.ResponseWriter, r *http.Request, cat *geocatalogo
 	scd.Version = VERSION
 	scd.Title = cat.Config.Metadata.Identification.Title
 	scd.Description = cat.Config.Metadata.Identification.Abstract
+	scd.ConformsTo = STACConformance
 
 	var searchLink = Link{}
 	searchLink.Rel = "search"
@@ -123,12 +220,35 @@ func STACAPIDescription(w http.ResponseWriter, r *http.Request, cat *geocatalogo
 
 	scd.Links = append(scd.Links, searchLink)
 
+	var conformanceLink = Link{}
+	conformanceLink.Rel = "conformance"
+	conformanceLink.Type = "application/json"
+	conformanceLink.Title = "conformance"
+	conformanceLink.Href = fmt.Sprintf("%s/conformance", cat.Config.Server.URL)
+
+	scd.Links = append(scd.Links, conformanceLink)
+
+	var dataLink = Link{}
+	dataLink.Rel = "data"
+	dataLink.Type = "application/json"
+	dataLink.Title = "collections"
+	dataLink.Href = fmt.Sprintf("%s/collections", cat.Config.Server.URL)
+
+	scd.Links = append(scd.Links, dataLink)
+
 	jsonBytes = geocatalogo.Struct2JSON(&scd, false)
 
 	geocatalogo.EmitResponse(cat, w, 200, jsonBytes)
 	return
 }
 
+// STACConformanceHandler provides the conformance classes of the API
+func STACConformanceHandler(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatalogue) {
+	jsonBytes := geocatalogo.Struct2JSON(&STACConformanceDeclaration{ConformsTo: STACConformance}, cat.Config.Server.PrettyPrint)
+	geocatalogo.EmitResponse(cat, w, 200, jsonBytes)
+	return
+}
+
 // STACOpenAPI generates an OpenAPI document or Swagger representation
 func STACOpenAPI(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatalogue) {
 	f := r.URL.Query().Get("f")
@@ -148,8 +268,97 @@ func STACOpenAPI(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCat
 	return
 }
 
-// STACCollections provides STAC compliant collection descriptions
+// STACCollections provides STAC compliant collection descriptions, of
+// all collections or of the collection given by the collectionId path
+// variable
 func STACCollections(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatalogue) {
+	var jsonBytes []byte
+	url := cat.Config.Server.URL
+
+	if id, ok := mux.Vars(r)["collectionId"]; ok {
+		collection, err := cat.Collection(id)
+		if err != nil {
+			emitCollectionError(w, cat, id, err)
+			return
+		}
+		stacCollection := Collection2STACCollection(url, collection)
+		jsonBytes = geocatalogo.Struct2JSON(stacCollection, cat.Config.Server.PrettyPrint)
+		geocatalogo.EmitResponse(cat, w, 200, jsonBytes)
+		return
+	}
+
+	collections, err := cat.Collections()
+	if err != nil {
+		emitCollectionError(w, cat, "", err)
+		return
+	}
+
+	list := STACCollectionList{Collections: []STACCollection{}}
+	for _, collection := range collections {
+		list.Collections = append(list.Collections, Collection2STACCollection(url, collection))
+	}
+	list.Links = []Link{
+		{Rel: "self", Type: "application/json", Href: fmt.Sprintf("%s/collections", url)},
+		{Rel: "root", Type: "application/json", Href: fmt.Sprintf("%s/stac", url)},
+	}
+
+	jsonBytes = geocatalogo.Struct2JSON(list, cat.Config.Server.PrettyPrint)
+	geocatalogo.EmitResponse(cat, w, 200, jsonBytes)
+	return
+}
+
+// emitCollectionError emits an exception for a failed collection lookup
+func emitCollectionError(w http.ResponseWriter, cat *geocatalogo.GeoCatalogue, id string, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		emitSTACException(w, cat, 404, "NotFound", fmt.Sprintf("collection not found: %s", id))
+		return
+	}
+	emitSTACException(w, cat, 500, "ServerError", fmt.Sprintf("collection error: %s", err))
+}
+
+// emitQueryError emits an exception for a failed query: a 400 for
+// queries the repository cannot run, else a 500
+func emitQueryError(w http.ResponseWriter, cat *geocatalogo.GeoCatalogue, err error) {
+	if errors.Is(err, repository.ErrInvalidQuery) {
+		emitSTACException(w, cat, 400, "InvalidParameterValue", err.Error())
+		return
+	}
+	emitSTACException(w, cat, 500, "ServerError", fmt.Sprintf("query error: %s", err))
+}
+
+// emitSTACException emits a STAC API error body
+func emitSTACException(w http.ResponseWriter, cat *geocatalogo.GeoCatalogue, status int, code string, description string) {
+	exception := STACException{Code: code, Description: description}
+	jsonBytes := geocatalogo.Struct2JSON(exception, cat.Config.Server.PrettyPrint)
+	geocatalogo.EmitResponse(cat, w, status, jsonBytes)
+}
+
+// STACItemHandler provides a single STAC compliant Item, optionally
+// scoped to the collection given by the collectionId path variable
+func STACItemHandler(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatalogue) {
+	vars := mux.Vars(r)
+	id := vars["itemId"]
+
+	results := cat.Get([]string{id})
+	if len(results.Records) == 0 {
+		emitSTACException(w, cat, 404, "NotFound", fmt.Sprintf("item not found: %s", id))
+		return
+	}
+	record := results.Records[0]
+
+	if collectionId, ok := vars["collectionId"]; ok && record.Collection() != collectionId {
+		emitSTACException(w, cat, 404, "NotFound", fmt.Sprintf("item not found in collection %s: %s", collectionId, id))
+		return
+	}
+
+	if format := r.URL.Query().Get("format"); format != "" {
+		emitRecordFormat(w, cat, &record, format)
+		return
+	}
+
+	stacItem := Record2STACItem(cat.Config.Server.URL, &record)
+	jsonBytes := geocatalogo.Struct2JSON(stacItem, cat.Config.Server.PrettyPrint)
+	geocatalogo.EmitResponse(cat, w, 200, jsonBytes)
 	return
 }
 
@@ -157,14 +366,18 @@ func STACCollections(w http.ResponseWriter, r *http.Request, cat *geocatalogo.Ge
 func STACItems(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatalogue) {
 	var jsonBytes []byte
 	var value []string
-	var filter string
+	var term string
+	var filter cql2.Expr
 	var bbox []float64
+	var intersects *metadata.Geometry
+	var relation string
 	var timeVal []time.Time
 	var limit = 10
 	var page int = 1
 	var from int
 	var ids []string
 	var collections []string
+	var sortby []search.SortField
 	var results search.Results
 	var stacFeatureCollection STACFeatureCollection
 
@@ -196,10 +409,43 @@ func STACItems(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatal
 		if stacSearch.Collections != nil {
 			kvp["collections"] = stacSearch.Collections
 		}
+		if len(stacSearch.SortBy) > 0 {
+			var tokens []string
+			for _, sb := range stacSearch.SortBy {
+				if strings.ToLower(sb.Direction) == "desc" {
+					tokens = append(tokens, "-"+sb.Field)
+				} else {
+					tokens = append(tokens, sb.Field)
+				}
+			}
+			kvp["sortby"] = []string{strings.Join(tokens, ",")}
+		}
 		if stacSearch.Bbox != [4]float64{0, 0, 0, 0} {
 			tmp := fmt.Sprintf("%f,%f,%f,%f", stacSearch.Bbox[0], stacSearch.Bbox[1], stacSearch.Bbox[2], stacSearch.Bbox[3])
 			kvp["bbox"] = []string{tmp}
 		}
+		if len(stacSearch.Intersects) > 0 && string(stacSearch.Intersects) != "null" {
+			kvp["intersects"] = []string{string(stacSearch.Intersects)}
+		}
+		if stacSearch.Relation != "" {
+			kvp["relation"] = []string{stacSearch.Relation}
+		}
+		if stacSearch.Q != "" {
+			kvp["q"] = []string{stacSearch.Q}
+		}
+		if len(stacSearch.Filter) > 0 {
+			var text string
+			if json.Unmarshal(stacSearch.Filter, &text) == nil {
+				kvp["filter"] = []string{text}
+				kvp["filter-lang"] = []string{"cql2-text"}
+			} else {
+				kvp["filter"] = []string{string(stacSearch.Filter)}
+				kvp["filter-lang"] = []string{"cql2-json"}
+			}
+			if stacSearch.FilterLang != "" {
+				kvp["filter-lang"] = []string{stacSearch.FilterLang}
+			}
+		}
 	}
 
 	value, _ = kvp["bbox"]
@@ -219,6 +465,40 @@ func STACItems(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatal
 			bbox = append(bbox, bt)
 		}
 	}
+	value, _ = kvp["intersects"]
+	if len(value) > 0 {
+		intersects = &metadata.Geometry{}
+		err := json.Unmarshal([]byte(value[0]), intersects)
+		if err == nil && intersects.IsEmpty() {
+			err = fmt.Errorf("empty geometry")
+		}
+		if err == nil && len(bbox) > 0 {
+			err = fmt.Errorf("bbox and intersects are mutually exclusive")
+		}
+		if err != nil {
+			exception := search.Exception{
+				Code:        20002,
+				Description: fmt.Sprintf("intersects error: %s", err)}
+			jsonBytes = geocatalogo.Struct2JSON(exception, cat.Config.Server.PrettyPrint)
+			geocatalogo.EmitResponse(cat, w, 400, jsonBytes)
+			return
+		}
+	}
+
+	value, _ = kvp["relation"]
+	if len(value) > 0 {
+		var err error
+		relation, err = search.ParseRelation(value[0])
+		if err != nil {
+			exception := search.Exception{
+				Code:        20002,
+				Description: err.Error()}
+			jsonBytes = geocatalogo.Struct2JSON(exception, cat.Config.Server.PrettyPrint)
+			geocatalogo.EmitResponse(cat, w, 400, jsonBytes)
+			return
+		}
+	}
+
 	value, _ = kvp["datetime"]
 	if len(value) > 0 {
 		for _, t := range strings.Split(value[0], "/") {
@@ -235,9 +515,44 @@ func STACItems(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatal
 		}
 	}
 
+	value, _ = kvp["q"]
+	if len(value) > 0 {
+		term = value[0]
+	}
+
 	value, _ = kvp["filter"]
 	if len(value) > 0 {
-		filter = value[0]
+		var err error
+		filterLang := "cql2-text"
+		if lang, _ := kvp["filter-lang"]; len(lang) > 0 {
+			filterLang = lang[0]
+		}
+		filter, err = cql2.Parse(value[0], filterLang)
+		if err == nil {
+			err = cql2.Validate(filter)
+		}
+		if err != nil {
+			exception := search.Exception{
+				Code:        20002,
+				Description: fmt.Sprintf("filter error: %s", err)}
+			jsonBytes = geocatalogo.Struct2JSON(exception, cat.Config.Server.PrettyPrint)
+			geocatalogo.EmitResponse(cat, w, 400, jsonBytes)
+			return
+		}
+	}
+
+	value, _ = kvp["sortby"]
+	if len(value) > 0 {
+		var err error
+		sortby, err = search.ParseSortBy(value[0])
+		if err != nil {
+			exception := search.Exception{
+				Code:        20002,
+				Description: err.Error()}
+			jsonBytes = geocatalogo.Struct2JSON(exception, cat.Config.Server.PrettyPrint)
+			geocatalogo.EmitResponse(cat, w, 400, jsonBytes)
+			return
+		}
 	}
 
 	value, _ = kvp["limit"]
@@ -265,12 +580,35 @@ func STACItems(w http.ResponseWriter, r *http.Request, cat *geocatalogo.GeoCatal
 	if len(value) > 0 {
 		collections = strings.Split(value[0], ",")
 	}
+	if id, ok := mux.Vars(r)["collectionId"]; ok {
+		if _, err := cat.Collection(id); err != nil {
+			emitCollectionError(w, cat, id, err)
+			return
+		}
+		collections = []string{id}
+	}
 	fmt.Println(collections == nil)
 
 	if len(ids) > 0 {
 		results = cat.Get(ids)
 	} else {
-		results = cat.Search(collections, filter, bbox, timeVal, from, limit)
+		var err error
+		results, err = cat.Query(r.Context(), search.Query{
+			Collections: collections,
+			Term:        term,
+			BBox:        bbox,
+			Intersects:  intersects,
+			Relation:    relation,
+			Time:        timeVal,
+			Filter:      filter,
+			SortBy:      sortby,
+			From:        from,
+			Size:        limit,
+		})
+		if err != nil {
+			emitQueryError(w, cat, err)
+			return
+		}
 	}
 
 	stacFeatureCollection = STACFeatureCollection{}
@@ -291,6 +629,10 @@ func STACRouter(cat *geocatalogo.GeoCatalogue) *mux.Router {
 		STACAPIDescription(w, r, cat)
 	}).Methods("GET")
 
+	router.HandleFunc("/conformance", func(w http.ResponseWriter, r *http.Request) {
+		STACConformanceHandler(w, r, cat)
+	}).Methods("GET")
+
 	router.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
 		STACOpenAPI(w, r, cat)
 	}).Methods("GET")
@@ -299,10 +641,18 @@ func STACRouter(cat *geocatalogo.GeoCatalogue) *mux.Router {
 		STACCollections(w, r, cat)
 	}).Methods("GET")
 
-	router.HandleFunc("/collections{collectionId}", func(w http.ResponseWriter, r *http.Request) {
+	router.HandleFunc("/collections/{collectionId}", func(w http.ResponseWriter, r *http.Request) {
 		STACCollections(w, r, cat)
 	}).Methods("GET")
 
+	router.HandleFunc("/collections/{collectionId}/items", func(w http.ResponseWriter, r *http.Request) {
+		STACItems(w, r, cat)
+	}).Methods("GET")
+
+	router.HandleFunc("/collections/{collectionId}/items/{itemId}", func(w http.ResponseWriter, r *http.Request) {
+		STACItemHandler(w, r, cat)
+	}).Methods("GET")
+
 	router.HandleFunc("/stac/search", func(w http.ResponseWriter, r *http.Request) {
 		STACItems(w, r, cat)
 	}).Methods("GET", "POST", "OPTIONS")
@@ -311,34 +661,103 @@ func STACRouter(cat *geocatalogo.GeoCatalogue) *mux.Router {
 		STACItems(w, r, cat)
 	}).Methods("GET", "POST")
 
-	router.HandleFunc("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
-		STACItems(w, r, cat)
+	router.HandleFunc("/items/{itemId}", func(w http.ResponseWriter, r *http.Request) {
+		STACItemHandler(w, r, cat)
 	}).Methods("GET")
 
 	return router
 }
 
+// Collection2STACCollection generates a STAC Collection, with links to
+// itself, its items and the catalogue root
+func Collection2STACCollection(url string, c metadata.Collection) STACCollection {
+	sc := STACCollection{
+		Version:     VERSION,
+		Type:        "Collection",
+		Id:          c.Identifier,
+		Title:       c.Title,
+		Description: c.Description,
+		Keywords:    c.Keywords,
+		License:     c.License,
+		Providers:   c.Providers,
+		Extent:      c.Extent,
+		Summaries:   c.Summaries,
+	}
+	if sc.License == "" {
+		sc.License = "proprietary"
+	}
+	if sc.Extent.Spatial.BBox == nil {
+		sc.Extent.Spatial.BBox = [][4]float64{{-180, -90, 180, 90}}
+	}
+	if sc.Extent.Temporal.Interval == nil {
+		sc.Extent.Temporal.Interval = [][2]*time.Time{{nil, nil}}
+	}
+
+	href := fmt.Sprintf("%s/collections/%s", url, c.Identifier)
+	sc.Links = []Link{
+		{Rel: "self", Type: "application/json", Href: href},
+		{Rel: "items", Type: "application/geo+json", Href: href + "/items"},
+		{Rel: "parent", Type: "application/json", Href: fmt.Sprintf("%s/stac", url)},
+		{Rel: "root", Type: "application/json", Href: fmt.Sprintf("%s/stac", url)},
+	}
+	for _, link := range c.Links {
+		sc.Links = append(sc.Links, Link{Rel: "related", Type: link.Type, Title: link.Name, Href: link.URL})
+	}
+	return sc
+}
+
+// Record2STACItem generates a STAC Item, with links to itself, its
+// collection and the catalogue root
+func Record2STACItem(url string, rec *metadata.Record) STACItem {
+	si := STACItem{}
+	si.Type = "Feature"
+	si.Id = rec.Identifier
+	si.StacVersion = "0.8.0"
+	si.BBox = rec.Geometry.Bounds()
+	if rec.Geometry.IsEmpty() {
+		// e.g. records parsed from CSW or ISO bounding boxes
+		si.BBox = rec.BoundingBox
+	}
+	si.Geometry = rec.Geometry
+	//si.Datetime = rec.Properties.ProductInfo.AcquisitionDate
+	si.Properties = STACItemProperties{rec.Properties}
+	si.StacExtensions = rec.StacExtensions
+
+	root := fmt.Sprintf("%s/stac", url)
+	if collection := rec.Collection(); collection != "" {
+		collectionHref := fmt.Sprintf("%s/collections/%s", url, collection)
+		si.Links = []Link{
+			{Rel: "self", Type: "application/geo+json", Href: fmt.Sprintf("%s/items/%s", collectionHref, rec.Identifier)},
+			{Rel: "parent", Type: "application/json", Href: collectionHref},
+			{Rel: "collection", Type: "application/json", Href: collectionHref},
+			{Rel: "root", Type: "application/json", Href: root},
+		}
+	} else {
+		si.Links = []Link{
+			{Rel: "self", Type: "application/geo+json", Href: fmt.Sprintf("%s/items/%s", url, rec.Identifier)},
+			{Rel: "parent", Type: "application/json", Href: root},
+			{Rel: "root", Type: "application/json", Href: root},
+		}
+	}
+	for _, link := range rec.Links {
+		rel := link.Rel
+		if rel == "" {
+			rel = "alternate"
+		}
+		si.Links = append(si.Links, Link{Rel: rel, Type: link.Type, Title: link.Name, Href: link.URL})
+	}
+
+	si.Assets = make(map[string]Link)
+	for _, asset := range rec.Assets {
+		si.Assets[asset.Name] = Link{Type: asset.Type, Title: asset.Description, Href: asset.URL, Roles: asset.Roles}
+	}
+	return si
+}
+
 func Results2STACFeatureCollection(limit int, url string, r *search.Results, s *STACFeatureCollection) {
 	s.Type = "FeatureCollection"
 	for _, rec := range r.Records {
-		si := STACItem{}
-		si.Type = "Feature"
-		si.Id = rec.Identifier
-		si.StacVersion = "0.8.0"
-		si.BBox = rec.Geometry.Bounds()
-		si.Geometry = rec.Geometry
-		//si.Datetime = rec.Properties.ProductInfo.AcquisitionDate
-		//si.Collection = rec.Properties.ProductInfo.Collection
-		si.Properties = rec.Properties
-		for _, link := range rec.Links {
-			sil := Link{Rel: "self", Href: link.URL}
-			si.Links = append(si.Links, sil)
-		}
-		si.Assets = make(map[string]Link)
-		for _, asset := range rec.Assets {
-			si.Assets[asset.Name] = Link{Type: asset.Type, Href: asset.URL}
-		}
-		s.Features = append(s.Features, si)
+		s.Features = append(s.Features, Record2STACItem(url, &rec))
 	}
 	nextLink := Link{Rel: "next"}
 	nextLink.Href = fmt.Sprintf("%s/stac/search?next=%d", url, r.NextRecord)
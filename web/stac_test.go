@@ -0,0 +1,121 @@
+package web_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-spatial/geocatalogo/metadata"
+	"github.com/go-spatial/geocatalogo/web"
+)
+
+func testRecord(id string, collection string) metadata.Record {
+	var rec metadata.Record
+	rec.Identifier = id
+	rec.Type = "Feature"
+	rec.Properties.Title = "Record " + id
+	rec.Properties.Collection = collection
+	rec.Geometry = metadata.NewEnvelope([4]float64{-75, 45, -74, 46})
+	rec.BoundingBox = rec.Geometry.Bounds()
+	return rec
+}
+
+func TestSTACItem(t *testing.T) {
+	cat := newCatalogue(t, testRecord("rec-1", "landsat"), testRecord("rec-2", ""))
+	if err := cat.PutCollection(metadata.Collection{Identifier: "landsat", Title: "Landsat"}); err != nil {
+		t.Fatal(err)
+	}
+	router := web.STACRouter(cat)
+
+	tests := []struct {
+		path   string
+		status int
+		self   string
+	}{
+		{"/items/rec-1", 200, "http://localhost:8000/collections/landsat/items/rec-1"},
+		{"/items/rec-2", 200, "http://localhost:8000/items/rec-2"},
+		{"/collections/landsat/items/rec-1", 200, "http://localhost:8000/collections/landsat/items/rec-1"},
+		{"/items/missing", 404, ""},
+		{"/collections/landsat/items/rec-2", 404, ""},
+		{"/collections/sentinel/items/rec-1", 404, ""},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+		if w.Code != test.status {
+			t.Errorf("%s: expected %d, got %d: %s", test.path, test.status, w.Code, w.Body)
+			continue
+		}
+		if test.status != 200 {
+			var exception web.STACException
+			if err := json.Unmarshal(w.Body.Bytes(), &exception); err != nil || exception.Code != "NotFound" {
+				t.Errorf("%s: expected a NotFound exception, got %s", test.path, w.Body)
+			}
+			continue
+		}
+		var item web.STACItem
+		if err := json.Unmarshal(w.Body.Bytes(), &item); err != nil {
+			t.Fatal(err)
+		}
+		if len(item.Links) == 0 || item.Links[0].Rel != "self" || item.Links[0].Href != test.self {
+			t.Errorf("%s: expected self link %s, got %+v", test.path, test.self, item.Links)
+		}
+	}
+}
+
+func TestSTACCollectionNotFound(t *testing.T) {
+	cat := newCatalogue(t, testRecord("rec-1", "landsat"))
+	if err := cat.PutCollection(metadata.Collection{Identifier: "landsat", Title: "Landsat"}); err != nil {
+		t.Fatal(err)
+	}
+	router := web.STACRouter(cat)
+
+	for path, status := range map[string]int{
+		"/collections/landsat":        200,
+		"/collections/landsat/items":  200,
+		"/collections/sentinel":       404,
+		"/collections/sentinel/items": 404,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != status {
+			t.Errorf("%s: expected %d, got %d: %s", path, status, w.Code, w.Body)
+			continue
+		}
+		var exception web.STACException
+		if status == 404 && (json.Unmarshal(w.Body.Bytes(), &exception) != nil || exception.Description != "collection not found: sentinel") {
+			t.Errorf("%s: unexpected exception %s", path, w.Body)
+		}
+	}
+}
+
+func TestSTACSearchError(t *testing.T) {
+	cat := newCatalogue(t, testRecord("rec-1", "landsat"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w := httptest.NewRecorder()
+	web.STACRouter(cat).ServeHTTP(w, httptest.NewRequest("GET", "/stac/search?q=record", nil).WithContext(ctx))
+	var exception web.STACException
+	if w.Code != 500 || json.Unmarshal(w.Body.Bytes(), &exception) != nil || exception.Code != "ServerError" {
+		t.Errorf("expected a 500 ServerError for a failed query, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestSTACItemBoundingBox(t *testing.T) {
+	rec := testRecord("rec-1", "")
+	rec.Geometry = metadata.Geometry{}
+	rec.BoundingBox = [4]float64{-75, 45, -74, 46}
+	cat := newCatalogue(t, rec)
+
+	w := httptest.NewRecorder()
+	web.STACRouter(cat).ServeHTTP(w, httptest.NewRequest("GET", "/items/rec-1", nil))
+	var item web.STACItem
+	if err := json.Unmarshal(w.Body.Bytes(), &item); err != nil {
+		t.Fatal(err)
+	}
+	if item.BBox != rec.BoundingBox {
+		t.Errorf("expected bbox %v, got %v", rec.BoundingBox, item.BBox)
+	}
+}